@@ -68,6 +68,10 @@ func init() {
 		"NewInfoSelfServiceRemoveWebAuthn":                        text.NewInfoSelfServiceRemoveWebAuthn("{name}", aSecondAgo),
 		"NewErrorValidationVerificationFlowExpired":               text.NewErrorValidationVerificationFlowExpired(-time.Second),
 		"NewInfoSelfServiceVerificationSuccessful":                text.NewInfoSelfServiceVerificationSuccessful(),
+		"NewInfoSelfServiceVerificationRevertAddressChange":       text.NewInfoSelfServiceVerificationRevertAddressChange("{address}"),
+		"NewInfoNodeLabelRevertAddressChange":                     text.NewInfoNodeLabelRevertAddressChange(),
+		"NewInfoSelfServiceVerificationAcceptInvitation":          text.NewInfoSelfServiceVerificationAcceptInvitation("{address}"),
+		"NewInfoNodeLabelAcceptInvitation":                        text.NewInfoNodeLabelAcceptInvitation(),
 		"NewVerificationEmailSent":                                text.NewVerificationEmailSent(),
 		"NewErrorValidationVerificationTokenInvalidOrAlreadyUsed": text.NewErrorValidationVerificationTokenInvalidOrAlreadyUsed(),
 		"NewErrorValidationVerificationRetrySuccess":              text.NewErrorValidationVerificationRetrySuccess(),
@@ -97,6 +101,7 @@ func init() {
 		"NewInfoLoginLookup":                                      text.NewInfoLoginLookup(),
 		"NewInfoLoginVerify":                                      text.NewInfoLoginVerify(),
 		"NewInfoLoginWith":                                        text.NewInfoLoginWith("{provider}"),
+		"NewInfoLoginLinkCredentials":                             text.NewInfoLoginLinkCredentials("{provider}", "{identifier}"),
 		"NewErrorValidationLoginFlowExpired":                      text.NewErrorValidationLoginFlowExpired(time.Second),
 		"NewErrorValidationLoginNoStrategyFound":                  text.NewErrorValidationLoginNoStrategyFound(),
 		"NewErrorValidationRegistrationNoStrategyFound":           text.NewErrorValidationRegistrationNoStrategyFound(),
@@ -122,6 +127,23 @@ func init() {
 		"NewInfoSelfServiceContinueLoginWebAuthn":                 text.NewInfoSelfServiceContinueLoginWebAuthn(),
 		"NewInfoSelfServiceLoginContinue":                         text.NewInfoSelfServiceLoginContinue(),
 		"NewErrorValidationSuchNoWebAuthnUser":                    text.NewErrorValidationSuchNoWebAuthnUser(),
+		"NewErrorValidationPasswordMaxLength":                     text.NewErrorValidationPasswordMaxLength(1, 2),
+		"NewErrorValidationPasswordCharacterClasses":              text.NewErrorValidationPasswordCharacterClasses([]string{"{character_class}"}),
+		"NewErrorValidationPasswordReused":                        text.NewErrorValidationPasswordReused(1),
+		"NewInfoSelfServiceSettingsPasswordExpired":               text.NewInfoSelfServiceSettingsPasswordExpired(),
+		"NewInfoSelfServiceSettingsCredentialsResetRequired":      text.NewInfoSelfServiceSettingsCredentialsResetRequired("password"),
+		"NewErrorValidationNoLoginMethod":                         text.NewErrorValidationNoLoginMethod(),
+		"NewErrorValidationWebAuthnAuthenticatorNotAllowed":       text.NewErrorValidationWebAuthnAuthenticatorNotAllowed(),
+		"NewInfoLoginTrustDevice":                                 text.NewInfoLoginTrustDevice(720 * time.Hour),
+		"NewInfoSelfServiceSettingsMFAEnrollmentRequired":         text.NewInfoSelfServiceSettingsMFAEnrollmentRequired(),
+		"NewInfoSelfServiceRevokeTrustedDevice":                   text.NewInfoSelfServiceRevokeTrustedDevice("{user_agent}", aSecondAgo, inAMinute),
+		"NewInfoSelfServiceSettingsAccountDeletionConfirm":        text.NewInfoSelfServiceSettingsAccountDeletionConfirm(),
+		"NewInfoSelfServiceSettingsAccountDeletion":               text.NewInfoSelfServiceSettingsAccountDeletion(),
+		"NewErrorValidationAccountDeletionNotConfirmed":           text.NewErrorValidationAccountDeletionNotConfirmed(),
+		"NewInfoSelfServiceSettingsAddressChangePending":          text.NewInfoSelfServiceSettingsAddressChangePending("{address}"),
+		"NewErrorValidationTooManyAddressChanges":                 text.NewErrorValidationTooManyAddressChanges(),
+		"NewInfoSelfServiceRegistrationPendingApproval":           text.NewInfoSelfServiceRegistrationPendingApproval(),
+		"NewInfoSelfServiceSettingsInvitationAccepted":            text.NewInfoSelfServiceSettingsInvitationAccepted(),
 	}
 }
 
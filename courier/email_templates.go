@@ -24,12 +24,17 @@ type (
 )
 
 const (
-	TypeRecoveryInvalid     TemplateType = "recovery_invalid"
-	TypeRecoveryValid       TemplateType = "recovery_valid"
-	TypeVerificationInvalid TemplateType = "verification_invalid"
-	TypeVerificationValid   TemplateType = "verification_valid"
-	TypeOTP                 TemplateType = "otp"
-	TypeTestStub            TemplateType = "stub"
+	TypeRecoveryInvalid      TemplateType = "recovery_invalid"
+	TypeRecoveryValid        TemplateType = "recovery_valid"
+	TypeVerificationInvalid  TemplateType = "verification_invalid"
+	TypeVerificationValid    TemplateType = "verification_valid"
+	TypeAccountDeleted       TemplateType = "account_deleted"
+	TypeAddressChangeConfirm TemplateType = "address_change_confirm"
+	TypeAddressChangeNotice  TemplateType = "address_change_notice"
+	TypeSecurityNotification TemplateType = "security_notification"
+	TypeInvitation           TemplateType = "invitation"
+	TypeOTP                  TemplateType = "otp"
+	TypeTestStub             TemplateType = "stub"
 )
 
 func GetEmailTemplateType(t EmailTemplate) (TemplateType, error) {
@@ -42,6 +47,16 @@ func GetEmailTemplateType(t EmailTemplate) (TemplateType, error) {
 		return TypeVerificationInvalid, nil
 	case *email.VerificationValid:
 		return TypeVerificationValid, nil
+	case *email.AccountDeleted:
+		return TypeAccountDeleted, nil
+	case *email.AddressChangeConfirm:
+		return TypeAddressChangeConfirm, nil
+	case *email.AddressChangeNotice:
+		return TypeAddressChangeNotice, nil
+	case *email.SecurityNotification:
+		return TypeSecurityNotification, nil
+	case *email.Invitation:
+		return TypeInvitation, nil
 	case *email.TestStub:
 		return TypeTestStub, nil
 	default:
@@ -75,6 +90,36 @@ func NewEmailTemplateFromMessage(d template.Dependencies, msg Message) (EmailTem
 			return nil, err
 		}
 		return email.NewVerificationValid(d, &t), nil
+	case TypeAccountDeleted:
+		var t email.AccountDeletedModel
+		if err := json.Unmarshal(msg.TemplateData, &t); err != nil {
+			return nil, err
+		}
+		return email.NewAccountDeleted(d, &t), nil
+	case TypeAddressChangeConfirm:
+		var t email.AddressChangeConfirmModel
+		if err := json.Unmarshal(msg.TemplateData, &t); err != nil {
+			return nil, err
+		}
+		return email.NewAddressChangeConfirm(d, &t), nil
+	case TypeAddressChangeNotice:
+		var t email.AddressChangeNoticeModel
+		if err := json.Unmarshal(msg.TemplateData, &t); err != nil {
+			return nil, err
+		}
+		return email.NewAddressChangeNotice(d, &t), nil
+	case TypeSecurityNotification:
+		var t email.SecurityNotificationModel
+		if err := json.Unmarshal(msg.TemplateData, &t); err != nil {
+			return nil, err
+		}
+		return email.NewSecurityNotification(d, &t), nil
+	case TypeInvitation:
+		var t email.InvitationModel
+		if err := json.Unmarshal(msg.TemplateData, &t); err != nil {
+			return nil, err
+		}
+		return email.NewInvitation(d, &t), nil
 	case TypeTestStub:
 		var t email.TestStubModel
 		if err := json.Unmarshal(msg.TemplateData, &t); err != nil {
@@ -15,11 +15,16 @@ import (
 
 func TestGetTemplateType(t *testing.T) {
 	for expectedType, tmpl := range map[courier.TemplateType]courier.EmailTemplate{
-		courier.TypeRecoveryInvalid:     &email.RecoveryInvalid{},
-		courier.TypeRecoveryValid:       &email.RecoveryValid{},
-		courier.TypeVerificationInvalid: &email.VerificationInvalid{},
-		courier.TypeVerificationValid:   &email.VerificationValid{},
-		courier.TypeTestStub:            &email.TestStub{},
+		courier.TypeRecoveryInvalid:      &email.RecoveryInvalid{},
+		courier.TypeRecoveryValid:        &email.RecoveryValid{},
+		courier.TypeVerificationInvalid:  &email.VerificationInvalid{},
+		courier.TypeVerificationValid:    &email.VerificationValid{},
+		courier.TypeAccountDeleted:       &email.AccountDeleted{},
+		courier.TypeAddressChangeConfirm: &email.AddressChangeConfirm{},
+		courier.TypeAddressChangeNotice:  &email.AddressChangeNotice{},
+		courier.TypeSecurityNotification: &email.SecurityNotification{},
+		courier.TypeInvitation:           &email.Invitation{},
+		courier.TypeTestStub:             &email.TestStub{},
 	} {
 		t.Run(fmt.Sprintf("case=%s", expectedType), func(t *testing.T) {
 			actualType, err := courier.GetEmailTemplateType(tmpl)
@@ -34,11 +39,16 @@ func TestNewEmailTemplateFromMessage(t *testing.T) {
 	ctx := context.Background()
 
 	for tmplType, expectedTmpl := range map[courier.TemplateType]courier.EmailTemplate{
-		courier.TypeRecoveryInvalid:     email.NewRecoveryInvalid(reg, &email.RecoveryInvalidModel{To: "foo"}),
-		courier.TypeRecoveryValid:       email.NewRecoveryValid(reg, &email.RecoveryValidModel{To: "bar", RecoveryURL: "http://foo.bar"}),
-		courier.TypeVerificationInvalid: email.NewVerificationInvalid(reg, &email.VerificationInvalidModel{To: "baz"}),
-		courier.TypeVerificationValid:   email.NewVerificationValid(reg, &email.VerificationValidModel{To: "faz", VerificationURL: "http://bar.foo"}),
-		courier.TypeTestStub:            email.NewTestStub(reg, &email.TestStubModel{To: "far", Subject: "test subject", Body: "test body"}),
+		courier.TypeRecoveryInvalid:      email.NewRecoveryInvalid(reg, &email.RecoveryInvalidModel{To: "foo"}),
+		courier.TypeRecoveryValid:        email.NewRecoveryValid(reg, &email.RecoveryValidModel{To: "bar", RecoveryURL: "http://foo.bar"}),
+		courier.TypeVerificationInvalid:  email.NewVerificationInvalid(reg, &email.VerificationInvalidModel{To: "baz"}),
+		courier.TypeVerificationValid:    email.NewVerificationValid(reg, &email.VerificationValidModel{To: "faz", VerificationURL: "http://bar.foo"}),
+		courier.TypeAccountDeleted:       email.NewAccountDeleted(reg, &email.AccountDeletedModel{To: "boo"}),
+		courier.TypeAddressChangeConfirm: email.NewAddressChangeConfirm(reg, &email.AddressChangeConfirmModel{To: "new", VerificationURL: "http://foo.bar"}),
+		courier.TypeAddressChangeNotice:  email.NewAddressChangeNotice(reg, &email.AddressChangeNoticeModel{To: "old", NewAddress: "new", RevertURL: "http://bar.foo"}),
+		courier.TypeSecurityNotification: email.NewSecurityNotification(reg, &email.SecurityNotificationModel{To: "qux", Event: email.SecurityEventPasswordChanged}),
+		courier.TypeInvitation:           email.NewInvitation(reg, &email.InvitationModel{To: "quux", InvitationURL: "http://foo.bar"}),
+		courier.TypeTestStub:             email.NewTestStub(reg, &email.TestStubModel{To: "far", Subject: "test subject", Body: "test body"}),
 	} {
 		t.Run(fmt.Sprintf("case=%s", tmplType), func(t *testing.T) {
 			tmplData, err := json.Marshal(expectedTmpl)
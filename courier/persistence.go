@@ -24,6 +24,9 @@ type (
 		// ListMessages lists all messages in the store given the page, itemsPerPage, status and recipient.
 		// Returns list of messages, total count of messages satisfied by given filter, and error if any
 		ListMessages(ctx context.Context, filter MessagesFilter) ([]Message, int64, error)
+
+		// DeleteMessagesByRecipient removes all messages sent or queued to the given recipient.
+		DeleteMessagesByRecipient(ctx context.Context, recipient string) error
 	}
 	PersistenceProvider interface {
 		CourierPersister() Persister
@@ -0,0 +1,46 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/ory/kratos/courier/template"
+)
+
+type (
+	AccountDeleted struct {
+		d template.Dependencies
+		m *AccountDeletedModel
+	}
+	AccountDeletedModel struct {
+		To string
+	}
+)
+
+func NewAccountDeleted(d template.Dependencies, m *AccountDeletedModel) *AccountDeleted {
+	return &AccountDeleted{d: d, m: m}
+}
+
+func (t *AccountDeleted) EmailRecipient() (string, error) {
+	return t.m.To, nil
+}
+
+func (t *AccountDeleted) EmailSubject(ctx context.Context) (string, error) {
+	subject, err := template.LoadText(ctx, t.d, os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)), "account_deleted/email.subject.gotmpl", "account_deleted/email.subject*", t.m, t.d.CourierConfig().CourierTemplatesAccountDeleted(ctx).Subject)
+
+	return strings.TrimSpace(subject), err
+}
+
+func (t *AccountDeleted) EmailBody(ctx context.Context) (string, error) {
+	return template.LoadHTML(ctx, t.d, os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)), "account_deleted/email.body.gotmpl", "account_deleted/email.body*", t.m, t.d.CourierConfig().CourierTemplatesAccountDeleted(ctx).Body.HTML)
+}
+
+func (t *AccountDeleted) EmailBodyPlaintext(ctx context.Context) (string, error) {
+	return template.LoadText(ctx, t.d, os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)), "account_deleted/email.body.plaintext.gotmpl", "account_deleted/email.body.plaintext*", t.m, t.d.CourierConfig().CourierTemplatesAccountDeleted(ctx).Body.PlainText)
+}
+
+func (t *AccountDeleted) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.m)
+}
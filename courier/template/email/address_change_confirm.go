@@ -0,0 +1,48 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/ory/kratos/courier/template"
+)
+
+type (
+	AddressChangeConfirm struct {
+		d template.Dependencies
+		m *AddressChangeConfirmModel
+	}
+	AddressChangeConfirmModel struct {
+		To              string
+		VerificationURL string
+		Identity        map[string]interface{}
+	}
+)
+
+func NewAddressChangeConfirm(d template.Dependencies, m *AddressChangeConfirmModel) *AddressChangeConfirm {
+	return &AddressChangeConfirm{d: d, m: m}
+}
+
+func (t *AddressChangeConfirm) EmailRecipient() (string, error) {
+	return t.m.To, nil
+}
+
+func (t *AddressChangeConfirm) EmailSubject(ctx context.Context) (string, error) {
+	subject, err := template.LoadText(ctx, t.d, os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)), "address_change/confirm/email.subject.gotmpl", "address_change/confirm/email.subject*", t.m, t.d.CourierConfig().CourierTemplatesAddressChangeConfirm(ctx).Subject)
+
+	return strings.TrimSpace(subject), err
+}
+
+func (t *AddressChangeConfirm) EmailBody(ctx context.Context) (string, error) {
+	return template.LoadHTML(ctx, t.d, os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)), "address_change/confirm/email.body.gotmpl", "address_change/confirm/email.body*", t.m, t.d.CourierConfig().CourierTemplatesAddressChangeConfirm(ctx).Body.HTML)
+}
+
+func (t *AddressChangeConfirm) EmailBodyPlaintext(ctx context.Context) (string, error) {
+	return template.LoadText(ctx, t.d, os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)), "address_change/confirm/email.body.plaintext.gotmpl", "address_change/confirm/email.body.plaintext*", t.m, t.d.CourierConfig().CourierTemplatesAddressChangeConfirm(ctx).Body.PlainText)
+}
+
+func (t *AddressChangeConfirm) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.m)
+}
@@ -0,0 +1,49 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/ory/kratos/courier/template"
+)
+
+type (
+	AddressChangeNotice struct {
+		d template.Dependencies
+		m *AddressChangeNoticeModel
+	}
+	AddressChangeNoticeModel struct {
+		To         string
+		NewAddress string
+		RevertURL  string
+		Identity   map[string]interface{}
+	}
+)
+
+func NewAddressChangeNotice(d template.Dependencies, m *AddressChangeNoticeModel) *AddressChangeNotice {
+	return &AddressChangeNotice{d: d, m: m}
+}
+
+func (t *AddressChangeNotice) EmailRecipient() (string, error) {
+	return t.m.To, nil
+}
+
+func (t *AddressChangeNotice) EmailSubject(ctx context.Context) (string, error) {
+	subject, err := template.LoadText(ctx, t.d, os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)), "address_change/notice/email.subject.gotmpl", "address_change/notice/email.subject*", t.m, t.d.CourierConfig().CourierTemplatesAddressChangeNotice(ctx).Subject)
+
+	return strings.TrimSpace(subject), err
+}
+
+func (t *AddressChangeNotice) EmailBody(ctx context.Context) (string, error) {
+	return template.LoadHTML(ctx, t.d, os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)), "address_change/notice/email.body.gotmpl", "address_change/notice/email.body*", t.m, t.d.CourierConfig().CourierTemplatesAddressChangeNotice(ctx).Body.HTML)
+}
+
+func (t *AddressChangeNotice) EmailBodyPlaintext(ctx context.Context) (string, error) {
+	return template.LoadText(ctx, t.d, os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)), "address_change/notice/email.body.plaintext.gotmpl", "address_change/notice/email.body.plaintext*", t.m, t.d.CourierConfig().CourierTemplatesAddressChangeNotice(ctx).Body.PlainText)
+}
+
+func (t *AddressChangeNotice) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.m)
+}
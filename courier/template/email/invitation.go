@@ -0,0 +1,48 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/ory/kratos/courier/template"
+)
+
+type (
+	Invitation struct {
+		d template.Dependencies
+		m *InvitationModel
+	}
+	InvitationModel struct {
+		To            string
+		InvitationURL string
+		Identity      map[string]interface{}
+	}
+)
+
+func NewInvitation(d template.Dependencies, m *InvitationModel) *Invitation {
+	return &Invitation{d: d, m: m}
+}
+
+func (t *Invitation) EmailRecipient() (string, error) {
+	return t.m.To, nil
+}
+
+func (t *Invitation) EmailSubject(ctx context.Context) (string, error) {
+	subject, err := template.LoadText(ctx, t.d, os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)), "invitation/email.subject.gotmpl", "invitation/email.subject*", t.m, t.d.CourierConfig().CourierTemplatesInvitation(ctx).Subject)
+
+	return strings.TrimSpace(subject), err
+}
+
+func (t *Invitation) EmailBody(ctx context.Context) (string, error) {
+	return template.LoadHTML(ctx, t.d, os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)), "invitation/email.body.gotmpl", "invitation/email.body*", t.m, t.d.CourierConfig().CourierTemplatesInvitation(ctx).Body.HTML)
+}
+
+func (t *Invitation) EmailBodyPlaintext(ctx context.Context) (string, error) {
+	return template.LoadText(ctx, t.d, os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)), "invitation/email.body.plaintext.gotmpl", "invitation/email.body.plaintext*", t.m, t.d.CourierConfig().CourierTemplatesInvitation(ctx).Body.PlainText)
+}
+
+func (t *Invitation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.m)
+}
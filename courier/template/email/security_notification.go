@@ -0,0 +1,57 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/ory/kratos/courier/template"
+)
+
+const (
+	SecurityEventPasswordChanged     = "password_changed"
+	SecurityEventSecondFactorAdded   = "second_factor_added"
+	SecurityEventSecondFactorRemoved = "second_factor_removed"
+	SecurityEventRecoveryCompleted   = "recovery_completed"
+	SecurityEventLoginNewDevice      = "login_new_device"
+)
+
+type (
+	SecurityNotification struct {
+		d template.Dependencies
+		m *SecurityNotificationModel
+	}
+	SecurityNotificationModel struct {
+		To        string
+		Event     string
+		UserAgent string
+		Identity  map[string]interface{}
+	}
+)
+
+func NewSecurityNotification(d template.Dependencies, m *SecurityNotificationModel) *SecurityNotification {
+	return &SecurityNotification{d: d, m: m}
+}
+
+func (t *SecurityNotification) EmailRecipient() (string, error) {
+	return t.m.To, nil
+}
+
+func (t *SecurityNotification) EmailSubject(ctx context.Context) (string, error) {
+	subject, err := template.LoadText(ctx, t.d, os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)), "security_notification/email.subject.gotmpl", "security_notification/email.subject*", t.m, t.d.CourierConfig().CourierTemplatesSecurityNotification(ctx).Subject)
+
+	return strings.TrimSpace(subject), err
+}
+
+func (t *SecurityNotification) EmailBody(ctx context.Context) (string, error) {
+	return template.LoadHTML(ctx, t.d, os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)), "security_notification/email.body.gotmpl", "security_notification/email.body*", t.m, t.d.CourierConfig().CourierTemplatesSecurityNotification(ctx).Body.HTML)
+}
+
+func (t *SecurityNotification) EmailBodyPlaintext(ctx context.Context) (string, error) {
+	return template.LoadText(ctx, t.d, os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)), "security_notification/email.body.plaintext.gotmpl", "security_notification/email.body.plaintext*", t.m, t.d.CourierConfig().CourierTemplatesSecurityNotification(ctx).Body.PlainText)
+}
+
+func (t *SecurityNotification) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.m)
+}
@@ -16,6 +16,11 @@ type (
 		CourierTemplatesVerificationValid() *config.CourierEmailTemplate
 		CourierTemplatesRecoveryInvalid() *config.CourierEmailTemplate
 		CourierTemplatesRecoveryValid() *config.CourierEmailTemplate
+		CourierTemplatesAccountDeleted() *config.CourierEmailTemplate
+		CourierTemplatesAddressChangeConfirm() *config.CourierEmailTemplate
+		CourierTemplatesAddressChangeNotice() *config.CourierEmailTemplate
+		CourierTemplatesSecurityNotification() *config.CourierEmailTemplate
+		CourierTemplatesInvitation() *config.CourierEmailTemplate
 	}
 
 	Dependencies interface {
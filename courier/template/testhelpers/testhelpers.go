@@ -72,6 +72,16 @@ func TestRemoteTemplates(t *testing.T, basePath string, tmplType courier.Templat
 			return email.NewVerificationInvalid(d, &email.VerificationInvalidModel{})
 		case courier.TypeVerificationValid:
 			return email.NewVerificationValid(d, &email.VerificationValidModel{})
+		case courier.TypeAccountDeleted:
+			return email.NewAccountDeleted(d, &email.AccountDeletedModel{})
+		case courier.TypeAddressChangeConfirm:
+			return email.NewAddressChangeConfirm(d, &email.AddressChangeConfirmModel{})
+		case courier.TypeAddressChangeNotice:
+			return email.NewAddressChangeNotice(d, &email.AddressChangeNoticeModel{})
+		case courier.TypeSecurityNotification:
+			return email.NewSecurityNotification(d, &email.SecurityNotificationModel{})
+		case courier.TypeInvitation:
+			return email.NewInvitation(d, &email.InvitationModel{})
 		default:
 			return nil
 		}
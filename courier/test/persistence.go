@@ -173,5 +173,31 @@ func TestPersister(ctx context.Context, newNetworkUnlessExisting NetworkWrapper,
 				require.ErrorIs(t, err, sqlcon.ErrNoRows)
 			})
 		})
+
+		t.Run("case=delete messages by recipient", func(t *testing.T) {
+			recipient := x.NewUUID().String() + "@ory.sh"
+			filter := courier.MessagesFilter{
+				Recipient:        recipient,
+				PaginationParams: x.PaginationParams{Page: 1, PerPage: 100},
+			}
+
+			_, other := newNetwork(t, ctx)
+			for _, p := range []PersisterWrapper{p, other} {
+				var m courier.Message
+				require.NoError(t, faker.FakeData(&m))
+				m.Recipient = recipient
+				require.NoError(t, p.AddMessage(ctx, &m))
+			}
+
+			require.NoError(t, p.DeleteMessagesByRecipient(ctx, recipient))
+
+			_, tc, err := p.ListMessages(ctx, filter)
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), tc)
+
+			_, tc, err = other.ListMessages(ctx, filter)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), tc, "messages on other networks must be kept")
+		})
 	}
 }
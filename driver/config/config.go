@@ -26,6 +26,7 @@ import (
 	"github.com/duo-labs/webauthn/protocol"
 
 	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/pquerna/otp"
 
 	"github.com/ory/x/jsonschemax"
 
@@ -69,6 +70,11 @@ const (
 	ViperKeyCourierTemplatesRecoveryValidEmail               = "courier.templates.recovery.valid.email"
 	ViperKeyCourierTemplatesVerificationInvalidEmail         = "courier.templates.verification.invalid.email"
 	ViperKeyCourierTemplatesVerificationValidEmail           = "courier.templates.verification.valid.email"
+	ViperKeyCourierTemplatesAccountDeletedEmail              = "courier.templates.account_deleted.email"
+	ViperKeyCourierTemplatesAddressChangeConfirmEmail        = "courier.templates.address_change.confirm.email"
+	ViperKeyCourierTemplatesAddressChangeNoticeEmail         = "courier.templates.address_change.notice.email"
+	ViperKeyCourierTemplatesSecurityNotificationEmail        = "courier.templates.security_notification.email"
+	ViperKeyCourierTemplatesInvitationEmail                  = "courier.templates.invitation.email"
 	ViperKeyCourierSMTPFrom                                  = "courier.smtp.from_address"
 	ViperKeyCourierSMTPFromName                              = "courier.smtp.from_name"
 	ViperKeyCourierSMTPHeaders                               = "courier.smtp.headers"
@@ -125,6 +131,9 @@ const (
 	ViperKeySelfServiceLoginRequestLifespan                  = "selfservice.flows.login.lifespan"
 	ViperKeySelfServiceLoginAfter                            = "selfservice.flows.login.after"
 	ViperKeySelfServiceLoginBeforeHooks                      = "selfservice.flows.login.before.hooks"
+	ViperKeySelfServiceLoginStyle                            = "selfservice.flows.login.style"
+	ViperKeySelfServiceLoginConcealAccountExistence          = "selfservice.flows.login.identifier_first.conceal_account_existence"
+	ViperKeySelfServiceLoginHomeRealmDiscovery               = "selfservice.flows.login.identifier_first.home_realm_discovery"
 	ViperKeySelfServiceErrorUI                               = "selfservice.flows.error.ui_url"
 	ViperKeySelfServiceLogoutBrowserDefaultReturnTo          = "selfservice.flows.logout.after." + DefaultBrowserReturnURL
 	ViperKeySelfServiceSettingsURL                           = "selfservice.flows.settings.ui_url"
@@ -133,6 +142,11 @@ const (
 	ViperKeySelfServiceSettingsRequestLifespan               = "selfservice.flows.settings.lifespan"
 	ViperKeySelfServiceSettingsPrivilegedAuthenticationAfter = "selfservice.flows.settings.privileged_session_max_age"
 	ViperKeySelfServiceSettingsRequiredAAL                   = "selfservice.flows.settings.required_aal"
+	ViperKeySelfServiceSettingsMFAEnrollmentEnabled          = "selfservice.flows.settings.mfa_enrollment.enabled"
+	ViperKeySelfServiceSettingsMFAEnrollmentSchemas          = "selfservice.flows.settings.mfa_enrollment.schemas"
+	ViperKeySelfServiceSettingsMFAEnrollmentTrait            = "selfservice.flows.settings.mfa_enrollment.trait"
+	ViperKeySelfServiceSettingsAccountDeletionBeforeHooks    = "selfservice.flows.settings.account_deletion.before.hooks"
+	ViperKeySelfServiceSettingsAccountDeletionAfter          = "selfservice.flows.settings.account_deletion.after"
 	ViperKeySelfServiceRecoveryAfter                         = "selfservice.flows.recovery.after"
 	ViperKeySelfServiceRecoveryBeforeHooks                   = "selfservice.flows.recovery.before.hooks"
 	ViperKeySelfServiceRecoveryEnabled                       = "selfservice.flows.recovery.enabled"
@@ -162,21 +176,47 @@ const (
 	ViperKeyDatabaseCleanupBatchSize                         = "database.cleanup.batch_size"
 	ViperKeyLinkLifespan                                     = "selfservice.methods.link.config.lifespan"
 	ViperKeyLinkBaseURL                                      = "selfservice.methods.link.config.base_url"
+	ViperKeyLinkInvitationLifespan                           = "selfservice.methods.link.config.invitation_lifespan"
 	ViperKeyPasswordHaveIBeenPwnedHost                       = "selfservice.methods.password.config.haveibeenpwned_host"
 	ViperKeyPasswordHaveIBeenPwnedEnabled                    = "selfservice.methods.password.config.haveibeenpwned_enabled"
+	ViperKeyPasswordHaveIBeenPwnedSource                     = "selfservice.methods.password.config.haveibeenpwned_source"
+	ViperKeyPasswordHaveIBeenPwnedPath                       = "selfservice.methods.password.config.haveibeenpwned_path"
+	ViperKeyPasswordDenyListPath                             = "selfservice.methods.password.config.deny_list_path"
 	ViperKeyPasswordMaxBreaches                              = "selfservice.methods.password.config.max_breaches"
 	ViperKeyPasswordMinLength                                = "selfservice.methods.password.config.min_password_length"
+	ViperKeyPasswordMaxLength                                = "selfservice.methods.password.config.max_password_length"
+	ViperKeyPasswordRequiredCharacterClasses                 = "selfservice.methods.password.config.required_character_classes"
+	ViperKeyPasswordHistorySize                              = "selfservice.methods.password.config.password_history_size"
+	ViperKeyPasswordMaxAge                                   = "selfservice.methods.password.config.max_password_age"
 	ViperKeyPasswordIdentifierSimilarityCheckEnabled         = "selfservice.methods.password.config.identifier_similarity_check_enabled"
 	ViperKeyIgnoreNetworkErrors                              = "selfservice.methods.password.config.ignore_network_errors"
 	ViperKeyTOTPIssuer                                       = "selfservice.methods.totp.config.issuer"
+	ViperKeyTOTPDigits                                       = "selfservice.methods.totp.config.digits"
+	ViperKeyTOTPPeriod                                       = "selfservice.methods.totp.config.period"
+	ViperKeyTOTPAlgorithm                                    = "selfservice.methods.totp.config.algorithm"
+	ViperKeyTOTPSkew                                         = "selfservice.methods.totp.config.skew"
+	ViperKeyTrustedDeviceLifespan                            = "selfservice.methods.trusted_device.config.lifespan"
+	ViperKeyAccountDeletionSendConfirmationEmail             = "selfservice.methods.account_deletion.config.send_confirmation_email"
+	ViperKeyProfileVerifyAddressChanges                      = "selfservice.methods.profile.config.verify_address_changes"
+	ViperKeyProfileAddressChangeRevertLifespan               = "selfservice.methods.profile.config.revert_lifespan"
 	ViperKeyOIDCBaseRedirectURL                              = "selfservice.methods.oidc.config.base_redirect_uri"
 	ViperKeyWebAuthnRPDisplayName                            = "selfservice.methods.webauthn.config.rp.display_name"
 	ViperKeyWebAuthnRPID                                     = "selfservice.methods.webauthn.config.rp.id"
 	ViperKeyWebAuthnRPOrigin                                 = "selfservice.methods.webauthn.config.rp.origin"
+	ViperKeyWebAuthnRPOrigins                                = "selfservice.methods.webauthn.config.rp.origins"
+	ViperKeyWebAuthnRPHosts                                  = "selfservice.methods.webauthn.config.rp.hosts"
 	ViperKeyWebAuthnRPIcon                                   = "selfservice.methods.webauthn.config.rp.issuer"
 	ViperKeyWebAuthnPasswordless                             = "selfservice.methods.webauthn.config.passwordless"
+	ViperKeyWebAuthnUserVerification                         = "selfservice.methods.webauthn.config.user_verification"
+	ViperKeyWebAuthnAttestationConveyance                    = "selfservice.methods.webauthn.config.attestation.conveyance"
+	ViperKeyWebAuthnAttestationRequired                      = "selfservice.methods.webauthn.config.attestation.required"
+	ViperKeyWebAuthnAttestationMetadataPath                  = "selfservice.methods.webauthn.config.attestation.metadata_path"
+	ViperKeyWebAuthnAttestationMetadataRootCertificatePath   = "selfservice.methods.webauthn.config.attestation.metadata_root_certificate_path"
+	ViperKeyWebAuthnAuthenticatorsAllow                      = "selfservice.methods.webauthn.config.authenticators.allow"
+	ViperKeyWebAuthnAuthenticatorsDeny                       = "selfservice.methods.webauthn.config.authenticators.deny"
 	ViperKeyClientHTTPNoPrivateIPRanges                      = "clients.http.disallow_private_ip_ranges"
 	ViperKeyClientHTTPPrivateIPExceptionURLs                 = "clients.http.private_ip_exception_urls"
+	ViperKeyOAuth2ProviderURL                                = "oauth2_provider.url"
 	ViperKeyVersion                                          = "version"
 )
 
@@ -192,6 +232,23 @@ const (
 	BcryptDefaultCost            uint32 = 12
 )
 
+const (
+	PasswordBreachSourceAPI  = "api"
+	PasswordBreachSourceFile = "file"
+)
+
+const (
+	LoginStyleUnified         = "unified"
+	LoginStyleIdentifierFirst = "identifier_first"
+)
+
+const (
+	PasswordCharacterClassLowercase = "lowercase"
+	PasswordCharacterClassUppercase = "uppercase"
+	PasswordCharacterClassDigits    = "digits"
+	PasswordCharacterClassSymbols   = "symbols"
+)
+
 // DefaultSessionCookieName returns the default cookie name for the kratos session.
 const DefaultSessionCookieName = "ory_kratos_session"
 
@@ -221,13 +278,65 @@ type (
 		ID  string `json:"id" koanf:"id"`
 		URL string `json:"url" koanf:"url"`
 	}
+	HomeRealm struct {
+		Domain   string `json:"domain" koanf:"domain"`
+		Provider string `json:"provider" koanf:"provider"`
+	}
+	WebAuthnRPHost struct {
+		Host string `json:"host" koanf:"host"`
+		ID   string `json:"id" koanf:"id"`
+	}
+	WebAuthnAttestationPolicy struct {
+		// Required rejects credentials which were registered without an attestation statement.
+		Required bool `json:"required"`
+
+		// MetadataPath is the path to a FIDO Metadata Service (MDS) blob. If set, only authenticators which
+		// are listed in the blob and have no compromised status are accepted.
+		MetadataPath string `json:"metadata_path"`
+
+		// MetadataRootCertificatePath is the path to the PEM encoded root certificate which the signature of the
+		// FIDO Metadata Service blob must chain to.
+		MetadataRootCertificatePath string `json:"metadata_root_certificate_path"`
+
+		// AllowedAAGUIDs lists the authenticator models which may be registered. If empty, all models are allowed.
+		AllowedAAGUIDs []string `json:"allowed_aaguids"`
+
+		// DeniedAAGUIDs lists the authenticator models which must not be registered.
+		DeniedAAGUIDs []string `json:"denied_aaguids"`
+	}
 	PasswordPolicy struct {
 		HaveIBeenPwnedHost               string `json:"haveibeenpwned_host"`
 		HaveIBeenPwnedEnabled            bool   `json:"haveibeenpwned_enabled"`
+		HaveIBeenPwnedSource             string `json:"haveibeenpwned_source"`
+		HaveIBeenPwnedPath               string `json:"haveibeenpwned_path"`
+		DenyListPath                     string `json:"deny_list_path"`
 		MaxBreaches                      uint   `json:"max_breaches"`
 		IgnoreNetworkErrors              bool   `json:"ignore_network_errors"`
 		MinPasswordLength                uint   `json:"min_password_length"`
 		IdentifierSimilarityCheckEnabled bool   `json:"identifier_similarity_check_enabled"`
+
+		// MaxPasswordLength is the maximum length of a password. Zero means that the length is not limited.
+		MaxPasswordLength uint `json:"max_password_length"`
+
+		// RequiredCharacterClasses lists the character classes (see PasswordCharacterClass*) of which
+		// a password must contain at least one character.
+		RequiredCharacterClasses []string `json:"required_character_classes"`
+
+		// PasswordHistorySize is the number of most recent passwords which may not be reused.
+		PasswordHistorySize uint `json:"password_history_size"`
+
+		// MaxPasswordAge is the duration after which a password must be changed. Zero disables password expiry.
+		MaxPasswordAge time.Duration `json:"max_password_age"`
+	}
+	MFAEnrollmentPolicy struct {
+		// Enabled requires identities without a second factor to enroll one before their session can be used.
+		Enabled bool `json:"enabled"`
+
+		// Schemas limits the policy to identities of these identity schemas. If empty, all identities are affected.
+		Schemas []string `json:"schemas"`
+
+		// Trait limits the policy to identities for which the trait at this path (e.g. `mfa_required`) is true.
+		Trait string `json:"trait"`
 	}
 	Schemas                  []Schema
 	CourierEmailBodyTemplate struct {
@@ -264,6 +373,11 @@ type (
 		CourierTemplatesVerificationValid(ctx context.Context) *CourierEmailTemplate
 		CourierTemplatesRecoveryInvalid(ctx context.Context) *CourierEmailTemplate
 		CourierTemplatesRecoveryValid(ctx context.Context) *CourierEmailTemplate
+		CourierTemplatesAccountDeleted(ctx context.Context) *CourierEmailTemplate
+		CourierTemplatesAddressChangeConfirm(ctx context.Context) *CourierEmailTemplate
+		CourierTemplatesAddressChangeNotice(ctx context.Context) *CourierEmailTemplate
+		CourierTemplatesSecurityNotification(ctx context.Context) *CourierEmailTemplate
+		CourierTemplatesInvitation(ctx context.Context) *CourierEmailTemplate
 		CourierMessageRetries(ctx context.Context) int
 	}
 )
@@ -338,6 +452,10 @@ func New(ctx context.Context, l *logrusx.Logger, stdOutOrErr io.Writer, opts ...
 				l.WithError(err).
 					Errorf("The changed identity schema configuration is invalid and could not be loaded. Rolling back to the last working configuration revision. Please address the validation errors before restarting the process.")
 			}
+			if err := c.validateSelfServiceHooks(ctx); err != nil {
+				l.WithError(err).
+					Errorf("The changed self-service hook configuration is invalid. Please address the validation errors before restarting the process.")
+			}
 		}),
 	}, opts...)
 
@@ -354,6 +472,9 @@ func New(ctx context.Context, l *logrusx.Logger, stdOutOrErr io.Writer, opts ...
 		if err := c.validateIdentitySchemas(ctx); err != nil {
 			return nil, err
 		}
+		if err := c.validateSelfServiceHooks(ctx); err != nil {
+			return nil, err
+		}
 	}
 
 	return c, nil
@@ -380,6 +501,35 @@ func (p *Config) getIdentitySchemaValidator(ctx context.Context) (*jsonschema.Sc
 	return p.identityMetaSchema, nil
 }
 
+// validateSelfServiceHooks checks the order of the registration hooks. The require_approval hook has to run before
+// the session hook, because otherwise identities which await approval are signed in.
+func (p *Config) validateSelfServiceHooks(ctx context.Context) error {
+	strategies := []string{HookGlobal}
+	if after, ok := p.GetProvider(ctx).Get(ViperKeySelfServiceRegistrationAfter).(map[string]interface{}); ok {
+		for strategy := range after {
+			if strategy != "hooks" && strategy != "default_browser_return_url" {
+				strategies = append(strategies, strategy)
+			}
+		}
+	}
+
+	for _, strategy := range strategies {
+		var session bool
+		for _, h := range p.SelfServiceFlowRegistrationAfterHooks(ctx, strategy) {
+			switch h.Name {
+			case "session":
+				session = true
+			case "require_approval":
+				if session {
+					return errors.Errorf("the require_approval hook must be configured before the session hook in %s", HookStrategyKey(ViperKeySelfServiceRegistrationAfter, strategy))
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 type validateIdentitySchemasContextKey int
 
 const validateIdentitySchemasClientKey validateIdentitySchemasContextKey = 1
@@ -540,6 +690,55 @@ func (p *Config) TOTPIssuer(ctx context.Context) string {
 	return p.GetProvider(ctx).StringF(ViperKeyTOTPIssuer, p.SelfPublicURL(ctx).Hostname())
 }
 
+// TOTPDigits returns the number of digits of newly enrolled TOTP devices.
+func (p *Config) TOTPDigits(ctx context.Context) otp.Digits {
+	return otp.Digits(p.GetProvider(ctx).IntF(ViperKeyTOTPDigits, int(otp.DigitsSix)))
+}
+
+// TOTPPeriod returns the number of seconds a code of newly enrolled TOTP devices is valid for.
+func (p *Config) TOTPPeriod(ctx context.Context) uint {
+	return uint(p.GetProvider(ctx).IntF(ViperKeyTOTPPeriod, 30))
+}
+
+// TOTPAlgorithm returns the hash algorithm of newly enrolled TOTP devices.
+func (p *Config) TOTPAlgorithm(ctx context.Context) otp.Algorithm {
+	switch p.GetProvider(ctx).StringF(ViperKeyTOTPAlgorithm, "sha1") {
+	case "sha256":
+		return otp.AlgorithmSHA256
+	case "sha512":
+		return otp.AlgorithmSHA512
+	default:
+		return otp.AlgorithmSHA1
+	}
+}
+
+// TOTPSkew returns the number of periods before and after the current one in which codes are accepted.
+func (p *Config) TOTPSkew(ctx context.Context) uint {
+	return uint(p.GetProvider(ctx).IntF(ViperKeyTOTPSkew, 1))
+}
+
+// TrustedDeviceLifespan returns how long a device is trusted after the second factor was completed on it.
+func (p *Config) TrustedDeviceLifespan(ctx context.Context) time.Duration {
+	return p.GetProvider(ctx).DurationF(ViperKeyTrustedDeviceLifespan, time.Hour*24*30)
+}
+
+// AccountDeletionSendConfirmationEmail returns whether an email is sent to the addresses of an identity
+// once it deleted its account.
+func (p *Config) AccountDeletionSendConfirmationEmail(ctx context.Context) bool {
+	return p.GetProvider(ctx).Bool(ViperKeyAccountDeletionSendConfirmationEmail)
+}
+
+// ProfileVerifyAddressChanges returns whether changes to identifier or recovery addresses made
+// in the profile settings method are held until the new address has been confirmed.
+func (p *Config) ProfileVerifyAddressChanges(ctx context.Context) bool {
+	return p.GetProvider(ctx).Bool(ViperKeyProfileVerifyAddressChanges)
+}
+
+// ProfileAddressChangeRevertLifespan returns how long the previous address can revert an address change.
+func (p *Config) ProfileAddressChangeRevertLifespan(ctx context.Context) time.Duration {
+	return p.GetProvider(ctx).DurationF(ViperKeyProfileAddressChangeRevertLifespan, time.Hour*24*7)
+}
+
 func (p *Config) OIDCRedirectURIBase(ctx context.Context) *url.URL {
 	return p.GetProvider(ctx).URIF(ViperKeyOIDCBaseRedirectURL, p.SelfPublicURL(ctx))
 }
@@ -610,6 +809,24 @@ func (p *Config) ClientHTTPPrivateIPExceptionURLs(ctx context.Context) []string
 	return p.GetProvider(ctx).Strings(ViperKeyClientHTTPPrivateIPExceptionURLs)
 }
 
+// OAuth2ProviderURL returns the admin URL of the OAuth2 provider, such as Ory Hydra, which Ory Kratos acts as
+// login provider for. It returns nil if no OAuth2 provider is configured.
+func (p *Config) OAuth2ProviderURL(ctx context.Context) *url.URL {
+	k := ViperKeyOAuth2ProviderURL
+	v := p.GetProvider(ctx).String(k)
+	if v == "" {
+		return nil
+	}
+
+	parsed, err := p.ParseURI(v)
+	if err != nil {
+		p.l.WithError(errors.WithStack(err)).
+			Errorf("Configuration value from key %s is not a valid URL: %s", k, v)
+		return nil
+	}
+	return parsed
+}
+
 func (p *Config) SelfServiceFlowRegistrationEnabled(ctx context.Context) bool {
 	return p.GetProvider(ctx).Bool(ViperKeySelfServiceRegistrationEnabled)
 }
@@ -642,6 +859,14 @@ func (p *Config) SelfServiceFlowRegistrationBeforeHooks(ctx context.Context) []S
 	return p.selfServiceHooks(ctx, ViperKeySelfServiceRegistrationBeforeHooks)
 }
 
+func (p *Config) SelfServiceFlowSettingsAccountDeletionBeforeHooks(ctx context.Context) []SelfServiceHook {
+	return p.selfServiceHooks(ctx, ViperKeySelfServiceSettingsAccountDeletionBeforeHooks)
+}
+
+func (p *Config) SelfServiceFlowSettingsAccountDeletionAfterHooks(ctx context.Context) []SelfServiceHook {
+	return p.selfServiceHooks(ctx, HookStrategyKey(ViperKeySelfServiceSettingsAccountDeletionAfter, HookGlobal))
+}
+
 func (p *Config) selfServiceHooks(ctx context.Context, key string) []SelfServiceHook {
 	pp := p.GetProvider(ctx)
 
@@ -902,6 +1127,22 @@ func (p *Config) SelfServiceFlowLoginRequestLifespan(ctx context.Context) time.D
 	return p.GetProvider(ctx).DurationF(ViperKeySelfServiceLoginRequestLifespan, time.Hour)
 }
 
+func (p *Config) SelfServiceFlowLoginIdentifierFirst(ctx context.Context) bool {
+	return p.GetProvider(ctx).StringF(ViperKeySelfServiceLoginStyle, LoginStyleUnified) == LoginStyleIdentifierFirst
+}
+
+func (p *Config) SelfServiceFlowLoginConcealAccountExistence(ctx context.Context) bool {
+	return p.GetProvider(ctx).Bool(ViperKeySelfServiceLoginConcealAccountExistence)
+}
+
+func (p *Config) SelfServiceFlowLoginHomeRealms(ctx context.Context) (hr []HomeRealm) {
+	if err := p.GetProvider(ctx).Koanf.Unmarshal(ViperKeySelfServiceLoginHomeRealmDiscovery, &hr); err != nil {
+		p.l.WithError(err).Errorf("Unable to decode values from configuration key: %s", ViperKeySelfServiceLoginHomeRealmDiscovery)
+		return nil
+	}
+	return hr
+}
+
 func (p *Config) SelfServiceFlowSettingsFlowLifespan(ctx context.Context) time.Duration {
 	return p.GetProvider(ctx).DurationF(ViperKeySelfServiceSettingsRequestLifespan, time.Hour)
 }
@@ -985,6 +1226,26 @@ func (p *Config) CourierTemplatesRecoveryValid(ctx context.Context) *CourierEmai
 	return p.CourierTemplatesHelper(ctx, ViperKeyCourierTemplatesRecoveryValidEmail)
 }
 
+func (p *Config) CourierTemplatesAccountDeleted(ctx context.Context) *CourierEmailTemplate {
+	return p.CourierTemplatesHelper(ctx, ViperKeyCourierTemplatesAccountDeletedEmail)
+}
+
+func (p *Config) CourierTemplatesAddressChangeConfirm(ctx context.Context) *CourierEmailTemplate {
+	return p.CourierTemplatesHelper(ctx, ViperKeyCourierTemplatesAddressChangeConfirmEmail)
+}
+
+func (p *Config) CourierTemplatesAddressChangeNotice(ctx context.Context) *CourierEmailTemplate {
+	return p.CourierTemplatesHelper(ctx, ViperKeyCourierTemplatesAddressChangeNoticeEmail)
+}
+
+func (p *Config) CourierTemplatesSecurityNotification(ctx context.Context) *CourierEmailTemplate {
+	return p.CourierTemplatesHelper(ctx, ViperKeyCourierTemplatesSecurityNotificationEmail)
+}
+
+func (p *Config) CourierTemplatesInvitation(ctx context.Context) *CourierEmailTemplate {
+	return p.CourierTemplatesHelper(ctx, ViperKeyCourierTemplatesInvitationEmail)
+}
+
 func (p *Config) CourierMessageRetries(ctx context.Context) int {
 	return p.GetProvider(ctx).IntF(ViperKeyCourierMessageRetries, 5)
 }
@@ -1123,6 +1384,11 @@ func (p *Config) SelfServiceLinkMethodBaseURL(ctx context.Context) *url.URL {
 	return p.GetProvider(ctx).RequestURIF(ViperKeyLinkBaseURL, p.SelfPublicURL(ctx))
 }
 
+// SelfServiceLinkMethodInvitationLifespan returns how long an invitation sent by an administrator can be accepted.
+func (p *Config) SelfServiceLinkMethodInvitationLifespan(ctx context.Context) time.Duration {
+	return p.GetProvider(ctx).DurationF(ViperKeyLinkInvitationLifespan, time.Hour*24*7)
+}
+
 func (p *Config) DatabaseCleanupSleepTables(ctx context.Context) time.Duration {
 	return p.GetProvider(ctx).Duration(ViperKeyDatabaseCleanupSleepTables)
 }
@@ -1178,6 +1444,14 @@ func (p *Config) SelfServiceSettingsRequiredAAL(ctx context.Context) string {
 	return p.GetProvider(ctx).String(ViperKeySelfServiceSettingsRequiredAAL)
 }
 
+func (p *Config) SelfServiceSettingsMFAEnrollment(ctx context.Context) *MFAEnrollmentPolicy {
+	return &MFAEnrollmentPolicy{
+		Enabled: p.GetProvider(ctx).Bool(ViperKeySelfServiceSettingsMFAEnrollmentEnabled),
+		Schemas: p.GetProvider(ctx).Strings(ViperKeySelfServiceSettingsMFAEnrollmentSchemas),
+		Trait:   p.GetProvider(ctx).String(ViperKeySelfServiceSettingsMFAEnrollmentTrait),
+	}
+}
+
 func (p *Config) CookieSameSiteMode(ctx context.Context) http.SameSite {
 	switch p.GetProvider(ctx).StringF(ViperKeyCookieSameSite, "Lax") {
 	case "Lax":
@@ -1218,6 +1492,14 @@ func (p *Config) SelfServiceFlowSettingsReturnTo(ctx context.Context, strategy s
 	)
 }
 
+// SelfServiceFlowSettingsAccountDeletionReturnTo returns where browsers are sent to once they deleted their account.
+func (p *Config) SelfServiceFlowSettingsAccountDeletionReturnTo(ctx context.Context) *url.URL {
+	return p.GetProvider(ctx).RequestURIF(
+		ViperKeySelfServiceSettingsAccountDeletionAfter+"."+DefaultBrowserReturnURL,
+		p.SelfServiceBrowserDefaultReturnTo(ctx),
+	)
+}
+
 func (p *Config) selfServiceReturnTo(ctx context.Context, key string, strategy string) *url.URL {
 	return p.GetProvider(ctx).RequestURIF(
 		key+"."+strategy+"."+DefaultBrowserReturnURL,
@@ -1235,10 +1517,17 @@ func (p *Config) PasswordPolicyConfig(ctx context.Context) *PasswordPolicy {
 	return &PasswordPolicy{
 		HaveIBeenPwnedHost:               p.GetProvider(ctx).StringF(ViperKeyPasswordHaveIBeenPwnedHost, "api.pwnedpasswords.com"),
 		HaveIBeenPwnedEnabled:            p.GetProvider(ctx).BoolF(ViperKeyPasswordHaveIBeenPwnedEnabled, true),
+		HaveIBeenPwnedSource:             p.GetProvider(ctx).StringF(ViperKeyPasswordHaveIBeenPwnedSource, PasswordBreachSourceAPI),
+		HaveIBeenPwnedPath:               p.GetProvider(ctx).String(ViperKeyPasswordHaveIBeenPwnedPath),
+		DenyListPath:                     p.GetProvider(ctx).String(ViperKeyPasswordDenyListPath),
 		MaxBreaches:                      uint(p.GetProvider(ctx).Int(ViperKeyPasswordMaxBreaches)),
 		IgnoreNetworkErrors:              p.GetProvider(ctx).BoolF(ViperKeyIgnoreNetworkErrors, true),
 		MinPasswordLength:                uint(p.GetProvider(ctx).IntF(ViperKeyPasswordMinLength, 8)),
 		IdentifierSimilarityCheckEnabled: p.GetProvider(ctx).BoolF(ViperKeyPasswordIdentifierSimilarityCheckEnabled, true),
+		MaxPasswordLength:                uint(p.GetProvider(ctx).IntF(ViperKeyPasswordMaxLength, 0)),
+		RequiredCharacterClasses:         p.GetProvider(ctx).Strings(ViperKeyPasswordRequiredCharacterClasses),
+		PasswordHistorySize:              uint(p.GetProvider(ctx).IntF(ViperKeyPasswordHistorySize, 0)),
+		MaxPasswordAge:                   p.GetProvider(ctx).DurationF(ViperKeyPasswordMaxAge, 0),
 	}
 }
 
@@ -1246,15 +1535,69 @@ func (p *Config) WebAuthnForPasswordless(ctx context.Context) bool {
 	return p.GetProvider(ctx).BoolF(ViperKeyWebAuthnPasswordless, false)
 }
 
+// WebAuthnRPOrigins returns the origins at which WebAuthn credentials may be created and used. If no list is
+// configured, the single `rp.origin` is used.
+func (p *Config) WebAuthnRPOrigins(ctx context.Context) []string {
+	if origins := p.GetProvider(ctx).Strings(ViperKeyWebAuthnRPOrigins); len(origins) > 0 {
+		return origins
+	}
+	if origin := p.GetProvider(ctx).String(ViperKeyWebAuthnRPOrigin); len(origin) > 0 {
+		return []string{origin}
+	}
+	return []string{}
+}
+
+func (p *Config) webAuthnRPOrigin(ctx context.Context) string {
+	if origins := p.WebAuthnRPOrigins(ctx); len(origins) > 0 {
+		return origins[0]
+	}
+	return ""
+}
+
+// WebAuthnRPID returns the relying party identifier to use for requests to the given host. Falls back to `rp.id`
+// if no identifier is configured for the host.
+func (p *Config) WebAuthnRPID(ctx context.Context, host string) string {
+	var hosts []WebAuthnRPHost
+	if err := p.GetProvider(ctx).Koanf.Unmarshal(ViperKeyWebAuthnRPHosts, &hosts); err != nil {
+		p.l.WithError(err).Errorf("Unable to decode values from configuration key: %s", ViperKeyWebAuthnRPHosts)
+	}
+
+	for _, h := range hosts {
+		if strings.EqualFold(h.Host, host) {
+			return h.ID
+		}
+	}
+	return p.GetProvider(ctx).String(ViperKeyWebAuthnRPID)
+}
+
 func (p *Config) WebAuthnConfig(ctx context.Context) *webauthn.Config {
 	return &webauthn.Config{
 		RPDisplayName: p.GetProvider(ctx).String(ViperKeyWebAuthnRPDisplayName),
 		RPID:          p.GetProvider(ctx).String(ViperKeyWebAuthnRPID),
-		RPOrigin:      p.GetProvider(ctx).String(ViperKeyWebAuthnRPOrigin),
+		RPOrigin:      p.webAuthnRPOrigin(ctx),
 		RPIcon:        p.GetProvider(ctx).String(ViperKeyWebAuthnRPIcon),
 		AuthenticatorSelection: protocol.AuthenticatorSelection{
-			UserVerification: protocol.VerificationDiscouraged,
+			UserVerification: protocol.UserVerificationRequirement(p.GetProvider(ctx).StringF(ViperKeyWebAuthnUserVerification, string(protocol.VerificationDiscouraged))),
 		},
+		AttestationPreference: p.webAuthnAttestationConveyance(ctx),
+	}
+}
+
+func (p *Config) webAuthnAttestationConveyance(ctx context.Context) protocol.ConveyancePreference {
+	// The attestation statement can only be verified if the browser passes it on unaltered.
+	if p.GetProvider(ctx).Bool(ViperKeyWebAuthnAttestationRequired) {
+		return protocol.PreferDirectAttestation
+	}
+	return protocol.ConveyancePreference(p.GetProvider(ctx).StringF(ViperKeyWebAuthnAttestationConveyance, string(protocol.PreferNoAttestation)))
+}
+
+func (p *Config) WebAuthnAttestationPolicy(ctx context.Context) *WebAuthnAttestationPolicy {
+	return &WebAuthnAttestationPolicy{
+		Required:                    p.GetProvider(ctx).Bool(ViperKeyWebAuthnAttestationRequired),
+		MetadataPath:                p.GetProvider(ctx).String(ViperKeyWebAuthnAttestationMetadataPath),
+		MetadataRootCertificatePath: p.GetProvider(ctx).String(ViperKeyWebAuthnAttestationMetadataRootCertificatePath),
+		AllowedAAGUIDs:              p.GetProvider(ctx).Strings(ViperKeyWebAuthnAuthenticatorsAllow),
+		DeniedAAGUIDs:               p.GetProvider(ctx).Strings(ViperKeyWebAuthnAuthenticatorsDeny),
 	}
 }
 
@@ -19,7 +19,9 @@ import (
 
 	"github.com/ory/x/snapshotx"
 
+	"github.com/duo-labs/webauthn/protocol"
 	"github.com/ghodss/yaml"
+	"github.com/pquerna/otp"
 	"github.com/spf13/cobra"
 
 	"github.com/ory/x/watcherx"
@@ -211,9 +213,9 @@ func TestViperProvider(t *testing.T) {
 				config  string
 				enabled bool
 			}{
-				{id: "password", enabled: true, config: `{"haveibeenpwned_host":"api.pwnedpasswords.com","haveibeenpwned_enabled":true,"ignore_network_errors":true,"max_breaches":0,"min_password_length":8,"identifier_similarity_check_enabled":true}`},
+				{id: "password", enabled: true, config: `{"haveibeenpwned_host":"api.pwnedpasswords.com","haveibeenpwned_enabled":true,"haveibeenpwned_source":"api","ignore_network_errors":true,"max_breaches":0,"min_password_length":8,"max_password_length":0,"required_character_classes":[],"password_history_size":0,"max_password_age":"0s","identifier_similarity_check_enabled":true}`},
 				{id: "oidc", enabled: true, config: `{"providers":[{"client_id":"a","client_secret":"b","id":"github","provider":"github","mapper_url":"http://test.kratos.ory.sh/default-identity.schema.json"}]}`},
-				{id: "totp", enabled: true, config: `{"issuer":"issuer.ory.sh"}`},
+				{id: "totp", enabled: true, config: `{"issuer":"issuer.ory.sh","digits":6,"period":30,"algorithm":"sha1","skew":1}`},
 			} {
 				strategy := p.SelfServiceStrategy(ctx, tc.id)
 				assert.Equal(t, tc.enabled, strategy.Enabled)
@@ -276,6 +278,29 @@ func TestViperProvider(t *testing.T) {
 
 		t.Run("method=totp", func(t *testing.T) {
 			assert.Equal(t, "issuer.ory.sh", p.TOTPIssuer(ctx))
+			assert.Equal(t, otp.DigitsSix, p.TOTPDigits(ctx))
+			assert.EqualValues(t, 30, p.TOTPPeriod(ctx))
+			assert.Equal(t, otp.AlgorithmSHA1, p.TOTPAlgorithm(ctx))
+			assert.EqualValues(t, 1, p.TOTPSkew(ctx))
+		})
+
+		t.Run("method=trusted_device", func(t *testing.T) {
+			assert.False(t, p.SelfServiceStrategy(ctx, "trusted_device").Enabled)
+			assert.Equal(t, time.Hour*24*30, p.TrustedDeviceLifespan(ctx))
+		})
+
+		t.Run("method=profile", func(t *testing.T) {
+			assert.True(t, p.ProfileVerifyAddressChanges(ctx))
+			assert.Equal(t, time.Hour*72, p.ProfileAddressChangeRevertLifespan(ctx))
+		})
+
+		t.Run("method=link", func(t *testing.T) {
+			assert.Equal(t, time.Hour*24*7, p.SelfServiceLinkMethodInvitationLifespan(ctx))
+		})
+
+		t.Run("method=account_deletion", func(t *testing.T) {
+			assert.False(t, p.SelfServiceStrategy(ctx, "account_deletion").Enabled)
+			assert.False(t, p.AccountDeletionSendConfirmationEmail(ctx))
 		})
 
 		t.Run("method=login", func(t *testing.T) {
@@ -330,6 +355,17 @@ func TestViperProvider(t *testing.T) {
 		t.Run("method=settings", func(t *testing.T) {
 			assert.Equal(t, time.Minute*99, p.SelfServiceFlowSettingsFlowLifespan(ctx))
 			assert.Equal(t, time.Minute*5, p.SelfServiceFlowSettingsPrivilegedSessionMaxAge(ctx))
+			assert.Equal(t, &config.MFAEnrollmentPolicy{Enabled: true, Schemas: []string{"default"}, Trait: "mfa_required"}, p.SelfServiceSettingsMFAEnrollment(ctx))
+
+			t.Run("hook=account_deletion", func(t *testing.T) {
+				assert.Equal(t, []config.SelfServiceHook{
+					{Name: "web_hook", Config: json.RawMessage(`{"body":"/path/to/template.jsonnet","method":"POST","url":"https://test.kratos.ory.sh/before_account_deletion_hook"}`)},
+				}, p.SelfServiceFlowSettingsAccountDeletionBeforeHooks(ctx))
+				assert.Equal(t, []config.SelfServiceHook{
+					{Name: "web_hook", Config: json.RawMessage(`{"body":"/path/to/template.jsonnet","method":"POST","url":"https://test.kratos.ory.sh/after_account_deletion_hook"}`)},
+				}, p.SelfServiceFlowSettingsAccountDeletionAfterHooks(ctx))
+				assert.Equal(t, "https://self-service/settings/account_deletion/return_to", p.SelfServiceFlowSettingsAccountDeletionReturnTo(ctx).String())
+			})
 
 			for _, tc := range []struct {
 				strategy string
@@ -773,6 +809,19 @@ func TestViperProvider_HaveIBeenPwned(t *testing.T) {
 		p.MustSet(ctx, config.ViperKeyIgnoreNetworkErrors, false)
 		assert.Equal(t, false, p.PasswordPolicyConfig(ctx).IgnoreNetworkErrors)
 	})
+
+	t.Run("case=hibp: source", func(t *testing.T) {
+		assert.Equal(t, config.PasswordBreachSourceAPI, p.PasswordPolicyConfig(ctx).HaveIBeenPwnedSource)
+		p.MustSet(ctx, config.ViperKeyPasswordHaveIBeenPwnedSource, config.PasswordBreachSourceFile)
+		p.MustSet(ctx, config.ViperKeyPasswordHaveIBeenPwnedPath, "/var/lib/hibp")
+		assert.Equal(t, config.PasswordBreachSourceFile, p.PasswordPolicyConfig(ctx).HaveIBeenPwnedSource)
+		assert.Equal(t, "/var/lib/hibp", p.PasswordPolicyConfig(ctx).HaveIBeenPwnedPath)
+	})
+
+	t.Run("case=deny list", func(t *testing.T) {
+		p.MustSet(ctx, config.ViperKeyPasswordDenyListPath, "/etc/deny.txt")
+		assert.Equal(t, "/etc/deny.txt", p.PasswordPolicyConfig(ctx).DenyListPath)
+	})
 }
 
 func TestLoadingTLSConfig(t *testing.T) {
@@ -1081,6 +1130,59 @@ func TestPasswordless(t *testing.T) {
 	assert.False(t, conf.WebAuthnForPasswordless(ctx))
 }
 
+func TestWebAuthnPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, configx.SkipValidation())
+	require.NoError(t, err)
+
+	assert.Equal(t, protocol.VerificationDiscouraged, conf.WebAuthnConfig(ctx).AuthenticatorSelection.UserVerification)
+	assert.Equal(t, protocol.PreferNoAttestation, conf.WebAuthnConfig(ctx).AttestationPreference)
+	assert.Equal(t, &config.WebAuthnAttestationPolicy{AllowedAAGUIDs: []string{}, DeniedAAGUIDs: []string{}}, conf.WebAuthnAttestationPolicy(ctx))
+
+	conf.MustSet(ctx, config.ViperKeyWebAuthnUserVerification, "required")
+	conf.MustSet(ctx, config.ViperKeyWebAuthnAttestationConveyance, "indirect")
+	assert.Equal(t, protocol.PreferIndirectAttestation, conf.WebAuthnConfig(ctx).AttestationPreference)
+
+	conf.MustSet(ctx, config.ViperKeyWebAuthnAttestationRequired, true)
+	conf.MustSet(ctx, config.ViperKeyWebAuthnAttestationMetadataPath, "/etc/kratos/mds.jwt")
+	conf.MustSet(ctx, config.ViperKeyWebAuthnAttestationMetadataRootCertificatePath, "/etc/kratos/mds-root.pem")
+	conf.MustSet(ctx, config.ViperKeyWebAuthnAuthenticatorsAllow, []string{"cb69481e-8ff7-4039-93ec-0a2729a154a8"})
+	conf.MustSet(ctx, config.ViperKeyWebAuthnAuthenticatorsDeny, []string{"00000000-0000-0000-0000-000000000000"})
+
+	assert.Equal(t, protocol.VerificationRequired, conf.WebAuthnConfig(ctx).AuthenticatorSelection.UserVerification)
+	assert.Equal(t, protocol.PreferDirectAttestation, conf.WebAuthnConfig(ctx).AttestationPreference)
+	assert.Equal(t, &config.WebAuthnAttestationPolicy{
+		Required:                    true,
+		MetadataPath:                "/etc/kratos/mds.jwt",
+		MetadataRootCertificatePath: "/etc/kratos/mds-root.pem",
+		AllowedAAGUIDs:              []string{"cb69481e-8ff7-4039-93ec-0a2729a154a8"},
+		DeniedAAGUIDs:               []string{"00000000-0000-0000-0000-000000000000"},
+	}, conf.WebAuthnAttestationPolicy(ctx))
+}
+
+func TestWebAuthnRelyingParty(t *testing.T) {
+	ctx := context.Background()
+
+	conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, configx.SkipValidation())
+	require.NoError(t, err)
+
+	conf.MustSet(ctx, config.ViperKeyWebAuthnRPID, "example.com")
+	conf.MustSet(ctx, config.ViperKeyWebAuthnRPOrigin, "https://app.example.com")
+
+	assert.Equal(t, []string{"https://app.example.com"}, conf.WebAuthnRPOrigins(ctx))
+	assert.Equal(t, "https://app.example.com", conf.WebAuthnConfig(ctx).RPOrigin)
+	assert.Equal(t, "example.com", conf.WebAuthnRPID(ctx, "admin.example.com"))
+
+	conf.MustSet(ctx, config.ViperKeyWebAuthnRPOrigins, []string{"https://admin.example.com", "android:apk-key-hash:ABCDEF"})
+	conf.MustSet(ctx, config.ViperKeyWebAuthnRPHosts, []map[string]interface{}{{"host": "admin.example.com", "id": "admin.example.com"}})
+
+	assert.Equal(t, []string{"https://admin.example.com", "android:apk-key-hash:ABCDEF"}, conf.WebAuthnRPOrigins(ctx))
+	assert.Equal(t, "https://admin.example.com", conf.WebAuthnConfig(ctx).RPOrigin)
+	assert.Equal(t, "admin.example.com", conf.WebAuthnRPID(ctx, "admin.example.com"))
+	assert.Equal(t, "example.com", conf.WebAuthnRPID(ctx, "app.example.com"))
+}
+
 func TestChangeMinPasswordLength(t *testing.T) {
 	t.Run("case=must fail on minimum password length below enforced minimum", func(t *testing.T) {
 		ctx := context.Background()
@@ -1207,3 +1309,29 @@ func TestCleanup(t *testing.T) {
 		assert.Equal(t, p.DatabaseCleanupBatchSize(ctx), 1)
 	})
 }
+
+func TestSelfServiceHookValidation(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name      string
+		hooks     []map[string]interface{}
+		expectErr bool
+	}{
+		{name: "approval before session", hooks: []map[string]interface{}{{"hook": "require_approval"}, {"hook": "session"}}},
+		{name: "approval without session", hooks: []map[string]interface{}{{"hook": "require_approval"}}},
+		{name: "approval after session", hooks: []map[string]interface{}{{"hook": "session"}, {"hook": "require_approval"}}, expectErr: true},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			_, err := config.New(ctx, logrusx.New("", ""), os.Stderr,
+				configx.WithConfigFiles("stub/.kratos.yaml"),
+				configx.WithValue(config.ViperKeySelfServiceRegistrationAfter+".password.hooks", tc.hooks))
+			if tc.expectErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "selfservice.flows.registration.after.password.hooks")
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
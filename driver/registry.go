@@ -35,6 +35,7 @@ import (
 	"github.com/ory/x/dbal"
 
 	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/hydra"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/selfservice/errorx"
 	password2 "github.com/ory/kratos/selfservice/strategy/password"
@@ -71,6 +72,7 @@ type Registry interface {
 	x.WriterProvider
 	x.LoggingProvider
 	x.HTTPClientProvider
+	x.TransactionalPersisterProvider
 
 	continuity.ManagementProvider
 	continuity.PersistenceProvider
@@ -85,12 +87,16 @@ type Registry interface {
 
 	hash.HashProvider
 
+	hydra.HydraProvider
+
 	identity.HandlerProvider
 	identity.ValidationProvider
 	identity.PoolProvider
 	identity.PrivilegedPoolProvider
 	identity.ManagementProvider
 	identity.ActiveCredentialsCounterStrategyProvider
+	identity.MFAEnrollmentRefresherProvider
+	identity.CredentialsAdminHandlerProvider
 
 	courier.HandlerProvider
 	courier.PersistenceProvider
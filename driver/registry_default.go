@@ -26,7 +26,9 @@ import (
 
 	"github.com/ory/kratos/selfservice/strategy/lookup"
 
+	"github.com/ory/kratos/selfservice/strategy/accountdeletion"
 	"github.com/ory/kratos/selfservice/strategy/totp"
+	"github.com/ory/kratos/selfservice/strategy/trusteddevice"
 
 	"github.com/luna-duclos/instrumentedsql"
 
@@ -65,6 +67,7 @@ import (
 	"github.com/ory/herodot"
 
 	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/hydra"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/selfservice/errorx"
 	password2 "github.com/ory/kratos/selfservice/strategy/password"
@@ -90,10 +93,14 @@ type RegistryDefault struct {
 	persister       persistence.Persister
 	migrationStatus popx.MigrationStatuses
 
-	hookVerifier         *hook.Verifier
-	hookSessionIssuer    *hook.SessionIssuer
-	hookSessionDestroyer *hook.SessionDestroyer
-	hookAddressVerifier  *hook.AddressVerifier
+	hookVerifier                 *hook.Verifier
+	hookSessionIssuer            *hook.SessionIssuer
+	hookSessionDestroyer         *hook.SessionDestroyer
+	hookAddressVerifier          *hook.AddressVerifier
+	hookCredentialsResetEnforcer *hook.CredentialsResetEnforcer
+	hookMFAEnrollmentEnforcer    *hook.MFAEnrollmentEnforcer
+	hookSecurityNotifier         *hook.SecurityNotifier
+	hookApprovalEnforcer         *hook.ApprovalEnforcer
 
 	identityHandler   *identity.Handler
 	identityValidator *identity.Validator
@@ -113,6 +120,10 @@ type RegistryDefault struct {
 
 	crypter cipher.Cipher
 
+	hydra hydra.Hydra
+
+	oidcDiscoveryCache *oidc.DiscoveryCache
+
 	errorHandler *errorx.Handler
 	errorManager *errorx.Manager
 
@@ -181,6 +192,7 @@ func (m *RegistryDefault) RegisterPublicRoutes(ctx context.Context, router *x.Ro
 func (m *RegistryDefault) RegisterAdminRoutes(ctx context.Context, router *x.RouterAdmin) {
 	m.RegistrationHandler().RegisterAdminRoutes(router)
 	m.LoginHandler().RegisterAdminRoutes(router)
+	m.AllLoginStrategies().RegisterAdminRoutes(router)
 	m.LogoutHandler().RegisterAdminRoutes(router)
 	m.SchemaHandler().RegisterAdminRoutes(router)
 	m.SettingsHandler().RegisterAdminRoutes(router)
@@ -293,6 +305,8 @@ func (m *RegistryDefault) selfServiceStrategies() []interface{} {
 			totp.NewStrategy(m),
 			webauthn.NewStrategy(m),
 			lookup.NewStrategy(m),
+			trusteddevice.NewStrategy(m),
+			accountdeletion.NewStrategy(m),
 		}
 	}
 
@@ -351,6 +365,15 @@ func (m *RegistryDefault) ActiveCredentialsCounterStrategies(ctx context.Context
 	return
 }
 
+func (m *RegistryDefault) CredentialsAdminHandlers() (credentialsAdminHandlers []identity.CredentialsAdminHandler) {
+	for _, strategy := range m.selfServiceStrategies() {
+		if s, ok := strategy.(identity.CredentialsAdminHandler); ok {
+			credentialsAdminHandlers = append(credentialsAdminHandlers, s)
+		}
+	}
+	return
+}
+
 func (m *RegistryDefault) IdentityValidator() *identity.Validator {
 	if m.identityValidator == nil {
 		m.identityValidator = identity.NewValidator(m)
@@ -406,6 +429,13 @@ func (m *RegistryDefault) SessionHandler() *session.Handler {
 	return m.sessionHandler
 }
 
+func (m *RegistryDefault) Hydra() hydra.Hydra {
+	if m.hydra == nil {
+		m.hydra = hydra.NewDefaultHydra(m)
+	}
+	return m.hydra
+}
+
 func (m *RegistryDefault) Cipher(ctx context.Context) cipher.Cipher {
 	if m.crypter == nil {
 		switch m.c.CipherAlgorithm(ctx) {
@@ -514,6 +544,10 @@ func (m *RegistryDefault) SessionManager() session.Manager {
 	return m.sessionManager
 }
 
+func (m *RegistryDefault) MFAEnrollmentRefresher() identity.MFAEnrollmentRefresher {
+	return m.SessionManager()
+}
+
 func (m *RegistryDefault) SelfServiceErrorManager() *errorx.Manager {
 	if m.errorManager == nil {
 		m.errorManager = errorx.NewManager(m)
@@ -681,10 +715,33 @@ func (m *RegistryDefault) VerificationTokenPersister() link.VerificationTokenPer
 	return m.Persister()
 }
 
+func (m *RegistryDefault) AddressChangePersister() link.AddressChangePersister {
+	return m.Persister()
+}
+
+func (m *RegistryDefault) InvitationPersister() link.InvitationPersister {
+	return m.Persister()
+}
+
+func (m *RegistryDefault) OIDCProviderPersister() oidc.ProviderPersister {
+	return m.Persister()
+}
+
+func (m *RegistryDefault) OIDCDiscoveryCache() *oidc.DiscoveryCache {
+	if m.oidcDiscoveryCache == nil {
+		m.oidcDiscoveryCache = oidc.NewDiscoveryCache()
+	}
+	return m.oidcDiscoveryCache
+}
+
 func (m *RegistryDefault) Persister() persistence.Persister {
 	return m.persister
 }
 
+func (m *RegistryDefault) TransactionalPersisterProvider() x.TransactionalPersister {
+	return m.persister
+}
+
 func (m *RegistryDefault) Ping() error {
 	return m.persister.Ping()
 }
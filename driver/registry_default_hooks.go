@@ -33,6 +33,34 @@ func (m *RegistryDefault) HookAddressVerifier() *hook.AddressVerifier {
 	return m.hookAddressVerifier
 }
 
+func (m *RegistryDefault) HookCredentialsResetEnforcer() *hook.CredentialsResetEnforcer {
+	if m.hookCredentialsResetEnforcer == nil {
+		m.hookCredentialsResetEnforcer = hook.NewCredentialsResetEnforcer(m)
+	}
+	return m.hookCredentialsResetEnforcer
+}
+
+func (m *RegistryDefault) HookMFAEnrollmentEnforcer() *hook.MFAEnrollmentEnforcer {
+	if m.hookMFAEnrollmentEnforcer == nil {
+		m.hookMFAEnrollmentEnforcer = hook.NewMFAEnrollmentEnforcer(m)
+	}
+	return m.hookMFAEnrollmentEnforcer
+}
+
+func (m *RegistryDefault) HookSecurityNotifier() *hook.SecurityNotifier {
+	if m.hookSecurityNotifier == nil {
+		m.hookSecurityNotifier = hook.NewSecurityNotifier(m)
+	}
+	return m.hookSecurityNotifier
+}
+
+func (m *RegistryDefault) HookApprovalEnforcer() *hook.ApprovalEnforcer {
+	if m.hookApprovalEnforcer == nil {
+		m.hookApprovalEnforcer = hook.NewApprovalEnforcer(m)
+	}
+	return m.hookApprovalEnforcer
+}
+
 func (m *RegistryDefault) WithHooks(hooks map[string]func(config.SelfServiceHook) interface{}) {
 	m.injectedSelfserviceHooks = hooks
 }
@@ -48,6 +76,14 @@ func (m *RegistryDefault) getHooks(credentialsType string, configs []config.Self
 			i = append(i, hook.NewWebHook(m, h.Config))
 		case hook.KeyAddressVerifier:
 			i = append(i, m.HookAddressVerifier())
+		case hook.KeyCredentialsResetEnforcer:
+			i = append(i, m.HookCredentialsResetEnforcer())
+		case hook.KeyMFAEnrollmentEnforcer:
+			i = append(i, m.HookMFAEnrollmentEnforcer())
+		case hook.KeySecurityNotifier:
+			i = append(i, m.HookSecurityNotifier())
+		case hook.KeyApprovalEnforcer:
+			i = append(i, m.HookApprovalEnforcer())
 		default:
 			var found bool
 			for name, m := range m.injectedSelfserviceHooks {
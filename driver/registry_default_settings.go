@@ -7,8 +7,25 @@ import (
 	"github.com/ory/kratos/selfservice/flow/settings"
 )
 
+// PostSettingsPrePersistHooks returns the hooks which run before the identity is persisted. Like the post persist
+// hooks, the global hooks are used if and only if the strategy defines no post persist hooks of its own. Hooks
+// such as the security notifier which implement both interfaces therefore always run both halves from the same list.
 func (m *RegistryDefault) PostSettingsPrePersistHooks(ctx context.Context, settingsType string) (b []settings.PostHookPrePersistExecutor) {
-	for _, v := range m.getHooks(settingsType, m.Config().SelfServiceFlowSettingsAfterHooks(ctx, settingsType)) {
+	hooks := m.getHooks(settingsType, m.Config().SelfServiceFlowSettingsAfterHooks(ctx, settingsType))
+
+	var hasPostPersistHooks bool
+	for _, v := range hooks {
+		if _, ok := v.(settings.PostHookPostPersistExecutor); ok {
+			hasPostPersistHooks = true
+			break
+		}
+	}
+
+	if !hasPostPersistHooks {
+		hooks = append(hooks, m.getHooks(config.HookGlobal, m.Config().SelfServiceFlowSettingsAfterHooks(ctx, config.HookGlobal))...)
+	}
+
+	for _, v := range hooks {
 		if hook, ok := v.(settings.PostHookPrePersistExecutor); ok {
 			b = append(b, hook)
 		}
@@ -51,6 +68,24 @@ func (m *RegistryDefault) PostSettingsPostPersistHooks(ctx context.Context, sett
 	return
 }
 
+func (m *RegistryDefault) PreAccountDeletionHooks(ctx context.Context) (b []settings.AccountDeletionPreHookExecutor) {
+	for _, v := range m.getHooks("account_deletion", m.Config().SelfServiceFlowSettingsAccountDeletionBeforeHooks(ctx)) {
+		if hook, ok := v.(settings.AccountDeletionPreHookExecutor); ok {
+			b = append(b, hook)
+		}
+	}
+	return
+}
+
+func (m *RegistryDefault) PostAccountDeletionHooks(ctx context.Context) (b []settings.AccountDeletionPostHookExecutor) {
+	for _, v := range m.getHooks("account_deletion", m.Config().SelfServiceFlowSettingsAccountDeletionAfterHooks(ctx)) {
+		if hook, ok := v.(settings.AccountDeletionPostHookExecutor); ok {
+			b = append(b, hook)
+		}
+	}
+	return
+}
+
 func (m *RegistryDefault) SettingsHookExecutor() *settings.HookExecutor {
 	if m.selfserviceSettingsExecutor == nil {
 		m.selfserviceSettingsExecutor = settings.NewHookExecutor(m)
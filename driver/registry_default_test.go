@@ -590,6 +590,69 @@ func TestDriverDefault_Hooks(t *testing.T) {
 				assert.Equal(t, expectedExecutors, h)
 			})
 		}
+
+		// AFTER pre persist hooks
+		for _, tc := range []struct {
+			uc     string
+			prep   func(conf *config.Config)
+			expect func(reg *driver.RegistryDefault) []settings.PostHookPrePersistExecutor
+		}{
+			{
+				uc:     "No hooks configured",
+				prep:   func(conf *config.Config) {},
+				expect: func(reg *driver.RegistryDefault) []settings.PostHookPrePersistExecutor { return nil },
+			},
+			{
+				uc: "A security_notification hook is configured on a global level",
+				prep: func(conf *config.Config) {
+					conf.MustSet(ctx, config.ViperKeySelfServiceSettingsAfter+".hooks", []map[string]interface{}{
+						{"hook": "web_hook", "config": map[string]interface{}{"url": "foo", "method": "POST"}},
+						{"hook": "security_notification"},
+					})
+				},
+				expect: func(reg *driver.RegistryDefault) []settings.PostHookPrePersistExecutor {
+					return []settings.PostHookPrePersistExecutor{
+						hook.NewSecurityNotifier(reg),
+					}
+				},
+			},
+			{
+				uc: "Global hooks are ignored if the strategy defines post persist hooks",
+				prep: func(conf *config.Config) {
+					conf.MustSet(ctx, config.ViperKeySelfServiceSettingsAfter+".totp.hooks", []map[string]interface{}{
+						{"hook": "web_hook", "config": map[string]interface{}{"url": "foo", "method": "GET"}},
+					})
+					conf.MustSet(ctx, config.ViperKeySelfServiceSettingsAfter+".hooks", []map[string]interface{}{
+						{"hook": "security_notification"},
+					})
+				},
+				expect: func(reg *driver.RegistryDefault) []settings.PostHookPrePersistExecutor { return nil },
+			},
+			{
+				uc: "A security_notification hook is configured on a strategy level",
+				prep: func(conf *config.Config) {
+					conf.MustSet(ctx, config.ViperKeySelfServiceSettingsAfter+".totp.hooks", []map[string]interface{}{
+						{"hook": "security_notification"},
+					})
+				},
+				expect: func(reg *driver.RegistryDefault) []settings.PostHookPrePersistExecutor {
+					return []settings.PostHookPrePersistExecutor{
+						hook.NewSecurityNotifier(reg),
+					}
+				},
+			},
+		} {
+			t.Run(fmt.Sprintf("after/pre persist/uc=%s", tc.uc), func(t *testing.T) {
+				conf, reg := internal.NewFastRegistryWithMocks(t)
+				tc.prep(conf)
+
+				h := reg.PostSettingsPrePersistHooks(ctx, "totp")
+
+				expectedExecutors := tc.expect(reg)
+				require.Len(t, h, len(expectedExecutors))
+				assert.Equal(t, expectedExecutors, h)
+			})
+		}
 	})
 }
 
@@ -761,6 +824,33 @@ func TestDriverDefault_Strategies(t *testing.T) {
 				},
 				expect: []string{"profile", "totp"},
 			},
+			{
+				prep: func(t *testing.T) *config.Config {
+					c := config.MustNew(t, l,
+						os.Stderr,
+						configx.WithValues(map[string]interface{}{
+							config.ViperKeyDSN: config.DefaultSQLiteMemoryDSN,
+							config.ViperKeySelfServiceStrategyConfig + ".totp.enabled":           true,
+							config.ViperKeySelfServiceStrategyConfig + ".trusted_device.enabled": true,
+						}),
+						configx.SkipValidation())
+					return c
+				},
+				expect: []string{"password", "profile", "totp", "trusted_device"},
+			},
+			{
+				prep: func(t *testing.T) *config.Config {
+					c := config.MustNew(t, l,
+						os.Stderr,
+						configx.WithValues(map[string]interface{}{
+							config.ViperKeyDSN: config.DefaultSQLiteMemoryDSN,
+							config.ViperKeySelfServiceStrategyConfig + ".account_deletion.enabled": true,
+						}),
+						configx.SkipValidation())
+					return c
+				},
+				expect: []string{"password", "profile", "account_deletion"},
+			},
 			{
 				prep: func(t *testing.T) *config.Config {
 					return config.MustNew(t, l,
@@ -822,7 +912,7 @@ func TestDefaultRegistry_AllStrategies(t *testing.T) {
 	})
 
 	t.Run("case=all settings strategies", func(t *testing.T) {
-		expects := []string{"password", "oidc", "profile", "totp", "webauthn", "lookup_secret"}
+		expects := []string{"password", "oidc", "profile", "totp", "webauthn", "lookup_secret", "trusted_device", "account_deletion"}
 		s := reg.AllSettingsStrategies()
 		require.Len(t, s, len(expects))
 		for k, e := range expects {
@@ -0,0 +1,198 @@
+package hydra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/urlx"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/x"
+)
+
+const (
+	routeLoginRequest       = "/oauth2/auth/requests/login"
+	routeAcceptLoginRequest = "/oauth2/auth/requests/login/accept"
+)
+
+type (
+	hydraDependencies interface {
+		config.Provider
+		x.HTTPClientProvider
+	}
+	HydraProvider interface {
+		Hydra() Hydra
+	}
+	// Hydra talks to the admin API of an OAuth2 provider, such as Ory Hydra, which uses Ory Kratos as its
+	// login provider.
+	Hydra interface {
+		// GetLoginRequest fetches the OAuth2 login request identified by the login challenge.
+		GetLoginRequest(ctx context.Context, challenge string) (*LoginRequest, error)
+
+		// AcceptLoginRequest accepts the OAuth2 login request on behalf of the session's identity and returns
+		// the URL the browser must be redirected to in order to continue the OAuth2 flow.
+		AcceptLoginRequest(ctx context.Context, challenge string, s *session.Session) (string, error)
+	}
+	DefaultHydra struct {
+		d hydraDependencies
+	}
+
+	// LoginRequest is the OAuth2 login request as returned by the OAuth2 provider.
+	LoginRequest struct {
+		Challenge  string          `json:"challenge"`
+		Skip       bool            `json:"skip"`
+		Subject    string          `json:"subject"`
+		RequestURL string          `json:"request_url"`
+		Client     json.RawMessage `json:"client,omitempty"`
+	}
+
+	acceptLoginRequest struct {
+		Subject     string   `json:"subject"`
+		Remember    bool     `json:"remember"`
+		RememberFor int64    `json:"remember_for"`
+		ACR         string   `json:"acr,omitempty"`
+		AMR         []string `json:"amr,omitempty"`
+	}
+
+	completedRequest struct {
+		RedirectTo string `json:"redirect_to"`
+	}
+)
+
+var _ Hydra = new(DefaultHydra)
+
+// RequiresLogin answers if the OAuth2 client asked the user to authenticate again, either using `prompt=login`
+// or using a `max_age` which has passed since the given authentication time.
+func (lr *LoginRequest) RequiresLogin(authenticatedAt time.Time) bool {
+	u, err := url.Parse(lr.RequestURL)
+	if err != nil {
+		return false
+	}
+
+	q := u.Query()
+	for _, prompt := range strings.Fields(q.Get("prompt")) {
+		if prompt == "login" {
+			return true
+		}
+	}
+
+	if raw := q.Get("max_age"); len(raw) > 0 {
+		maxAge, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			// Fail closed if the client sent a max_age we do not understand.
+			return true
+		}
+		return time.Since(authenticatedAt) > time.Duration(maxAge)*time.Second
+	}
+
+	return false
+}
+
+func NewDefaultHydra(d hydraDependencies) *DefaultHydra {
+	return &DefaultHydra{d: d}
+}
+
+func (h *DefaultHydra) adminURL(ctx context.Context, path, challenge string) (string, error) {
+	u := h.d.Config().OAuth2ProviderURL(ctx)
+	if u == nil {
+		return "", errors.WithStack(herodot.ErrInternalServerError.WithReason("A login_challenge was provided but no OAuth2 provider URL is configured. Set oauth2_provider.url to the admin URL of your OAuth2 provider."))
+	}
+
+	return urlx.CopyWithQuery(urlx.AppendPaths(u, path), url.Values{"login_challenge": {challenge}}).String(), nil
+}
+
+func (h *DefaultHydra) do(ctx context.Context, method, path, challenge string, body, result interface{}) error {
+	u, err := h.adminURL(ctx, path, challenge)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&b).Encode(body); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	req, err := retryablehttp.NewRequest(method, u, b.Bytes())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := h.d.HTTPClient(ctx).Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to reach the OAuth2 provider: %s", err))
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusGone:
+		return errors.WithStack(herodot.ErrBadRequest.WithReason("The login_challenge is invalid or has expired. Please restart the OAuth2 flow."))
+	case res.StatusCode < 200 || res.StatusCode >= 300:
+		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("The OAuth2 provider responded with an unexpected status code %d.", res.StatusCode))
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to decode the response of the OAuth2 provider: %s", err))
+	}
+
+	return nil
+}
+
+func (h *DefaultHydra) GetLoginRequest(ctx context.Context, challenge string) (*LoginRequest, error) {
+	if len(challenge) == 0 {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReason("The login_challenge must not be empty."))
+	}
+
+	var lr LoginRequest
+	if err := h.do(ctx, "GET", routeLoginRequest, challenge, nil, &lr); err != nil {
+		return nil, err
+	}
+
+	return &lr, nil
+}
+
+func (h *DefaultHydra) AcceptLoginRequest(ctx context.Context, challenge string, s *session.Session) (string, error) {
+	amr := make([]string, len(s.AMR))
+	for k, m := range s.AMR {
+		amr[k] = string(m.Method)
+	}
+
+	// The OAuth2 provider remembers the login for as long as the session lives, but only if the session cookie
+	// itself outlives the browser session.
+	body := &acceptLoginRequest{
+		Subject: s.IdentityID.String(),
+		ACR:     string(s.AuthenticatorAssuranceLevel),
+		AMR:     amr,
+	}
+	if h.d.Config().SessionPersistentCookie(ctx) {
+		body.Remember = true
+		body.RememberFor = int64(h.d.Config().SessionLifespan(ctx).Seconds())
+	}
+
+	var cr completedRequest
+	if err := h.do(ctx, "PUT", routeAcceptLoginRequest, challenge, body, &cr); err != nil {
+		return "", err
+	}
+
+	if len(cr.RedirectTo) == 0 {
+		return "", errors.WithStack(herodot.ErrInternalServerError.WithReason("The OAuth2 provider did not return a URL to redirect to."))
+	}
+
+	return cr.RedirectTo, nil
+}
@@ -0,0 +1,130 @@
+package hydra_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/hydra"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/x"
+)
+
+func TestDefaultHydra(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+
+	var accepted []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("login_challenge") != "challenge" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/oauth2/auth/requests/login":
+			assert.Equal(t, "GET", r.Method)
+			_, _ = w.Write([]byte(`{"challenge":"challenge","skip":true,"subject":"subject","request_url":"https://client.example.org/"}`))
+		case "/oauth2/auth/requests/login/accept":
+			assert.Equal(t, "PUT", r.Method)
+			accepted, _ = io.ReadAll(r.Body)
+			_, _ = w.Write([]byte(`{"redirect_to":"https://hydra.example.org/oauth2/auth?login_verifier=verifier"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	conf.MustSet(ctx, config.ViperKeyOAuth2ProviderURL, ts.URL)
+
+	t.Run("method=GetLoginRequest", func(t *testing.T) {
+		lr, err := reg.Hydra().GetLoginRequest(ctx, "challenge")
+		require.NoError(t, err)
+		assert.Equal(t, "challenge", lr.Challenge)
+		assert.True(t, lr.Skip)
+		assert.Equal(t, "subject", lr.Subject)
+
+		_, err = reg.Hydra().GetLoginRequest(ctx, "unknown")
+		require.Error(t, err)
+		assert.Contains(t, fmt.Sprintf("%+v", err), "invalid or has expired")
+
+		_, err = reg.Hydra().GetLoginRequest(ctx, "")
+		require.Error(t, err)
+	})
+
+	t.Run("method=AcceptLoginRequest", func(t *testing.T) {
+		s := &session.Session{IdentityID: x.NewUUID(), AuthenticatorAssuranceLevel: identity.AuthenticatorAssuranceLevel2}
+		s.CompletedLoginFor(identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
+		s.CompletedLoginFor(identity.CredentialsTypeTOTP, identity.AuthenticatorAssuranceLevel2)
+
+		for _, tc := range []struct {
+			persistent  bool
+			remember    bool
+			rememberFor int64
+		}{
+			{persistent: true, remember: true, rememberFor: 3600},
+			{persistent: false, remember: false, rememberFor: 0},
+		} {
+			t.Run(fmt.Sprintf("persistent=%v", tc.persistent), func(t *testing.T) {
+				conf.MustSet(ctx, config.ViperKeySessionPersistentCookie, tc.persistent)
+				conf.MustSet(ctx, config.ViperKeySessionLifespan, "1h")
+
+				redirectTo, err := reg.Hydra().AcceptLoginRequest(ctx, "challenge", s)
+				require.NoError(t, err)
+				assert.Equal(t, "https://hydra.example.org/oauth2/auth?login_verifier=verifier", redirectTo)
+
+				assert.Equal(t, s.IdentityID.String(), gjson.GetBytes(accepted, "subject").String(), "%s", accepted)
+				assert.Equal(t, tc.remember, gjson.GetBytes(accepted, "remember").Bool(), "%s", accepted)
+				assert.Equal(t, tc.rememberFor, gjson.GetBytes(accepted, "remember_for").Int(), "%s", accepted)
+				assert.Equal(t, "aal2", gjson.GetBytes(accepted, "acr").String(), "%s", accepted)
+				assert.Equal(t, `["password","totp"]`, gjson.GetBytes(accepted, "amr").Raw, "%s", accepted)
+			})
+		}
+
+		_, err := reg.Hydra().AcceptLoginRequest(ctx, "unknown", s)
+		require.Error(t, err)
+	})
+
+	t.Run("case=fails without an OAuth2 provider URL", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyOAuth2ProviderURL, "")
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyOAuth2ProviderURL, ts.URL)
+		})
+
+		_, err := reg.Hydra().GetLoginRequest(ctx, "challenge")
+		require.Error(t, err)
+		assert.Contains(t, fmt.Sprintf("%+v", err), "oauth2_provider.url")
+	})
+}
+
+func TestLoginRequestRequiresLogin(t *testing.T) {
+	authenticatedAt := time.Now().Add(-time.Minute)
+	for k, tc := range []struct {
+		requestURL string
+		expected   bool
+	}{
+		{requestURL: "https://hydra.example.org/oauth2/auth?client_id=client", expected: false},
+		{requestURL: "https://hydra.example.org/oauth2/auth?prompt=login", expected: true},
+		{requestURL: "https://hydra.example.org/oauth2/auth?prompt=consent+login", expected: true},
+		{requestURL: "https://hydra.example.org/oauth2/auth?prompt=consent", expected: false},
+		{requestURL: "https://hydra.example.org/oauth2/auth?max_age=30", expected: true},
+		{requestURL: "https://hydra.example.org/oauth2/auth?max_age=0", expected: true},
+		{requestURL: "https://hydra.example.org/oauth2/auth?max_age=3600", expected: false},
+		{requestURL: "https://hydra.example.org/oauth2/auth?max_age=invalid", expected: true},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			lr := &hydra.LoginRequest{RequestURL: tc.requestURL}
+			assert.Equal(t, tc.expected, lr.RequiresLogin(authenticatedAt))
+		})
+	}
+}
@@ -2,10 +2,12 @@ package identity
 
 import (
 	"context"
+	"encoding/json"
 	"reflect"
 	"time"
 
 	"github.com/ory/kratos/ui/node"
+	"github.com/ory/kratos/x"
 
 	"github.com/gofrs/uuid"
 
@@ -97,6 +99,14 @@ type Credentials struct {
 
 	IdentityID uuid.UUID `json:"-" faker:"-" db:"identity_id"`
 
+	// WebAuthnUserHandle mirrors the user handle of WebAuthn credentials so that discoverable credentials can be
+	// looked up by an indexed exact match.
+	WebAuthnUserHandle sqlxx.NullString `json:"-" faker:"-" db:"webauthn_user_handle"`
+
+	// TOTPLastUsedTimeStep mirrors the time step of the last accepted TOTP code so that it can be advanced by a
+	// conditional update.
+	TOTPLastUsedTimeStep sqlxx.NullInt64 `json:"-" faker:"-" db:"totp_last_used_time_step"`
+
 	// CreatedAt is a helper struct field for gobuffalo.pop.
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 
@@ -144,6 +154,29 @@ type (
 	ActiveCredentialsCounterStrategyProvider interface {
 		ActiveCredentialsCounterStrategies(context.Context) []ActiveCredentialsCounter
 	}
+
+	// swagger:ignore
+	CredentialsAdminHandler interface {
+		ID() CredentialsType
+
+		// RegisterAdminCredentialsRoutes registers the admin endpoints which manage the credentials of an identity,
+		// below RouteItemCredentials.
+		RegisterAdminCredentialsRoutes(admin *x.RouterAdmin)
+	}
+
+	// swagger:ignore
+	CredentialsAdminHandlerProvider interface {
+		CredentialsAdminHandlers() []CredentialsAdminHandler
+	}
+
+	// swagger:ignore
+	CredentialsDescriber interface {
+		ID() CredentialsType
+
+		// DescribeCredentials returns the configuration of the credentials without any secrets, for example
+		// the names of registered security keys.
+		DescribeCredentials(c Credentials) (json.RawMessage, error)
+	}
 )
 
 func (c CredentialsTypeTable) TableName(ctx context.Context) string {
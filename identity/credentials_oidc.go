@@ -7,6 +7,9 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/ory/herodot"
+	"github.com/ory/x/stringslice"
+
 	"github.com/ory/kratos/x"
 )
 
@@ -18,6 +21,7 @@ type CredentialsOIDC struct {
 }
 
 // CredentialsOIDCProvider is contains a specific OpenID COnnect credential for a particular connection (e.g. Google).
+// The initial tokens are replaced when they are refreshed using the admin API.
 //
 // swagger:model identityCredentialsOidcProvider
 type CredentialsOIDCProvider struct {
@@ -55,3 +59,33 @@ func NewCredentialsOIDC(idToken, accessToken, refreshToken, provider, subject st
 func OIDCUniqueID(provider, subject string) string {
 	return fmt.Sprintf("%s:%s", provider, subject)
 }
+
+// MergeCredentialsOIDC adds the providers of the given OpenID Connect credentials to the identity's
+// OpenID Connect credentials. Providers which are linked to the identity already are skipped.
+func (i *Identity) MergeCredentialsOIDC(c Credentials) error {
+	var linked CredentialsOIDC
+	if err := json.Unmarshal(c.Config, &linked); err != nil {
+		return errors.WithStack(err)
+	}
+
+	var conf CredentialsOIDC
+	creds, err := i.ParseCredentials(CredentialsTypeOIDC, &conf)
+	if errors.Is(err, herodot.ErrNotFound) {
+		i.SetCredentials(CredentialsTypeOIDC, c)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, p := range linked.Providers {
+		id := OIDCUniqueID(p.Provider, p.Subject)
+		if stringslice.Has(creds.Identifiers, id) {
+			continue
+		}
+
+		creds.Identifiers = append(creds.Identifiers, id)
+		conf.Providers = append(conf.Providers, p)
+	}
+
+	return i.SetCredentialsWithConfig(CredentialsTypeOIDC, *creds, conf)
+}
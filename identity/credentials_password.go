@@ -1,9 +1,28 @@
 package identity
 
+import "time"
+
 // CredentialsPassword is contains the configuration for credentials of the type password.
 //
 // swagger:model identityCredentialsPassword
 type CredentialsPassword struct {
 	// HashedPassword is a hash-representation of the password.
 	HashedPassword string `json:"hashed_password"`
+
+	// PreviousHashedPasswords contains the hashes of previously used passwords, the most recent one first.
+	// It is used to prevent the reuse of passwords and is only populated if a password history is configured.
+	PreviousHashedPasswords []string `json:"previous_hashed_passwords,omitempty"`
+
+	// ChangedAt is the time when the password was last set.
+	ChangedAt *time.Time `json:"changed_at,omitempty"`
+}
+
+// IsExpired returns true if the password was set more than maxAge ago. Passwords for which the time
+// they were changed at is unknown never expire. A maxAge of zero disables password expiry.
+func (c *CredentialsPassword) IsExpired(maxAge time.Duration) bool {
+	if maxAge <= 0 || c.ChangedAt == nil {
+		return false
+	}
+
+	return c.ChangedAt.Add(maxAge).Before(time.Now())
 }
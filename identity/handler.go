@@ -39,6 +39,8 @@ type (
 		x.CSRFProvider
 		cipher.Provider
 		hash.HashProvider
+		ActiveCredentialsCounterStrategyProvider
+		CredentialsAdminHandlerProvider
 	}
 	HandlerProvider interface {
 		IdentityHandler() *Handler
@@ -62,8 +64,8 @@ func NewHandler(r handlerDependencies) *Handler {
 
 func (h *Handler) RegisterPublicRoutes(public *x.RouterPublic) {
 	h.r.CSRFHandler().IgnoreGlobs(
-		RouteCollection, RouteCollection+"/*",
-		x.AdminPrefix+RouteCollection, x.AdminPrefix+RouteCollection+"/*",
+		RouteCollection, RouteCollection+"/*", RouteCollection+"/*/*",
+		x.AdminPrefix+RouteCollection, x.AdminPrefix+RouteCollection+"/*", x.AdminPrefix+RouteCollection+"/*/*",
 	)
 
 	public.GET(RouteCollection, x.RedirectToAdminRoute(h.r))
@@ -72,6 +74,8 @@ func (h *Handler) RegisterPublicRoutes(public *x.RouterPublic) {
 	public.POST(RouteCollection, x.RedirectToAdminRoute(h.r))
 	public.PUT(RouteItem, x.RedirectToAdminRoute(h.r))
 	public.PATCH(RouteItem, x.RedirectToAdminRoute(h.r))
+	public.GET(RouteItemCredentials, x.RedirectToAdminRoute(h.r))
+	public.POST(RouteItemApprove, x.RedirectToAdminRoute(h.r))
 
 	public.GET(x.AdminPrefix+RouteCollection, x.RedirectToAdminRoute(h.r))
 	public.GET(x.AdminPrefix+RouteItem, x.RedirectToAdminRoute(h.r))
@@ -79,6 +83,8 @@ func (h *Handler) RegisterPublicRoutes(public *x.RouterPublic) {
 	public.POST(x.AdminPrefix+RouteCollection, x.RedirectToAdminRoute(h.r))
 	public.PUT(x.AdminPrefix+RouteItem, x.RedirectToAdminRoute(h.r))
 	public.PATCH(x.AdminPrefix+RouteItem, x.RedirectToAdminRoute(h.r))
+	public.GET(x.AdminPrefix+RouteItemCredentials, x.RedirectToAdminRoute(h.r))
+	public.POST(x.AdminPrefix+RouteItemApprove, x.RedirectToAdminRoute(h.r))
 }
 
 func (h *Handler) RegisterAdminRoutes(admin *x.RouterAdmin) {
@@ -89,6 +95,13 @@ func (h *Handler) RegisterAdminRoutes(admin *x.RouterAdmin) {
 
 	admin.POST(RouteCollection, h.create)
 	admin.PUT(RouteItem, h.update)
+
+	admin.GET(RouteItemCredentials, h.listCredentials)
+	admin.POST(RouteItemApprove, h.approve)
+
+	for _, ch := range h.r.CredentialsAdminHandlers() {
+		ch.RegisterAdminCredentialsRoutes(admin)
+	}
 }
 
 // A list of identities.
@@ -254,6 +267,13 @@ type AdminCreateIdentityBody struct {
 	//
 	// required: false
 	State State `json:"state"`
+
+	// CredentialsResetRequired forces the identity to replace its credentials after the next login.
+	//
+	// Can be `password` or `second_factor`.
+	//
+	// required: false
+	CredentialsResetRequired CredentialsReset `json:"credentials_reset_required,omitempty"`
 }
 
 // swagger:model adminIdentityImportCredentials
@@ -346,6 +366,11 @@ func (h *Handler) create(w http.ResponseWriter, r *http.Request, _ httprouter.Pa
 		state = cr.State
 	}
 
+	if err := cr.CredentialsResetRequired.IsValid(); err != nil {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithReasonf("%s", err).WithWrap(err)))
+		return
+	}
+
 	i := &Identity{
 		SchemaID:            cr.SchemaID,
 		Traits:              []byte(cr.Traits),
@@ -355,6 +380,8 @@ func (h *Handler) create(w http.ResponseWriter, r *http.Request, _ httprouter.Pa
 		RecoveryAddresses:   cr.RecoveryAddresses,
 		MetadataAdmin:       []byte(cr.MetadataAdmin),
 		MetadataPublic:      []byte(cr.MetadataPublic),
+
+		CredentialsResetRequired: cr.CredentialsResetRequired,
 	}
 
 	if err := h.importCredentials(r.Context(), i, cr.Credentials); err != nil {
@@ -422,6 +449,14 @@ type AdminUpdateIdentityBody struct {
 	//
 	// required: true
 	State State `json:"state"`
+
+	// CredentialsResetRequired forces the identity to replace its credentials after the next login.
+	//
+	// Can be `password` or `second_factor`. Set to an empty string to remove the requirement. If
+	// omitted, the current value is kept.
+	//
+	// required: false
+	CredentialsResetRequired *CredentialsReset `json:"credentials_reset_required,omitempty"`
 }
 
 // swagger:route PUT /admin/identities/{id} v0alpha2 adminUpdateIdentity
@@ -480,6 +515,14 @@ func (h *Handler) update(w http.ResponseWriter, r *http.Request, ps httprouter.P
 		identity.StateChangedAt = &stateChangedAt
 	}
 
+	if ur.CredentialsResetRequired != nil {
+		if err := ur.CredentialsResetRequired.IsValid(); err != nil {
+			h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithReasonf("%s", err).WithWrap(err)))
+			return
+		}
+		identity.CredentialsResetRequired = *ur.CredentialsResetRequired
+	}
+
 	identity.Traits = []byte(ur.Traits)
 	identity.MetadataPublic = []byte(ur.MetadataPublic)
 	identity.MetadataAdmin = []byte(ur.MetadataAdmin)
@@ -0,0 +1,70 @@
+package identity
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/sqlxx"
+
+	"github.com/ory/kratos/x"
+)
+
+const RouteItemApprove = RouteItem + "/approve"
+
+// swagger:parameters adminApproveIdentity
+// nolint:deadcode,unused
+type adminApproveIdentity struct {
+	// ID must be set to the ID of the pending identity you want to approve
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+}
+
+// swagger:route POST /admin/identities/{id}/approve v0alpha2 adminApproveIdentity
+//
+// # Approve a Pending Identity
+//
+// Approves an identity which is in the `pending` state, for example because it signed up while the
+// `require_approval` registration hook was enabled. The identity becomes `active` and is able to sign in.
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  200: identity
+//	  400: jsonError
+//	  404: jsonError
+//	  500: jsonError
+func (h *Handler) approve(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i, err := h.r.PrivilegedIdentityPool().GetIdentityConfidential(r.Context(), x.ParseUUID(ps.ByName("id")))
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if i.State != StatePending {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Only identities in state %s can be approved but this identity is %s.", StatePending, i.State)))
+		return
+	}
+
+	stateChangedAt := sqlxx.NullTime(time.Now())
+	i.State = StateActive
+	i.StateChangedAt = &stateChangedAt
+
+	if err := h.r.PrivilegedIdentityPool().UpdateIdentity(r.Context(), i); err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	h.r.Writer().Write(w, r, WithCredentialsMetadataAndAdminMetadataInJSON(*i))
+}
@@ -0,0 +1,117 @@
+package identity
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/ory/kratos/x"
+)
+
+const RouteItemCredentials = RouteItem + "/credentials"
+
+// Credentials Description
+//
+// Describes an identity's credentials without revealing any secrets.
+//
+// swagger:model identityCredentialsDescription
+type CredentialsDescription struct {
+	// Type discriminates between different types of credentials.
+	//
+	// required: true
+	Type CredentialsType `json:"type"`
+
+	// Identifiers represents a list of unique identifiers this credential type matches.
+	Identifiers []string `json:"identifiers"`
+
+	// Version refers to the version of the credential. Useful when changing the config schema.
+	Version int `json:"version"`
+
+	// Metadata describes the credentials, for example the security keys registered for WebAuthn or the number of
+	// unused lookup secrets. Its format depends on the credentials type and it never contains any secrets.
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+
+	// CreatedAt is the time the credentials were created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the time the credentials were last updated.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// A list of credentials descriptions.
+// swagger:model identityCredentialsDescriptionList
+// nolint:deadcode,unused
+type identityCredentialsDescriptionList []CredentialsDescription
+
+// swagger:parameters adminListIdentityCredentials
+// nolint:deadcode,unused
+type adminListIdentityCredentials struct {
+	// ID must be set to the ID of identity you want to get
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+}
+
+// swagger:route GET /admin/identities/{id}/credentials v0alpha2 adminListIdentityCredentials
+//
+// # List an Identity's Credentials
+//
+// Lists the credentials of an identity together with metadata which is safe to share with support staff, for example
+// the IDs and names of registered security keys. Secrets such as password hashes or TOTP keys are never returned.
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  200: identityCredentialsDescriptionList
+//	  404: jsonError
+//	  500: jsonError
+func (h *Handler) listCredentials(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i, err := h.r.PrivilegedIdentityPool().GetIdentityConfidential(r.Context(), x.ParseUUID(ps.ByName("id")))
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	describers := map[CredentialsType]CredentialsDescriber{}
+	for _, strategy := range h.r.ActiveCredentialsCounterStrategies(r.Context()) {
+		if d, ok := strategy.(CredentialsDescriber); ok {
+			describers[d.ID()] = d
+		}
+	}
+
+	result := make([]CredentialsDescription, 0, len(i.Credentials))
+	for _, c := range i.Credentials {
+		description := CredentialsDescription{
+			Type:        c.Type,
+			Identifiers: c.Identifiers,
+			Version:     c.Version,
+			CreatedAt:   c.CreatedAt,
+			UpdatedAt:   c.UpdatedAt,
+		}
+
+		if d, ok := describers[c.Type]; ok {
+			if description.Metadata, err = d.DescribeCredentials(c); err != nil {
+				h.r.Writer().WriteError(w, r, err)
+				return
+			}
+		}
+
+		result = append(result, description)
+	}
+
+	sort.Slice(result, func(a, b int) bool {
+		return result[a].Type < result[b].Type
+	})
+
+	h.r.Writer().Write(w, r, result)
+}
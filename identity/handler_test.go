@@ -30,6 +30,7 @@ import (
 	"github.com/ory/kratos/internal"
 	"github.com/ory/kratos/internal/testhelpers"
 	"github.com/ory/kratos/schema"
+	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/x"
 )
 
@@ -738,6 +739,37 @@ func TestHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("case=should list credentials without secrets", func(t *testing.T) {
+		i := identity.NewIdentity("")
+		i.SetCredentials(identity.CredentialsTypePassword, identity.Credentials{
+			Type:        identity.CredentialsTypePassword,
+			Identifiers: []string{x.NewUUID().String()},
+			Config:      sqlxx.JSONRawMessage(`{"hashed_password":"pst"}`),
+		})
+		i.SetCredentials(identity.CredentialsTypeLookup, identity.Credentials{
+			Type:        identity.CredentialsTypeLookup,
+			Identifiers: []string{i.ID.String()},
+			Config:      sqlxx.JSONRawMessage(`{"recovery_codes":[{"code":"secret-code"}]}`),
+		})
+		i.Traits = identity.Traits("{}")
+		require.NoError(t, reg.Persister().CreateIdentity(context.Background(), i))
+
+		for name, ts := range map[string]*httptest.Server{"public": publicTS, "admin": adminTS} {
+			t.Run("endpoint="+name, func(t *testing.T) {
+				res := get(t, ts, "/identities/"+i.ID.String()+"/credentials", http.StatusOK)
+				require.Len(t, res.Array(), 2, "%s", res.Raw)
+				assert.EqualValues(t, identity.CredentialsTypeLookup, res.Get("0.type").String(), "%s", res.Raw)
+				assert.EqualValues(t, 1, res.Get("0.metadata.remaining").Int(), "%s", res.Raw)
+				assert.EqualValues(t, identity.CredentialsTypePassword, res.Get("1.type").String(), "%s", res.Raw)
+				assert.False(t, res.Get("1.metadata").Exists(), "%s", res.Raw)
+				assert.NotContains(t, res.Raw, "pst")
+				assert.NotContains(t, res.Raw, "secret-code")
+			})
+		}
+
+		_ = get(t, adminTS, "/identities/"+x.NewUUID().String()+"/credentials", http.StatusNotFound)
+	})
+
 	t.Run("case=should not be able to create an identity with an invalid schema", func(t *testing.T) {
 		for name, ts := range map[string]*httptest.Server{"public": publicTS, "admin": adminTS} {
 			t.Run("endpoint="+name, func(t *testing.T) {
@@ -814,6 +846,101 @@ func TestHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("case=should approve a pending identity", func(t *testing.T) {
+		for name, ts := range map[string]*httptest.Server{"public": publicTS, "admin": adminTS} {
+			t.Run("endpoint="+name, func(t *testing.T) {
+				var cr identity.AdminCreateIdentityBody
+				cr.SchemaID = "employee"
+				cr.Traits = []byte(`{"email":"` + x.NewUUID().String() + `@ory.sh"}`)
+				cr.State = identity.StatePending
+
+				res := send(t, ts, "POST", "/identities", http.StatusCreated, &cr)
+				assert.EqualValues(t, identity.StatePending, res.Get("state").String(), "%s", res.Raw)
+				id := res.Get("id").String()
+
+				res = send(t, ts, "POST", "/identities/"+id+"/approve", http.StatusOK, nil)
+				assert.EqualValues(t, identity.StateActive, res.Get("state").String(), "%s", res.Raw)
+				assert.True(t, res.Get("state_changed_at").Exists(), "%s", res.Raw)
+
+				res = send(t, ts, "POST", "/identities/"+id+"/approve", http.StatusBadRequest, nil)
+				assert.Contains(t, res.Get("error.reason").String(), "can be approved", "%s", res.Raw)
+
+				_ = send(t, ts, "POST", "/identities/"+x.NewUUID().String()+"/approve", http.StatusNotFound, nil)
+			})
+		}
+	})
+
+	t.Run("case=should require and clear a credentials reset", func(t *testing.T) {
+		for name, ts := range map[string]*httptest.Server{"public": publicTS, "admin": adminTS} {
+			t.Run("endpoint="+name, func(t *testing.T) {
+				var cr identity.AdminCreateIdentityBody
+				cr.SchemaID = "employee"
+				cr.Traits = []byte(`{"email":"` + x.NewUUID().String() + `@ory.sh"}`)
+				cr.CredentialsResetRequired = identity.CredentialsResetPassword
+
+				res := send(t, ts, "POST", "/identities", http.StatusCreated, &cr)
+				assert.EqualValues(t, identity.CredentialsResetPassword, res.Get("credentials_reset_required").String(), "%s", res.Raw)
+
+				id := res.Get("id").String()
+				res = send(t, ts, "PUT", "/identities/"+id, http.StatusOK, &identity.AdminUpdateIdentityBody{
+					Traits: cr.Traits,
+				})
+				assert.EqualValues(t, identity.CredentialsResetPassword, res.Get("credentials_reset_required").String(), "%s", res.Raw)
+
+				reset := identity.CredentialsResetSecondFactor
+				res = send(t, ts, "PUT", "/identities/"+id, http.StatusOK, &identity.AdminUpdateIdentityBody{
+					Traits:                   cr.Traits,
+					CredentialsResetRequired: &reset,
+				})
+				assert.EqualValues(t, identity.CredentialsResetSecondFactor, res.Get("credentials_reset_required").String(), "%s", res.Raw)
+
+				reset = identity.CredentialsResetNone
+				res = send(t, ts, "PUT", "/identities/"+id, http.StatusOK, &identity.AdminUpdateIdentityBody{
+					Traits:                   cr.Traits,
+					CredentialsResetRequired: &reset,
+				})
+				assert.False(t, res.Get("credentials_reset_required").Exists(), "%s", res.Raw)
+
+				reset = "invalid-reset"
+				res = send(t, ts, "PUT", "/identities/"+id, http.StatusBadRequest, &identity.AdminUpdateIdentityBody{
+					Traits:                   cr.Traits,
+					CredentialsResetRequired: &reset,
+				})
+				assert.Contains(t, res.Get("error.reason").String(), `identity credentials reset is not valid`, "%s", res.Raw)
+			})
+		}
+	})
+
+	t.Run("case=should refresh the second factor enrollment requirement of the sessions", func(t *testing.T) {
+		i := &identity.Identity{Traits: identity.Traits(fmt.Sprintf(`{"subject":"%s"}`, x.NewUUID().String()))}
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+
+		s, err := session.NewActiveSession(ctx, i, conf, time.Now().UTC(), identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
+		require.NoError(t, err)
+		require.NoError(t, reg.SessionPersister().UpsertSession(ctx, s))
+
+		update := func(t *testing.T, expected bool) {
+			send(t, adminTS, "PUT", "/identities/"+i.ID.String(), http.StatusOK, &identity.AdminUpdateIdentityBody{
+				Traits: json.RawMessage(i.Traits),
+				Credentials: &identity.AdminIdentityImportCredentials{
+					Password: &identity.AdminIdentityImportCredentialsPassword{
+						Config: identity.AdminIdentityImportCredentialsPasswordConfig{Password: "pswd1234"},
+					},
+				},
+			})
+
+			actual, err := reg.SessionPersister().GetSession(ctx, s.ID)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual.MFAEnrollmentRequired)
+		}
+
+		conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentEnabled, true)
+		update(t, true)
+
+		conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentEnabled, false)
+		update(t, false)
+	})
+
 	t.Run("case=should create and sync metadata and update privileged traits", func(t *testing.T) {
 		for name, ts := range map[string]*httptest.Server{"public": publicTS, "admin": adminTS} {
 			t.Run("endpoint="+name, func(t *testing.T) {
@@ -16,6 +16,7 @@ import (
 
 	"github.com/ory/herodot"
 	"github.com/ory/x/sqlxx"
+	"github.com/ory/x/stringslice"
 
 	"github.com/ory/kratos/driver/config"
 
@@ -27,7 +28,8 @@ import (
 
 // An Identity's State
 //
-// The state can either be `active` or `inactive`.
+// The state can either be `active`, `inactive`, or `pending`. Identities which are `pending` await
+// the approval of an administrator.
 //
 // swagger:model identityState
 type State string
@@ -35,16 +37,51 @@ type State string
 const (
 	StateActive   State = "active"
 	StateInactive State = "inactive"
+	StatePending  State = "pending"
 )
 
 func (lt State) IsValid() error {
 	switch lt {
-	case StateActive, StateInactive:
+	case StateActive, StateInactive, StatePending:
 		return nil
 	}
 	return errors.New("identity state is not valid")
 }
 
+// CredentialsReset marks that an identity must replace some of its credentials
+//
+// The value can either be empty, `password`, or `second_factor`. If set, the identity
+// is sent to the settings flow after the next login and must update the respective
+// credentials before the session can be used.
+//
+// swagger:model identityCredentialsReset
+type CredentialsReset string
+
+const (
+	CredentialsResetNone         CredentialsReset = ""
+	CredentialsResetPassword     CredentialsReset = "password"
+	CredentialsResetSecondFactor CredentialsReset = "second_factor"
+)
+
+func (cr CredentialsReset) IsValid() error {
+	switch cr {
+	case CredentialsResetNone, CredentialsResetPassword, CredentialsResetSecondFactor:
+		return nil
+	}
+	return errors.New("identity credentials reset is not valid")
+}
+
+// IsSatisfiedBy returns true if updating the given credentials type resolves the reset requirement.
+func (cr CredentialsReset) IsSatisfiedBy(t CredentialsType) bool {
+	switch cr {
+	case CredentialsResetPassword:
+		return t == CredentialsTypePassword
+	case CredentialsResetSecondFactor:
+		return t == CredentialsTypeTOTP || t == CredentialsTypeWebAuthn || t == CredentialsTypeLookup
+	}
+	return false
+}
+
 //type IdentifierCredential struct {
 //	Subject      string `json:"subject"`
 //	Provider     string `json:"provider"`
@@ -94,6 +131,9 @@ type Identity struct {
 	// StateChangedAt contains the last time when the identity's state changed.
 	StateChangedAt *sqlxx.NullTime `json:"state_changed_at,omitempty" faker:"-" db:"state_changed_at"`
 
+	// CredentialsResetRequired is set if the identity must replace its credentials after the next login.
+	CredentialsResetRequired CredentialsReset `json:"credentials_reset_required,omitempty" faker:"-" db:"credentials_reset_required"`
+
 	// Traits represent an identity's traits. The identity is able to create, modify, and delete traits
 	// in a self-service manner. The input will always be validated against the JSON Schema defined
 	// in `schema_url`.
@@ -274,6 +314,33 @@ func (i *Identity) CopyWithoutCredentials() *Identity {
 	return &ii
 }
 
+// EmailAddresses returns the unique email addresses of the identity's verifiable and recovery addresses.
+func (i *Identity) EmailAddresses() []string {
+	var addresses []string
+	for _, a := range i.VerifiableAddresses {
+		if a.Via == AddressTypeEmail {
+			addresses = append(addresses, a.Value)
+		}
+	}
+	for _, a := range i.RecoveryAddresses {
+		if a.Via == AddressTypeEmail {
+			addresses = append(addresses, a.Value)
+		}
+	}
+	return stringslice.Unique(addresses)
+}
+
+// PhoneNumbers returns the unique phone numbers of the identity's verifiable addresses.
+func (i *Identity) PhoneNumbers() []string {
+	var numbers []string
+	for _, a := range i.VerifiableAddresses {
+		if a.Via == AddressTypePhone {
+			numbers = append(numbers, a.Value)
+		}
+	}
+	return stringslice.Unique(numbers)
+}
+
 func NewIdentity(traitsSchemaID string) *Identity {
 	if traitsSchemaID == "" {
 		traitsSchemaID = config.DefaultIdentityTraitsSchemaID
@@ -90,6 +90,31 @@ func TestIdentityCredentials(t *testing.T) {
 	assert.Equal(t, &expectedTOTP, actual)
 }
 
+func TestMergeCredentialsOIDC(t *testing.T) {
+	i := NewIdentity(config.DefaultIdentityTraitsSchemaID)
+
+	google, err := NewCredentialsOIDC("", "", "", "google", "123")
+	require.NoError(t, err)
+	require.NoError(t, i.MergeCredentialsOIDC(*google))
+
+	actual, found := i.GetCredentials(CredentialsTypeOIDC)
+	require.True(t, found, "should set the credentials if the identity has none")
+	assert.Equal(t, []string{"google:123"}, actual.Identifiers)
+
+	github, err := NewCredentialsOIDC("", "", "", "github", "456")
+	require.NoError(t, err)
+	require.NoError(t, i.MergeCredentialsOIDC(*github))
+	require.NoError(t, i.MergeCredentialsOIDC(*github))
+
+	var conf CredentialsOIDC
+	actual, err = i.ParseCredentials(CredentialsTypeOIDC, &conf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"google:123", "github:456"}, actual.Identifiers, "should add each provider only once")
+	require.Len(t, conf.Providers, 2)
+	assert.Equal(t, "github", conf.Providers[1].Provider)
+	assert.Equal(t, "456", conf.Providers[1].Subject)
+}
+
 func TestMarshalExcludesCredentials(t *testing.T) {
 	i := NewIdentity(config.DefaultIdentityTraitsSchemaID)
 	i.Credentials = map[CredentialsType]Credentials{
@@ -239,3 +264,30 @@ func TestValidateNID(t *testing.T) {
 		})
 	}
 }
+
+func TestEmailAddresses(t *testing.T) {
+	i := &Identity{
+		VerifiableAddresses: []VerifiableAddress{
+			{Via: AddressTypeEmail, Value: "foo@ory.sh"},
+			{Via: "sms", Value: "+4917612345678"},
+		},
+		RecoveryAddresses: []RecoveryAddress{
+			{Via: AddressTypeEmail, Value: "foo@ory.sh"},
+			{Via: AddressTypeEmail, Value: "bar@ory.sh"},
+		},
+	}
+	assert.Equal(t, []string{"foo@ory.sh", "bar@ory.sh"}, i.EmailAddresses())
+	assert.Empty(t, new(Identity).EmailAddresses())
+}
+
+func TestPhoneNumbers(t *testing.T) {
+	i := &Identity{
+		VerifiableAddresses: []VerifiableAddress{
+			{Via: AddressTypeEmail, Value: "foo@ory.sh"},
+			{Via: AddressTypePhone, Value: "+4917612345678"},
+			{Via: AddressTypePhone, Value: "+4917612345678"},
+		},
+	}
+	assert.Equal(t, []string{"+4917612345678"}, i.PhoneNumbers())
+	assert.Empty(t, new(Identity).PhoneNumbers())
+}
@@ -28,10 +28,19 @@ type (
 		courier.Provider
 		ValidationProvider
 		ActiveCredentialsCounterStrategyProvider
+		MFAEnrollmentRefresherProvider
 	}
 	ManagementProvider interface {
 		IdentityManager() *Manager
 	}
+
+	// MFAEnrollmentRefresher updates whether the sessions of an identity require it to set up a second factor.
+	MFAEnrollmentRefresher interface {
+		RefreshMFAEnrollment(ctx context.Context, i *Identity) error
+	}
+	MFAEnrollmentRefresherProvider interface {
+		MFAEnrollmentRefresher() MFAEnrollmentRefresher
+	}
 	Manager struct {
 		r managerDependencies
 	}
@@ -111,7 +120,11 @@ func (m *Manager) Update(ctx context.Context, updated *Identity, opts ...Manager
 		return err
 	}
 
-	return m.r.IdentityPool().(PrivilegedPool).UpdateIdentity(ctx, updated)
+	if err := m.r.IdentityPool().(PrivilegedPool).UpdateIdentity(ctx, updated); err != nil {
+		return err
+	}
+
+	return m.r.MFAEnrollmentRefresher().RefreshMFAEnrollment(ctx, updated)
 }
 
 func (m *Manager) UpdateSchemaID(ctx context.Context, id uuid.UUID, schemaID string, opts ...ManagerOption) error {
@@ -130,7 +143,11 @@ func (m *Manager) UpdateSchemaID(ctx context.Context, id uuid.UUID, schemaID str
 		return err
 	}
 
-	return m.r.IdentityPool().(PrivilegedPool).UpdateIdentity(ctx, original)
+	if err := m.r.IdentityPool().(PrivilegedPool).UpdateIdentity(ctx, original); err != nil {
+		return err
+	}
+
+	return m.r.MFAEnrollmentRefresher().RefreshMFAEnrollment(ctx, original)
 }
 
 func (m *Manager) SetTraits(ctx context.Context, id uuid.UUID, traits Traits, opts ...ManagerOption) (*Identity, error) {
@@ -160,7 +177,11 @@ func (m *Manager) UpdateTraits(ctx context.Context, id uuid.UUID, traits Traits,
 		return err
 	}
 
-	return m.r.IdentityPool().(PrivilegedPool).UpdateIdentity(ctx, updated)
+	if err := m.r.IdentityPool().(PrivilegedPool).UpdateIdentity(ctx, updated); err != nil {
+		return err
+	}
+
+	return m.r.MFAEnrollmentRefresher().RefreshMFAEnrollment(ctx, updated)
 }
 
 func (m *Manager) validate(ctx context.Context, i *Identity, o *managerOptions) error {
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/gofrs/uuid"
+
+	"github.com/ory/x/sqlxx"
 )
 
 type (
@@ -39,6 +41,18 @@ type (
 		// FindByCredentialsIdentifier returns an identity by querying for it's credential identifiers.
 		FindByCredentialsIdentifier(ctx context.Context, ct CredentialsType, match string) (*Identity, *Credentials, error)
 
+		// FindByWebAuthnUserHandle returns the identity including its raw credentials whose WebAuthn credentials
+		// were registered with the given user handle.
+		FindByWebAuthnUserHandle(ctx context.Context, userHandle []byte) (*Identity, error)
+
+		// UseTOTPTimeStep records that a TOTP code of the given time step was accepted and stores the updated
+		// credentials config. It returns sqlcon.ErrNoRows if this or a later time step was used already.
+		UseTOTPTimeStep(ctx context.Context, credentialsID uuid.UUID, step uint64, config sqlxx.JSONRawMessage) error
+
+		// UpdateCredentialsConfigIfUnchanged stores the config of the credentials if it still equals previous. It
+		// returns sqlcon.ErrNoRows if the credentials were changed or removed in the meantime.
+		UpdateCredentialsConfigIfUnchanged(ctx context.Context, credentialsID uuid.UUID, previous, config sqlxx.JSONRawMessage) error
+
 		// DeleteIdentity removes an identity by its id. Will return an error
 		// if identity exists, backend connectivity is broken, or trait validation fails.
 		DeleteIdentity(context.Context, uuid.UUID) error
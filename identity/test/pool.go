@@ -473,6 +473,91 @@ func TestPool(ctx context.Context, conf *config.Config, p interface {
 			})
 		})
 
+		t.Run("case=find identity by its webauthn user handle", func(t *testing.T) {
+			handle := x.NewUUID()
+			expected := identity.NewIdentity("")
+			expected.SetCredentials(identity.CredentialsTypeWebAuthn, identity.Credentials{
+				Type:        identity.CredentialsTypeWebAuthn,
+				Identifiers: []string{x.NewUUID().String()},
+				Config:      sqlxx.JSONRawMessage(fmt.Sprintf(`{"credentials":[],"user_handle":"%s"}`, base64.StdEncoding.EncodeToString(handle.Bytes()))),
+			})
+			require.NoError(t, p.CreateIdentity(ctx, expected))
+			createdIDs = append(createdIDs, expected.ID)
+
+			actual, err := p.FindByWebAuthnUserHandle(ctx, handle.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, expected.ID, actual.ID)
+			_, ok := actual.GetCredentials(identity.CredentialsTypeWebAuthn)
+			assert.True(t, ok)
+
+			for _, unknown := range [][]byte{nil, x.NewUUID().Bytes(), handle.Bytes()[:8]} {
+				_, err = p.FindByWebAuthnUserHandle(ctx, unknown)
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			}
+
+			t.Run("not if on another network", func(t *testing.T) {
+				_, p := testhelpers.NewNetwork(t, ctx, p)
+				_, err := p.FindByWebAuthnUserHandle(ctx, handle.Bytes())
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+		})
+
+		t.Run("case=use totp time step", func(t *testing.T) {
+			expected := identity.NewIdentity("")
+			expected.SetCredentials(identity.CredentialsTypeTOTP, identity.Credentials{
+				Type:        identity.CredentialsTypeTOTP,
+				Identifiers: []string{x.NewUUID().String()},
+				Config:      sqlxx.JSONRawMessage(`{"totp_url":"otpauth://totp/foo","last_used_time_step":5}`),
+			})
+			require.NoError(t, p.CreateIdentity(ctx, expected))
+			createdIDs = append(createdIDs, expected.ID)
+			c := expected.Credentials[identity.CredentialsTypeTOTP]
+
+			for _, step := range []uint64{4, 5} {
+				require.ErrorIs(t, p.UseTOTPTimeStep(ctx, c.ID, step, c.Config), sqlcon.ErrNoRows, "%d", step)
+			}
+
+			require.NoError(t, p.UseTOTPTimeStep(ctx, c.ID, 6, sqlxx.JSONRawMessage(`{"totp_url":"otpauth://totp/foo","last_used_time_step":6}`)))
+			require.ErrorIs(t, p.UseTOTPTimeStep(ctx, c.ID, 6, c.Config), sqlcon.ErrNoRows)
+
+			actual, err := p.GetIdentityConfidential(ctx, expected.ID)
+			require.NoError(t, err)
+			assert.EqualValues(t, 6, gjson.GetBytes(actual.Credentials[identity.CredentialsTypeTOTP].Config, "last_used_time_step").Int())
+
+			t.Run("not if on another network", func(t *testing.T) {
+				_, p := testhelpers.NewNetwork(t, ctx, p)
+				require.ErrorIs(t, p.UseTOTPTimeStep(ctx, c.ID, 7, c.Config), sqlcon.ErrNoRows)
+			})
+		})
+
+		t.Run("case=update credentials config if unchanged", func(t *testing.T) {
+			expected := identity.NewIdentity("")
+			expected.SetCredentials(identity.CredentialsTypeOIDC, identity.Credentials{
+				Type:        identity.CredentialsTypeOIDC,
+				Identifiers: []string{"google:" + x.NewUUID().String()},
+				Config:      sqlxx.JSONRawMessage(`{"providers":[{"provider":"google","initial_refresh_token":"0"}]}`),
+			})
+			require.NoError(t, p.CreateIdentity(ctx, expected))
+			createdIDs = append(createdIDs, expected.ID)
+
+			stored, err := p.GetIdentityConfidential(ctx, expected.ID)
+			require.NoError(t, err)
+			c := stored.Credentials[identity.CredentialsTypeOIDC]
+
+			first := sqlxx.JSONRawMessage(`{"providers":[{"provider":"google","initial_refresh_token":"1"}]}`)
+			require.NoError(t, p.UpdateCredentialsConfigIfUnchanged(ctx, c.ID, c.Config, first))
+			require.ErrorIs(t, p.UpdateCredentialsConfigIfUnchanged(ctx, c.ID, c.Config, sqlxx.JSONRawMessage(`{"providers":[{"provider":"google","initial_refresh_token":"2"}]}`)), sqlcon.ErrNoRows)
+
+			actual, err := p.GetIdentityConfidential(ctx, expected.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "1", gjson.GetBytes(actual.Credentials[identity.CredentialsTypeOIDC].Config, "providers.0.initial_refresh_token").String())
+
+			t.Run("not if on another network", func(t *testing.T) {
+				_, p := testhelpers.NewNetwork(t, ctx, p)
+				require.ErrorIs(t, p.UpdateCredentialsConfigIfUnchanged(ctx, c.ID, first, c.Config), sqlcon.ErrNoRows)
+			})
+		})
+
 		t.Run("case=find identity by its credentials respects cases", func(t *testing.T) {
 			caseSensitive := "6Q(%ZKd~8u_(5uea@ory.sh"
 			caseInsensitiveWithSpaces := " 6Q(%ZKD~8U_(5uea@ORY.sh "
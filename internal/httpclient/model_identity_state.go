@@ -16,13 +16,14 @@ import (
 	"fmt"
 )
 
-// IdentityState The state can either be `active` or `inactive`.
+// IdentityState The state can either be `active`, `inactive`, or `pending`. Identities which are `pending` await the approval of an administrator.
 type IdentityState string
 
 // List of identityState
 const (
 	IDENTITYSTATE_ACTIVE   IdentityState = "active"
 	IDENTITYSTATE_INACTIVE IdentityState = "inactive"
+	IDENTITYSTATE_PENDING  IdentityState = "pending"
 )
 
 func (v *IdentityState) UnmarshalJSON(src []byte) error {
@@ -32,7 +33,7 @@ func (v *IdentityState) UnmarshalJSON(src []byte) error {
 		return err
 	}
 	enumTypeValue := IdentityState(value)
-	for _, existing := range []IdentityState{"active", "inactive"} {
+	for _, existing := range []IdentityState{"active", "inactive", "pending"} {
 		if existing == enumTypeValue {
 			*v = enumTypeValue
 			return nil
@@ -31,6 +31,7 @@ import (
 
 	"github.com/ory/kratos/driver"
 	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/persistence/sql"
 	"github.com/ory/kratos/selfservice/flow/login"
 	"github.com/ory/kratos/selfservice/flow/recovery"
 	"github.com/ory/kratos/selfservice/flow/registration"
@@ -121,6 +122,7 @@ func TestMigrations(t *testing.T) {
 					fsx.Merge(os.DirFS("../migrations/sql")),
 					popx.NewMigrator(c, logrusx.New("", "", logrusx.ForceLevel(logrus.DebugLevel)), nil, 1*time.Minute),
 					popx.WithTestdata(t, os.DirFS("./testdata")),
+					popx.WithGoMigrations(sql.GoMigrations),
 				)
 				require.NoError(t, err)
 				require.NoError(t, tm.Up(ctx))
@@ -172,6 +174,13 @@ func TestMigrations(t *testing.T) {
 					migratest.ContainsExpectedIds(t, filepath.Join("fixtures", "identity"), found)
 				})
 
+				t.Run("case=webauthn_user_handle", func(t *testing.T) {
+					id := x.ParseUUID("0b5d3f6e-3c8a-4d1b-9f2e-6a7c8d9e0f12")
+					actual, err := d.PrivilegedIdentityPool().FindByWebAuthnUserHandle(context.Background(), id.Bytes())
+					require.NoError(t, err)
+					assert.Equal(t, id, actual.ID)
+				})
+
 				t.Run("case=verification_token", func(t *testing.T) {
 					var ids []link.VerificationToken
 
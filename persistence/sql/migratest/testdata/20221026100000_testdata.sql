@@ -0,0 +1,2 @@
+INSERT INTO identities (id, nid, schema_id, traits, created_at, updated_at) VALUES ('0b5d3f6e-3c8a-4d1b-9f2e-6a7c8d9e0f12', '884f556e-eb3a-4b9f-bee3-11345642c6c0', 'default', '{"email":"webauthn@ory.sh"}', '2013-10-07 08:23:19', '2013-10-07 08:23:19');
+INSERT INTO identity_credentials (id, nid, config, identity_credential_type_id, identity_id, created_at, updated_at, version) VALUES ('1a9e4c2d-7b3f-4e5a-8c6d-2f0b1e3d4c5a', '884f556e-eb3a-4b9f-bee3-11345642c6c0', '{"credentials":[{"id":"Zm9vYmFy","public_key":"cHVibGljLWtleQ==","attestation_type":"none","authenticator":{"aaguid":"AAAAAAAAAAAAAAAAAAAAAA==","sign_count":0,"clone_warning":false},"display_name":"my key","added_at":"2013-10-07T08:23:19Z","is_passwordless":true}],"user_handle":"C10/bjyKTRufLmp8jZ4PEg=="}', (SELECT id FROM identity_credential_types WHERE name = 'webauthn'), '0b5d3f6e-3c8a-4d1b-9f2e-6a7c8d9e0f12', '2013-10-07 08:23:19', '2013-10-07 08:23:19', 0);
@@ -0,0 +1 @@
+ALTER TABLE identities ADD credentials_reset_required VARCHAR(32) NOT NULL DEFAULT '';
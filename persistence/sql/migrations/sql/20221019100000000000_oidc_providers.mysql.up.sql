@@ -0,0 +1,13 @@
+CREATE TABLE `selfservice_oidc_providers` (
+`id` char(36) NOT NULL,
+PRIMARY KEY(`id`),
+`nid` char(36) NOT NULL,
+`provider_id` VARCHAR(255) NOT NULL,
+`config` JSON NOT NULL,
+`client_secret` TEXT NOT NULL,
+`apple_private_key` TEXT NOT NULL,
+`created_at` DATETIME NOT NULL,
+`updated_at` DATETIME NOT NULL,
+CONSTRAINT `selfservice_oidc_providers_nid_fk_idx` FOREIGN KEY (`nid`) REFERENCES `networks` (`id`) ON UPDATE RESTRICT ON DELETE CASCADE
+) ENGINE=InnoDB;
+CREATE UNIQUE INDEX `selfservice_oidc_providers_nid_provider_id_uq_idx` ON `selfservice_oidc_providers` (`nid`, `provider_id`);
@@ -0,0 +1,11 @@
+CREATE TABLE "selfservice_oidc_providers" (
+"id" TEXT PRIMARY KEY,
+"nid" CHAR(36) NOT NULL REFERENCES networks(id) ON DELETE CASCADE ON UPDATE RESTRICT,
+"provider_id" TEXT NOT NULL,
+"config" TEXT NOT NULL,
+"client_secret" TEXT NOT NULL,
+"apple_private_key" TEXT NOT NULL,
+"created_at" DATETIME NOT NULL,
+"updated_at" DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX "selfservice_oidc_providers_nid_provider_id_uq_idx" ON "selfservice_oidc_providers" ("nid", "provider_id");
@@ -0,0 +1 @@
+DROP TABLE "session_trusted_devices";
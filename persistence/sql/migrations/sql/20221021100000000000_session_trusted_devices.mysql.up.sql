@@ -0,0 +1,13 @@
+CREATE TABLE `session_trusted_devices` (
+`id` char(36) NOT NULL,
+PRIMARY KEY(`id`),
+`nid` char(36) NOT NULL,
+`identity_id` char(36) NOT NULL,
+`user_agent` TEXT NOT NULL,
+`expires_at` DATETIME NOT NULL,
+`created_at` DATETIME NOT NULL,
+`updated_at` DATETIME NOT NULL,
+CONSTRAINT `session_trusted_devices_nid_fk_idx` FOREIGN KEY (`nid`) REFERENCES `networks` (`id`) ON UPDATE RESTRICT ON DELETE CASCADE,
+CONSTRAINT `session_trusted_devices_identity_id_fk_idx` FOREIGN KEY (`identity_id`) REFERENCES `identities` (`id`) ON UPDATE RESTRICT ON DELETE CASCADE
+) ENGINE=InnoDB;
+CREATE INDEX `session_trusted_devices_nid_identity_id_idx` ON `session_trusted_devices` (`nid`, `identity_id`);
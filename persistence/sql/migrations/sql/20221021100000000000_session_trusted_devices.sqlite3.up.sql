@@ -0,0 +1,10 @@
+CREATE TABLE "session_trusted_devices" (
+"id" TEXT PRIMARY KEY,
+"nid" CHAR(36) NOT NULL REFERENCES networks(id) ON DELETE CASCADE ON UPDATE RESTRICT,
+"identity_id" CHAR(36) NOT NULL REFERENCES identities(id) ON DELETE CASCADE ON UPDATE RESTRICT,
+"user_agent" TEXT NOT NULL,
+"expires_at" DATETIME NOT NULL,
+"created_at" DATETIME NOT NULL,
+"updated_at" DATETIME NOT NULL
+);
+CREATE INDEX "session_trusted_devices_nid_identity_id_idx" ON "session_trusted_devices" ("nid", "identity_id");
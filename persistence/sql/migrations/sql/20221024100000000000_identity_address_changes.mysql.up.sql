@@ -0,0 +1,23 @@
+CREATE TABLE `identity_address_changes` (
+`id` char(36) NOT NULL,
+PRIMARY KEY(`id`),
+`nid` char(36) NOT NULL,
+`identity_id` char(36) NOT NULL,
+`selfservice_verification_flow_id` char(36) NOT NULL,
+`confirm_token` VARCHAR(64) NOT NULL,
+`revert_token` VARCHAR(64) NOT NULL,
+`address` VARCHAR(400) NOT NULL,
+`traits` JSON NOT NULL,
+`previous_traits` JSON NOT NULL,
+`state` VARCHAR(16) NOT NULL,
+`expires_at` DATETIME NOT NULL,
+`revert_expires_at` DATETIME NOT NULL,
+`issued_at` DATETIME NOT NULL,
+`created_at` DATETIME NOT NULL,
+`updated_at` DATETIME NOT NULL,
+CONSTRAINT `identity_address_changes_nid_fk_idx` FOREIGN KEY (`nid`) REFERENCES `networks` (`id`) ON UPDATE RESTRICT ON DELETE CASCADE,
+CONSTRAINT `identity_address_changes_identity_id_fk_idx` FOREIGN KEY (`identity_id`) REFERENCES `identities` (`id`) ON UPDATE RESTRICT ON DELETE CASCADE
+) ENGINE=InnoDB;
+CREATE UNIQUE INDEX `identity_address_changes_confirm_token_uq_idx` ON `identity_address_changes` (`confirm_token`);
+CREATE UNIQUE INDEX `identity_address_changes_revert_token_uq_idx` ON `identity_address_changes` (`revert_token`);
+CREATE INDEX `identity_address_changes_nid_identity_id_idx` ON `identity_address_changes` (`nid`, `identity_id`);
@@ -0,0 +1,23 @@
+CREATE TABLE "identity_address_changes" (
+"id" UUID NOT NULL,
+PRIMARY KEY("id"),
+"nid" UUID NOT NULL,
+"identity_id" UUID NOT NULL,
+"selfservice_verification_flow_id" UUID NOT NULL,
+"confirm_token" VARCHAR(64) NOT NULL,
+"revert_token" VARCHAR(64) NOT NULL,
+"address" VARCHAR(400) NOT NULL,
+"traits" jsonb NOT NULL,
+"previous_traits" jsonb NOT NULL,
+"state" VARCHAR(16) NOT NULL,
+"expires_at" timestamp NOT NULL,
+"revert_expires_at" timestamp NOT NULL,
+"issued_at" timestamp NOT NULL,
+"created_at" timestamp NOT NULL,
+"updated_at" timestamp NOT NULL,
+CONSTRAINT "identity_address_changes_nid_fk_idx" FOREIGN KEY ("nid") REFERENCES "networks" ("id") ON UPDATE RESTRICT ON DELETE CASCADE,
+CONSTRAINT "identity_address_changes_identity_id_fk_idx" FOREIGN KEY ("identity_id") REFERENCES "identities" ("id") ON UPDATE RESTRICT ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX "identity_address_changes_confirm_token_uq_idx" ON "identity_address_changes" ("confirm_token");
+CREATE UNIQUE INDEX "identity_address_changes_revert_token_uq_idx" ON "identity_address_changes" ("revert_token");
+CREATE INDEX "identity_address_changes_nid_identity_id_idx" ON "identity_address_changes" ("nid", "identity_id");
@@ -0,0 +1,20 @@
+CREATE TABLE "identity_address_changes" (
+"id" TEXT PRIMARY KEY,
+"nid" CHAR(36) NOT NULL REFERENCES networks(id) ON DELETE CASCADE ON UPDATE RESTRICT,
+"identity_id" CHAR(36) NOT NULL REFERENCES identities(id) ON DELETE CASCADE ON UPDATE RESTRICT,
+"selfservice_verification_flow_id" CHAR(36) NOT NULL,
+"confirm_token" TEXT NOT NULL,
+"revert_token" TEXT NOT NULL,
+"address" TEXT NOT NULL,
+"traits" TEXT NOT NULL,
+"previous_traits" TEXT NOT NULL,
+"state" TEXT NOT NULL,
+"expires_at" DATETIME NOT NULL,
+"revert_expires_at" DATETIME NOT NULL,
+"issued_at" DATETIME NOT NULL,
+"created_at" DATETIME NOT NULL,
+"updated_at" DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX "identity_address_changes_confirm_token_uq_idx" ON "identity_address_changes" ("confirm_token");
+CREATE UNIQUE INDEX "identity_address_changes_revert_token_uq_idx" ON "identity_address_changes" ("revert_token");
+CREATE INDEX "identity_address_changes_nid_identity_id_idx" ON "identity_address_changes" ("nid", "identity_id");
@@ -0,0 +1 @@
+DROP TABLE `session_seen_devices`;
@@ -0,0 +1,14 @@
+CREATE TABLE "session_seen_devices" (
+"id" UUID NOT NULL,
+PRIMARY KEY("id"),
+"nid" UUID NOT NULL,
+"identity_id" UUID NOT NULL,
+"user_agent" TEXT NOT NULL,
+"device" VARCHAR(255) NOT NULL,
+"expires_at" timestamp NOT NULL,
+"created_at" timestamp NOT NULL,
+"updated_at" timestamp NOT NULL,
+CONSTRAINT "session_seen_devices_nid_fk_idx" FOREIGN KEY ("nid") REFERENCES "networks" ("id") ON UPDATE RESTRICT ON DELETE CASCADE,
+CONSTRAINT "session_seen_devices_identity_id_fk_idx" FOREIGN KEY ("identity_id") REFERENCES "identities" ("id") ON UPDATE RESTRICT ON DELETE CASCADE
+);
+CREATE INDEX "session_seen_devices_nid_identity_id_device_idx" ON "session_seen_devices" ("nid", "identity_id", "device");
@@ -0,0 +1,11 @@
+CREATE TABLE "session_seen_devices" (
+"id" TEXT PRIMARY KEY,
+"nid" CHAR(36) NOT NULL REFERENCES networks(id) ON DELETE CASCADE ON UPDATE RESTRICT,
+"identity_id" CHAR(36) NOT NULL REFERENCES identities(id) ON DELETE CASCADE ON UPDATE RESTRICT,
+"user_agent" TEXT NOT NULL,
+"device" VARCHAR(255) NOT NULL,
+"expires_at" DATETIME NOT NULL,
+"created_at" DATETIME NOT NULL,
+"updated_at" DATETIME NOT NULL
+);
+CREATE INDEX "session_seen_devices_nid_identity_id_device_idx" ON "session_seen_devices" ("nid", "identity_id", "device");
@@ -0,0 +1,17 @@
+CREATE TABLE `identity_invitations` (
+`id` char(36) NOT NULL,
+PRIMARY KEY(`id`),
+`nid` char(36) NOT NULL,
+`identity_id` char(36) NOT NULL,
+`token` VARCHAR(64) NOT NULL,
+`address` VARCHAR(400) NOT NULL,
+`state` VARCHAR(16) NOT NULL,
+`expires_at` DATETIME NOT NULL,
+`issued_at` DATETIME NOT NULL,
+`created_at` DATETIME NOT NULL,
+`updated_at` DATETIME NOT NULL,
+CONSTRAINT `identity_invitations_nid_fk_idx` FOREIGN KEY (`nid`) REFERENCES `networks` (`id`) ON UPDATE RESTRICT ON DELETE CASCADE,
+CONSTRAINT `identity_invitations_identity_id_fk_idx` FOREIGN KEY (`identity_id`) REFERENCES `identities` (`id`) ON UPDATE RESTRICT ON DELETE CASCADE
+) ENGINE=InnoDB;
+CREATE UNIQUE INDEX `identity_invitations_token_uq_idx` ON `identity_invitations` (`token`);
+CREATE INDEX `identity_invitations_nid_identity_id_idx` ON `identity_invitations` (`nid`, `identity_id`);
@@ -0,0 +1,14 @@
+CREATE TABLE "identity_invitations" (
+"id" TEXT PRIMARY KEY,
+"nid" CHAR(36) NOT NULL REFERENCES networks(id) ON DELETE CASCADE ON UPDATE RESTRICT,
+"identity_id" CHAR(36) NOT NULL REFERENCES identities(id) ON DELETE CASCADE ON UPDATE RESTRICT,
+"token" TEXT NOT NULL,
+"address" TEXT NOT NULL,
+"state" TEXT NOT NULL,
+"expires_at" DATETIME NOT NULL,
+"issued_at" DATETIME NOT NULL,
+"created_at" DATETIME NOT NULL,
+"updated_at" DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX "identity_invitations_token_uq_idx" ON "identity_invitations" ("token");
+CREATE INDEX "identity_invitations_nid_identity_id_idx" ON "identity_invitations" ("nid", "identity_id");
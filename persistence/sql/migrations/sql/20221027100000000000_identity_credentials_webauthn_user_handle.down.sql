@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "identity_credentials_nid_webauthn_user_handle_idx";
+ALTER TABLE "identity_credentials" DROP COLUMN "webauthn_user_handle";
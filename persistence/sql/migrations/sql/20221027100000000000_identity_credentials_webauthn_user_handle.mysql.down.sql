@@ -0,0 +1,2 @@
+DROP INDEX `identity_credentials_nid_webauthn_user_handle_idx` ON `identity_credentials`;
+ALTER TABLE `identity_credentials` DROP COLUMN `webauthn_user_handle`;
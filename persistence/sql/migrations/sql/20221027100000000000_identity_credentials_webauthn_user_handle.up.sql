@@ -0,0 +1,2 @@
+ALTER TABLE "identity_credentials" ADD COLUMN "webauthn_user_handle" VARCHAR(128) NULL;
+CREATE INDEX "identity_credentials_nid_webauthn_user_handle_idx" ON "identity_credentials" ("nid", "webauthn_user_handle");
@@ -0,0 +1 @@
+ALTER TABLE `identity_credentials` DROP COLUMN `totp_last_used_time_step`;
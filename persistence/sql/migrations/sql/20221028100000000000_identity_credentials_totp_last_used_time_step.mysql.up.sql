@@ -0,0 +1 @@
+ALTER TABLE `identity_credentials` ADD COLUMN `totp_last_used_time_step` BIGINT NULL;
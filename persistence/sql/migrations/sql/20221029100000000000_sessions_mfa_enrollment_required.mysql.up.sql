@@ -0,0 +1 @@
+ALTER TABLE `sessions` ADD COLUMN `mfa_enrollment_required` BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,76 @@
+package sql
+
+import (
+	"github.com/gobuffalo/pop/v6"
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/x/popx"
+)
+
+// GoMigrations are the migrations which can not be expressed in SQL for all dialects.
+var GoMigrations = popx.Migrations{
+	{
+		Version:   "20221027100000000001",
+		Path:      "20221027100000000001_identity_credentials_webauthn_user_handle_backfill",
+		Name:      "identity_credentials_webauthn_user_handle_backfill",
+		DBType:    "all",
+		Direction: "up",
+		Type:      "go",
+		Runner:    backfillWebAuthnUserHandles,
+	},
+	{
+		Version:   "20221027100000000001",
+		Path:      "20221027100000000001_identity_credentials_webauthn_user_handle_backfill",
+		Name:      "identity_credentials_webauthn_user_handle_backfill",
+		DBType:    "all",
+		Direction: "down",
+		Type:      "go",
+		Runner: func(popx.Migration, *pop.Connection, *pop.Tx) error {
+			// The column is dropped by the previous migration.
+			return nil
+		},
+	},
+}
+
+// backfillWebAuthnUserHandles copies the user handle of WebAuthn credentials created before the
+// webauthn_user_handle column existed from the credentials config into the column. This is done
+// in Go because SQLite is not built with JSON support.
+func backfillWebAuthnUserHandles(_ popx.Migration, _ *pop.Connection, tx *pop.Tx) error {
+	rows, err := tx.Query(`SELECT ic.id, ic.config
+FROM identity_credentials ic
+         INNER JOIN identity_credential_types ict on ic.identity_credential_type_id = ict.id
+WHERE ict.name = 'webauthn'
+  AND ic.webauthn_user_handle IS NULL`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	handles := map[string]string{}
+	for rows.Next() {
+		var id string
+		var config []byte
+		if err := rows.Scan(&id, &config); err != nil {
+			return errors.WithStack(err)
+		}
+
+		if handle := gjson.GetBytes(config, "user_handle").String(); len(handle) > 0 {
+			handles[id] = handle
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := rows.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	for id, handle := range handles {
+		if _, err := tx.Exec(tx.Rebind(`UPDATE identity_credentials SET webauthn_user_handle = ? WHERE id = ?`), handle, id); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
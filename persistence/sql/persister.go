@@ -53,7 +53,7 @@ type (
 )
 
 func NewPersister(ctx context.Context, r persisterDependencies, c *pop.Connection) (*Persister, error) {
-	m, err := popx.NewMigrationBox(fsx.Merge(migrations, networkx.Migrations), popx.NewMigrator(c, r.Logger(), r.Tracer(ctx), 0))
+	m, err := popx.NewMigrationBox(fsx.Merge(migrations, networkx.Migrations), popx.NewMigrator(c, r.Logger(), r.Tracer(ctx), 0), popx.WithGoMigrations(GoMigrations))
 	if err != nil {
 		return nil, err
 	}
@@ -146,6 +146,18 @@ func (p *Persister) CleanupDatabase(ctx context.Context, wait time.Duration, old
 	}
 	time.Sleep(wait)
 
+	p.r.Logger().Println("Cleaning up expired trusted devices")
+	if err := p.DeleteExpiredTrustedDevices(ctx, currentTime, batchSize); err != nil {
+		return err
+	}
+	time.Sleep(wait)
+
+	p.r.Logger().Println("Cleaning up expired seen devices")
+	if err := p.DeleteExpiredSeenDevices(ctx, currentTime, batchSize); err != nil {
+		return err
+	}
+	time.Sleep(wait)
+
 	p.r.Logger().Println("Cleaning up expired continuity containers")
 	if err := p.DeleteExpiredContinuitySessions(ctx, currentTime, batchSize); err != nil {
 		return err
@@ -0,0 +1,97 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ory/x/sqlcon"
+
+	"github.com/ory/kratos/selfservice/strategy/link"
+)
+
+var _ link.AddressChangePersister = new(Persister)
+
+func (p *Persister) CreateAddressChange(ctx context.Context, change *link.AddressChange) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.CreateAddressChange")
+	defer span.End()
+
+	confirm, revert := change.ConfirmToken, change.RevertToken
+	change.ConfirmToken = p.hmacValue(ctx, confirm)
+	change.RevertToken = p.hmacValue(ctx, revert)
+	change.NID = p.NetworkID(ctx)
+
+	if err := sqlcon.HandleError(p.GetConnection(ctx).Create(change)); err != nil {
+		return err
+	}
+
+	change.ConfirmToken, change.RevertToken = confirm, revert
+	return nil
+}
+
+func (p *Persister) GetPendingAddressChange(ctx context.Context, fID uuid.UUID, token string) (*link.AddressChange, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.GetPendingAddressChange")
+	defer span.End()
+
+	var c link.AddressChange
+	for _, secret := range p.r.Config().SecretsSession(ctx) {
+		if err := p.GetConnection(ctx).Where("confirm_token = ? AND nid = ? AND state = ? AND selfservice_verification_flow_id = ?",
+			p.hmacValueWithSecret(ctx, token, secret), p.NetworkID(ctx), link.AddressChangeStatePending, fID).First(&c); err != nil {
+			if err = sqlcon.HandleError(err); !errors.Is(err, sqlcon.ErrNoRows) {
+				return nil, err
+			}
+			continue
+		}
+		return &c, nil
+	}
+
+	return nil, errors.WithStack(sqlcon.ErrNoRows)
+}
+
+func (p *Persister) GetAddressChangeByRevertToken(ctx context.Context, token string) (*link.AddressChange, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.GetAddressChangeByRevertToken")
+	defer span.End()
+
+	var c link.AddressChange
+	for _, secret := range p.r.Config().SecretsSession(ctx) {
+		if err := p.GetConnection(ctx).Where("revert_token = ? AND nid = ?",
+			p.hmacValueWithSecret(ctx, token, secret), p.NetworkID(ctx)).First(&c); err != nil {
+			if err = sqlcon.HandleError(err); !errors.Is(err, sqlcon.ErrNoRows) {
+				return nil, err
+			}
+			continue
+		}
+		return &c, nil
+	}
+
+	return nil, errors.WithStack(sqlcon.ErrNoRows)
+}
+
+// UpdateAddressChangeState moves the address change to another state. It returns sqlcon.ErrNoRows if the
+// address change is no longer in the expected state, for example because it was confirmed concurrently.
+func (p *Persister) UpdateAddressChangeState(ctx context.Context, id uuid.UUID, from, to link.AddressChangeState) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.UpdateAddressChangeState")
+	defer span.End()
+
+	/* #nosec G201 TableName is static */
+	count, err := p.GetConnection(ctx).RawQuery(fmt.Sprintf("UPDATE %s SET state = ?, updated_at = ? WHERE id = ? AND nid = ? AND state = ?", new(link.AddressChange).TableName(ctx)),
+		to, time.Now().UTC(), id, p.NetworkID(ctx), from).ExecWithCount()
+	if err != nil {
+		return sqlcon.HandleError(err)
+	} else if count == 0 {
+		return errors.WithStack(sqlcon.ErrNoRows)
+	}
+	return nil
+}
+
+func (p *Persister) DeletePendingAddressChanges(ctx context.Context, identityID uuid.UUID) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.DeletePendingAddressChanges")
+	defer span.End()
+
+	/* #nosec G201 TableName is static */
+	return sqlcon.HandleError(p.GetConnection(ctx).RawQuery(fmt.Sprintf("DELETE FROM %s WHERE identity_id = ? AND nid = ? AND state = ?", new(link.AddressChange).TableName(ctx)),
+		identityID, p.NetworkID(ctx), link.AddressChangeStatePending).Exec())
+}
@@ -105,6 +105,38 @@ func TestPersister_Session_Cleanup(t *testing.T) {
 	})
 }
 
+func TestPersister_TrustedDevices_Cleanup(t *testing.T) {
+	_, reg := internal.NewFastRegistryWithMocks(t)
+	p := reg.Persister()
+	currentTime := time.Now()
+	ctx := context.Background()
+
+	t.Run("case=should not throw error on cleanup trusted devices", func(t *testing.T) {
+		assert.Nil(t, p.DeleteExpiredTrustedDevices(ctx, currentTime, reg.Config().DatabaseCleanupBatchSize(ctx)))
+	})
+
+	t.Run("case=should throw error on cleanup trusted devices", func(t *testing.T) {
+		p.GetConnection(ctx).Close()
+		assert.Error(t, p.DeleteExpiredTrustedDevices(ctx, currentTime, reg.Config().DatabaseCleanupBatchSize(ctx)))
+	})
+}
+
+func TestPersister_SeenDevices_Cleanup(t *testing.T) {
+	_, reg := internal.NewFastRegistryWithMocks(t)
+	p := reg.Persister()
+	currentTime := time.Now()
+	ctx := context.Background()
+
+	t.Run("case=should not throw error on cleanup seen devices", func(t *testing.T) {
+		assert.Nil(t, p.DeleteExpiredSeenDevices(ctx, currentTime, reg.Config().DatabaseCleanupBatchSize(ctx)))
+	})
+
+	t.Run("case=should throw error on cleanup seen devices", func(t *testing.T) {
+		p.GetConnection(ctx).Close()
+		assert.Error(t, p.DeleteExpiredSeenDevices(ctx, currentTime, reg.Config().DatabaseCleanupBatchSize(ctx)))
+	})
+}
+
 func TestPersister_Settings_Cleanup(t *testing.T) {
 	_, reg := internal.NewFastRegistryWithMocks(t)
 	p := reg.Persister()
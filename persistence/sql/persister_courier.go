@@ -167,3 +167,17 @@ func (p *Persister) IncrementMessageSendCount(ctx context.Context, id uuid.UUID)
 
 	return nil
 }
+
+func (p *Persister) DeleteMessagesByRecipient(ctx context.Context, recipient string) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.DeleteMessagesByRecipient")
+	defer span.End()
+
+	// #nosec G201
+	return sqlcon.HandleError(p.GetConnection(ctx).RawQuery(fmt.Sprintf(
+		"DELETE FROM %s WHERE recipient = ? AND nid = ?",
+		"courier_messages",
+	),
+		recipient,
+		p.NetworkID(ctx),
+	).Exec())
+}
@@ -3,7 +3,10 @@ package sql
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -18,6 +21,7 @@ import (
 	"github.com/gobuffalo/pop/v6"
 	"github.com/gofrs/uuid"
 	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
 
 	"github.com/ory/herodot"
 	"github.com/ory/x/errorsx"
@@ -126,6 +130,110 @@ WHERE ici.identifier = ?
 	return i.CopyWithoutCredentials(), creds, nil
 }
 
+func (p *Persister) FindByWebAuthnUserHandle(ctx context.Context, userHandle []byte) (*identity.Identity, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.FindByWebAuthnUserHandle")
+	defer span.End()
+
+	if len(userHandle) == 0 {
+		return nil, errors.WithStack(sqlcon.ErrNoRows)
+	}
+
+	var c identity.Credentials
+	// #nosec G201
+	if err := p.GetConnection(ctx).RawQuery(fmt.Sprintf(`SELECT
+    ic.identity_id
+FROM %s ic
+         INNER JOIN %s ict on ic.identity_credential_type_id = ict.id
+WHERE ic.webauthn_user_handle = ?
+  AND ic.nid = ?
+  AND ict.name = ?
+LIMIT 1`,
+		"identity_credentials",
+		"identity_credential_types",
+	),
+		base64.StdEncoding.EncodeToString(userHandle),
+		p.NetworkID(ctx),
+		identity.CredentialsTypeWebAuthn,
+	).First(&c); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+
+	return p.GetIdentityConfidential(ctx, c.IdentityID)
+}
+
+func (p *Persister) UseTOTPTimeStep(ctx context.Context, credentialsID uuid.UUID, step uint64, config sqlxx.JSONRawMessage) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.UseTOTPTimeStep")
+	defer span.End()
+
+	// #nosec G201
+	count, err := p.GetConnection(ctx).RawQuery(fmt.Sprintf(`UPDATE %s SET totp_last_used_time_step = ?, config = ?, updated_at = ?
+WHERE id = ?
+  AND nid = ?
+  AND (totp_last_used_time_step IS NULL OR totp_last_used_time_step < ?)`,
+		new(identity.Credentials).TableName(ctx),
+	),
+		int64(step),
+		config,
+		time.Now().UTC(),
+		credentialsID,
+		p.NetworkID(ctx),
+		int64(step),
+	).ExecWithCount()
+	if err != nil {
+		return sqlcon.HandleError(err)
+	} else if count == 0 {
+		return errors.WithStack(sqlcon.ErrNoRows)
+	}
+
+	return nil
+}
+
+func (p *Persister) UpdateCredentialsConfigIfUnchanged(ctx context.Context, credentialsID uuid.UUID, previous, config sqlxx.JSONRawMessage) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.UpdateCredentialsConfigIfUnchanged")
+	defer span.End()
+
+	return p.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
+		// SQLite does not support row locks, but serializes write transactions anyway.
+		lock := " FOR UPDATE"
+		if p.isSQLite {
+			lock = ""
+		}
+
+		var current identity.Credentials
+		// #nosec G201
+		if err := tx.RawQuery(fmt.Sprintf(`SELECT * FROM %s WHERE id = ? AND nid = ?%s`,
+			new(identity.Credentials).TableName(ctx), lock),
+			credentialsID, p.NetworkID(ctx),
+		).First(&current); err != nil {
+			return sqlcon.HandleError(err)
+		}
+
+		if equal, err := jsonEqual(current.Config, previous); err != nil {
+			return err
+		} else if !equal {
+			return errors.WithStack(sqlcon.ErrNoRows)
+		}
+
+		// #nosec G201
+		return sqlcon.HandleError(tx.RawQuery(fmt.Sprintf(`UPDATE %s SET config = ?, updated_at = ? WHERE id = ? AND nid = ?`,
+			new(identity.Credentials).TableName(ctx)),
+			config, time.Now().UTC(), credentialsID, p.NetworkID(ctx),
+		).Exec())
+	})
+}
+
+// jsonEqual compares two JSON documents regardless of their formatting, which may be changed by the database.
+func jsonEqual(a, b []byte) (bool, error) {
+	var av, bv interface{}
+	if err := json.Unmarshal(a, &av); err != nil {
+		return false, errors.WithStack(err)
+	}
+	if err := json.Unmarshal(b, &bv); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return reflect.DeepEqual(av, bv), nil
+}
+
 func (p *Persister) findIdentityCredentialsType(ctx context.Context, ct identity.CredentialsType) (*identity.CredentialsTypeTable, error) {
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.findIdentityCredentialsType")
 	defer span.End()
@@ -159,6 +267,14 @@ func (p *Persister) createIdentityCredentials(ctx context.Context, i *identity.I
 		cred.IdentityID = i.ID
 		cred.NID = nid
 		cred.CredentialTypeID = ct.ID
+		switch cred.Type {
+		case identity.CredentialsTypeWebAuthn:
+			cred.WebAuthnUserHandle = sqlxx.NullString(gjson.GetBytes(cred.Config, "user_handle").String())
+		case identity.CredentialsTypeTOTP:
+			if step := gjson.GetBytes(cred.Config, "last_used_time_step"); step.Exists() {
+				cred.TOTPLastUsedTimeStep = sqlxx.NullInt64{Int: step.Int(), Valid: true}
+			}
+		}
 		if err := c.Create(&cred); err != nil {
 			return sqlcon.HandleError(err)
 		}
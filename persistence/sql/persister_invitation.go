@@ -0,0 +1,102 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ory/x/sqlcon"
+
+	"github.com/ory/kratos/selfservice/strategy/link"
+)
+
+var _ link.InvitationPersister = new(Persister)
+
+func (p *Persister) CreateInvitation(ctx context.Context, invitation *link.Invitation) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.CreateInvitation")
+	defer span.End()
+
+	token := invitation.Token
+	invitation.Token = p.hmacValue(ctx, token)
+	invitation.NID = p.NetworkID(ctx)
+
+	if err := sqlcon.HandleError(p.GetConnection(ctx).Create(invitation)); err != nil {
+		return err
+	}
+
+	invitation.Token = token
+	return nil
+}
+
+func (p *Persister) GetInvitationByToken(ctx context.Context, token string) (*link.Invitation, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.GetInvitationByToken")
+	defer span.End()
+
+	var invitation link.Invitation
+	for _, secret := range p.r.Config().SecretsSession(ctx) {
+		if err := p.GetConnection(ctx).Where("token = ? AND nid = ? AND state = ?",
+			p.hmacValueWithSecret(ctx, token, secret), p.NetworkID(ctx), link.InvitationStatePending).First(&invitation); err != nil {
+			if err = sqlcon.HandleError(err); !errors.Is(err, sqlcon.ErrNoRows) {
+				return nil, err
+			}
+			continue
+		}
+		return &invitation, nil
+	}
+
+	return nil, errors.WithStack(sqlcon.ErrNoRows)
+}
+
+// DeletePendingInvitations deletes the invitations of an identity which were not accepted yet. It returns
+// sqlcon.ErrNoRows if there are none, for example because the identity accepted its invitation already.
+func (p *Persister) DeletePendingInvitations(ctx context.Context, identityID uuid.UUID) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.DeletePendingInvitations")
+	defer span.End()
+
+	/* #nosec G201 TableName is static */
+	count, err := p.GetConnection(ctx).RawQuery(fmt.Sprintf("DELETE FROM %s WHERE identity_id = ? AND nid = ? AND state = ?", new(link.Invitation).TableName(ctx)),
+		identityID, p.NetworkID(ctx), link.InvitationStatePending).ExecWithCount()
+	if err != nil {
+		return sqlcon.HandleError(err)
+	} else if count == 0 {
+		return errors.WithStack(sqlcon.ErrNoRows)
+	}
+	return nil
+}
+
+func (p *Persister) UseInvitation(ctx context.Context, token string) (*link.Invitation, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.UseInvitation")
+	defer span.End()
+
+	var invitation link.Invitation
+
+	nid := p.NetworkID(ctx)
+	if err := sqlcon.HandleError(p.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) (err error) {
+		for _, secret := range p.r.Config().SecretsSession(ctx) {
+			if err = tx.Where("token = ? AND nid = ? AND state = ?", p.hmacValueWithSecret(ctx, token, secret), nid, link.InvitationStatePending).First(&invitation); err != nil {
+				if !errors.Is(sqlcon.HandleError(err), sqlcon.ErrNoRows) {
+					return err
+				}
+			} else {
+				break
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		invitation.State = link.InvitationStateAccepted
+
+		/* #nosec G201 TableName is static */
+		return tx.RawQuery(fmt.Sprintf("UPDATE %s SET state = ?, updated_at = ? WHERE id = ? AND nid = ?", invitation.TableName(ctx)),
+			link.InvitationStateAccepted, time.Now().UTC(), invitation.ID, nid).Exec()
+	})); err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
@@ -0,0 +1,67 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/x/sqlcon"
+
+	"github.com/ory/kratos/selfservice/strategy/oidc"
+)
+
+var _ oidc.ProviderPersister = new(Persister)
+
+func (p *Persister) CreateOIDCProvider(ctx context.Context, c *oidc.StoredConfiguration) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.CreateOIDCProvider")
+	defer span.End()
+
+	c.NID = p.NetworkID(ctx)
+	return sqlcon.HandleError(p.GetConnection(ctx).Create(c))
+}
+
+func (p *Persister) GetOIDCProvider(ctx context.Context, id string) (*oidc.StoredConfiguration, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.GetOIDCProvider")
+	defer span.End()
+
+	var c oidc.StoredConfiguration
+	if err := p.GetConnection(ctx).Where("provider_id = ? AND nid = ?", id, p.NetworkID(ctx)).First(&c); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+	return &c, nil
+}
+
+func (p *Persister) ListOIDCProviders(ctx context.Context) ([]oidc.StoredConfiguration, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ListOIDCProviders")
+	defer span.End()
+
+	cs := make([]oidc.StoredConfiguration, 0)
+	if err := p.GetConnection(ctx).Where("nid = ?", p.NetworkID(ctx)).Order("created_at ASC, id ASC").All(&cs); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+	return cs, nil
+}
+
+func (p *Persister) UpdateOIDCProvider(ctx context.Context, c *oidc.StoredConfiguration) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.UpdateOIDCProvider")
+	defer span.End()
+
+	c.NID = p.NetworkID(ctx)
+	return p.update(ctx, c)
+}
+
+func (p *Persister) DeleteOIDCProvider(ctx context.Context, id string) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.DeleteOIDCProvider")
+	defer span.End()
+
+	if count, err := p.GetConnection(ctx).RawQuery(
+		// #nosec
+		fmt.Sprintf("DELETE FROM %s WHERE provider_id=? AND nid=?",
+			new(oidc.StoredConfiguration).TableName(ctx)), id, p.NetworkID(ctx)).ExecWithCount(); err != nil {
+		return sqlcon.HandleError(err)
+	} else if count == 0 {
+		return errors.WithStack(sqlcon.ErrNoRows)
+	}
+	return nil
+}
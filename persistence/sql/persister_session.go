@@ -14,6 +14,7 @@ import (
 
 	"github.com/ory/x/sqlcon"
 
+	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/session"
 )
 
@@ -104,6 +105,12 @@ func (p *Persister) DeleteSessionsByIdentity(ctx context.Context, identityID uui
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.DeleteSessionsByIdentity")
 	defer span.End()
 
+	// Trusted devices would otherwise let the next sign in skip the second factor. They are deleted even if the
+	// identity has no sessions.
+	if err := p.DeleteTrustedDevicesByIdentity(ctx, identityID); err != nil {
+		return err
+	}
+
 	// #nosec G201
 	count, err := p.GetConnection(ctx).RawQuery(fmt.Sprintf(
 		"DELETE FROM %s WHERE identity_id = ? AND nid = ?",
@@ -226,6 +233,42 @@ func (p *Persister) RevokeSessionsIdentityExcept(ctx context.Context, iID, sID u
 	return count, nil
 }
 
+// RevokeSessionsIdentityAboveAAL marks all sessions of an identity inactive which were authenticated with a higher
+// assurance level than the given one. Assurance levels are ordered lexicographically.
+func (p *Persister) RevokeSessionsIdentityAboveAAL(ctx context.Context, iID uuid.UUID, aal identity.AuthenticatorAssuranceLevel) (int, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.RevokeSessionsIdentityAboveAAL")
+	defer span.End()
+
+	// #nosec G201
+	count, err := p.GetConnection(ctx).RawQuery(fmt.Sprintf(
+		"UPDATE %s SET active = false WHERE identity_id = ? AND aal > ? AND nid = ?",
+		"sessions",
+	),
+		iID,
+		aal,
+		p.NetworkID(ctx),
+	).ExecWithCount()
+	if err != nil {
+		return 0, sqlcon.HandleError(err)
+	}
+	return count, nil
+}
+
+func (p *Persister) SetMFAEnrollmentRequiredByIdentity(ctx context.Context, iID uuid.UUID, required bool) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.SetMFAEnrollmentRequiredByIdentity")
+	defer span.End()
+
+	// #nosec G201
+	return sqlcon.HandleError(p.GetConnection(ctx).RawQuery(fmt.Sprintf(
+		"UPDATE %s SET mfa_enrollment_required = ? WHERE identity_id = ? AND nid = ?",
+		"sessions",
+	),
+		required,
+		iID,
+		p.NetworkID(ctx),
+	).Exec())
+}
+
 func (p *Persister) DeleteExpiredSessions(ctx context.Context, expiresAt time.Time, limit int) error {
 	err := p.GetConnection(ctx).RawQuery(fmt.Sprintf(
 		"DELETE FROM %s WHERE id in (SELECT id FROM (SELECT id FROM %s c WHERE expires_at <= ? and nid = ? ORDER BY expires_at ASC LIMIT %d ) AS s )",
@@ -241,3 +284,167 @@ func (p *Persister) DeleteExpiredSessions(ctx context.Context, expiresAt time.Ti
 	}
 	return nil
 }
+
+func (p *Persister) CreateTrustedDevice(ctx context.Context, d *session.TrustedDevice) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.CreateTrustedDevice")
+	defer span.End()
+
+	d.NID = p.NetworkID(ctx)
+	return sqlcon.HandleError(p.GetConnection(ctx).Create(d))
+}
+
+func (p *Persister) GetTrustedDevice(ctx context.Context, iID, id uuid.UUID) (*session.TrustedDevice, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.GetTrustedDevice")
+	defer span.End()
+
+	var d session.TrustedDevice
+	if err := p.GetConnection(ctx).Where("id = ? AND identity_id = ? AND nid = ?", id, iID, p.NetworkID(ctx)).First(&d); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+	return &d, nil
+}
+
+func (p *Persister) ListTrustedDevicesByIdentity(ctx context.Context, iID uuid.UUID) ([]session.TrustedDevice, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ListTrustedDevicesByIdentity")
+	defer span.End()
+
+	ds := make([]session.TrustedDevice, 0)
+	if err := p.GetConnection(ctx).
+		Where("identity_id = ? AND nid = ? AND expires_at > ?", iID, p.NetworkID(ctx), time.Now().UTC()).
+		Order("created_at ASC, id ASC").
+		All(&ds); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+	return ds, nil
+}
+
+func (p *Persister) DeleteTrustedDevice(ctx context.Context, iID, id uuid.UUID) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.DeleteTrustedDevice")
+	defer span.End()
+
+	// #nosec G201
+	count, err := p.GetConnection(ctx).RawQuery(fmt.Sprintf(
+		"DELETE FROM %s WHERE id = ? AND identity_id = ? AND nid = ?",
+		new(session.TrustedDevice).TableName(ctx),
+	),
+		id,
+		iID,
+		p.NetworkID(ctx),
+	).ExecWithCount()
+	if err != nil {
+		return sqlcon.HandleError(err)
+	}
+	if count == 0 {
+		return errors.WithStack(sqlcon.ErrNoRows)
+	}
+	return nil
+}
+
+func (p *Persister) DeleteTrustedDevicesByIdentity(ctx context.Context, iID uuid.UUID) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.DeleteTrustedDevicesByIdentity")
+	defer span.End()
+
+	// #nosec G201
+	if err := p.GetConnection(ctx).RawQuery(fmt.Sprintf(
+		"DELETE FROM %s WHERE identity_id = ? AND nid = ?",
+		new(session.TrustedDevice).TableName(ctx),
+	),
+		iID,
+		p.NetworkID(ctx),
+	).Exec(); err != nil {
+		return sqlcon.HandleError(err)
+	}
+	return nil
+}
+
+func (p *Persister) DeleteExpiredTrustedDevices(ctx context.Context, expiresAt time.Time, limit int) error {
+	// #nosec G201
+	err := p.GetConnection(ctx).RawQuery(fmt.Sprintf(
+		"DELETE FROM %s WHERE id in (SELECT id FROM (SELECT id FROM %s c WHERE expires_at <= ? and nid = ? ORDER BY expires_at ASC LIMIT %d ) AS s )",
+		new(session.TrustedDevice).TableName(ctx),
+		new(session.TrustedDevice).TableName(ctx),
+		limit,
+	),
+		expiresAt,
+		p.NetworkID(ctx),
+	).Exec()
+	if err != nil {
+		return sqlcon.HandleError(err)
+	}
+	return nil
+}
+
+func (p *Persister) CreateSeenDevice(ctx context.Context, d *session.SeenDevice) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.CreateSeenDevice")
+	defer span.End()
+
+	d.NID = p.NetworkID(ctx)
+	return sqlcon.HandleError(p.GetConnection(ctx).Create(d))
+}
+
+func (p *Persister) GetSeenDevice(ctx context.Context, iID uuid.UUID, device string) (*session.SeenDevice, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.GetSeenDevice")
+	defer span.End()
+
+	var d session.SeenDevice
+	if err := p.GetConnection(ctx).
+		Where("identity_id = ? AND device = ? AND nid = ? AND expires_at > ?", iID, device, p.NetworkID(ctx), time.Now().UTC()).
+		Order("created_at ASC, id ASC").
+		First(&d); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+	return &d, nil
+}
+
+func (p *Persister) HasSeenDevices(ctx context.Context, iID uuid.UUID) (bool, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.HasSeenDevices")
+	defer span.End()
+
+	exists, err := p.GetConnection(ctx).
+		Where("identity_id = ? AND nid = ? AND expires_at > ?", iID, p.NetworkID(ctx), time.Now().UTC()).
+		Exists(new(session.SeenDevice))
+	if err != nil {
+		return false, sqlcon.HandleError(err)
+	}
+	return exists, nil
+}
+
+func (p *Persister) ExtendSeenDevice(ctx context.Context, id uuid.UUID, expiresAt time.Time) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ExtendSeenDevice")
+	defer span.End()
+
+	// #nosec G201
+	count, err := p.GetConnection(ctx).RawQuery(fmt.Sprintf(
+		"UPDATE %s SET expires_at = ?, updated_at = ? WHERE id = ? AND nid = ?",
+		new(session.SeenDevice).TableName(ctx),
+	),
+		expiresAt,
+		time.Now().UTC(),
+		id,
+		p.NetworkID(ctx),
+	).ExecWithCount()
+	if err != nil {
+		return sqlcon.HandleError(err)
+	}
+	if count == 0 {
+		return errors.WithStack(sqlcon.ErrNoRows)
+	}
+	return nil
+}
+
+func (p *Persister) DeleteExpiredSeenDevices(ctx context.Context, expiresAt time.Time, limit int) error {
+	// #nosec G201
+	err := p.GetConnection(ctx).RawQuery(fmt.Sprintf(
+		"DELETE FROM %s WHERE id in (SELECT id FROM (SELECT id FROM %s c WHERE expires_at <= ? and nid = ? ORDER BY expires_at ASC LIMIT %d ) AS s )",
+		new(session.SeenDevice).TableName(ctx),
+		new(session.SeenDevice).TableName(ctx),
+		limit,
+	),
+		expiresAt,
+		p.NetworkID(ctx),
+	).Exec()
+	if err != nil {
+		return sqlcon.HandleError(err)
+	}
+	return nil
+}
@@ -42,6 +42,7 @@ import (
 	settings "github.com/ory/kratos/selfservice/flow/settings/test"
 	verification "github.com/ory/kratos/selfservice/flow/verification/test"
 	link "github.com/ory/kratos/selfservice/strategy/link/test"
+	oidc "github.com/ory/kratos/selfservice/strategy/oidc/test"
 	session "github.com/ory/kratos/session/test"
 	"github.com/ory/kratos/x"
 	"github.com/ory/x/sqlcon"
@@ -255,6 +256,10 @@ func TestPersister(t *testing.T) {
 				pop.SetLogger(pl(t))
 				link.TestPersister(ctx, conf, p)(t)
 			})
+			t.Run("contract=oidc.TestPersister", func(t *testing.T) {
+				pop.SetLogger(pl(t))
+				oidc.TestPersister(ctx, p)(t)
+			})
 			t.Run("contract=continuity.TestPersister", func(t *testing.T) {
 				pop.SetLogger(pl(t))
 				continuity.TestPersister(ctx, p)(t)
@@ -2,6 +2,7 @@ package schema
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -113,6 +114,48 @@ func NewPasswordPolicyViolationError(instancePtr string, reason string) error {
 	})
 }
 
+func NewPasswordMaxLengthError(instancePtr string, expected, actual int) error {
+	t := text.NewErrorValidationPasswordMaxLength(expected, actual)
+	return errors.WithStack(&ValidationError{
+		ValidationError: &jsonschema.ValidationError{
+			Message:     fmt.Sprintf("the password must be at most %d characters long but got %d", expected, actual),
+			InstancePtr: instancePtr,
+			Context: &ValidationErrorContextPasswordPolicyViolation{
+				Reason: t.Text,
+			},
+		},
+		Messages: new(text.Messages).Add(t),
+	})
+}
+
+func NewPasswordCharacterClassesError(instancePtr string, missing []string) error {
+	t := text.NewErrorValidationPasswordCharacterClasses(missing)
+	return errors.WithStack(&ValidationError{
+		ValidationError: &jsonschema.ValidationError{
+			Message:     fmt.Sprintf("the password is missing characters of the following types: %s", strings.Join(missing, ", ")),
+			InstancePtr: instancePtr,
+			Context: &ValidationErrorContextPasswordPolicyViolation{
+				Reason: t.Text,
+			},
+		},
+		Messages: new(text.Messages).Add(t),
+	})
+}
+
+func NewPasswordReusedError(instancePtr string, historySize int) error {
+	t := text.NewErrorValidationPasswordReused(historySize)
+	return errors.WithStack(&ValidationError{
+		ValidationError: &jsonschema.ValidationError{
+			Message:     fmt.Sprintf("the password must not match any of the last %d passwords", historySize),
+			InstancePtr: instancePtr,
+			Context: &ValidationErrorContextPasswordPolicyViolation{
+				Reason: t.Text,
+			},
+		},
+		Messages: new(text.Messages).Add(t),
+	})
+}
+
 func NewMissingIdentifierError() error {
 	return errors.WithStack(&ValidationError{
 		ValidationError: &jsonschema.ValidationError{
@@ -291,6 +334,16 @@ func NewValidationListError(errs []*ValidationError) error {
 	return errors.WithStack(&ValidationListError{Validations: errs})
 }
 
+func NewNoLoginMethod() error {
+	return errors.WithStack(&ValidationError{
+		ValidationError: &jsonschema.ValidationError{
+			Message:     `account does not exist or has no login method set up`,
+			InstancePtr: "#/identifier",
+		},
+		Messages: new(text.Messages).Add(text.NewErrorValidationNoLoginMethod()),
+	})
+}
+
 func NewNoWebAuthnCredentials() error {
 	return errors.WithStack(&ValidationError{
 		ValidationError: &jsonschema.ValidationError{
@@ -300,3 +353,35 @@ func NewNoWebAuthnCredentials() error {
 		Messages: new(text.Messages).Add(text.NewErrorValidationSuchNoWebAuthnUser()),
 	})
 }
+
+func NewWebAuthnAuthenticatorNotAllowedError() error {
+	return errors.WithStack(&ValidationError{
+		ValidationError: &jsonschema.ValidationError{
+			Message:     `the security key does not satisfy the attestation policy`,
+			InstancePtr: "#/",
+		},
+		Messages: new(text.Messages).Add(text.NewErrorValidationWebAuthnAuthenticatorNotAllowed()),
+	})
+}
+
+func NewAccountDeletionNotConfirmedError() error {
+	t := text.NewErrorValidationAccountDeletionNotConfirmed()
+	return errors.WithStack(&ValidationError{
+		ValidationError: &jsonschema.ValidationError{
+			Message:     `account deletion was not confirmed`,
+			InstancePtr: "#/account_deletion_confirm",
+		},
+		Messages: new(text.Messages).Add(t),
+	})
+}
+
+func NewTooManyAddressChangesError() error {
+	t := text.NewErrorValidationTooManyAddressChanges()
+	return errors.WithStack(&ValidationError{
+		ValidationError: &jsonschema.ValidationError{
+			Message:     `only one address can be changed at a time`,
+			InstancePtr: "#/traits",
+		},
+		Messages: new(text.Messages).Add(t),
+	})
+}
@@ -27,6 +27,9 @@ var (
 
 	// ErrSessionRequiredForHigherAAL is returned when someone requests AAL2 or AAL3 even though no active session exists yet.
 	ErrSessionRequiredForHigherAAL = herodot.ErrUnauthorized.WithID(text.ErrIDSessionRequiredForHigherAAL).WithError("aal2 and aal3 can only be requested if a session exists already").WithReason("You can not requested a higher AAL (AAL2/AAL3) without an active session.")
+
+	// ErrOAuth2SubjectMismatch is returned when the OAuth2 login request asks for another identity than the one signed in.
+	ErrOAuth2SubjectMismatch = herodot.ErrForbidden.WithError("the OAuth2 login request is for another identity").WithReason("The OAuth2 client requested to sign in another user than the one who is signed in already. Please sign out and try again.")
 )
 
 type (
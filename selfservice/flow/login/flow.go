@@ -101,6 +101,10 @@ type Flow struct {
 	//
 	// This value can be one of "aal1", "aal2", "aal3".
 	RequestedAAL identity.AuthenticatorAssuranceLevel `json:"requested_aal" faker:"len=4" db:"requested_aal"`
+
+	// OAuth2LoginChallenge holds the login challenge of the OAuth2 provider, such as Ory Hydra, which started this
+	// flow. If set, the OAuth2 login request is accepted once the user signed in.
+	OAuth2LoginChallenge sqlxx.NullString `json:"oauth2_login_challenge,omitempty" faker:"-" db:"oauth2_login_challenge"`
 }
 
 func NewFlow(conf *config.Config, exp time.Duration, csrf string, r *http.Request, flowType flow.Type) (*Flow, error) {
@@ -134,7 +138,8 @@ func NewFlow(conf *config.Config, exp time.Duration, csrf string, r *http.Reques
 		RequestedAAL: identity.AuthenticatorAssuranceLevel(strings.ToLower(stringsx.Coalesce(
 			r.URL.Query().Get("aal"),
 			string(identity.AuthenticatorAssuranceLevel1)))),
-		InternalContext: []byte("{}"),
+		InternalContext:      []byte("{}"),
+		OAuth2LoginChallenge: sqlxx.NullString(r.URL.Query().Get("login_challenge")),
 	}, nil
 }
 
@@ -23,6 +23,7 @@ import (
 	"github.com/ory/x/urlx"
 
 	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/hydra"
 	"github.com/ory/kratos/selfservice/errorx"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/session"
@@ -42,8 +43,10 @@ type (
 	handlerDependencies interface {
 		HookExecutorProvider
 		FlowPersistenceProvider
+		hydra.HydraProvider
 		errorx.ManagementProvider
 		StrategyProvider
+		identity.PrivilegedPoolProvider
 		session.HandlerProvider
 		session.ManagementProvider
 		x.WriterProvider
@@ -117,6 +120,18 @@ func (h *Handler) NewLoginFlow(w http.ResponseWriter, r *http.Request, ft flow.T
 		return nil, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to parse AuthenticationMethod Assurance Level (AAL): %s", cs.ToUnknownCaseErr()))
 	}
 
+	var loginRequest *hydra.LoginRequest
+	if len(f.OAuth2LoginChallenge) > 0 {
+		if f.Type != flow.TypeBrowser {
+			return nil, errors.WithStack(herodot.ErrBadRequest.WithReason("The login_challenge query parameter can only be used with browser login flows."))
+		}
+
+		// Fail early if the login challenge is unknown to the OAuth2 provider or has expired.
+		if loginRequest, err = h.d.Hydra().GetLoginRequest(r.Context(), f.OAuth2LoginChallenge.String()); err != nil {
+			return nil, err
+		}
+	}
+
 	// We assume an error means the user has no session
 	sess, err := h.d.SessionManager().FetchFromRequest(r.Context(), r)
 	if e := new(session.ErrNoActiveSessionFound); errors.As(err, &e) {
@@ -136,6 +151,17 @@ func (h *Handler) NewLoginFlow(w http.ResponseWriter, r *http.Request, ft flow.T
 		return nil, err
 	} else {
 		// A session exists already
+		if loginRequest != nil {
+			if len(loginRequest.Subject) > 0 && loginRequest.Subject != sess.IdentityID.String() {
+				return nil, errors.WithStack(ErrOAuth2SubjectMismatch)
+			}
+
+			// The OAuth2 client asked for a fresh login, so the existing session can not be used as it is.
+			if loginRequest.RequiresLogin(sess.AuthenticatedAt) {
+				f.Refresh = true
+			}
+		}
+
 		if f.Refresh {
 			// We are refreshing so let's continue
 			goto preLoginHook
@@ -154,6 +180,16 @@ func (h *Handler) NewLoginFlow(w http.ResponseWriter, r *http.Request, ft flow.T
 			return nil, errors.WithStack(ErrAlreadyLoggedIn)
 		}
 
+		// The second factor is not required again on devices the identity trusts. This does not apply to
+		// OAuth2 login requests, which are only accepted once the session completed the second factor.
+		if f.RequestedAAL == identity.AuthenticatorAssuranceLevel2 && loginRequest == nil {
+			if trusted, err := h.d.SessionManager().IsTrustedDevice(r.Context(), r, sess.IdentityID); err != nil {
+				return nil, err
+			} else if trusted {
+				return nil, errors.WithStack(ErrAlreadyLoggedIn)
+			}
+		}
+
 		// Looks like we are requesting an AAL which is higher than what the session has.
 		goto preLoginHook
 	}
@@ -167,13 +203,20 @@ preLoginHook:
 		f.UI.Messages.Add(text.NewInfoLoginMFA())
 	}
 
-	var s Strategy
-	for _, s = range h.d.LoginStrategies(r.Context()) {
-		if err := s.PopulateLoginMethod(r, f.RequestedAAL, f); err != nil {
-			return nil, err
+	if h.usesIdentifierFirst(r, f) {
+		h.populateIdentifierFirst(f)
+	} else {
+		for _, s := range h.d.LoginStrategies(r.Context()) {
+			if err := s.PopulateLoginMethod(r, f.RequestedAAL, f); err != nil {
+				return nil, err
+			}
 		}
 	}
 
+	if h.usesTrustedDevices(r, f) {
+		h.populateTrustedDevice(r, f)
+	}
+
 	if err := sortNodes(r.Context(), f.UI.Nodes); err != nil {
 		return nil, err
 	}
@@ -201,6 +244,7 @@ func (h *Handler) FromOldFlow(w http.ResponseWriter, r *http.Request, of Flow) (
 	}
 
 	nf.RequestURL = of.RequestURL
+	nf.OAuth2LoginChallenge = of.OAuth2LoginChallenge
 	return nf, nil
 }
 
@@ -303,6 +347,14 @@ type initializeSelfServiceLoginFlowForBrowsers struct {
 	// in: query
 	ReturnTo string `json:"return_to"`
 
+	// An Optional OAuth2 Login Challenge
+	//
+	// If set, the login request of the OAuth2 provider configured at `oauth2_provider.url` is accepted
+	// once the user signed in, and the browser is redirected back to the OAuth2 provider.
+	//
+	// in: query
+	LoginChallenge string `json:"login_challenge"`
+
 	// HTTP Cookies
 	//
 	// When using the SDK in a browser app, on the server side you must include the HTTP Cookie Header
@@ -325,6 +377,10 @@ type initializeSelfServiceLoginFlowForBrowsers struct {
 // exists already, the browser will be redirected to `urls.default_redirect_url` unless the query parameter
 // `?refresh=true` was set.
 //
+// If the query parameter `?login_challenge=` is set, the OAuth2 login request is accepted after the user signed in
+// and the browser is redirected back to the OAuth2 provider. If a valid user session exists already, the login
+// request is accepted right away.
+//
 // If this endpoint is called via an AJAX request, the response contains the flow without a redirect. In the
 // case of an error, the `error.id` of the JSON response body can be one of:
 //
@@ -350,6 +406,11 @@ type initializeSelfServiceLoginFlowForBrowsers struct {
 func (h *Handler) initBrowserFlow(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	a, err := h.NewLoginFlow(w, r, flow.TypeBrowser)
 	if errors.Is(err, ErrAlreadyLoggedIn) {
+		if challenge := r.URL.Query().Get("login_challenge"); len(challenge) > 0 {
+			h.acceptOAuth2LoginRequest(w, r, challenge)
+			return
+		}
+
 		returnTo, redirErr := x.SecureRedirectTo(r, h.d.Config().SelfServiceBrowserDefaultReturnTo(r.Context()),
 			x.SecureRedirectAllowSelfServiceURLs(h.d.Config().SelfPublicURL(r.Context())),
 			x.SecureRedirectAllowURLs(h.d.Config().SelfServiceBrowserAllowedReturnToDomains(r.Context())),
@@ -369,6 +430,25 @@ func (h *Handler) initBrowserFlow(w http.ResponseWriter, r *http.Request, ps htt
 	x.AcceptToRedirectOrJSON(w, r, h.d.Writer(), a, a.AppendTo(h.d.Config().SelfServiceFlowLoginUI(r.Context())).String())
 }
 
+// acceptOAuth2LoginRequest accepts the OAuth2 login request with the session the user is already signed in with.
+func (h *Handler) acceptOAuth2LoginRequest(w http.ResponseWriter, r *http.Request, challenge string) {
+	sess, err := h.d.SessionManager().FetchFromRequest(r.Context(), r)
+	if err != nil {
+		h.d.SelfServiceErrorManager().Forward(r.Context(), w, r, err)
+		return
+	}
+
+	requestedAAL := identity.AuthenticatorAssuranceLevel1
+	if r.URL.Query().Get("aal") == string(identity.AuthenticatorAssuranceLevel2) {
+		requestedAAL = identity.AuthenticatorAssuranceLevel2
+	}
+
+	if err := h.d.LoginHookExecutor().acceptOAuth2LoginRequest(w, r, challenge, x.RequestURL(r).String(), requestedAAL, sess); err != nil {
+		h.d.SelfServiceErrorManager().Forward(r.Context(), w, r, err)
+		return
+	}
+}
+
 // nolint:deadcode,unused
 // swagger:parameters getSelfServiceLoginFlow
 type getSelfServiceLoginFlow struct {
@@ -609,6 +689,15 @@ continueLogin:
 		return
 	}
 
+	if err := h.submitIdentifierFirst(w, r, f); err == nil {
+		return
+	} else if !errors.Is(err, flow.ErrStrategyNotResponsible) {
+		h.d.LoginFlowErrorHandler().WriteFlowError(w, r, f, node.IdentifierFirstGroup, err)
+		return
+	}
+
+	trustDevice := h.trustDeviceRequested(r, f)
+
 	var i *identity.Identity
 	var group node.UiNodeGroup
 	for _, ss := range h.d.AllLoginStrategies() {
@@ -632,6 +721,13 @@ continueLogin:
 		method := ss.CompletedAuthenticationMethod(r.Context())
 		sess.CompletedLoginFor(method.Method, method.AAL)
 		i = interim
+
+		if trustDevice && method.AAL == identity.AuthenticatorAssuranceLevel2 {
+			if err := h.d.SessionManager().IssueTrustedDeviceCookie(r.Context(), w, r, i.ID); err != nil {
+				h.d.LoginFlowErrorHandler().WriteFlowError(w, r, f, group, err)
+				return
+			}
+		}
 		break
 	}
 
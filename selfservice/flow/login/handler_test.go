@@ -20,6 +20,7 @@ import (
 
 	"github.com/gobuffalo/httptest"
 	"github.com/gofrs/uuid"
+	"github.com/julienschmidt/httprouter"
 
 	"github.com/ory/kratos/corpx"
 
@@ -34,6 +35,7 @@ import (
 	"github.com/ory/kratos/internal"
 	"github.com/ory/kratos/internal/testhelpers"
 	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/x"
 )
 
@@ -484,6 +486,190 @@ func TestFlowLifecycle(t *testing.T) {
 			})
 		})
 
+		t.Run("flow=oauth2", func(t *testing.T) {
+			var accepted []byte
+			loginRequests := map[string]string{
+				"challenge":     `{"challenge":"challenge"}`,
+				"other-subject": `{"challenge":"other-subject","subject":"` + x.NewUUID().String() + `"}`,
+				"prompt-login":  `{"challenge":"prompt-login","request_url":"https://oauth2.example.org/oauth2/auth?prompt=login"}`,
+				"max-age":       `{"challenge":"max-age","request_url":"https://oauth2.example.org/oauth2/auth?max_age=0"}`,
+			}
+			oauth2Provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				challenge := r.URL.Query().Get("login_challenge")
+				loginRequest, ok := loginRequests[challenge]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				switch r.URL.Path {
+				case "/oauth2/auth/requests/login":
+					_, _ = w.Write([]byte(loginRequest))
+				case "/oauth2/auth/requests/login/accept":
+					accepted, _ = io.ReadAll(r.Body)
+					_, _ = w.Write([]byte(`{"redirect_to":"http://` + r.Host + `/callback?login_challenge=` + challenge + `"}`))
+				case "/callback":
+					_, _ = w.Write([]byte("callback"))
+				}
+			}))
+			t.Cleanup(oauth2Provider.Close)
+
+			t.Run("case=fails if no OAuth2 provider is configured", func(t *testing.T) {
+				res, body := initFlow(t, url.Values{"login_challenge": {"challenge"}}, false)
+				assert.Contains(t, res.Request.URL.String(), errorTS.URL)
+				assert.Contains(t, gjson.GetBytes(body, "reason").String(), "oauth2_provider.url", "%s", body)
+			})
+
+			conf.MustSet(ctx, config.ViperKeyOAuth2ProviderURL, oauth2Provider.URL)
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeyOAuth2ProviderURL, "")
+			})
+
+			t.Run("case=stores the login challenge", func(t *testing.T) {
+				res, body := initFlow(t, url.Values{"login_challenge": {"challenge"}}, false)
+				assert.Contains(t, res.Request.URL.String(), loginTS.URL)
+				assert.Equal(t, "challenge", gjson.GetBytes(body, "oauth2_login_challenge").String(), "%s", body)
+			})
+
+			t.Run("case=rejects an unknown login challenge", func(t *testing.T) {
+				res, body := initFlow(t, url.Values{"login_challenge": {"unknown"}}, false)
+				assert.Contains(t, res.Request.URL.String(), errorTS.URL)
+				assert.Contains(t, gjson.GetBytes(body, "reason").String(), "invalid or has expired", "%s", body)
+			})
+
+			t.Run("case=rejects the login challenge for API flows", func(t *testing.T) {
+				res, body := initFlow(t, url.Values{"login_challenge": {"challenge"}}, true)
+				assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+				assert.Contains(t, gjson.GetBytes(body, "error.reason").String(), "browser login flows", "%s", body)
+			})
+
+			t.Run("case=accepts the login challenge if a session exists", func(t *testing.T) {
+				res, body := initAuthenticatedFlow(t, url.Values{"login_challenge": {"challenge"}}, false)
+				assert.Equal(t, oauth2Provider.URL+"/callback?login_challenge=challenge", res.Request.URL.String())
+				assert.Equal(t, "callback", string(body))
+				assert.True(t, gjson.GetBytes(accepted, "subject").Exists(), "%s", accepted)
+			})
+
+			t.Run("case=refuses a login challenge for another identity", func(t *testing.T) {
+				accepted = nil
+				res, body := initAuthenticatedFlow(t, url.Values{"login_challenge": {"other-subject"}}, false)
+				assert.Contains(t, res.Request.URL.String(), errorTS.URL)
+				assert.Contains(t, gjson.GetBytes(body, "reason").String(), "another user", "%s", body)
+				assert.Empty(t, accepted)
+			})
+
+			for _, challenge := range []string{"prompt-login", "max-age"} {
+				t.Run("case=forces a fresh login for challenge="+challenge, func(t *testing.T) {
+					accepted = nil
+					res, body := initAuthenticatedFlow(t, url.Values{"login_challenge": {challenge}}, false)
+					assert.Contains(t, res.Request.URL.String(), loginTS.URL)
+					assertion(body, true, false)
+					assert.Equal(t, challenge, gjson.GetBytes(body, "oauth2_login_challenge").String(), "%s", body)
+					assert.Empty(t, accepted)
+				})
+			}
+
+			newIdentity := func(t *testing.T, credentials map[identity.CredentialsType]identity.Credentials) *identity.Identity {
+				i := &identity.Identity{Traits: identity.Traits(`{}`), State: identity.StateActive, Credentials: credentials}
+				require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+				return i
+			}
+
+			passwordCredentials := func(changedAt time.Time) identity.Credentials {
+				return identity.Credentials{
+					Type:        identity.CredentialsTypePassword,
+					Identifiers: []string{testhelpers.RandomEmail()},
+					Config:      sqlxx.JSONRawMessage(`{"hashed_password":"$argon2id$v=19$m=32,t=2,p=4$cm94YnRVOW5jZzFzcVE4bQ$MNzk5BtR2vUhrp6qQEjRNw","changed_at":"` + changedAt.UTC().Format(time.RFC3339) + `"}`),
+				}
+			}
+
+			initRefusedFlow := func(t *testing.T, i *identity.Identity, client *http.Client) *url.URL {
+				accepted = nil
+				req := x.NewTestHTTPRequest(t, "GET", ts.URL+login.RouteInitBrowserFlow+"?login_challenge=challenge", nil)
+				_, res := testhelpers.MockMakeAuthenticatedRequestWithClientAndID(t, reg, conf, router.Router, req, client, i)
+				require.Equal(t, http.StatusSeeOther, res.StatusCode)
+				assert.Empty(t, accepted, "the login request must not be accepted")
+
+				location, err := res.Location()
+				require.NoError(t, err)
+				return location
+			}
+
+			t.Run("case=refuses the login challenge if the identity must set up a second factor", func(t *testing.T) {
+				conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentEnabled, true)
+				t.Cleanup(func() {
+					conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentEnabled, false)
+				})
+
+				i := newIdentity(t, map[identity.CredentialsType]identity.Credentials{
+					identity.CredentialsTypePassword: passwordCredentials(time.Now()),
+				})
+				location := initRefusedFlow(t, i, testhelpers.NewNoRedirectClientWithCookies(t))
+				assert.Equal(t, "/self-service/settings/browser", location.Path)
+			})
+
+			t.Run("case=refuses the login challenge if the identity must reset its credentials", func(t *testing.T) {
+				i := newIdentity(t, map[identity.CredentialsType]identity.Credentials{
+					identity.CredentialsTypePassword: passwordCredentials(time.Now()),
+				})
+				i.CredentialsResetRequired = identity.CredentialsResetPassword
+				require.NoError(t, reg.PrivilegedIdentityPool().UpdateIdentity(ctx, i))
+
+				location := initRefusedFlow(t, i, testhelpers.NewNoRedirectClientWithCookies(t))
+				assert.Equal(t, "/self-service/settings/browser", location.Path)
+			})
+
+			t.Run("case=refuses the login challenge if the password expired", func(t *testing.T) {
+				conf.MustSet(ctx, config.ViperKeyPasswordMaxAge, "1h")
+				t.Cleanup(func() {
+					conf.MustSet(ctx, config.ViperKeyPasswordMaxAge, "0s")
+				})
+
+				i := newIdentity(t, map[identity.CredentialsType]identity.Credentials{
+					identity.CredentialsTypePassword: passwordCredentials(time.Now().Add(-2 * time.Hour)),
+				})
+				location := initRefusedFlow(t, i, testhelpers.NewNoRedirectClientWithCookies(t))
+				assert.Equal(t, "/self-service/settings/browser", location.Path)
+			})
+
+			t.Run("case=refuses the login challenge if the session does not satisfy the AAL even on a trusted device", func(t *testing.T) {
+				conf.MustSet(ctx, config.ViperKeySessionWhoAmIAAL, config.HighestAvailableAAL)
+				conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+session.TrustedDeviceMethod+".enabled", true)
+				t.Cleanup(func() {
+					conf.MustSet(ctx, config.ViperKeySessionWhoAmIAAL, "aal1")
+					conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+session.TrustedDeviceMethod+".enabled", false)
+				})
+
+				i := newIdentity(t, map[identity.CredentialsType]identity.Credentials{
+					identity.CredentialsTypePassword: passwordCredentials(time.Now()),
+					identity.CredentialsTypeWebAuthn: {Type: identity.CredentialsTypeWebAuthn, Config: sqlxx.JSONRawMessage(`{"credentials":[{"is_passwordless":false}]}`), Identifiers: []string{testhelpers.RandomEmail()}},
+				})
+
+				trust := "/" + x.NewUUID().String() + "/trust"
+				router.GET(trust, func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+					require.NoError(t, reg.SessionManager().IssueTrustedDeviceCookie(r.Context(), w, r, i.ID))
+					w.WriteHeader(http.StatusNoContent)
+				})
+				client := testhelpers.NewNoRedirectClientWithCookies(t)
+				res, err := client.Get(ts.URL + trust)
+				require.NoError(t, err)
+				require.NoError(t, res.Body.Close())
+
+				location := initRefusedFlow(t, i, client)
+				assert.Equal(t, login.RouteInitBrowserFlow, location.Path)
+				assert.Equal(t, "aal2", location.Query().Get("aal"))
+				assert.Equal(t, "challenge", location.Query().Get("login_challenge"))
+
+				// The trusted device must not skip the second factor for the OAuth2 login request either.
+				res, err = client.Get(location.String())
+				require.NoError(t, err)
+				require.NoError(t, res.Body.Close())
+				require.Equal(t, http.StatusSeeOther, res.StatusCode)
+				assert.Contains(t, res.Header.Get("Location"), loginTS.URL)
+				assert.Empty(t, accepted)
+			})
+		})
+
 		t.Run("case=relative redirect when self-service login ui is a relative URL", func(t *testing.T) {
 			reg.Config().MustSet(ctx, config.ViperKeySelfServiceLoginUI, "/login-ts")
 			assert.Regexp(
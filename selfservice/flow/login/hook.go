@@ -4,11 +4,16 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/pkg/errors"
 
+	"github.com/ory/x/urlx"
+
+	"github.com/ory/kratos/continuity"
 	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/hydra"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/session"
@@ -35,6 +40,10 @@ type (
 type (
 	executorDependencies interface {
 		config.Provider
+		continuity.ManagementProvider
+		hydra.HydraProvider
+		identity.PrivilegedPoolProvider
+		identity.ManagementProvider
 		session.ManagementProvider
 		session.PersistenceProvider
 		x.CSRFTokenGeneratorProvider
@@ -74,6 +83,18 @@ func (e *HookExecutor) requiresAAL2(r *http.Request, s *session.Session, a *Flow
 		return nil, false
 	}
 
+	// The login challenge is passed on to the second factor's login flow which accepts it instead.
+	if len(a.OAuth2LoginChallenge) > 0 {
+		u, err := url.Parse(aalErr.RedirectTo)
+		if err != nil {
+			return nil, false
+		}
+		q := u.Query()
+		q.Set("login_challenge", a.OAuth2LoginChallenge.String())
+		u.RawQuery = q.Encode()
+		aalErr.RedirectTo = u.String()
+	}
+
 	return aalErr, true
 }
 
@@ -105,6 +126,16 @@ func (e *HookExecutor) PostLoginHook(w http.ResponseWriter, r *http.Request, g n
 		return err
 	}
 
+	mfaEnrollmentRequired, err := e.d.SessionManager().RequiresMFAEnrollment(r.Context(), i)
+	if err != nil {
+		return err
+	}
+	s.MFAEnrollmentRequired = mfaEnrollmentRequired
+
+	if err := e.linkCredentials(w, r, a, s, i); err != nil {
+		return e.handleLoginError(w, r, g, a, i, err)
+	}
+
 	// Verify the redirect URL before we do any other processing.
 	c := e.d.Config()
 	returnTo, err := x.SecureRedirectTo(r, c.SelfServiceBrowserDefaultReturnTo(r.Context()),
@@ -181,6 +212,10 @@ func (e *HookExecutor) PostLoginHook(w http.ResponseWriter, r *http.Request, g n
 		WithField("session_id", s.ID).
 		Info("Identity authenticated successfully and was issued an Ory Kratos Session Cookie.")
 
+	if len(a.OAuth2LoginChallenge) > 0 {
+		return e.acceptOAuth2LoginRequest(w, r, a.OAuth2LoginChallenge.String(), a.RequestURL, a.RequestedAAL, s)
+	}
+
 	if x.IsJSONRequest(r) {
 		// Browser flows rely on cookies. Adding tokens in the mix will confuse consumers.
 		s.Token = ""
@@ -204,6 +239,88 @@ func (e *HookExecutor) PostLoginHook(w http.ResponseWriter, r *http.Request, g n
 	return nil
 }
 
+// acceptOAuth2LoginRequest accepts the OAuth2 login request with the session and sends the browser back to the
+// OAuth2 provider. The session is only handed to the OAuth2 provider if it could be used like any other session:
+// the identity must not have to set up a second factor or to replace its credentials, and the session must have
+// the requested AAL. Trusted devices do not replace the second factor here, because the OAuth2 provider can not
+// tell these sessions apart. Otherwise, the browser is sent to the settings or the second factor's login flow.
+func (e *HookExecutor) acceptOAuth2LoginRequest(w http.ResponseWriter, r *http.Request, challenge, requestURL string, requestedAAL identity.AuthenticatorAssuranceLevel, s *session.Session) error {
+	ctx := r.Context()
+	i, err := e.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, s.IdentityID)
+	if err != nil {
+		return err
+	}
+
+	settingsURL := urlx.AppendPaths(e.d.Config().SelfPublicURL(ctx), "/self-service/settings/browser").String()
+
+	mfaEnrollmentRequired, err := e.d.SessionManager().RequiresMFAEnrollment(ctx, i)
+	if err != nil {
+		return err
+	}
+	if mfaEnrollmentRequired {
+		return e.redirectOAuth2LoginRequest(w, r, session.NewErrMFAEnrollmentRequired(settingsURL), settingsURL)
+	}
+
+	if i.CredentialsResetRequired != identity.CredentialsResetNone || passwordExpired(i, e.d.Config().PasswordPolicyConfig(ctx).MaxPasswordAge) {
+		return e.redirectOAuth2LoginRequest(w, r, session.NewErrCredentialsResetRequired(settingsURL), settingsURL)
+	}
+
+	var aalErr *session.ErrAALNotSatisfied
+	s.SetAuthenticatorAssuranceLevel()
+	if s.AuthenticatorAssuranceLevel < requestedAAL {
+		aalErr = session.NewErrAALNotSatisfied(
+			urlx.CopyWithQuery(urlx.AppendPaths(e.d.Config().SelfPublicURL(ctx), RouteInitBrowserFlow), url.Values{"aal": {string(requestedAAL)}}).String())
+	} else if err := e.d.SessionManager().DoesSessionSatisfy(r, s, e.d.Config().SessionWhoAmIAAL(ctx), session.WithoutTrustedDevices()); err != nil && !errors.As(err, &aalErr) {
+		return err
+	}
+
+	if aalErr != nil {
+		if err := aalErr.PassReturnToParameter(requestURL); err != nil {
+			return err
+		}
+
+		// The login challenge is passed on to the second factor's login flow which accepts it instead.
+		u, err := url.Parse(aalErr.RedirectTo)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		q := u.Query()
+		q.Set("login_challenge", challenge)
+		u.RawQuery = q.Encode()
+		aalErr.RedirectTo = u.String()
+
+		return e.redirectOAuth2LoginRequest(w, r, aalErr, aalErr.RedirectTo)
+	}
+
+	redirectTo, err := e.d.Hydra().AcceptLoginRequest(ctx, challenge, s)
+	if err != nil {
+		return err
+	}
+
+	return e.redirectOAuth2LoginRequest(w, r, flow.NewBrowserLocationChangeRequiredError(redirectTo), redirectTo)
+}
+
+// redirectOAuth2LoginRequest sends the browser to redirectTo. AJAX clients receive the given error instead
+// which tells them where to send the browser.
+func (e *HookExecutor) redirectOAuth2LoginRequest(w http.ResponseWriter, r *http.Request, err error, redirectTo string) error {
+	if x.IsJSONRequest(r) {
+		e.d.Writer().WriteError(w, r, err)
+		return nil
+	}
+
+	http.Redirect(w, r, redirectTo, http.StatusSeeOther)
+	return nil
+}
+
+// passwordExpired answers if the identity's password is older than maxAge.
+func passwordExpired(i *identity.Identity, maxAge time.Duration) bool {
+	var o identity.CredentialsPassword
+	if _, err := i.ParseCredentials(identity.CredentialsTypePassword, &o); err != nil {
+		return false
+	}
+	return o.IsExpired(maxAge)
+}
+
 func (e *HookExecutor) PreLoginHook(w http.ResponseWriter, r *http.Request, a *Flow) error {
 	for _, executor := range e.d.PreLoginHooks(r.Context()) {
 		if err := executor.ExecuteLoginPreHook(w, r, a); err != nil {
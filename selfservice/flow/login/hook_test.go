@@ -2,6 +2,7 @@ package login_test
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/url"
 	"testing"
@@ -22,6 +23,7 @@ import (
 	"github.com/ory/kratos/internal/testhelpers"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/text"
 	"github.com/ory/kratos/x"
 )
 
@@ -83,6 +85,18 @@ func TestLoginExecutor(t *testing.T) {
 					assert.EqualValues(t, "https://www.ory.sh/", res.Request.URL.String())
 				})
 
+				t.Run("case=do not accept the OAuth2 login request if the identity must set up a second factor", func(t *testing.T) {
+					t.Cleanup(testhelpers.SelfServiceHookConfigReset(t, conf))
+					conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentEnabled, true)
+					t.Cleanup(func() {
+						conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentEnabled, false)
+					})
+
+					res, body := makeRequestPost(t, newServer(t, flow.TypeBrowser, nil), true, url.Values{"login_challenge": {"challenge"}})
+					assert.EqualValues(t, http.StatusForbidden, res.StatusCode, "%s", body)
+					assert.EqualValues(t, text.ErrIDMFAEnrollmentRequired, gjson.Get(body, "error.id").String(), "%s", body)
+				})
+
 				t.Run("case=pass if hooks pass", func(t *testing.T) {
 					t.Cleanup(testhelpers.SelfServiceHookConfigReset(t, conf))
 					viperSetPost(t, conf, strategy.String(), []config.SelfServiceHook{{Name: "err", Config: []byte(`{}`)}})
@@ -226,6 +240,73 @@ func TestLoginExecutor(t *testing.T) {
 				})
 			})
 
+			t.Run("method=PostLoginHook with an OAuth2 login challenge", func(t *testing.T) {
+				var accepted []byte
+				oauth2Provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.URL.Path {
+					case "/oauth2/auth/requests/login/accept":
+						assert.Equal(t, "PUT", r.Method)
+						assert.Equal(t, "challenge", r.URL.Query().Get("login_challenge"))
+						accepted, _ = io.ReadAll(r.Body)
+						_, _ = w.Write([]byte(`{"redirect_to":"http://` + r.Host + `/callback"}`))
+					case "/callback":
+						_, _ = w.Write([]byte("callback"))
+					default:
+						w.WriteHeader(http.StatusNotFound)
+					}
+				}))
+				t.Cleanup(oauth2Provider.Close)
+				conf.MustSet(ctx, config.ViperKeyOAuth2ProviderURL, oauth2Provider.URL)
+				t.Cleanup(func() {
+					conf.MustSet(ctx, config.ViperKeyOAuth2ProviderURL, "")
+				})
+
+				t.Run("case=accept the login request and redirect to the OAuth2 provider", func(t *testing.T) {
+					t.Cleanup(testhelpers.SelfServiceHookConfigReset(t, conf))
+					conf.MustSet(ctx, config.ViperKeySessionPersistentCookie, true)
+					conf.MustSet(ctx, config.ViperKeySessionLifespan, "1h")
+					useIdentity := testhelpers.SelfServiceHookCreateFakeIdentity(t, reg)
+
+					res, body := makeRequestPost(t, newServer(t, flow.TypeBrowser, useIdentity), false, url.Values{"login_challenge": {"challenge"}})
+					assert.EqualValues(t, http.StatusOK, res.StatusCode)
+					assert.EqualValues(t, oauth2Provider.URL+"/callback", res.Request.URL.String())
+					assert.Equal(t, "callback", body)
+
+					assert.Equal(t, useIdentity.ID.String(), gjson.GetBytes(accepted, "subject").String(), "%s", accepted)
+					assert.True(t, gjson.GetBytes(accepted, "remember").Bool(), "%s", accepted)
+					assert.EqualValues(t, 3600, gjson.GetBytes(accepted, "remember_for").Int(), "%s", accepted)
+					assert.Equal(t, "aal1", gjson.GetBytes(accepted, "acr").String(), "%s", accepted)
+					assert.Equal(t, `["password"]`, gjson.GetBytes(accepted, "amr").Raw, "%s", accepted)
+				})
+
+				t.Run("case=ask the browser to redirect to the OAuth2 provider for JSON requests", func(t *testing.T) {
+					t.Cleanup(testhelpers.SelfServiceHookConfigReset(t, conf))
+
+					res, body := makeRequestPost(t, newServer(t, flow.TypeBrowser, nil), true, url.Values{"login_challenge": {"challenge"}})
+					assert.EqualValues(t, http.StatusUnprocessableEntity, res.StatusCode)
+					assert.Equal(t, oauth2Provider.URL+"/callback", gjson.Get(body, "redirect_browser_to").String(), "%s", body)
+				})
+
+				t.Run("case=pass the login challenge to the second factor", func(t *testing.T) {
+					conf.MustSet(ctx, config.ViperKeySessionWhoAmIAAL, "highest_available")
+					_ = testhelpers.NewLoginUIFlowEchoServer(t, reg)
+					t.Cleanup(func() {
+						conf.MustSet(ctx, config.ViperKeySessionWhoAmIAAL, "aal1")
+					})
+					t.Cleanup(testhelpers.SelfServiceHookConfigReset(t, conf))
+
+					useIdentity := &identity.Identity{Credentials: map[identity.CredentialsType]identity.Credentials{
+						identity.CredentialsTypePassword: {Type: identity.CredentialsTypePassword, Config: []byte(`{"hashed_password": "$argon2id$v=19$m=32,t=2,p=4$cm94YnRVOW5jZzFzcVE4bQ$MNzk5BtR2vUhrp6qQEjRNw"}`), Identifiers: []string{testhelpers.RandomEmail()}},
+						identity.CredentialsTypeWebAuthn: {Type: identity.CredentialsTypeWebAuthn, Config: []byte(`{"credentials":[{"is_passwordless":false}]}`), Identifiers: []string{testhelpers.RandomEmail()}},
+					}}
+					require.NoError(t, reg.Persister().CreateIdentity(context.Background(), useIdentity))
+
+					res, _ := makeRequestPost(t, newServer(t, flow.TypeBrowser, useIdentity), false, url.Values{"login_challenge": {"challenge"}})
+					assert.Contains(t, res.Request.URL.String(), "/self-service/login/browser?aal=aal2")
+					assert.Equal(t, "challenge", res.Request.URL.Query().Get("login_challenge"))
+				})
+			})
+
 			t.Run("type=api", func(t *testing.T) {
 				t.Run("method=PreLoginHook", testhelpers.TestSelfServicePreHook(
 					config.ViperKeySelfServiceLoginBeforeHooks,
@@ -0,0 +1,244 @@
+package login
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/x/decoderx"
+	"github.com/ory/x/sqlcon"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/schema"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/kratos/x"
+)
+
+// MethodIdentifierFirst is the method used to submit the identifier in identifier-first login flows.
+const MethodIdentifierFirst = "identifier_first"
+
+// SubmitSelfServiceLoginFlowWithIdentifierFirstMethodBody is used to decode the first step of
+// identifier-first login flows.
+//
+// swagger:model submitSelfServiceLoginFlowWithIdentifierFirstMethodBody
+type SubmitSelfServiceLoginFlowWithIdentifierFirstMethodBody struct {
+	// Identifier is the email or username of the user trying to log in.
+	//
+	// required: true
+	Identifier string `json:"identifier"`
+
+	// The CSRF Token
+	CSRFToken string `json:"csrf_token"`
+
+	// Method should be set to "identifier_first" when submitting the identifier.
+	//
+	// required: true
+	Method string `json:"method"`
+}
+
+// usesIdentifierFirst returns true if the flow asks for the identifier before showing any login methods.
+//
+// Refreshing a session or upgrading the AAL uses the identity of the existing session instead.
+func (h *Handler) usesIdentifierFirst(r *http.Request, f *Flow) bool {
+	return h.d.Config().SelfServiceFlowLoginIdentifierFirst(r.Context()) &&
+		!f.Refresh && f.RequestedAAL == identity.AuthenticatorAssuranceLevel1
+}
+
+func (h *Handler) populateIdentifierFirst(f *Flow) {
+	f.UI.SetNode(node.NewInputField("identifier", "", node.DefaultGroup, node.InputAttributeTypeText, node.WithRequiredInputAttribute).WithMetaLabel(text.NewInfoNodeLabelID()))
+	f.UI.GetNodes().Append(node.NewInputField("method", MethodIdentifierFirst, node.IdentifierFirstGroup, node.InputAttributeTypeSubmit).WithMetaLabel(text.NewInfoSelfServiceLoginContinue()))
+}
+
+// submitIdentifierFirst handles the first step of identifier-first login flows. It replaces the identifier
+// form with the login methods available to the identity and sends the updated flow back to the client.
+//
+// Returns flow.ErrStrategyNotResponsible if the request does not submit an identifier.
+func (h *Handler) submitIdentifierFirst(w http.ResponseWriter, r *http.Request, f *Flow) error {
+	if !h.usesIdentifierFirst(r, f) {
+		return errors.WithStack(flow.ErrStrategyNotResponsible)
+	}
+
+	if method, err := flow.MethodFromRequest(r); err != nil {
+		return err
+	} else if method != MethodIdentifierFirst {
+		return errors.WithStack(flow.ErrStrategyNotResponsible)
+	}
+
+	var p SubmitSelfServiceLoginFlowWithIdentifierFirstMethodBody
+	if err := h.hd.Decode(r, &p,
+		decoderx.HTTPDecoderSetValidatePayloads(true),
+		decoderx.MustHTTPRawJSONSchemaCompiler(identifierFirstSchema),
+		decoderx.HTTPDecoderJSONFollowsFormFormat()); err != nil {
+		return err
+	}
+
+	f.UI.Nodes.SetValueAttribute("identifier", p.Identifier)
+	if err := flow.EnsureCSRF(h.d, r, f.Type, h.d.Config().DisableAPIFlowEnforcement(r.Context()), h.d.GenerateCSRFToken, p.CSRFToken); err != nil {
+		return err
+	}
+
+	methods, providers, err := h.identifierFirstMethods(r, strings.TrimSpace(p.Identifier))
+	if err != nil {
+		return err
+	}
+
+	identifierNodes := f.UI.Nodes
+	f.UI.Nodes = node.Nodes{}
+	f.UI.ResetMessages()
+	for _, s := range h.d.LoginStrategies(r.Context()) {
+		if _, ok := methods[s.ID()]; !ok {
+			continue
+		}
+
+		if err := s.PopulateLoginMethod(r, f.RequestedAAL, f); err != nil {
+			return err
+		}
+	}
+
+	if providers != nil {
+		f.UI.Nodes = filterProviders(f.UI.Nodes, providers)
+	}
+
+	if !hasLoginMethod(f.UI.Nodes) {
+		// Keep asking for the identifier.
+		f.UI.Nodes = identifierNodes
+		return schema.NewNoLoginMethod()
+	}
+
+	if f.UI.Nodes.Find("identifier") != nil {
+		f.UI.Nodes.Upsert(node.NewInputField("identifier", p.Identifier, node.DefaultGroup, node.InputAttributeTypeHidden))
+	}
+
+	if f.Type == flow.TypeBrowser {
+		f.UI.SetCSRF(h.d.GenerateCSRFToken(r))
+	}
+
+	if err := sortNodes(r.Context(), f.UI.Nodes); err != nil {
+		return err
+	}
+
+	if err := h.d.LoginFlowPersister().UpdateLoginFlow(r.Context(), f); err != nil {
+		return err
+	}
+
+	if f.Type == flow.TypeBrowser && !x.IsJSONRequest(r) {
+		http.Redirect(w, r, f.AppendTo(h.d.Config().SelfServiceFlowLoginUI(r.Context())).String(), http.StatusSeeOther)
+		return nil
+	}
+
+	// The login is not completed yet, so we respond like we do for validation errors and send the flow.
+	h.d.Writer().WriteCode(w, r, http.StatusBadRequest, f)
+	return nil
+}
+
+// identifierFirstMethods returns the login methods to show for the given identifier. If the OpenID Connect
+// method is returned, the returned providers restrict which providers are shown.
+func (h *Handler) identifierFirstMethods(r *http.Request, identifier string) (map[identity.CredentialsType]struct{}, []string, error) {
+	ctx := r.Context()
+
+	if at := strings.LastIndex(identifier, "@"); at > -1 {
+		domain := identifier[at+1:]
+		for _, realm := range h.d.Config().SelfServiceFlowLoginHomeRealms(ctx) {
+			if strings.EqualFold(realm.Domain, domain) {
+				return map[identity.CredentialsType]struct{}{identity.CredentialsTypeOIDC: {}}, []string{realm.Provider}, nil
+			}
+		}
+	}
+
+	if h.d.Config().SelfServiceFlowLoginConcealAccountExistence(ctx) {
+		// Show every login method regardless of the identifier so that the response does not reveal whether
+		// the account exists or which credentials it uses.
+		methods := make(map[identity.CredentialsType]struct{})
+		for _, s := range h.d.LoginStrategies(ctx) {
+			methods[s.ID()] = struct{}{}
+		}
+		return methods, nil, nil
+	}
+
+	i, err := h.findIdentityByIdentifier(r, identifier)
+	if errors.Is(err, sqlcon.ErrNoRows) {
+		return nil, nil, schema.NewNoLoginMethod()
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	var providers []string
+	methods := make(map[identity.CredentialsType]struct{}, len(i.Credentials))
+	for ct, c := range i.Credentials {
+		methods[ct] = struct{}{}
+
+		if ct == identity.CredentialsTypeOIDC {
+			var conf identity.CredentialsOIDC
+			if err := json.Unmarshal(c.Config, &conf); err != nil {
+				return nil, nil, errors.WithStack(err)
+			}
+
+			providers = []string{}
+			for _, p := range conf.Providers {
+				providers = append(providers, p.Provider)
+			}
+		}
+	}
+
+	return methods, providers, nil
+}
+
+// findIdentityByIdentifier looks up the identity by its password or WebAuthn identifier and falls back to
+// its verifiable addresses, which also finds identities which only use OpenID Connect.
+func (h *Handler) findIdentityByIdentifier(r *http.Request, identifier string) (*identity.Identity, error) {
+	ctx := r.Context()
+
+	for _, ct := range []identity.CredentialsType{identity.CredentialsTypePassword, identity.CredentialsTypeWebAuthn} {
+		// The identity returned here does not include its credentials.
+		i, _, err := h.d.PrivilegedIdentityPool().FindByCredentialsIdentifier(ctx, ct, identifier)
+		if err == nil {
+			return h.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+		} else if !errors.Is(err, sqlcon.ErrNoRows) {
+			return nil, err
+		}
+	}
+
+	address, err := h.d.PrivilegedIdentityPool().FindVerifiableAddressByValue(ctx, identity.VerifiableAddressTypeEmail, strings.ToLower(identifier))
+	if err != nil {
+		return nil, err
+	}
+
+	return h.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, address.IdentityID)
+}
+
+// filterProviders removes all OpenID Connect provider nodes except the given providers.
+func filterProviders(nodes node.Nodes, providers []string) node.Nodes {
+	filtered := make(node.Nodes, 0, len(nodes))
+	for _, n := range nodes {
+		if n.Group == node.OpenIDConnectGroup && n.ID() == "provider" {
+			var found bool
+			for _, p := range providers {
+				if n.GetValue() == p {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				continue
+			}
+		}
+
+		filtered = append(filtered, n)
+	}
+	return filtered
+}
+
+// hasLoginMethod returns true if at least one node besides the identifier and CSRF token was populated.
+func hasLoginMethod(nodes node.Nodes) bool {
+	for _, n := range nodes {
+		if n.Group != node.DefaultGroup {
+			return true
+		}
+	}
+	return false
+}
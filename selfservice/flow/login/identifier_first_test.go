@@ -0,0 +1,141 @@
+package login_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/x/sqlxx"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/x"
+)
+
+func TestIdentifierFirst(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	publicTS, _ := testhelpers.NewKratosServer(t, reg)
+	_ = testhelpers.NewLoginUIFlowEchoServer(t, reg)
+	_ = testhelpers.NewErrorTestServer(t, reg)
+
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/password.schema.json")
+	testhelpers.StrategyEnable(t, conf, identity.CredentialsTypePassword.String(), true)
+	testhelpers.StrategyEnable(t, conf, identity.CredentialsTypeOIDC.String(), true)
+	conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+".oidc.config.providers", []map[string]interface{}{
+		{"id": "google", "provider": "google", "client_id": "a", "client_secret": "b", "mapper_url": "file://./stub/oidc.jsonnet"},
+		{"id": "corp", "provider": "generic", "client_id": "a", "client_secret": "b", "mapper_url": "file://./stub/oidc.jsonnet", "issuer_url": "https://corp.example.com"},
+	})
+	conf.MustSet(ctx, config.ViperKeySelfServiceLoginStyle, config.LoginStyleIdentifierFirst)
+	conf.MustSet(ctx, config.ViperKeySelfServiceLoginHomeRealmDiscovery, []map[string]interface{}{
+		{"domain": "corp.example.com", "provider": "corp"},
+	})
+
+	passwordUser := x.NewUUID().String()
+	require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, &identity.Identity{
+		Credentials: map[identity.CredentialsType]identity.Credentials{
+			identity.CredentialsTypePassword: {
+				Type:        identity.CredentialsTypePassword,
+				Identifiers: []string{passwordUser},
+				Config:      sqlxx.JSONRawMessage(`{"hashed_password":"$2a$08$.cOYmAd.vCpDOoiVJrO5B.hjTLKQQ6cAK40u8uB.FnZDyPvVvQ9Q."}`), // foobar
+			}},
+		State:  identity.StateActive,
+		Traits: identity.Traits(`{"username":"` + passwordUser + `"}`),
+	}))
+
+	oidcUser := x.NewUUID().String() + "@ory.sh"
+	require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, &identity.Identity{
+		Credentials: map[identity.CredentialsType]identity.Credentials{
+			identity.CredentialsTypeOIDC: {
+				Type:        identity.CredentialsTypeOIDC,
+				Identifiers: []string{identity.OIDCUniqueID("google", oidcUser)},
+				Config:      sqlxx.JSONRawMessage(`{"providers":[{"provider":"google","subject":"` + oidcUser + `"}]}`),
+			}},
+		State:  identity.StateActive,
+		Traits: identity.Traits(`{"username":"` + oidcUser + `"}`),
+		VerifiableAddresses: []identity.VerifiableAddress{
+			{Value: oidcUser, Via: identity.VerifiableAddressTypeEmail, Status: identity.VerifiableAddressStatusCompleted, Verified: true},
+		},
+	}))
+
+	submitIdentifier := func(t *testing.T, identifier string) (string, *http.Response) {
+		client := testhelpers.NewClientWithCookies(t)
+		f := testhelpers.InitializeLoginFlowViaBrowser(t, client, publicTS, false, true)
+
+		values := testhelpers.SDKFormFieldsToURLValues(f.Ui.Nodes)
+		assert.Equal(t, login.MethodIdentifierFirst, values.Get("method"))
+
+		values.Set("identifier", identifier)
+		return testhelpers.LoginMakeRequest(t, false, true, f, client, testhelpers.EncodeFormAsJSON(t, false, values))
+	}
+
+	t.Run("case=shows only the password method for identities with a password", func(t *testing.T) {
+		body, res := submitIdentifier(t, passwordUser)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+		assert.True(t, gjson.Get(body, "ui.nodes.#(attributes.name==password)").Exists(), "%s", body)
+		assert.Equal(t, "hidden", gjson.Get(body, "ui.nodes.#(attributes.name==identifier).attributes.type").String(), "%s", body)
+		assert.Equal(t, passwordUser, gjson.Get(body, "ui.nodes.#(attributes.name==identifier).attributes.value").String(), "%s", body)
+		assert.False(t, gjson.Get(body, "ui.nodes.#(attributes.name==provider)").Exists(), "%s", body)
+	})
+
+	t.Run("case=shows only the linked provider for identities using social sign in", func(t *testing.T) {
+		body, res := submitIdentifier(t, oidcUser)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+		assert.False(t, gjson.Get(body, "ui.nodes.#(attributes.name==password)").Exists(), "%s", body)
+		assert.Equal(t, []interface{}{"google"}, gjson.Get(body, "ui.nodes.#(attributes.name==provider)#.attributes.value").Value(), "%s", body)
+	})
+
+	t.Run("case=routes email domains to the configured provider", func(t *testing.T) {
+		body, res := submitIdentifier(t, "someone@corp.example.com")
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+		assert.False(t, gjson.Get(body, "ui.nodes.#(attributes.name==password)").Exists(), "%s", body)
+		assert.Equal(t, []interface{}{"corp"}, gjson.Get(body, "ui.nodes.#(attributes.name==provider)#.attributes.value").Value(), "%s", body)
+	})
+
+	t.Run("case=reveals that an account does not exist", func(t *testing.T) {
+		body, res := submitIdentifier(t, x.NewUUID().String())
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+		assert.EqualValues(t, text.ErrorValidationNoLoginMethod, gjson.Get(body, "ui.nodes.#(attributes.name==identifier).messages.0.id").Int(), "%s", body)
+		assert.Equal(t, login.MethodIdentifierFirst, gjson.Get(body, "ui.nodes.#(attributes.name==method).attributes.value").String(), "%s", body)
+	})
+
+	t.Run("case=conceals whether an account exists", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceLoginConcealAccountExistence, true)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceLoginConcealAccountExistence, false)
+		})
+
+		nodes := func(t *testing.T, identifier string) []interface{} {
+			body, res := submitIdentifier(t, identifier)
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+			assert.Empty(t, gjson.Get(body, "ui.messages").Array(), "%s", body)
+			assert.True(t, gjson.Get(body, "ui.nodes.#(attributes.name==password)").Exists(), "%s", body)
+			assert.ElementsMatch(t, []interface{}{"google", "corp"}, gjson.Get(body, "ui.nodes.#(attributes.name==provider)#.attributes.value").Value(), "%s", body)
+			return gjson.Get(body, "ui.nodes.#.attributes.name").Value().([]interface{})
+		}
+
+		unknown := nodes(t, x.NewUUID().String())
+		assert.Equal(t, unknown, nodes(t, passwordUser))
+		assert.Equal(t, unknown, nodes(t, oidcUser))
+	})
+
+	t.Run("case=shows all methods when using the unified style", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceLoginStyle, config.LoginStyleUnified)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceLoginStyle, config.LoginStyleIdentifierFirst)
+		})
+
+		f := testhelpers.InitializeLoginFlowViaBrowser(t, testhelpers.NewClientWithCookies(t), publicTS, false, true)
+		values := testhelpers.SDKFormFieldsToURLValues(f.Ui.Nodes)
+		assert.Equal(t, "password", values.Get("method"), "%v", values)
+		assert.Equal(t, "google", values.Get("provider"), "%v", values)
+	})
+}
@@ -0,0 +1,135 @@
+package login
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/ory/herodot"
+
+	"github.com/ory/kratos/continuity"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/session"
+)
+
+const (
+	// InternalContextKeyLinkCredentials is the key of the credentials to link in the flow's internal context.
+	InternalContextKeyLinkCredentials = "link_credentials"
+
+	continuityKeyLinkCredentials = "ory_kratos_login_link_credentials"
+)
+
+// LinkCredentials are credentials which are added to an existing identity once the user
+// signed in to that identity with one of its existing credentials.
+type LinkCredentials struct {
+	// IdentityID is the ID of the identity the credentials will be linked to.
+	IdentityID uuid.UUID `json:"identity_id"`
+
+	// Credentials are the credentials to link.
+	Credentials identity.Credentials `json:"credentials"`
+}
+
+// SetLinkCredentials stores credentials which are linked to the identity once the login succeeded.
+func (f *Flow) SetLinkCredentials(lc *LinkCredentials) (err error) {
+	f.EnsureInternalContext()
+	f.InternalContext, err = sjson.SetBytes(f.InternalContext, InternalContextKeyLinkCredentials, lc)
+	return errors.WithStack(err)
+}
+
+// GetLinkCredentials returns the credentials to link or nil if the flow does not link any credentials.
+func (f *Flow) GetLinkCredentials() (*LinkCredentials, error) {
+	raw := gjson.GetBytes(f.InternalContext, InternalContextKeyLinkCredentials)
+	if !raw.IsObject() {
+		return nil, nil
+	}
+
+	var lc LinkCredentials
+	if err := json.Unmarshal([]byte(raw.Raw), &lc); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &lc, nil
+}
+
+// linkCredentials adds the credentials stored in the flow to the identity which just signed in.
+//
+// The credentials are only linked once the session satisfies the required authenticator assurance
+// level. Otherwise signing in with the first factor alone would suffice to add a new way to sign in.
+// In that case the credentials are kept in a continuity container and linked once the second factor
+// was provided in the follow-up login flow.
+func (e *HookExecutor) linkCredentials(w http.ResponseWriter, r *http.Request, f *Flow, s *session.Session, i *identity.Identity) error {
+	lc, err := f.GetLinkCredentials()
+	if err != nil {
+		return err
+	}
+
+	if lc != nil {
+		f.InternalContext, err = sjson.DeleteBytes(f.InternalContext, InternalContextKeyLinkCredentials)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if _, required := e.requiresAAL2(r, s, f); required {
+		if lc == nil || lc.IdentityID != i.ID {
+			return nil
+		}
+
+		return e.d.ContinuityManager().Pause(r.Context(), w, r, continuityKeyLinkCredentials,
+			continuity.WithIdentity(i),
+			continuity.WithPayload(lc),
+			continuity.WithLifespan(e.d.Config().SelfServiceFlowLoginRequestLifespan(r.Context())))
+	}
+
+	if lc == nil && f.Type == flow.TypeBrowser {
+		var paused LinkCredentials
+		if _, err := e.d.ContinuityManager().Continue(r.Context(), w, r, continuityKeyLinkCredentials,
+			continuity.WithIdentity(i),
+			continuity.WithPayload(&paused)); err == nil {
+			lc = &paused
+		}
+	}
+
+	if lc == nil {
+		return nil
+	}
+
+	if lc.IdentityID != i.ID {
+		// The user signed in to another account than the one which has the same identifier.
+		e.d.Logger().
+			WithRequest(r).
+			WithField("identity_id", i.ID).
+			WithField("link_identity_id", lc.IdentityID).
+			Debug("Not linking credentials because the user signed in to a different identity.")
+		return nil
+	}
+
+	// The identity passed to the hook might not contain its credentials.
+	ii, err := e.d.PrivilegedIdentityPool().GetIdentityConfidential(r.Context(), i.ID)
+	if err != nil {
+		return err
+	}
+
+	if lc.Credentials.Type != identity.CredentialsTypeOIDC {
+		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Linking credentials of type %s is not supported.", lc.Credentials.Type))
+	}
+
+	if err := ii.MergeCredentialsOIDC(lc.Credentials); err != nil {
+		return err
+	}
+
+	if err := e.d.IdentityManager().Update(r.Context(), ii, identity.ManagerAllowWriteProtectedTraits); err != nil {
+		return err
+	}
+
+	e.d.Audit().
+		WithRequest(r).
+		WithField("identity_id", i.ID).
+		WithField("credentials_type", lc.Credentials.Type).
+		Info("Linked credentials to the identity after it signed in.")
+	return nil
+}
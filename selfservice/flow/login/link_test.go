@@ -0,0 +1,141 @@
+package login_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/x/sqlxx"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/x"
+)
+
+func TestLinkCredentials(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/password.schema.json")
+
+	newIdentity := func(t *testing.T) *identity.Identity {
+		id := x.NewUUID().String()
+		i := &identity.Identity{
+			Credentials: map[identity.CredentialsType]identity.Credentials{
+				identity.CredentialsTypePassword: {
+					Type:        identity.CredentialsTypePassword,
+					Identifiers: []string{id},
+					Config:      sqlxx.JSONRawMessage(`{"hashed_password":"$2a$08$.cOYmAd.vCpDOoiVJrO5B.hjTLKQQ6cAK40u8uB.FnZDyPvVvQ9Q."}`),
+				}},
+			State:  identity.StateActive,
+			Traits: identity.Traits(`{"username":"` + id + `"}`),
+		}
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+		return i
+	}
+
+	signIn := func(t *testing.T, i *identity.Identity, lc *login.LinkCredentials) {
+		r := httptest.NewRequest("POST", "/self-service/login", nil)
+		f, err := login.NewFlow(conf, time.Minute, "", r, flow.TypeAPI)
+		require.NoError(t, err)
+		f.Active = identity.CredentialsTypePassword
+		require.NoError(t, f.SetLinkCredentials(lc))
+
+		s := session.NewInactiveSession()
+		s.CompletedLoginFor(identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
+
+		w := httptest.NewRecorder()
+		require.NoError(t, reg.LoginHookExecutor().PostLoginHook(w, r, identity.CredentialsTypePassword.ToUiNodeGroup(), f, i.CopyWithoutCredentials(), s))
+		require.Equal(t, http.StatusOK, w.Code, "%s", w.Body.String())
+	}
+
+	t.Run("case=links the credentials after signing in", func(t *testing.T) {
+		i := newIdentity(t)
+		creds, err := identity.NewCredentialsOIDC("", "", "", "google", i.ID.String())
+		require.NoError(t, err)
+
+		signIn(t, i, &login.LinkCredentials{IdentityID: i.ID, Credentials: *creds})
+
+		actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+		require.NoError(t, err)
+		assert.Contains(t, actual.Credentials, identity.CredentialsTypePassword)
+		require.Contains(t, actual.Credentials, identity.CredentialsTypeOIDC)
+		assert.Equal(t, []string{"google:" + i.ID.String()}, actual.Credentials[identity.CredentialsTypeOIDC].Identifiers)
+	})
+
+	t.Run("case=does not link the credentials to another identity", func(t *testing.T) {
+		i := newIdentity(t)
+		creds, err := identity.NewCredentialsOIDC("", "", "", "google", i.ID.String())
+		require.NoError(t, err)
+
+		signIn(t, i, &login.LinkCredentials{IdentityID: x.NewUUID(), Credentials: *creds})
+
+		actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+		require.NoError(t, err)
+		assert.NotContains(t, actual.Credentials, identity.CredentialsTypeOIDC)
+	})
+
+	t.Run("case=links the credentials only once the second factor was provided", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySessionWhoAmIAAL, "highest_available")
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySessionWhoAmIAAL, "aal1")
+		})
+
+		i := newIdentity(t)
+		i.Credentials[identity.CredentialsTypeWebAuthn] = identity.Credentials{
+			Type:        identity.CredentialsTypeWebAuthn,
+			Identifiers: []string{x.NewUUID().String()},
+			Config:      sqlxx.JSONRawMessage(`{"credentials":[{"is_passwordless":false}]}`),
+		}
+		require.NoError(t, reg.PrivilegedIdentityPool().UpdateIdentity(ctx, i))
+
+		creds, err := identity.NewCredentialsOIDC("", "", "", "google", i.ID.String())
+		require.NoError(t, err)
+
+		browserSignIn := func(t *testing.T, lc *login.LinkCredentials, cookies []*http.Cookie, methods ...identity.CredentialsType) *httptest.ResponseRecorder {
+			r := httptest.NewRequest("POST", "/self-service/login", nil)
+			for _, c := range cookies {
+				r.AddCookie(c)
+			}
+			f, err := login.NewFlow(conf, time.Minute, "", r, flow.TypeBrowser)
+			require.NoError(t, err)
+			f.Active = methods[len(methods)-1]
+			if lc != nil {
+				require.NoError(t, f.SetLinkCredentials(lc))
+			}
+
+			s := session.NewInactiveSession()
+			s.CompletedLoginFor(identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
+			for _, m := range methods[1:] {
+				s.CompletedLoginFor(m, identity.AuthenticatorAssuranceLevel2)
+			}
+
+			w := httptest.NewRecorder()
+			require.NoError(t, reg.LoginHookExecutor().PostLoginHook(w, r, f.Active.ToUiNodeGroup(), f, i.CopyWithoutCredentials(), s))
+			return w
+		}
+
+		w := browserSignIn(t, &login.LinkCredentials{IdentityID: i.ID, Credentials: *creds}, nil, identity.CredentialsTypePassword)
+		assert.Contains(t, w.Header().Get("Location"), "aal=aal2")
+
+		actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+		require.NoError(t, err)
+		assert.NotContains(t, actual.Credentials, identity.CredentialsTypeOIDC)
+
+		browserSignIn(t, nil, w.Result().Cookies(), identity.CredentialsTypePassword, identity.CredentialsTypeWebAuthn)
+
+		actual, err = reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+		require.NoError(t, err)
+		require.Contains(t, actual.Credentials, identity.CredentialsTypeOIDC)
+		assert.Equal(t, []string{"google:" + i.ID.String()}, actual.Credentials[identity.CredentialsTypeOIDC].Identifiers)
+	})
+}
@@ -0,0 +1,11 @@
+package login
+
+import (
+	_ "embed"
+)
+
+//go:embed .schema/identifier_first.schema.json
+var identifierFirstSchema []byte
+
+//go:embed .schema/trust_device.schema.json
+var trustDeviceSchema []byte
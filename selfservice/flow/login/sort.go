@@ -11,6 +11,7 @@ func sortNodes(ctx context.Context, n node.Nodes) error {
 		node.SortByGroups([]node.UiNodeGroup{
 			node.OpenIDConnectGroup,
 			node.DefaultGroup,
+			node.IdentifierFirstGroup,
 			node.WebAuthnGroup,
 			node.PasswordGroup,
 			node.TOTPGroup,
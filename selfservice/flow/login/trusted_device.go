@@ -0,0 +1,48 @@
+package login
+
+import (
+	"net/http"
+
+	"github.com/ory/x/decoderx"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/ui/node"
+)
+
+// usesTrustedDevices returns true if the flow completes the second factor in a browser and the
+// identity may choose to remember the device.
+func (h *Handler) usesTrustedDevices(r *http.Request, f *Flow) bool {
+	return f.Type == flow.TypeBrowser && f.RequestedAAL == identity.AuthenticatorAssuranceLevel2 &&
+		h.d.Config().SelfServiceStrategy(r.Context(), session.TrustedDeviceMethod).Enabled
+}
+
+func (h *Handler) populateTrustedDevice(r *http.Request, f *Flow) {
+	f.UI.SetNode(node.NewInputField(node.TrustDevice, false, node.DefaultGroup, node.InputAttributeTypeCheckbox).
+		WithMetaLabel(text.NewInfoLoginTrustDevice(h.d.Config().TrustedDeviceLifespan(r.Context()))))
+}
+
+// trustDeviceRequested returns true if the identity chose to remember the device. The request body
+// is kept so that the login strategies can decode it afterwards.
+func (h *Handler) trustDeviceRequested(r *http.Request, f *Flow) bool {
+	if !h.usesTrustedDevices(r, f) {
+		return false
+	}
+
+	var p struct {
+		TrustDevice bool `json:"trust_device"`
+	}
+
+	if err := h.hd.Decode(r, &p,
+		decoderx.MustHTTPRawJSONSchemaCompiler(trustDeviceSchema),
+		decoderx.HTTPKeepRequestBody(true),
+		decoderx.HTTPDecoderSetValidatePayloads(false),
+		decoderx.HTTPDecoderSetIgnoreParseErrorsStrategy(decoderx.ParseErrorUseEmptyValueOnConversionErrors),
+		decoderx.HTTPDecoderJSONFollowsFormFormat()); err != nil {
+		return false
+	}
+
+	return p.TrustDevice
+}
@@ -1,6 +1,7 @@
 package logout
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 
@@ -15,6 +16,7 @@ import (
 
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/selfservice/errorx"
+	"github.com/ory/kratos/selfservice/flow/login"
 	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/x"
 )
@@ -33,6 +35,7 @@ type (
 		session.PersistenceProvider
 		errorx.ManagementProvider
 		config.Provider
+		login.StrategyProvider
 	}
 	HandlerProvider interface {
 		LogoutHandler() *Handler
@@ -41,6 +44,15 @@ type (
 		d  handlerDependencies
 		dx *decoderx.HTTP
 	}
+
+	// UpstreamLogoutStrategy is implemented by login strategies which can end the session at an upstream
+	// identity provider as well, for example using OpenID Connect RP-initiated logout.
+	UpstreamLogoutStrategy interface {
+		// UpstreamLogoutURL returns the URL the browser is redirected to in order to log out at the upstream
+		// identity provider, which in turn redirects to returnTo. It returns nil if the session was not
+		// established at an upstream identity provider supporting logout.
+		UpstreamLogoutURL(ctx context.Context, s *session.Session, returnTo *url.URL) (*url.URL, error)
+	}
 )
 
 func NewHandler(d handlerDependencies) *Handler {
@@ -78,6 +90,16 @@ type selfServiceLogoutUrl struct {
 	LogoutToken string `json:"logout_token"`
 }
 
+// swagger:model selfServiceUpstreamLogout
+type selfServiceUpstreamLogout struct {
+	// UpstreamLogoutURL must be opened in the browser to end the session at the upstream identity provider
+	// the user signed in with. The provider redirects the browser to the logout return URL afterwards.
+	//
+	// format: uri
+	// required: true
+	UpstreamLogoutURL string `json:"upstream_logout_url"`
+}
+
 // swagger:parameters createSelfServiceLogoutFlowUrlForBrowsers
 // nolint:deadcode,unused
 type createSelfServiceLogoutFlowUrlForBrowsers struct {
@@ -219,10 +241,14 @@ type submitSelfServiceLogoutFlow struct {
 // This endpoint logs out an identity in a self-service manner.
 //
 // If the `Accept` HTTP header is not set to `application/json`, the browser will be redirected (HTTP 303 See Other)
-// to the `return_to` parameter of the initial request or fall back to `urls.default_return_to`.
+// to the `return_to` parameter of the initial request or fall back to `urls.default_return_to`. If the user signed
+// in with an OpenID Connect provider which has an `end_session_endpoint` configured, the browser is redirected
+// through the provider's logout first.
 //
 // If the `Accept` HTTP header is set to `application/json`, a 204 No Content response
-// will be sent on successful logout instead.
+// will be sent on successful logout instead. If the session has to be ended at the upstream OpenID Connect
+// provider as well, a 200 OK response containing the `upstream_logout_url` is sent, which the client must open
+// in the browser.
 //
 // This endpoint is NOT INTENDED for API clients and only works
 // with browsers (Chrome, Firefox, ...). For API clients you can
@@ -236,6 +262,7 @@ type submitSelfServiceLogoutFlow struct {
 //	Schemes: http, https
 //
 //	Responses:
+//	  200: selfServiceUpstreamLogout
 //	  303: emptyResponse
 //	  204: emptyResponse
 //	  500: jsonError
@@ -265,10 +292,10 @@ func (h *Handler) submitLogout(w http.ResponseWriter, r *http.Request, ps httpro
 		return
 	}
 
-	h.completeLogout(w, r)
+	h.completeLogout(w, r, sess)
 }
 
-func (h *Handler) completeLogout(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) completeLogout(w http.ResponseWriter, r *http.Request, sess *session.Session) {
 	_ = h.d.CSRFHandler().RegenerateToken(w, r)
 
 	ret, err := x.SecureRedirectTo(r, h.d.Config().SelfServiceFlowLogoutRedirectURL(r.Context()),
@@ -281,10 +308,41 @@ func (h *Handler) completeLogout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	upstream, err := h.upstreamLogoutURL(r.Context(), sess, ret)
+	if err != nil {
+		h.d.SelfServiceErrorManager().Forward(r.Context(), w, r, err)
+		return
+	}
+
 	if x.IsJSONRequest(r) {
+		if upstream != nil {
+			h.d.Writer().Write(w, r, &selfServiceUpstreamLogout{UpstreamLogoutURL: upstream.String()})
+			return
+		}
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
+	if upstream != nil {
+		ret = upstream
+	}
+
 	http.Redirect(w, r, ret.String(), http.StatusSeeOther)
 }
+
+func (h *Handler) upstreamLogoutURL(ctx context.Context, sess *session.Session, returnTo *url.URL) (*url.URL, error) {
+	for _, s := range h.d.AllLoginStrategies() {
+		us, ok := s.(UpstreamLogoutStrategy)
+		if !ok {
+			continue
+		}
+
+		if u, err := us.UpstreamLogoutURL(ctx, sess, returnTo); err != nil {
+			return nil, err
+		} else if u != nil {
+			return u, nil
+		}
+	}
+
+	return nil, nil
+}
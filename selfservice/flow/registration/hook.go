@@ -85,7 +85,26 @@ func NewHookExecutor(d executorDependencies) *HookExecutor {
 	return &HookExecutor{d: d}
 }
 
-func (e *HookExecutor) PostRegistrationHook(w http.ResponseWriter, r *http.Request, ct identity.CredentialsType, a *Flow, i *identity.Identity) error {
+type PostRegistrationHookOption func(o *postRegistrationHookOptions)
+
+type postRegistrationHookOptions struct {
+	provider string
+}
+
+// WithAuthenticationMethodProvider records the provider the identity registered at, for example the ID of the
+// OpenID Connect provider, in the authentication methods of the session.
+func WithAuthenticationMethodProvider(provider string) PostRegistrationHookOption {
+	return func(o *postRegistrationHookOptions) {
+		o.provider = provider
+	}
+}
+
+func (e *HookExecutor) PostRegistrationHook(w http.ResponseWriter, r *http.Request, ct identity.CredentialsType, a *Flow, i *identity.Identity, opts ...PostRegistrationHookOption) error {
+	hookOptions := new(postRegistrationHookOptions)
+	for _, f := range opts {
+		f(hookOptions)
+	}
+
 	e.d.Logger().
 		WithRequest(r).
 		WithField("identity_id", i.ID).
@@ -151,8 +170,23 @@ func (e *HookExecutor) PostRegistrationHook(w http.ResponseWriter, r *http.Reque
 		WithField("identity_id", i.ID).
 		Info("A new identity has registered using self-service registration.")
 
-	s, err := session.NewActiveSession(r.Context(), i, e.d.Config(), time.Now().UTC(), ct, identity.AuthenticatorAssuranceLevel1)
-	if err != nil {
+	// Identities which are not active yet, for example because they await approval, get a session which is never
+	// activated. It only carries the identity to the post persist hooks, which must not issue it.
+	s := session.NewInactiveSession()
+	if i.IsActive() {
+		if s, err = session.NewActiveSession(r.Context(), i, e.d.Config(), time.Now().UTC(), ct, identity.AuthenticatorAssuranceLevel1); err != nil {
+			return err
+		}
+	} else {
+		s.CompletedLoginFor(ct, identity.AuthenticatorAssuranceLevel1)
+		s.Identity, s.IdentityID = i, i.ID
+	}
+
+	if len(hookOptions.provider) > 0 {
+		s.AMR[len(s.AMR)-1].Provider = hookOptions.provider
+	}
+
+	if s.MFAEnrollmentRequired, err = e.d.SessionManager().RequiresMFAEnrollment(r.Context(), i); err != nil {
 		return err
 	}
 
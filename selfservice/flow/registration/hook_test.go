@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gobuffalo/httptest"
+	"github.com/gofrs/uuid"
 	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -147,6 +148,26 @@ func TestRegistrationExecutor(t *testing.T) {
 					assert.NotEmpty(t, gjson.Get(body, "identity.id"))
 				})
 
+				t.Run("case=do not sign in identities which await approval", func(t *testing.T) {
+					t.Cleanup(testhelpers.SelfServiceHookConfigReset(t, conf))
+					viperSetPost(t, conf, strategy, []config.SelfServiceHook{{Name: "require_approval"}, {Name: "session"}})
+					i := testhelpers.SelfServiceHookFakeIdentity(t)
+
+					res, body := makeRequestPost(t, newServer(t, i, flow.TypeAPI), true, url.Values{})
+					assert.EqualValues(t, http.StatusOK, res.StatusCode)
+					assert.Equal(t, i.ID.String(), gjson.Get(body, "identity.id").String(), body)
+					assert.Equal(t, string(identity.StatePending), gjson.Get(body, "identity.state").String(), body)
+					assert.False(t, gjson.Get(body, "session_token").Exists(), body)
+
+					actual, err := reg.IdentityPool().GetIdentity(ctx, i.ID)
+					require.NoError(t, err)
+					assert.Equal(t, identity.StatePending, actual.State)
+
+					sessions, err := reg.SessionPersister().ListSessionsByIdentity(ctx, i.ID, nil, 1, 10, uuid.Nil)
+					require.NoError(t, err)
+					assert.Len(t, sessions, 0)
+				})
+
 				t.Run("case=pass without hooks for browser flow with application/json", func(t *testing.T) {
 					t.Cleanup(testhelpers.SelfServiceHookConfigReset(t, conf))
 
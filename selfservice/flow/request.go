@@ -76,13 +76,23 @@ var dec = decoderx.NewHTTP()
 func MethodEnabledAndAllowedFromRequest(r *http.Request, expected string, d interface {
 	config.Provider
 }) error {
+	method, err := MethodFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	return MethodEnabledAndAllowed(r.Context(), expected, method, d)
+}
+
+// MethodFromRequest returns the `method` field of the request payload without consuming the request body.
+func MethodFromRequest(r *http.Request) (string, error) {
 	var method struct {
 		Method string `json:"method" form:"method"`
 	}
 
 	compiler, err := decoderx.HTTPRawJSONSchemaCompiler(methodSchema)
 	if err != nil {
-		return errors.WithStack(err)
+		return "", errors.WithStack(err)
 	}
 
 	if err := dec.Decode(r, &method, compiler,
@@ -90,10 +100,10 @@ func MethodEnabledAndAllowedFromRequest(r *http.Request, expected string, d inte
 		decoderx.HTTPDecoderAllowedMethods("POST", "PUT", "PATCH", "GET"),
 		decoderx.HTTPDecoderSetValidatePayloads(false),
 		decoderx.HTTPDecoderJSONFollowsFormFormat()); err != nil {
-		return errors.WithStack(err)
+		return "", errors.WithStack(err)
 	}
 
-	return MethodEnabledAndAllowed(r.Context(), expected, method.Method, d)
+	return method.Method, nil
 }
 
 func MethodEnabledAndAllowed(ctx context.Context, expected, actual string, d interface {
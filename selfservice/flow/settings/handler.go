@@ -10,6 +10,7 @@ import (
 	"github.com/ory/herodot"
 	"github.com/ory/nosurf"
 	"github.com/ory/x/sqlcon"
+	"github.com/ory/x/stringslice"
 	"github.com/ory/x/urlx"
 
 	"github.com/ory/kratos/continuity"
@@ -38,6 +39,14 @@ func ContinuityKey(id string) string {
 	return ContinuityPrefix + "_" + id
 }
 
+// mfaEnrollmentStrategies lists the settings strategies which remain available while the identity
+// must set up a second factor.
+var mfaEnrollmentStrategies = []string{
+	string(identity.CredentialsTypeTOTP),
+	string(identity.CredentialsTypeWebAuthn),
+	string(identity.CredentialsTypeLookup),
+}
+
 type (
 	handlerDependencies interface {
 		x.CSRFProvider
@@ -116,16 +125,33 @@ func (h *Handler) NewFlow(w http.ResponseWriter, r *http.Request, i *identity.Id
 		return nil, err
 	}
 
+	enrollMFA, err := h.d.SessionManager().RequiresMFAEnrollment(r.Context(), i)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, strategy := range h.d.SettingsStrategies(r.Context()) {
 		if err := h.d.ContinuityManager().Abort(r.Context(), w, r, ContinuityKey(strategy.SettingsStrategyID())); err != nil {
 			return nil, err
 		}
 
+		if enrollMFA && !stringslice.Has(mfaEnrollmentStrategies, strategy.SettingsStrategyID()) {
+			continue
+		}
+
 		if err := strategy.PopulateSettingsMethod(r, i, f); err != nil {
 			return nil, err
 		}
 	}
 
+	if enrollMFA {
+		f.UI.Messages.Add(text.NewInfoSelfServiceSettingsMFAEnrollmentRequired())
+	}
+
+	if i.CredentialsResetRequired != identity.CredentialsResetNone {
+		f.UI.Messages.Add(text.NewInfoSelfServiceSettingsCredentialsResetRequired(string(i.CredentialsResetRequired)))
+	}
+
 	ds, err := h.d.Config().DefaultIdentityTraitsSchemaURL(r.Context())
 	if err != nil {
 		return nil, err
@@ -544,9 +570,20 @@ func (h *Handler) submitSettingsFlow(w http.ResponseWriter, r *http.Request, ps
 		return
 	}
 
+	enrollMFA, err := h.d.SessionManager().RequiresMFAEnrollment(r.Context(), ss.Identity)
+	if err != nil {
+		h.d.SettingsFlowErrorHandler().WriteFlowError(w, r, node.DefaultGroup, f, ss.Identity, err)
+		return
+	}
+
 	var s string
 	var updateContext *UpdateContext
 	for _, strat := range h.d.AllSettingsStrategies() {
+		// Only the second factor can be set up until the identity has enrolled one.
+		if enrollMFA && !stringslice.Has(mfaEnrollmentStrategies, strat.SettingsStrategyID()) {
+			continue
+		}
+
 		uc, err := strat.Settings(w, r, f, ss)
 		if errors.Is(err, flow.ErrStrategyNotResponsible) {
 			continue
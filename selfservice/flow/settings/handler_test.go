@@ -204,6 +204,29 @@ func TestHandler(t *testing.T) {
 		})
 	})
 
+	t.Run("case=only offers second factors if the identity must set up one", func(t *testing.T) {
+		testhelpers.StrategyEnable(t, conf, identity.CredentialsTypeTOTP.String(), true)
+		conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentEnabled, true)
+		t.Cleanup(func() {
+			testhelpers.StrategyEnable(t, conf, identity.CredentialsTypeTOTP.String(), false)
+			conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentEnabled, false)
+		})
+
+		user1 := testhelpers.NewHTTPClientWithArbitrarySessionToken(t, reg)
+		res, body := initFlow(t, user1, true)
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+		assert.EqualValues(t, text.InfoSelfServiceSettingsMFAEnrollmentRequired, gjson.GetBytes(body, "ui.messages.0.id").Int(), "%s", body)
+		assert.NotEmpty(t, gjson.GetBytes(body, `ui.nodes.#(group=="totp")`).Raw, "%s", body)
+		assert.Empty(t, gjson.GetBytes(body, `ui.nodes.#(group=="password")`).Raw, "%s", body)
+		assert.Empty(t, gjson.GetBytes(body, `ui.nodes.#(group=="profile")`).Raw, "%s", body)
+
+		var f kratos.SelfServiceSettingsFlow
+		require.NoError(t, json.Unmarshal(body, &f))
+		actual, res := testhelpers.SettingsMakeRequest(t, true, false, &f, user1, `{"method":"password","password":"`+x.NewUUID().String()+`"}`)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", actual)
+		assert.EqualValues(t, text.ErrorValidationSettingsNoStrategyFound, gjson.Get(actual, "ui.messages.0.id").Int(), "%s", actual)
+	})
+
 	t.Run("endpoint=fetch", func(t *testing.T) {
 		t.Run("description=fetching a non-existent flow should return a 404 error", func(t *testing.T) {
 			_, _, err := testhelpers.NewSDKCustomClient(publicTS, otherUser).V0alpha2Api.GetSelfServiceSettingsFlow(context.Background()).Id("i-do-not-exist").Execute()
@@ -20,6 +20,7 @@ import (
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/x"
 )
 
@@ -39,12 +40,27 @@ type (
 	}
 	PostHookPostPersistExecutorFunc func(w http.ResponseWriter, r *http.Request, a *Flow, s *identity.Identity) error
 
+	AccountDeletionPreHookExecutor interface {
+		ExecuteSettingsAccountDeletionPreHook(w http.ResponseWriter, r *http.Request, a *Flow, i *identity.Identity) error
+	}
+	AccountDeletionPreHookExecutorFunc func(w http.ResponseWriter, r *http.Request, a *Flow, i *identity.Identity) error
+
+	AccountDeletionPostHookExecutor interface {
+		ExecuteSettingsAccountDeletionPostHook(w http.ResponseWriter, r *http.Request, a *Flow, i *identity.Identity) error
+	}
+	AccountDeletionPostHookExecutorFunc func(w http.ResponseWriter, r *http.Request, a *Flow, i *identity.Identity) error
+
 	HooksProvider interface {
 		PreSettingsHooks(ctx context.Context) []PreHookExecutor
 		PostSettingsPrePersistHooks(ctx context.Context, settingsType string) []PostHookPrePersistExecutor
 		PostSettingsPostPersistHooks(ctx context.Context, settingsType string) []PostHookPostPersistExecutor
 	}
 
+	AccountDeletionHooksProvider interface {
+		PreAccountDeletionHooks(ctx context.Context) []AccountDeletionPreHookExecutor
+		PostAccountDeletionHooks(ctx context.Context) []AccountDeletionPostHookExecutor
+	}
+
 	executorDependencies interface {
 		identity.ManagementProvider
 		identity.ValidationProvider
@@ -53,6 +69,8 @@ type (
 		HandlerProvider
 		HooksProvider
 		FlowPersistenceProvider
+		session.PersistenceProvider
+		session.ManagementProvider
 
 		x.CSRFTokenGeneratorProvider
 		x.LoggingProvider
@@ -78,6 +96,14 @@ func (f PostHookPostPersistExecutorFunc) ExecuteSettingsPostPersistHook(w http.R
 	return f(w, r, a, s)
 }
 
+func (f AccountDeletionPreHookExecutorFunc) ExecuteSettingsAccountDeletionPreHook(w http.ResponseWriter, r *http.Request, a *Flow, i *identity.Identity) error {
+	return f(w, r, a, i)
+}
+
+func (f AccountDeletionPostHookExecutorFunc) ExecuteSettingsAccountDeletionPostHook(w http.ResponseWriter, r *http.Request, a *Flow, i *identity.Identity) error {
+	return f(w, r, a, i)
+}
+
 func PostHookPostPersistExecutorNames(e []PostHookPostPersistExecutor) []string {
 	names := make([]string, len(e))
 	for k, ee := range e {
@@ -199,6 +225,14 @@ func (e *HookExecutor) PostSettingsHook(w http.ResponseWriter, r *http.Request,
 		e.d.Logger().WithRequest(r).WithFields(logFields).Debug("ExecuteSettingsPrePersistHook completed successfully.")
 	}
 
+	// Adding or rotating credentials of the requested type resolves an administrator's request to reset the
+	// credentials. Removing credentials, for example one of several WebAuthn keys, does not count as a reset.
+	if ct := identity.CredentialsType(settingsType); ctxUpdate.credentialsAddedOrRotated && i.CredentialsResetRequired.IsSatisfiedBy(ct) {
+		if _, ok := i.GetCredentials(ct); ok {
+			i.CredentialsResetRequired = identity.CredentialsResetNone
+		}
+	}
+
 	options := []identity.ManagerOption{identity.ManagerExposeValidationErrorsForInternalTypeAssertion}
 	ttl := e.d.Config().SelfServiceFlowSettingsPrivilegedSessionMaxAge(r.Context())
 	if ctxUpdate.Session.AuthenticatedAt.Add(ttl).After(time.Now()) {
@@ -220,6 +254,14 @@ func (e *HookExecutor) PostSettingsHook(w http.ResponseWriter, r *http.Request,
 		WithField("identity_id", i.ID).
 		Debug("An identity's settings have been updated.")
 
+	// Trusted devices skip the second factor, so they must no longer be trusted once the credentials change.
+	switch identity.CredentialsType(settingsType) {
+	case identity.CredentialsTypePassword, identity.CredentialsTypeTOTP, identity.CredentialsTypeWebAuthn, identity.CredentialsTypeLookup:
+		if err := e.d.SessionPersister().DeleteTrustedDevicesByIdentity(r.Context(), i.ID); err != nil {
+			return err
+		}
+	}
+
 	ctxUpdate.UpdateIdentity(i)
 	ctxUpdate.Flow.State = StateSuccess
 	if hookOptions.cb != nil {
@@ -235,7 +277,11 @@ func (e *HookExecutor) PostSettingsHook(w http.ResponseWriter, r *http.Request,
 
 	ctxUpdate.Flow.UI = newFlow.UI
 	ctxUpdate.Flow.UI.ResetMessages()
-	ctxUpdate.Flow.UI.AddMessage(node.DefaultGroup, text.NewInfoSelfServiceSettingsUpdateSuccess())
+	if ctxUpdate.message != nil {
+		ctxUpdate.Flow.UI.AddMessage(node.DefaultGroup, ctxUpdate.message)
+	} else {
+		ctxUpdate.Flow.UI.AddMessage(node.DefaultGroup, text.NewInfoSelfServiceSettingsUpdateSuccess())
+	}
 	if err := e.d.SettingsFlowPersister().UpdateSettingsFlow(r.Context(), ctxUpdate.Flow); err != nil {
 		return err
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/tidwall/gjson"
 
 	"github.com/gobuffalo/httptest"
+	"github.com/gofrs/uuid"
 	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -43,6 +44,7 @@ func TestSettingsExecutor(t *testing.T) {
 				},
 			})
 
+			var lastIdentityID uuid.UUID
 			newServer := func(t *testing.T, ft flow.Type) *httptest.Server {
 				router := httprouter.New()
 				handleErr := testhelpers.SelfServiceHookSettingsErrorHandler
@@ -59,6 +61,8 @@ func TestSettingsExecutor(t *testing.T) {
 
 				router.GET("/settings/post", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 					i := testhelpers.SelfServiceHookCreateFakeIdentity(t, reg)
+					require.NoError(t, reg.SessionPersister().CreateTrustedDevice(r.Context(), session.NewTrustedDevice(i.ID, "Mozilla/5.0", time.Hour)))
+					lastIdentityID = i.ID
 					sess, _ := session.NewActiveSession(ctx, i, conf, time.Now().UTC(), identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
 
 					a, err := settings.NewFlow(conf, time.Minute, r, sess.Identity, ft)
@@ -156,6 +160,21 @@ func TestSettingsExecutor(t *testing.T) {
 					assert.NotEmpty(t, gjson.Get(body, "identity.id"))
 				})
 
+				t.Run("case=revoke trusted devices if credentials change", func(t *testing.T) {
+					t.Cleanup(testhelpers.SelfServiceHookConfigReset(t, conf))
+
+					res, _ := makeRequestPost(t, newServer(t, flow.TypeBrowser), true, url.Values{})
+					assert.EqualValues(t, http.StatusOK, res.StatusCode)
+
+					devices, err := reg.SessionPersister().ListTrustedDevicesByIdentity(ctx, lastIdentityID)
+					require.NoError(t, err)
+					if strategy == settings.StrategyProfile {
+						assert.Len(t, devices, 1)
+					} else {
+						assert.Len(t, devices, 0)
+					}
+				})
+
 				t.Run("case=pass without hooks for browser flow with application/json", func(t *testing.T) {
 					t.Cleanup(testhelpers.SelfServiceHookConfigReset(t, conf))
 
@@ -17,6 +17,8 @@ func sortNodes(ctx context.Context, n node.Nodes, schemaRef string) error {
 			node.LookupGroup,
 			node.WebAuthnGroup,
 			node.TOTPGroup,
+			node.TrustedDeviceGroup,
+			node.AccountDeletionGroup,
 		}),
 		node.SortUseOrderAppend([]string{
 			// Lookup
@@ -16,6 +16,7 @@ import (
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/text"
 	"github.com/ory/kratos/x"
 )
 
@@ -32,12 +33,26 @@ type UpdateContext struct {
 	Session  *session.Session
 	Flow     *Flow
 	toUpdate *identity.Identity
+	message  *text.Message
+
+	credentialsAddedOrRotated bool
 }
 
 func (c *UpdateContext) UpdateIdentity(i *identity.Identity) {
 	c.toUpdate = i
 }
 
+// SetSuccessMessage replaces the message which is shown once the identity has been updated.
+func (c *UpdateContext) SetSuccessMessage(m *text.Message) {
+	c.message = m
+}
+
+// MarkCredentialsAddedOrRotated records that the update adds new credentials or replaces existing ones. Only
+// such updates resolve an administrator's request to reset the credentials.
+func (c *UpdateContext) MarkCredentialsAddedOrRotated() {
+	c.credentialsAddedOrRotated = true
+}
+
 func (c *UpdateContext) GetIdentityToUpdate() (*identity.Identity, error) {
 	if c.toUpdate == nil {
 		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Could not find a identity to update."))
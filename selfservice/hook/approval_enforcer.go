@@ -0,0 +1,80 @@
+package hook
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/x/sqlxx"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/registration"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/x"
+)
+
+var (
+	_ registration.PostHookPrePersistExecutor  = new(ApprovalEnforcer)
+	_ registration.PostHookPostPersistExecutor = new(ApprovalEnforcer)
+)
+
+type (
+	approvalEnforcerDependencies interface {
+		config.Provider
+		registration.FlowPersistenceProvider
+		x.LoggingProvider
+		x.WriterProvider
+	}
+	ApprovalEnforcer struct {
+		r approvalEnforcerDependencies
+	}
+)
+
+func NewApprovalEnforcer(r approvalEnforcerDependencies) *ApprovalEnforcer {
+	return &ApprovalEnforcer{r: r}
+}
+
+// ExecutePostRegistrationPrePersistHook puts the newly registered identity into the `pending` state
+// until an administrator approves it using the admin API. The state is set before the identity is
+// persisted, so that the identity is never active.
+func (e *ApprovalEnforcer) ExecutePostRegistrationPrePersistHook(_ http.ResponseWriter, _ *http.Request, _ *registration.Flow, i *identity.Identity) error {
+	stateChangedAt := sqlxx.NullTime(time.Now().UTC())
+	i.State = identity.StatePending
+	i.StateChangedAt = &stateChangedAt
+	return nil
+}
+
+// ExecutePostRegistrationPostPersistHook aborts the registration of pending identities, so that no session
+// is issued. This is why this hook must run before the session hook, which the configuration enforces.
+//
+// API and AJAX clients receive the pending identity, browsers are sent back to the registration UI
+// which explains that the account awaits approval.
+func (e *ApprovalEnforcer) ExecutePostRegistrationPostPersistHook(w http.ResponseWriter, r *http.Request, f *registration.Flow, s *session.Session) error {
+	ctx := r.Context()
+	i := s.Identity
+	if i.State != identity.StatePending {
+		return nil
+	}
+
+	e.r.Logger().
+		WithRequest(r).
+		WithField("identity_id", i.ID).
+		Info("The registered identity awaits the approval of an administrator.")
+
+	if f.Type == flow.TypeAPI || x.IsJSONRequest(r) {
+		e.r.Writer().Write(w, r, &registration.APIFlowResponse{Identity: i.CopyWithoutCredentials()})
+		return errors.WithStack(registration.ErrHookAbortFlow)
+	}
+
+	f.UI.Messages.Set(text.NewInfoSelfServiceRegistrationPendingApproval())
+	if err := e.r.RegistrationFlowPersister().UpdateRegistrationFlow(ctx, f); err != nil {
+		return err
+	}
+
+	http.Redirect(w, r, f.AppendTo(e.r.Config().SelfServiceFlowRegistrationUI(ctx)).String(), http.StatusSeeOther)
+	return errors.WithStack(registration.ErrHookAbortFlow)
+}
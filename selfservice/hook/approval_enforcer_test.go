@@ -0,0 +1,106 @@
+package hook_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/registration"
+	"github.com/ory/kratos/selfservice/hook"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/text"
+)
+
+func TestApprovalEnforcer(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationUI, "https://www.ory.sh/registration")
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/stub.schema.json")
+
+	h := hook.NewApprovalEnforcer(reg)
+
+	newSession := func(t *testing.T) *session.Session {
+		i := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+		require.NoError(t, h.ExecutePostRegistrationPrePersistHook(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil), &registration.Flow{}, i))
+		assert.Equal(t, identity.StatePending, i.State)
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+
+		// The registration hook executor does not activate sessions of pending identities.
+		_, err := session.NewActiveSession(ctx, i, conf, time.Now().UTC(), identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
+		require.ErrorIs(t, err, session.ErrIdentityDisabled)
+
+		s := session.NewInactiveSession()
+		s.Identity, s.IdentityID = i, i.ID
+		return s
+	}
+
+	expectPending := func(t *testing.T, s *session.Session) {
+		i, err := reg.IdentityPool().GetIdentity(ctx, s.Identity.ID)
+		require.NoError(t, err)
+		assert.Equal(t, identity.StatePending, i.State)
+		require.NotNil(t, i.StateChangedAt)
+
+		_, err = reg.SessionPersister().GetSession(ctx, s.ID)
+		assert.Error(t, err, "no session must be issued")
+	}
+
+	t.Run("flow=browser", func(t *testing.T) {
+		s := newSession(t)
+		r := httptest.NewRequest("POST", "/", nil)
+		f, err := registration.NewFlow(conf, time.Minute, "csrf", r, flow.TypeBrowser)
+		require.NoError(t, err)
+		require.NoError(t, reg.RegistrationFlowPersister().CreateRegistrationFlow(ctx, f))
+
+		w := httptest.NewRecorder()
+		err = h.ExecutePostRegistrationPostPersistHook(w, r, f, s)
+		require.True(t, errors.Is(err, registration.ErrHookAbortFlow), "%+v", err)
+
+		expectPending(t, s)
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "https://www.ory.sh/registration?flow="+f.ID.String(), w.Header().Get("Location"))
+		assert.Empty(t, w.Header().Get("Set-Cookie"))
+
+		stored, err := reg.RegistrationFlowPersister().GetRegistrationFlow(ctx, f.ID)
+		require.NoError(t, err)
+		require.Len(t, stored.UI.Messages, 1)
+		assert.Equal(t, text.InfoSelfServiceRegistrationPendingApproval, stored.UI.Messages[0].ID)
+	})
+
+	t.Run("flow=api", func(t *testing.T) {
+		s := newSession(t)
+		w := httptest.NewRecorder()
+		err := h.ExecutePostRegistrationPostPersistHook(w, httptest.NewRequest("POST", "/", nil), &registration.Flow{Type: flow.TypeAPI}, s)
+		require.True(t, errors.Is(err, registration.ErrHookAbortFlow), "%+v", err)
+
+		expectPending(t, s)
+		body := w.Body.Bytes()
+		assert.Equal(t, s.Identity.ID.String(), gjson.GetBytes(body, "identity.id").String())
+		assert.Equal(t, string(identity.StatePending), gjson.GetBytes(body, "identity.state").String())
+		assert.False(t, gjson.GetBytes(body, "session").Exists())
+		assert.False(t, gjson.GetBytes(body, "session_token").Exists())
+	})
+
+	t.Run("case=active identities are not aborted", func(t *testing.T) {
+		i := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+		s, err := session.NewActiveSession(ctx, i, conf, time.Now().UTC(), identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		require.NoError(t, h.ExecutePostRegistrationPostPersistHook(w, httptest.NewRequest("POST", "/", nil), &registration.Flow{Type: flow.TypeBrowser}, s))
+		assert.Empty(t, w.Body.String())
+		assert.Empty(t, w.Header().Get("Location"))
+	})
+}
@@ -0,0 +1,78 @@
+package hook
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/x/urlx"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/selfservice/flow/settings"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/kratos/x"
+)
+
+var _ login.PostHookExecutor = new(CredentialsResetEnforcer)
+
+type (
+	credentialsResetEnforcerDependencies interface {
+		config.Provider
+		session.ManagementProvider
+		session.PersistenceProvider
+		x.WriterProvider
+	}
+	CredentialsResetEnforcer struct {
+		r credentialsResetEnforcerDependencies
+	}
+)
+
+func NewCredentialsResetEnforcer(r credentialsResetEnforcerDependencies) *CredentialsResetEnforcer {
+	return &CredentialsResetEnforcer{r: r}
+}
+
+// ExecuteLoginPostHook restricts the session of identities which must reset their credentials to the
+// settings flow, regardless of the flow type. The session is issued nonetheless because the settings
+// flow requires it, but it can not be used with the whoami endpoint until the credentials have been
+// reset.
+//
+// Browsers are sent straight to the settings flow. AJAX clients receive the session cookie and API
+// clients the session token together with an error which tells them to complete the settings flow.
+func (e *CredentialsResetEnforcer) ExecuteLoginPostHook(w http.ResponseWriter, r *http.Request, _ node.UiNodeGroup, f *login.Flow, s *session.Session) error {
+	if s.Identity.CredentialsResetRequired == identity.CredentialsResetNone {
+		return nil
+	}
+
+	redirectTo := urlx.AppendPaths(e.r.Config().SelfPublicURL(r.Context()), settings.RouteInitBrowserFlow)
+	if len(f.ReturnTo) > 0 {
+		redirectTo = urlx.CopyWithQuery(redirectTo, url.Values{"return_to": {f.ReturnTo}})
+	}
+	resetErr := session.NewErrCredentialsResetRequired(redirectTo.String())
+
+	if f.Type == flow.TypeAPI {
+		if err := e.r.SessionPersister().UpsertSession(r.Context(), s); err != nil {
+			return errors.WithStack(err)
+		}
+
+		resetErr.SessionToken = s.Token
+		e.r.Writer().WriteError(w, r, resetErr)
+		return errors.WithStack(login.ErrHookAbortFlow)
+	}
+
+	if err := e.r.SessionManager().UpsertAndIssueCookie(r.Context(), w, r, s); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if x.IsJSONRequest(r) {
+		e.r.Writer().WriteError(w, r, resetErr)
+		return errors.WithStack(login.ErrHookAbortFlow)
+	}
+
+	http.Redirect(w, r, redirectTo.String(), http.StatusSeeOther)
+	return errors.WithStack(login.ErrHookAbortFlow)
+}
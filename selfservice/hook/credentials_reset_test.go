@@ -0,0 +1,99 @@
+package hook_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/selfservice/hook"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/ui/node"
+)
+
+func TestCredentialsResetEnforcer(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+
+	conf.MustSet(ctx, config.ViperKeyPublicBaseURL, "http://localhost/")
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/stub.schema.json")
+
+	h := hook.NewCredentialsResetEnforcer(reg)
+
+	newSession := func(t *testing.T, reset identity.CredentialsReset) *session.Session {
+		var i identity.Identity
+		require.NoError(t, faker.FakeData(&i))
+		i.CredentialsResetRequired = reset
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, &i))
+
+		s, err := session.NewActiveSession(ctx, &i, conf, time.Now().UTC(), identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
+		require.NoError(t, err)
+		return s
+	}
+
+	t.Run("case=does nothing if no reset is required", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/", nil)
+		f := &login.Flow{Type: flow.TypeBrowser}
+
+		require.NoError(t, h.ExecuteLoginPostHook(w, r, node.PasswordGroup, f, newSession(t, identity.CredentialsResetNone)))
+		assert.Empty(t, w.Header().Get("Location"))
+	})
+
+	t.Run("case=returns a restricted session token to api flows", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/", nil)
+		f := &login.Flow{Type: flow.TypeAPI}
+		s := newSession(t, identity.CredentialsResetPassword)
+
+		err := h.ExecuteLoginPostHook(w, r, node.PasswordGroup, f, s)
+		require.ErrorIs(t, err, login.ErrHookAbortFlow)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.EqualValues(t, text.ErrIDCredentialsResetRequired, gjson.Get(w.Body.String(), "error.id").String(), "%s", w.Body.String())
+		assert.Equal(t, s.Token, gjson.Get(w.Body.String(), "session_token").String(), "%s", w.Body.String())
+		assert.Equal(t, "http://localhost/self-service/settings/browser", gjson.Get(w.Body.String(), "redirect_browser_to").String(), "%s", w.Body.String())
+
+		stored, err := reg.SessionPersister().GetSessionByToken(ctx, s.Token)
+		require.NoError(t, err)
+		assert.Equal(t, s.ID, stored.ID)
+	})
+
+	t.Run("case=returns an error to ajax clients", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/", nil)
+		r.Header.Set("Accept", "application/json")
+		f := &login.Flow{Type: flow.TypeBrowser}
+
+		err := h.ExecuteLoginPostHook(w, r, node.PasswordGroup, f, newSession(t, identity.CredentialsResetPassword))
+		require.ErrorIs(t, err, login.ErrHookAbortFlow)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.EqualValues(t, text.ErrIDCredentialsResetRequired, gjson.Get(w.Body.String(), "error.id").String(), "%s", w.Body.String())
+		assert.Empty(t, gjson.Get(w.Body.String(), "session_token").String(), "%s", w.Body.String())
+		assert.NotEmpty(t, w.Header().Get("Set-Cookie"))
+	})
+
+	t.Run("case=redirects browsers to the settings flow", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/", nil)
+		f := &login.Flow{Type: flow.TypeBrowser, ReturnTo: "https://www.ory.sh/"}
+
+		err := h.ExecuteLoginPostHook(w, r, node.PasswordGroup, f, newSession(t, identity.CredentialsResetSecondFactor))
+		require.ErrorIs(t, err, login.ErrHookAbortFlow)
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "http://localhost/self-service/settings/browser?return_to=https%3A%2F%2Fwww.ory.sh%2F", w.Header().Get("Location"))
+		assert.NotEmpty(t, w.Header().Get("Set-Cookie"))
+	})
+}
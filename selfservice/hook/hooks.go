@@ -1,8 +1,12 @@
 package hook
 
 const (
-	KeySessionIssuer    = "session"
-	KeySessionDestroyer = "revoke_active_sessions"
-	KeyWebHook          = "web_hook"
-	KeyAddressVerifier  = "require_verified_address"
+	KeySessionIssuer            = "session"
+	KeySessionDestroyer         = "revoke_active_sessions"
+	KeyWebHook                  = "web_hook"
+	KeyAddressVerifier          = "require_verified_address"
+	KeyCredentialsResetEnforcer = "require_credentials_reset"
+	KeyMFAEnrollmentEnforcer    = "require_mfa_enrollment"
+	KeySecurityNotifier         = "security_notification"
+	KeyApprovalEnforcer         = "require_approval"
 )
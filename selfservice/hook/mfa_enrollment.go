@@ -0,0 +1,85 @@
+package hook
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/x/urlx"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/selfservice/flow/registration"
+	"github.com/ory/kratos/selfservice/flow/settings"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/kratos/x"
+)
+
+var (
+	_ login.PostHookExecutor                   = new(MFAEnrollmentEnforcer)
+	_ registration.PostHookPostPersistExecutor = new(MFAEnrollmentEnforcer)
+)
+
+type (
+	mfaEnrollmentEnforcerDependencies interface {
+		config.Provider
+		session.ManagementProvider
+	}
+	MFAEnrollmentEnforcer struct {
+		r mfaEnrollmentEnforcerDependencies
+	}
+)
+
+func NewMFAEnrollmentEnforcer(r mfaEnrollmentEnforcerDependencies) *MFAEnrollmentEnforcer {
+	return &MFAEnrollmentEnforcer{r: r}
+}
+
+// ExecuteLoginPostHook sends browsers of identities which must set up a second factor straight
+// to the settings flow. The session is issued nonetheless because the settings flow requires it,
+// but it can not be used with the whoami endpoint until a second factor was set up.
+//
+// API and AJAX clients receive their session as usual and must handle the error returned by the
+// whoami endpoint.
+func (e *MFAEnrollmentEnforcer) ExecuteLoginPostHook(w http.ResponseWriter, r *http.Request, _ node.UiNodeGroup, f *login.Flow, s *session.Session) error {
+	if f.Type != flow.TypeBrowser || x.IsJSONRequest(r) {
+		return nil
+	}
+
+	if !s.MFAEnrollmentRequired {
+		return nil
+	}
+
+	if err := e.r.SessionManager().UpsertAndIssueCookie(r.Context(), w, r, s); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return e.redirectToSettings(w, r, f.ReturnTo, login.ErrHookAbortFlow)
+}
+
+// ExecutePostRegistrationPostPersistHook sends browsers of identities which must set up a second
+// factor straight to the settings flow. It must run after the session hook, which issues the
+// session the settings flow requires.
+func (e *MFAEnrollmentEnforcer) ExecutePostRegistrationPostPersistHook(w http.ResponseWriter, r *http.Request, f *registration.Flow, s *session.Session) error {
+	if f.Type != flow.TypeBrowser || x.IsJSONRequest(r) {
+		return nil
+	}
+
+	if !s.MFAEnrollmentRequired {
+		return nil
+	}
+
+	return e.redirectToSettings(w, r, f.ReturnTo, registration.ErrHookAbortFlow)
+}
+
+func (e *MFAEnrollmentEnforcer) redirectToSettings(w http.ResponseWriter, r *http.Request, returnTo string, abort error) error {
+	redirectTo := urlx.AppendPaths(e.r.Config().SelfPublicURL(r.Context()), settings.RouteInitBrowserFlow)
+	if len(returnTo) > 0 {
+		redirectTo = urlx.CopyWithQuery(redirectTo, url.Values{"return_to": {returnTo}})
+	}
+
+	http.Redirect(w, r, redirectTo.String(), http.StatusSeeOther)
+	return errors.WithStack(abort)
+}
@@ -0,0 +1,117 @@
+package hook_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/selfservice/flow/registration"
+	"github.com/ory/kratos/selfservice/hook"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/ui/node"
+)
+
+func TestMFAEnrollmentEnforcer(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+
+	conf.MustSet(ctx, config.ViperKeyPublicBaseURL, "http://localhost/")
+	conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentEnabled, true)
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/stub.schema.json")
+
+	h := hook.NewMFAEnrollmentEnforcer(reg)
+
+	newSession := func(t *testing.T, credentials map[identity.CredentialsType]identity.Credentials) *session.Session {
+		var i identity.Identity
+		require.NoError(t, faker.FakeData(&i))
+		i.Credentials = credentials
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, &i))
+
+		s, err := session.NewActiveSession(ctx, &i, conf, time.Now().UTC(), identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
+		require.NoError(t, err)
+		s.MFAEnrollmentRequired, err = reg.SessionManager().RequiresMFAEnrollment(ctx, &i)
+		require.NoError(t, err)
+		return s
+	}
+
+	withoutMFA := func(t *testing.T) *session.Session {
+		return newSession(t, nil)
+	}
+
+	withMFA := func(t *testing.T) *session.Session {
+		return newSession(t, map[identity.CredentialsType]identity.Credentials{
+			identity.CredentialsTypeWebAuthn: {
+				Type:        identity.CredentialsTypeWebAuthn,
+				Config:      []byte(`{"credentials":[{"is_passwordless":false}]}`),
+				Identifiers: []string{testhelpers.RandomEmail()},
+			},
+		})
+	}
+
+	t.Run("case=does nothing if a second factor is set up", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/", nil)
+		f := &login.Flow{Type: flow.TypeBrowser}
+
+		require.NoError(t, h.ExecuteLoginPostHook(w, r, node.PasswordGroup, f, withMFA(t)))
+		assert.Empty(t, w.Header().Get("Location"))
+	})
+
+	t.Run("case=does nothing if the policy is disabled", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentEnabled, false)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentEnabled, true)
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/", nil)
+		f := &login.Flow{Type: flow.TypeBrowser}
+
+		require.NoError(t, h.ExecuteLoginPostHook(w, r, node.PasswordGroup, f, withoutMFA(t)))
+		assert.Empty(t, w.Header().Get("Location"))
+	})
+
+	t.Run("case=does nothing for api flows", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/", nil)
+		f := &login.Flow{Type: flow.TypeAPI}
+
+		require.NoError(t, h.ExecuteLoginPostHook(w, r, node.PasswordGroup, f, withoutMFA(t)))
+		assert.Empty(t, w.Header().Get("Location"))
+	})
+
+	t.Run("case=redirects browsers to the settings flow after login", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/", nil)
+		f := &login.Flow{Type: flow.TypeBrowser, ReturnTo: "https://www.ory.sh/"}
+
+		err := h.ExecuteLoginPostHook(w, r, node.PasswordGroup, f, withoutMFA(t))
+		require.ErrorIs(t, err, login.ErrHookAbortFlow)
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "http://localhost/self-service/settings/browser?return_to=https%3A%2F%2Fwww.ory.sh%2F", w.Header().Get("Location"))
+		assert.NotEmpty(t, w.Header().Get("Set-Cookie"))
+	})
+
+	t.Run("case=redirects browsers to the settings flow after registration", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/", nil)
+		f := &registration.Flow{Type: flow.TypeBrowser}
+
+		err := h.ExecutePostRegistrationPostPersistHook(w, r, f, withoutMFA(t))
+		require.ErrorIs(t, err, registration.ErrHookAbortFlow)
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "http://localhost/self-service/settings/browser", w.Header().Get("Location"))
+	})
+}
@@ -0,0 +1,191 @@
+package hook
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/ory/x/httpx"
+	"github.com/ory/x/sqlcon"
+
+	"github.com/ory/kratos/courier"
+	"github.com/ory/kratos/courier/template/email"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/selfservice/flow/recovery"
+	"github.com/ory/kratos/selfservice/flow/settings"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/kratos/x"
+)
+
+var (
+	_ login.PostHookExecutor               = new(SecurityNotifier)
+	_ recovery.PostHookExecutor            = new(SecurityNotifier)
+	_ settings.PostHookPrePersistExecutor  = new(SecurityNotifier)
+	_ settings.PostHookPostPersistExecutor = new(SecurityNotifier)
+)
+
+// InternalContextKeySecurityEvents is the key of the settings flow's internal context which holds the
+// events detected before the identity was updated until the identity has been persisted.
+const InternalContextKeySecurityEvents = "security_notification_events"
+
+type (
+	securityNotifierDependencies interface {
+		config.Provider
+		courier.Provider
+		courier.ConfigProvider
+		identity.PoolProvider
+		identity.PrivilegedPoolProvider
+		identity.ManagementProvider
+		session.ManagementProvider
+		session.PersistenceProvider
+		x.LoggingProvider
+
+		HTTPClient(ctx context.Context, opts ...httpx.ResilientOptions) *retryablehttp.Client
+	}
+	SecurityNotifier struct {
+		r securityNotifierDependencies
+	}
+)
+
+func NewSecurityNotifier(r securityNotifierDependencies) *SecurityNotifier {
+	return &SecurityNotifier{r: r}
+}
+
+// ExecuteLoginPostHook notifies the identity if it signed in from a device which was not seen before.
+// Devices are identified by a signed cookie, which is why only browser flows are taken into account.
+// The user agent is only used to describe the device in the notification. Nothing is sent for the
+// first sign-in which is recorded, because every device is new at that point.
+func (e *SecurityNotifier) ExecuteLoginPostHook(w http.ResponseWriter, r *http.Request, _ node.UiNodeGroup, f *login.Flow, s *session.Session) error {
+	if f.Type != flow.TypeBrowser {
+		return nil
+	}
+
+	ctx := r.Context()
+	deviceID, err := e.r.SessionManager().SeenDeviceID(ctx, w, r)
+	if err != nil {
+		return err
+	}
+
+	seen, err := e.r.SessionPersister().GetSeenDevice(ctx, s.Identity.ID, deviceID)
+	if err == nil {
+		return e.r.SessionPersister().ExtendSeenDevice(ctx, seen.ID, time.Now().UTC().Add(session.SeenDeviceLifespan))
+	} else if !errors.Is(err, sqlcon.ErrNoRows) {
+		return err
+	}
+
+	known, err := e.r.SessionPersister().HasSeenDevices(ctx, s.Identity.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := e.r.SessionPersister().CreateSeenDevice(ctx, session.NewSeenDevice(s.Identity.ID, deviceID, r.UserAgent())); err != nil {
+		return err
+	}
+
+	if !known {
+		return nil
+	}
+
+	return e.notify(ctx, s.Identity.ID, email.SecurityEventLoginNewDevice, r.UserAgent())
+}
+
+// ExecutePostRecoveryHook notifies the identity that its account was recovered.
+func (e *SecurityNotifier) ExecutePostRecoveryHook(_ http.ResponseWriter, r *http.Request, _ *recovery.Flow, s *session.Session) error {
+	return e.notify(r.Context(), s.Identity.ID, email.SecurityEventRecoveryCompleted, r.UserAgent())
+}
+
+// ExecuteSettingsPrePersistHook compares the updated identity with the stored one and remembers which
+// security relevant changes were made. The notifications are only sent once the identity was persisted.
+func (e *SecurityNotifier) ExecuteSettingsPrePersistHook(_ http.ResponseWriter, r *http.Request, f *settings.Flow, i *identity.Identity) error {
+	ctx := r.Context()
+	original, err := e.r.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+	if err != nil {
+		return err
+	}
+
+	events := []string{}
+	if passwordChanged(original, i) {
+		events = append(events, email.SecurityEventPasswordChanged)
+	}
+
+	before, err := e.r.IdentityManager().CountActiveMultiFactorCredentials(ctx, original)
+	if err != nil {
+		return err
+	}
+
+	after, err := e.r.IdentityManager().CountActiveMultiFactorCredentials(ctx, i)
+	if err != nil {
+		return err
+	}
+
+	if after > before {
+		events = append(events, email.SecurityEventSecondFactorAdded)
+	} else if after < before {
+		events = append(events, email.SecurityEventSecondFactorRemoved)
+	}
+
+	f.InternalContext, err = sjson.SetBytes(f.InternalContext, InternalContextKeySecurityEvents, events)
+	return errors.WithStack(err)
+}
+
+// ExecuteSettingsPostPersistHook sends the notifications for the changes remembered before the identity was persisted.
+func (e *SecurityNotifier) ExecuteSettingsPostPersistHook(_ http.ResponseWriter, r *http.Request, f *settings.Flow, i *identity.Identity) error {
+	for _, event := range gjson.GetBytes(f.InternalContext, InternalContextKeySecurityEvents).Array() {
+		if err := e.notify(r.Context(), i.ID, event.String(), r.UserAgent()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *SecurityNotifier) notify(ctx context.Context, identityID uuid.UUID, event, userAgent string) error {
+	i, err := e.r.IdentityPool().GetIdentity(ctx, identityID)
+	if err != nil {
+		return err
+	}
+
+	model, err := x.StructToMap(i)
+	if err != nil {
+		return err
+	}
+
+	for _, address := range i.EmailAddresses() {
+		if _, err := e.r.Courier(ctx).QueueEmail(ctx, email.NewSecurityNotification(e.r, &email.SecurityNotificationModel{
+			To:        address,
+			Event:     event,
+			UserAgent: userAgent,
+			Identity:  model,
+		})); err != nil {
+			return err
+		}
+	}
+
+	e.r.Logger().
+		WithField("identity_id", identityID).
+		WithField("event", event).
+		Debug("Queued security notifications.")
+	return nil
+}
+
+func passwordChanged(original, updated *identity.Identity) bool {
+	hash := func(i *identity.Identity) string {
+		c, ok := i.GetCredentials(identity.CredentialsTypePassword)
+		if !ok {
+			return ""
+		}
+		return gjson.GetBytes(c.Config, "hashed_password").String()
+	}
+
+	next := hash(updated)
+	return len(next) > 0 && hash(original) != next
+}
@@ -0,0 +1,192 @@
+package hook_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/courier/template/email"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/selfservice/flow/recovery"
+	"github.com/ory/kratos/selfservice/flow/settings"
+	"github.com/ory/kratos/selfservice/hook"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/ui/node"
+)
+
+func TestSecurityNotifier(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/stub.schema.json")
+
+	h := hook.NewSecurityNotifier(reg)
+
+	newIdentity := func(t *testing.T, credentials map[identity.CredentialsType]identity.Credentials) (*identity.Identity, string) {
+		var i identity.Identity
+		require.NoError(t, faker.FakeData(&i))
+		address := strings.ToLower(testhelpers.RandomEmail())
+		i.VerifiableAddresses = nil
+		i.RecoveryAddresses = []identity.RecoveryAddress{{Value: address, Via: identity.RecoveryAddressTypeEmail}}
+		i.Credentials = credentials
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, &i))
+		return &i, address
+	}
+
+	expectNoMessage := func(t *testing.T, address string) {
+		message, err := reg.CourierPersister().LatestQueuedMessage(ctx)
+		if err == nil {
+			assert.NotEqual(t, address, message.Recipient)
+		}
+	}
+
+	// login signs in from the browser which holds the given cookies and returns the cookies issued by the hook.
+	login := func(t *testing.T, i *identity.Identity, ft flow.Type, userAgent string, cookies ...*http.Cookie) []*http.Cookie {
+		s, err := session.NewActiveSession(ctx, i, conf, time.Now().UTC(), identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
+		require.NoError(t, err)
+
+		w, r := httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil)
+		r.Header.Set("User-Agent", userAgent)
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		require.NoError(t, h.ExecuteLoginPostHook(w, r, node.PasswordGroup, &login.Flow{Type: ft}, s))
+		return w.Result().Cookies()
+	}
+
+	firefox := "Mozilla/5.0 (X11; Linux x86_64; rv:105.0) Gecko/20100101 Firefox/105.0"
+
+	t.Run("case=notifies about sign-ins from new devices", func(t *testing.T) {
+		i, address := newIdentity(t, nil)
+
+		cookies := login(t, i, flow.TypeBrowser, firefox)
+		require.Len(t, cookies, 1)
+		assert.Equal(t, session.SeenDeviceCookieName, cookies[0].Name)
+		expectNoMessage(t, address)
+
+		// The device is identified by its cookie and not by its user agent.
+		login(t, i, flow.TypeBrowser, "Mozilla/5.0 (X11; Linux x86_64; rv:106.0) Gecko/20100101 Firefox/106.0", cookies...)
+		expectNoMessage(t, address)
+
+		login(t, i, flow.TypeBrowser, firefox)
+		message := testhelpers.CourierExpectMessage(t, reg, address, "New sign-in to your account")
+		assert.Contains(t, message.Body, firefox)
+	})
+
+	t.Run("case=treats devices with a tampered cookie as new", func(t *testing.T) {
+		i, address := newIdentity(t, nil)
+
+		cookies := login(t, i, flow.TypeBrowser, firefox)
+		require.Len(t, cookies, 1)
+		expectNoMessage(t, address)
+
+		cookies[0].Value = "invalid" + cookies[0].Value
+		login(t, i, flow.TypeBrowser, firefox, cookies...)
+		testhelpers.CourierExpectMessage(t, reg, address, "New sign-in to your account")
+	})
+
+	t.Run("case=ignores API flows", func(t *testing.T) {
+		i, address := newIdentity(t, nil)
+
+		assert.Empty(t, login(t, i, flow.TypeAPI, firefox))
+		assert.Empty(t, login(t, i, flow.TypeAPI, "curl/7.79.1"))
+		expectNoMessage(t, address)
+
+		known, err := reg.SessionPersister().HasSeenDevices(ctx, i.ID)
+		require.NoError(t, err)
+		assert.False(t, known)
+	})
+
+	t.Run("case=notifies about completed recoveries", func(t *testing.T) {
+		i, address := newIdentity(t, nil)
+		s, err := session.NewActiveSession(ctx, i, conf, time.Now().UTC(), identity.CredentialsTypeRecoveryLink, identity.AuthenticatorAssuranceLevel1)
+		require.NoError(t, err)
+
+		require.NoError(t, h.ExecutePostRecoveryHook(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil), &recovery.Flow{}, s))
+		testhelpers.CourierExpectMessage(t, reg, address, "Your account was recovered")
+	})
+
+	t.Run("case=settings", func(t *testing.T) {
+		password := func(hash string) map[identity.CredentialsType]identity.Credentials {
+			return map[identity.CredentialsType]identity.Credentials{
+				identity.CredentialsTypePassword: {
+					Type:        identity.CredentialsTypePassword,
+					Config:      []byte(`{"hashed_password":"` + hash + `"}`),
+					Identifiers: []string{testhelpers.RandomEmail()},
+				},
+			}
+		}
+
+		run := func(t *testing.T, original map[identity.CredentialsType]identity.Credentials, update func(i *identity.Identity)) (*settings.Flow, string) {
+			i, address := newIdentity(t, original)
+			updated, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+			require.NoError(t, err)
+			update(updated)
+
+			w, r := httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil)
+			f := &settings.Flow{InternalContext: []byte("{}")}
+			require.NoError(t, h.ExecuteSettingsPrePersistHook(w, r, f, updated))
+			require.NoError(t, h.ExecuteSettingsPostPersistHook(w, r, f, updated))
+			return f, address
+		}
+
+		t.Run("case=password changed", func(t *testing.T) {
+			f, address := run(t, password("old"), func(i *identity.Identity) {
+				c, _ := i.GetCredentials(identity.CredentialsTypePassword)
+				c.Config = []byte(`{"hashed_password":"new"}`)
+				i.SetCredentials(identity.CredentialsTypePassword, *c)
+			})
+
+			assert.Equal(t, []interface{}{email.SecurityEventPasswordChanged}, gjson.GetBytes(f.InternalContext, hook.InternalContextKeySecurityEvents).Value())
+			testhelpers.CourierExpectMessage(t, reg, address, "Your password was changed")
+		})
+
+		t.Run("case=second factor added", func(t *testing.T) {
+			f, address := run(t, password("old"), func(i *identity.Identity) {
+				i.SetCredentials(identity.CredentialsTypeWebAuthn, identity.Credentials{
+					Type:        identity.CredentialsTypeWebAuthn,
+					Config:      []byte(`{"credentials":[{"is_passwordless":false}]}`),
+					Identifiers: []string{testhelpers.RandomEmail()},
+				})
+			})
+
+			assert.Equal(t, []interface{}{email.SecurityEventSecondFactorAdded}, gjson.GetBytes(f.InternalContext, hook.InternalContextKeySecurityEvents).Value())
+			testhelpers.CourierExpectMessage(t, reg, address, "A second factor was added to your account")
+		})
+
+		t.Run("case=second factor removed", func(t *testing.T) {
+			f, address := run(t, map[identity.CredentialsType]identity.Credentials{
+				identity.CredentialsTypeWebAuthn: {
+					Type:        identity.CredentialsTypeWebAuthn,
+					Config:      []byte(`{"credentials":[{"is_passwordless":false}]}`),
+					Identifiers: []string{testhelpers.RandomEmail()},
+				},
+			}, func(i *identity.Identity) {
+				i.DeleteCredentialsType(identity.CredentialsTypeWebAuthn)
+			})
+
+			assert.Equal(t, []interface{}{email.SecurityEventSecondFactorRemoved}, gjson.GetBytes(f.InternalContext, hook.InternalContextKeySecurityEvents).Value())
+			testhelpers.CourierExpectMessage(t, reg, address, "A second factor was removed from your account")
+		})
+
+		t.Run("case=nothing security relevant changed", func(t *testing.T) {
+			f, address := run(t, password("old"), func(i *identity.Identity) {
+				i.Traits = identity.Traits(`{"bar":"baz"}`)
+			})
+
+			assert.Empty(t, gjson.GetBytes(f.InternalContext, hook.InternalContextKeySecurityEvents).Array())
+			expectNoMessage(t, address)
+		})
+	})
+}
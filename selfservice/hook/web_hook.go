@@ -33,6 +33,8 @@ var _ registration.PostHookPrePersistExecutor = new(WebHook)
 var _ verification.PostHookExecutor = new(WebHook)
 var _ recovery.PostHookExecutor = new(WebHook)
 var _ settings.PostHookPostPersistExecutor = new(WebHook)
+var _ settings.AccountDeletionPreHookExecutor = new(WebHook)
+var _ settings.AccountDeletionPostHookExecutor = new(WebHook)
 
 type (
 	webHookDependencies interface {
@@ -191,6 +193,28 @@ func (e *WebHook) ExecuteSettingsPostPersistHook(_ http.ResponseWriter, req *htt
 	})
 }
 
+func (e *WebHook) ExecuteSettingsAccountDeletionPreHook(_ http.ResponseWriter, req *http.Request, flow *settings.Flow, id *identity.Identity) error {
+	ctx, _ := e.deps.Tracer(req.Context()).Tracer().Start(req.Context(), "selfservice.hook.ExecuteSettingsAccountDeletionPreHook")
+	return e.execute(ctx, &templateContext{
+		Flow:           flow,
+		RequestHeaders: req.Header,
+		RequestMethod:  req.Method,
+		RequestURL:     x.RequestURL(req).String(),
+		Identity:       id,
+	})
+}
+
+func (e *WebHook) ExecuteSettingsAccountDeletionPostHook(_ http.ResponseWriter, req *http.Request, flow *settings.Flow, id *identity.Identity) error {
+	ctx, _ := e.deps.Tracer(req.Context()).Tracer().Start(req.Context(), "selfservice.hook.ExecuteSettingsAccountDeletionPostHook")
+	return e.execute(ctx, &templateContext{
+		Flow:           flow,
+		RequestHeaders: req.Header,
+		RequestMethod:  req.Method,
+		RequestURL:     x.RequestURL(req).String(),
+		Identity:       id,
+	})
+}
+
 func (e *WebHook) execute(ctx context.Context, data *templateContext) error {
 	span := trace.SpanFromContext(ctx)
 	attrs := map[string]string{
@@ -200,6 +200,26 @@ func TestWebHooks(t *testing.T) {
 				return bodyWithFlowAndIdentity(req, f, s)
 			},
 		},
+		{
+			uc:         "Pre Account Deletion Hook",
+			createFlow: func() flow.Flow { return &settings.Flow{ID: x.NewUUID()} },
+			callWebHook: func(wh *hook.WebHook, req *http.Request, f flow.Flow, s *session.Session) error {
+				return wh.ExecuteSettingsAccountDeletionPreHook(nil, req, f.(*settings.Flow), s.Identity)
+			},
+			expectedBody: func(req *http.Request, f flow.Flow, s *session.Session) string {
+				return bodyWithFlowAndIdentity(req, f, s)
+			},
+		},
+		{
+			uc:         "Post Account Deletion Hook",
+			createFlow: func() flow.Flow { return &settings.Flow{ID: x.NewUUID()} },
+			callWebHook: func(wh *hook.WebHook, req *http.Request, f flow.Flow, s *session.Session) error {
+				return wh.ExecuteSettingsAccountDeletionPostHook(nil, req, f.(*settings.Flow), s.Identity)
+			},
+			expectedBody: func(req *http.Request, f flow.Flow, s *session.Session) string {
+				return bodyWithFlowAndIdentity(req, f, s)
+			},
+		},
 	} {
 		t.Run("uc="+tc.uc, func(t *testing.T) {
 			for _, auth := range []struct {
@@ -0,0 +1,18 @@
+package accountdeletion
+
+import (
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/ui/node"
+)
+
+func NewConfirmNode() *node.Node {
+	return node.NewInputField(node.AccountDeletionConfirm, false, node.AccountDeletionGroup,
+		node.InputAttributeTypeCheckbox).
+		WithMetaLabel(text.NewInfoSelfServiceSettingsAccountDeletionConfirm())
+}
+
+func NewSubmitNode() *node.Node {
+	return node.NewInputField("method", StrategyID, node.AccountDeletionGroup,
+		node.InputAttributeTypeSubmit).
+		WithMetaLabel(text.NewInfoSelfServiceSettingsAccountDeletion())
+}
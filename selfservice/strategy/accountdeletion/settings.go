@@ -0,0 +1,233 @@
+package accountdeletion
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ory/x/decoderx"
+	"github.com/ory/x/sqlcon"
+
+	"github.com/ory/kratos/courier/template/email"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/schema"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/settings"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/x"
+)
+
+func (s *Strategy) RegisterSettingsRoutes(_ *x.RouterPublic) {
+}
+
+func (s *Strategy) SettingsStrategyID() string {
+	return StrategyID
+}
+
+// swagger:model submitSelfServiceSettingsFlowWithAccountDeletionMethodBody
+type submitSelfServiceSettingsFlowWithAccountDeletionMethodBody struct {
+	// Confirm the Account Deletion
+	//
+	// Must be set to true, otherwise the account is not deleted.
+	Confirm bool `json:"account_deletion_confirm"`
+
+	// CSRFToken is the anti-CSRF token
+	CSRFToken string `json:"csrf_token"`
+
+	// Method
+	//
+	// Should be set to "account_deletion" when trying to delete the account.
+	//
+	// required: true
+	Method string `json:"method"`
+
+	// Flow is flow ID.
+	//
+	// swagger:ignore
+	Flow string `json:"flow"`
+}
+
+func (p *submitSelfServiceSettingsFlowWithAccountDeletionMethodBody) GetFlowID() uuid.UUID {
+	return x.ParseUUID(p.Flow)
+}
+
+func (p *submitSelfServiceSettingsFlowWithAccountDeletionMethodBody) SetFlowID(rid uuid.UUID) {
+	p.Flow = rid.String()
+}
+
+func (s *Strategy) Settings(w http.ResponseWriter, r *http.Request, f *settings.Flow, ss *session.Session) (*settings.UpdateContext, error) {
+	var p submitSelfServiceSettingsFlowWithAccountDeletionMethodBody
+	ctxUpdate, err := settings.PrepareUpdate(s.d, w, r, f, ss, settings.ContinuityKey(s.SettingsStrategyID()), &p)
+	if errors.Is(err, settings.ErrContinuePreviousAction) {
+		if err := s.continueSettingsFlow(w, r, ctxUpdate, &p); err != nil {
+			return ctxUpdate, s.handleSettingsError(w, r, ctxUpdate, &p, err)
+		}
+		return ctxUpdate, errors.WithStack(flow.ErrCompletedByStrategy)
+	} else if err != nil {
+		return ctxUpdate, s.handleSettingsError(w, r, ctxUpdate, &p, err)
+	}
+
+	if err := s.decodeSettingsFlow(r, &p); err != nil {
+		return ctxUpdate, s.handleSettingsError(w, r, ctxUpdate, &p, err)
+	}
+
+	if p.Method != s.SettingsStrategyID() {
+		return nil, errors.WithStack(flow.ErrStrategyNotResponsible)
+	}
+
+	// This does not come from the payload!
+	p.Flow = ctxUpdate.Flow.ID.String()
+	if err := s.continueSettingsFlow(w, r, ctxUpdate, &p); err != nil {
+		return ctxUpdate, s.handleSettingsError(w, r, ctxUpdate, &p, err)
+	}
+
+	return ctxUpdate, errors.WithStack(flow.ErrCompletedByStrategy)
+}
+
+func (s *Strategy) decodeSettingsFlow(r *http.Request, dest interface{}) error {
+	compiler, err := decoderx.HTTPRawJSONSchemaCompiler(settingsSchema)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return s.hd.Decode(r, dest, compiler,
+		decoderx.HTTPDecoderSetValidatePayloads(true),
+		decoderx.HTTPDecoderJSONFollowsFormFormat(),
+	)
+}
+
+func (s *Strategy) continueSettingsFlow(
+	w http.ResponseWriter, r *http.Request,
+	ctxUpdate *settings.UpdateContext, p *submitSelfServiceSettingsFlowWithAccountDeletionMethodBody,
+) error {
+	if err := flow.MethodEnabledAndAllowed(r.Context(), s.SettingsStrategyID(), p.Method, s.d); err != nil {
+		return err
+	}
+
+	if err := flow.EnsureCSRF(s.d, r, ctxUpdate.Flow.Type, s.d.Config().DisableAPIFlowEnforcement(r.Context()), s.d.GenerateCSRFToken, p.CSRFToken); err != nil {
+		return err
+	}
+
+	if !p.Confirm {
+		return schema.NewAccountDeletionNotConfirmedError()
+	}
+
+	if ctxUpdate.Session.AuthenticatedAt.Add(s.d.Config().SelfServiceFlowSettingsPrivilegedSessionMaxAge(r.Context())).Before(time.Now()) {
+		return errors.WithStack(settings.NewFlowNeedsReAuth())
+	}
+
+	return s.continueSettingsFlowDelete(w, r, ctxUpdate)
+}
+
+func (s *Strategy) continueSettingsFlowDelete(w http.ResponseWriter, r *http.Request, ctxUpdate *settings.UpdateContext) error {
+	ctx := r.Context()
+	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, ctxUpdate.Session.IdentityID)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range s.d.PreAccountDeletionHooks(ctx) {
+		if err := h.ExecuteSettingsAccountDeletionPreHook(w, r, ctxUpdate.Flow, i); err != nil {
+			if errors.Is(err, settings.ErrHookAbortFlow) {
+				return errors.WithStack(flow.ErrCompletedByStrategy)
+			}
+			return err
+		}
+	}
+
+	addresses := i.EmailAddresses()
+
+	// Either everything is deleted or nothing is.
+	if err := s.d.TransactionalPersisterProvider().Transaction(ctx, func(ctx context.Context, _ *pop.Connection) error {
+		if err := s.d.SessionManager().PurgeFromRequest(ctx, w, r); err != nil && !errors.Is(err, sqlcon.ErrNoRows) {
+			return err
+		}
+
+		if err := s.d.SessionPersister().DeleteSessionsByIdentity(ctx, i.ID); err != nil && !errors.Is(err, sqlcon.ErrNoRows) {
+			return err
+		}
+
+		if err := s.d.PrivilegedIdentityPool().DeleteIdentity(ctx, i.ID); err != nil {
+			return err
+		}
+
+		for _, recipient := range append(i.PhoneNumbers(), addresses...) {
+			if err := s.d.CourierPersister().DeleteMessagesByRecipient(ctx, recipient); err != nil {
+				return err
+			}
+		}
+
+		if !s.d.Config().AccountDeletionSendConfirmationEmail(ctx) {
+			return nil
+		}
+
+		// The message is stored until it is sent, so it must not contain data of the deleted account.
+		for _, address := range addresses {
+			if _, err := s.d.Courier(ctx).QueueEmail(ctx, email.NewAccountDeleted(s.d, &email.AccountDeletedModel{
+				To: address,
+			})); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.d.Audit().
+		WithRequest(r).
+		WithField("identity_id", i.ID).
+		Info("An identity deleted its own account.")
+
+	// The account is gone at this point, which is why errors of post hooks can only be logged.
+	for k, h := range s.d.PostAccountDeletionHooks(ctx) {
+		if err := h.ExecuteSettingsAccountDeletionPostHook(w, r, ctxUpdate.Flow, i); err != nil {
+			if errors.Is(err, settings.ErrHookAbortFlow) {
+				return nil
+			}
+
+			s.d.Logger().
+				WithRequest(r).
+				WithField("executor", k).
+				WithField("identity_id", i.ID).
+				WithError(err).
+				Error("A post account deletion hook failed after the account was deleted.")
+		}
+	}
+
+	if ctxUpdate.Flow.Type == flow.TypeAPI || x.IsJSONRequest(r) {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+
+	http.Redirect(w, r, s.d.Config().SelfServiceFlowSettingsAccountDeletionReturnTo(ctx).String(), http.StatusSeeOther)
+	return nil
+}
+
+func (s *Strategy) PopulateSettingsMethod(r *http.Request, _ *identity.Identity, f *settings.Flow) error {
+	f.UI.SetCSRF(s.d.GenerateCSRFToken(r))
+	f.UI.Nodes.Append(NewConfirmNode())
+	f.UI.Nodes.Append(NewSubmitNode())
+	return nil
+}
+
+func (s *Strategy) handleSettingsError(w http.ResponseWriter, r *http.Request, ctxUpdate *settings.UpdateContext, p *submitSelfServiceSettingsFlowWithAccountDeletionMethodBody, err error) error {
+	// Do not pause flow if the flow type is an API flow as we can't save cookies in those flows.
+	if e := new(settings.FlowNeedsReAuth); errors.As(err, &e) && ctxUpdate.Flow != nil && ctxUpdate.Flow.Type == flow.TypeBrowser {
+		if err := s.d.ContinuityManager().Pause(r.Context(), w, r, settings.ContinuityKey(s.SettingsStrategyID()), settings.ContinuityOptions(p, ctxUpdate.GetSessionIdentity())...); err != nil {
+			return err
+		}
+	}
+
+	if ctxUpdate.Flow != nil {
+		ctxUpdate.Flow.UI.ResetMessages()
+		ctxUpdate.Flow.UI.SetCSRF(s.d.GenerateCSRFToken(r))
+	}
+
+	return err
+}
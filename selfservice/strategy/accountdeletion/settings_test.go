@@ -0,0 +1,276 @@
+package accountdeletion_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/x/sqlcon"
+
+	"github.com/ory/kratos/courier"
+	"github.com/ory/kratos/courier/template/email"
+	"github.com/ory/kratos/courier/template/sms"
+	"github.com/ory/kratos/driver"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/kratos/x"
+)
+
+func createIdentity(t *testing.T, reg driver.Registry) (*identity.Identity, string) {
+	email := fmt.Sprintf("%s@ory.sh", x.NewUUID())
+	i := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+	i.Traits = identity.Traits(fmt.Sprintf(`{"email":"%s"}`, email))
+	i.VerifiableAddresses = []identity.VerifiableAddress{*identity.NewVerifiableEmailAddress(email, i.ID)}
+	require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(context.Background(), i))
+	return i, email
+}
+
+func newStubEmail(reg driver.Registry, to string) *email.TestStub {
+	return email.NewTestStub(reg, &email.TestStubModel{To: to, Subject: "test subject", Body: "test body"})
+}
+
+func TestCompleteSettings(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+string(identity.CredentialsTypePassword)+".enabled", false)
+	conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+".profile.enabled", false)
+	conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+".account_deletion.enabled", true)
+	conf.MustSet(ctx, config.ViperKeySelfServiceSettingsRequiredAAL, "aal1")
+
+	router := x.NewRouterPublic()
+	publicTS, _ := testhelpers.NewKratosServerWithRouters(t, reg, router, x.NewRouterAdmin())
+
+	_ = testhelpers.NewErrorTestServer(t, reg)
+	_ = testhelpers.NewSettingsUIFlowEchoServer(t, reg)
+	loginTS := testhelpers.NewLoginUIFlowEchoServer(t, reg)
+
+	returnTS := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("account deleted"))
+	}))
+	t.Cleanup(returnTS.Close)
+	conf.MustSet(ctx, config.ViperKeySelfServiceSettingsAccountDeletionAfter+"."+config.DefaultBrowserReturnURL, returnTS.URL+"/deleted")
+
+	var hookLock sync.Mutex
+	var hookCalls []string
+	hookTS := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hookLock.Lock()
+		defer hookLock.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		hookCalls = append(hookCalls, r.URL.Path+":"+gjson.GetBytes(body, "identity_id").String())
+		if r.URL.Path == "/block" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(hookTS.Close)
+	resetHookCalls := func() []string {
+		hookLock.Lock()
+		defer hookLock.Unlock()
+		calls := hookCalls
+		hookCalls = nil
+		return calls
+	}
+
+	conf.MustSet(ctx, config.ViperKeySelfServiceSettingsPrivilegedAuthenticationAfter, "1m")
+
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/identity.schema.json")
+	conf.MustSet(ctx, config.ViperKeySecretsDefault, []string{"not-a-secure-session-key"})
+
+	doAPIFlow := func(t *testing.T, v func(url.Values), id *identity.Identity) (string, *http.Response) {
+		apiClient := testhelpers.NewHTTPClientWithIdentitySessionToken(t, reg, id)
+		f := testhelpers.InitializeSettingsFlowViaAPI(t, apiClient, publicTS)
+		values := testhelpers.SDKFormFieldsToURLValues(f.Ui.Nodes)
+		v(values)
+		payload := testhelpers.EncodeFormAsJSON(t, true, values)
+		return testhelpers.SettingsMakeRequest(t, true, false, f, apiClient, payload)
+	}
+
+	doBrowserFlow := func(t *testing.T, spa bool, v func(url.Values), id *identity.Identity) (string, *http.Response) {
+		browserClient := testhelpers.NewHTTPClientWithIdentitySessionCookie(t, reg, id)
+		f := testhelpers.InitializeSettingsFlowViaBrowser(t, browserClient, spa, publicTS)
+		values := testhelpers.SDKFormFieldsToURLValues(f.Ui.Nodes)
+		v(values)
+		return testhelpers.SettingsMakeRequest(t, false, spa, f, browserClient, testhelpers.EncodeFormAsJSON(t, spa, values))
+	}
+
+	confirm := func(v url.Values) {
+		v.Set("method", "account_deletion")
+		v.Set(node.AccountDeletionConfirm, "true")
+	}
+
+	identityExists := func(t *testing.T, id *identity.Identity) bool {
+		_, err := reg.PrivilegedIdentityPool().GetIdentity(ctx, id.ID)
+		if errors.Is(err, sqlcon.ErrNoRows) {
+			return false
+		}
+		require.NoError(t, err)
+		return true
+	}
+
+	listMessages := func(t *testing.T, recipient string) []courier.Message {
+		messages, _, err := reg.CourierPersister().ListMessages(ctx, courier.MessagesFilter{
+			Recipient:        recipient,
+			PaginationParams: x.PaginationParams{Page: 1, PerPage: 100},
+		})
+		require.NoError(t, err)
+		return messages
+	}
+
+	t.Run("case=shows a confirmation and a delete button", func(t *testing.T) {
+		id, _ := createIdentity(t, reg)
+
+		browserClient := testhelpers.NewHTTPClientWithIdentitySessionCookie(t, reg, id)
+		f := testhelpers.InitializeSettingsFlowViaBrowser(t, browserClient, true, publicTS)
+		raw, err := json.Marshal(f.Ui.Nodes)
+		require.NoError(t, err)
+
+		assert.Equal(t, "checkbox", gjson.GetBytes(raw, `#(attributes.name=="`+node.AccountDeletionConfirm+`").attributes.type`).String(), "%s", raw)
+		assert.Equal(t, "account_deletion", gjson.GetBytes(raw, `#(group=="account_deletion")#|#(attributes.name=="method").attributes.value`).String(), "%s", raw)
+	})
+
+	t.Run("case=should not delete the account without confirmation", func(t *testing.T) {
+		id, _ := createIdentity(t, reg)
+
+		actual, res := doAPIFlow(t, func(v url.Values) {
+			v.Set("method", "account_deletion")
+		}, id)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", actual)
+		assert.EqualValues(t, text.ErrorValidationAccountDeletionNotConfirmed, gjson.Get(actual, "ui.nodes.#(attributes.name==\""+node.AccountDeletionConfirm+"\").messages.0.id").Int(), "%s", actual)
+		assert.True(t, identityExists(t, id))
+	})
+
+	t.Run("case=should delete the account", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyAccountDeletionSendConfirmationEmail, true)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyAccountDeletionSendConfirmationEmail, false)
+		})
+
+		for _, tc := range []struct {
+			d string
+			f func(t *testing.T, v func(url.Values), id *identity.Identity) (string, *http.Response)
+		}{
+			{d: "api", f: doAPIFlow},
+			{d: "spa", f: func(t *testing.T, v func(url.Values), id *identity.Identity) (string, *http.Response) {
+				return doBrowserFlow(t, true, v, id)
+			}},
+			{d: "browser", f: func(t *testing.T, v func(url.Values), id *identity.Identity) (string, *http.Response) {
+				return doBrowserFlow(t, false, v, id)
+			}},
+		} {
+			t.Run("type="+tc.d, func(t *testing.T) {
+				id, email := createIdentity(t, reg)
+				_, err := reg.Courier(ctx).QueueEmail(ctx, newStubEmail(reg, email))
+				require.NoError(t, err)
+
+				actual, res := tc.f(t, confirm, id)
+				if tc.d == "browser" {
+					assert.Equal(t, http.StatusOK, res.StatusCode, "%s", actual)
+					assert.Equal(t, returnTS.URL+"/deleted", res.Request.URL.String())
+				} else {
+					assert.Equal(t, http.StatusNoContent, res.StatusCode, "%s", actual)
+				}
+
+				assert.False(t, identityExists(t, id))
+
+				sessions, err := reg.SessionPersister().ListSessionsByIdentity(ctx, id.ID, nil, 1, 10, x.EmptyUUID)
+				require.NoError(t, err)
+				assert.Empty(t, sessions)
+
+				messages := listMessages(t, email)
+				require.Len(t, messages, 1, "only the confirmation email must be left")
+				assert.Equal(t, courier.TypeAccountDeleted, messages[0].TemplateType)
+				assert.JSONEq(t, `{"To":"`+email+`"}`, string(messages[0].TemplateData), "the message must not contain data of the deleted account")
+			})
+		}
+	})
+
+	t.Run("case=should keep the courier messages of other recipients", func(t *testing.T) {
+		id, _ := createIdentity(t, reg)
+		_, other := createIdentity(t, reg)
+		_, err := reg.Courier(ctx).QueueEmail(ctx, newStubEmail(reg, other))
+		require.NoError(t, err)
+
+		actual, res := doAPIFlow(t, confirm, id)
+		assert.Equal(t, http.StatusNoContent, res.StatusCode, "%s", actual)
+		assert.Len(t, listMessages(t, other), 1)
+	})
+
+	t.Run("case=should delete the courier messages sent to the phone numbers", func(t *testing.T) {
+		id, _ := createIdentity(t, reg)
+		phone := fmt.Sprintf("+49176%07d", time.Now().UnixNano()%10000000)
+		id.VerifiableAddresses = append(id.VerifiableAddresses, *identity.NewVerifiablePhoneAddress(phone, id.ID))
+		require.NoError(t, reg.PrivilegedIdentityPool().UpdateIdentity(ctx, id))
+
+		_, err := reg.Courier(ctx).QueueSMS(ctx, sms.NewTestStub(reg, &sms.TestStubModel{To: phone, Body: "test body"}))
+		require.NoError(t, err)
+		require.Len(t, listMessages(t, phone), 1)
+
+		actual, res := doAPIFlow(t, confirm, id)
+		assert.Equal(t, http.StatusNoContent, res.StatusCode, "%s", actual)
+		assert.Empty(t, listMessages(t, phone))
+	})
+
+	t.Run("case=should run the account deletion hooks", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceSettingsAccountDeletionBeforeHooks, []config.SelfServiceHook{
+			{Name: "web_hook", Config: json.RawMessage(`{"url":"` + hookTS.URL + `/before","method":"POST","body":"file://./stub/hook.jsonnet"}`)},
+		})
+		conf.MustSet(ctx, config.HookStrategyKey(config.ViperKeySelfServiceSettingsAccountDeletionAfter, config.HookGlobal), []config.SelfServiceHook{
+			{Name: "web_hook", Config: json.RawMessage(`{"url":"` + hookTS.URL + `/after","method":"POST","body":"file://./stub/hook.jsonnet"}`)},
+		})
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceSettingsAccountDeletionBeforeHooks, nil)
+			conf.MustSet(ctx, config.HookStrategyKey(config.ViperKeySelfServiceSettingsAccountDeletionAfter, config.HookGlobal), nil)
+		})
+		resetHookCalls()
+
+		id, _ := createIdentity(t, reg)
+		actual, res := doAPIFlow(t, confirm, id)
+		assert.Equal(t, http.StatusNoContent, res.StatusCode, "%s", actual)
+		assert.False(t, identityExists(t, id))
+		assert.Equal(t, []string{"/before:" + id.ID.String(), "/after:" + id.ID.String()}, resetHookCalls())
+	})
+
+	t.Run("case=should not delete the account if a pre hook fails", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceSettingsAccountDeletionBeforeHooks, []config.SelfServiceHook{
+			{Name: "web_hook", Config: json.RawMessage(`{"url":"` + hookTS.URL + `/block","method":"POST","body":"file://./stub/hook.jsonnet"}`)},
+		})
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceSettingsAccountDeletionBeforeHooks, nil)
+		})
+		resetHookCalls()
+
+		id, _ := createIdentity(t, reg)
+		actual, res := doAPIFlow(t, confirm, id)
+		assert.NotEqual(t, http.StatusNoContent, res.StatusCode, "%s", actual)
+		assert.True(t, identityExists(t, id))
+		assert.Equal(t, []string{"/block:" + id.ID.String()}, resetHookCalls())
+	})
+
+	t.Run("case=can not delete the account without privileged session", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceSettingsPrivilegedAuthenticationAfter, "1ns")
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceSettingsPrivilegedAuthenticationAfter, "1m")
+		})
+
+		id, _ := createIdentity(t, reg)
+		actual, res := doBrowserFlow(t, false, confirm, id)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Contains(t, res.Request.URL.String(), loginTS.URL+"/login-ts", "%s", actual)
+		assert.True(t, identityExists(t, id))
+	})
+}
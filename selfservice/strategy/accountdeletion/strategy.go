@@ -0,0 +1,72 @@
+package accountdeletion
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-retryablehttp"
+
+	"github.com/ory/kratos/continuity"
+	"github.com/ory/kratos/courier"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/errorx"
+	"github.com/ory/kratos/selfservice/flow/settings"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/kratos/x"
+	"github.com/ory/x/decoderx"
+	"github.com/ory/x/httpx"
+)
+
+const StrategyID = "account_deletion"
+
+var _ settings.Strategy = new(Strategy)
+
+type strategyDependencies interface {
+	x.LoggingProvider
+	x.WriterProvider
+	x.CSRFTokenGeneratorProvider
+	x.CSRFProvider
+	x.TransactionalPersisterProvider
+
+	config.Provider
+
+	continuity.ManagementProvider
+
+	errorx.ManagementProvider
+
+	courier.Provider
+	courier.ConfigProvider
+	courier.PersistenceProvider
+
+	settings.FlowPersistenceProvider
+	settings.HookExecutorProvider
+	settings.HooksProvider
+	settings.AccountDeletionHooksProvider
+	settings.ErrorHandlerProvider
+
+	identity.PrivilegedPoolProvider
+
+	session.HandlerProvider
+	session.ManagementProvider
+	session.PersistenceProvider
+
+	HTTPClient(ctx context.Context, opts ...httpx.ResilientOptions) *retryablehttp.Client
+}
+
+// Strategy lets an identity delete its own account, sessions, and courier messages in the settings flow.
+type Strategy struct {
+	d  strategyDependencies
+	hd *decoderx.HTTP
+}
+
+func NewStrategy(d strategyDependencies) *Strategy {
+	return &Strategy{
+		d:  d,
+		hd: decoderx.NewHTTP(),
+	}
+}
+
+func (s *Strategy) NodeGroup() node.UiNodeGroup {
+	return node.AccountDeletionGroup
+}
@@ -0,0 +1,180 @@
+package link
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/verification"
+	"github.com/ory/kratos/x"
+	"github.com/ory/x/jsonx"
+	"github.com/ory/x/randx"
+)
+
+// AddressChangeState represents the state of an address change.
+type AddressChangeState string
+
+const (
+	// AddressChangeStatePending is the state of an address change which has not been confirmed yet.
+	AddressChangeStatePending AddressChangeState = "pending"
+
+	// AddressChangeStateApplied is the state of an address change which has been confirmed by the new address.
+	AddressChangeStateApplied AddressChangeState = "applied"
+
+	// AddressChangeStateReverted is the state of an address change which has been reverted by the previous address.
+	AddressChangeStateReverted AddressChangeState = "reverted"
+)
+
+// AddressChange holds a change to the identifier or recovery addresses of an identity until
+// the new address has been confirmed. The previous address is able to revert the change.
+type AddressChange struct {
+	// ID represents the address change's unique ID.
+	//
+	// required: true
+	// type: string
+	// format: uuid
+	ID uuid.UUID `json:"id" db:"id" faker:"-"`
+
+	// ConfirmToken is sent to the new address and applies the change. It can not be longer than 64 chars!
+	ConfirmToken string `json:"-" db:"confirm_token"`
+
+	// RevertToken is sent to the previous addresses and reverts the change. It can not be longer than 64 chars!
+	RevertToken string `json:"-" db:"revert_token"`
+
+	// Address is the new address which needs to be confirmed.
+	//
+	// required: true
+	Address string `json:"address" db:"address"`
+
+	// Traits are the traits which are applied once the new address has been confirmed.
+	Traits identity.Traits `json:"traits" faker:"-" db:"traits"`
+
+	// PreviousTraits are the traits which are restored if the change is reverted.
+	PreviousTraits identity.Traits `json:"previous_traits" faker:"-" db:"previous_traits"`
+
+	// State is the state of the address change.
+	//
+	// required: true
+	State AddressChangeState `json:"state" db:"state"`
+
+	// ExpiresAt is the time (UTC) when the confirmation token expires.
+	// required: true
+	ExpiresAt time.Time `json:"expires_at" faker:"time_type" db:"expires_at"`
+
+	// RevertExpiresAt is the time (UTC) when the revert token expires.
+	// required: true
+	RevertExpiresAt time.Time `json:"revert_expires_at" faker:"time_type" db:"revert_expires_at"`
+
+	// IssuedAt is the time (UTC) when the address change was requested.
+	// required: true
+	IssuedAt time.Time `json:"issued_at" faker:"time_type" db:"issued_at"`
+
+	// CreatedAt is a helper struct field for gobuffalo.pop.
+	CreatedAt time.Time `json:"-" faker:"-" db:"created_at"`
+	// UpdatedAt is a helper struct field for gobuffalo.pop.
+	UpdatedAt time.Time `json:"-" faker:"-" db:"updated_at"`
+	// IdentityID is a helper struct field for gobuffalo.pop.
+	IdentityID uuid.UUID `json:"identity_id" faker:"-" db:"identity_id"`
+	// FlowID is a helper struct field for gobuffalo.pop.
+	FlowID uuid.UUID `json:"-" faker:"-" db:"selfservice_verification_flow_id"`
+	NID    uuid.UUID `json:"-" faker:"-" db:"nid"`
+}
+
+func (AddressChange) TableName(ctx context.Context) string {
+	return "identity_address_changes"
+}
+
+func NewAddressChange(i *identity.Identity, previous identity.Traits, address string, f *verification.Flow, expiresIn, revertExpiresIn time.Duration) *AddressChange {
+	now := time.Now().UTC()
+	return &AddressChange{
+		ID:              x.NewUUID(),
+		ConfirmToken:    randx.MustString(32, randx.AlphaNum),
+		RevertToken:     randx.MustString(32, randx.AlphaNum),
+		Address:         address,
+		Traits:          i.Traits,
+		PreviousTraits:  previous,
+		State:           AddressChangeStatePending,
+		ExpiresAt:       now.Add(expiresIn),
+		RevertExpiresAt: now.Add(revertExpiresIn),
+		IssuedAt:        now,
+		IdentityID:      i.ID,
+		FlowID:          f.ID,
+	}
+}
+
+// Valid returns an error if the address change can no longer be confirmed.
+func (c *AddressChange) Valid() error {
+	if c.ExpiresAt.Before(time.Now().UTC()) {
+		return errors.WithStack(flow.NewFlowExpiredError(c.ExpiresAt))
+	}
+	return nil
+}
+
+// Revertible returns true if the previous address can still revert the address change.
+func (c *AddressChange) Revertible() bool {
+	return c.State != AddressChangeStateReverted && c.RevertExpiresAt.After(time.Now().UTC())
+}
+
+// addressPaths returns the paths of the traits which were changed to the new address.
+func (c *AddressChange) addressPaths() []string {
+	previous := jsonx.Flatten(json.RawMessage(c.PreviousTraits))
+
+	var paths []string
+	for path, value := range jsonx.Flatten(json.RawMessage(c.Traits)) {
+		if v, ok := value.(string); !ok || !strings.EqualFold(v, c.Address) {
+			continue
+		}
+		if v, ok := previous[path].(string); ok && strings.EqualFold(v, c.Address) {
+			continue
+		}
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// Apply sets the new address in the given traits. All other traits are left untouched because they
+// might have changed since the address change was requested.
+func (c *AddressChange) Apply(traits identity.Traits) (identity.Traits, error) {
+	for _, path := range c.addressPaths() {
+		updated, err := sjson.SetBytes(traits, path, gjson.GetBytes(c.Traits, path).Value())
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		traits = updated
+	}
+	return traits, nil
+}
+
+// Revert restores the previous addresses in the given traits. All other traits are left untouched, as
+// are addresses which have been changed again since.
+func (c *AddressChange) Revert(traits identity.Traits) (identity.Traits, error) {
+	for _, path := range c.addressPaths() {
+		if !strings.EqualFold(gjson.GetBytes(traits, path).String(), c.Address) {
+			continue
+		}
+
+		var updated []byte
+		var err error
+		if previous := gjson.GetBytes(c.PreviousTraits, path); previous.Exists() {
+			updated, err = sjson.SetBytes(traits, path, previous.Value())
+		} else {
+			updated, err = sjson.DeleteBytes(traits, path)
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		traits = updated
+	}
+	return traits, nil
+}
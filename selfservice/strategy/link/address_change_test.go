@@ -0,0 +1,39 @@
+package link_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/strategy/link"
+)
+
+func TestAddressChange(t *testing.T) {
+	change := &link.AddressChange{
+		Address:        "new@ory.sh",
+		PreviousTraits: identity.Traits(`{"email":"old@ory.sh","name":"old"}`),
+		Traits:         identity.Traits(`{"email":"new@ory.sh","recovery":["new@ory.sh"],"name":"new"}`),
+	}
+
+	t.Run("method=Apply", func(t *testing.T) {
+		actual, err := change.Apply(identity.Traits(`{"email":"old@ory.sh","name":"changed since"}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"email":"new@ory.sh","recovery":["new@ory.sh"],"name":"changed since"}`, string(actual))
+	})
+
+	t.Run("method=Revert", func(t *testing.T) {
+		t.Run("case=restores only the address", func(t *testing.T) {
+			actual, err := change.Revert(identity.Traits(`{"email":"new@ory.sh","recovery":["new@ory.sh"],"name":"changed since"}`))
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"email":"old@ory.sh","recovery":[],"name":"changed since"}`, string(actual))
+		})
+
+		t.Run("case=keeps addresses which were changed again", func(t *testing.T) {
+			actual, err := change.Revert(identity.Traits(`{"email":"newer@ory.sh","name":"new"}`))
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"email":"newer@ory.sh","name":"new"}`, string(actual))
+		})
+	})
+}
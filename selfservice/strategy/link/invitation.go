@@ -0,0 +1,96 @@
+package link
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/x"
+	"github.com/ory/x/randx"
+)
+
+// InvitationState represents the state of an invitation.
+type InvitationState string
+
+const (
+	// InvitationStatePending is the state of an invitation which has not been accepted yet.
+	InvitationStatePending InvitationState = "pending"
+
+	// InvitationStateAccepted is the state of an invitation which has been accepted by the invited address.
+	InvitationStateAccepted InvitationState = "accepted"
+)
+
+// Invitation is sent by an administrator to the address of an identity which was created on behalf
+// of the invited user. Accepting it signs the user in so that they are able to set up their credentials.
+//
+// swagger:model selfServiceInvitation
+type Invitation struct {
+	// ID represents the invitation's unique ID.
+	//
+	// required: true
+	// type: string
+	// format: uuid
+	ID uuid.UUID `json:"id" db:"id" faker:"-"`
+
+	// Token is sent to the invited address. It can not be longer than 64 chars!
+	Token string `json:"-" db:"token"`
+
+	// Address is the address the invitation was sent to.
+	//
+	// required: true
+	Address string `json:"address" db:"address"`
+
+	// State is the state of the invitation.
+	//
+	// required: true
+	State InvitationState `json:"state" db:"state"`
+
+	// ExpiresAt is the time (UTC) when the invitation expires.
+	// required: true
+	ExpiresAt time.Time `json:"expires_at" faker:"time_type" db:"expires_at"`
+
+	// IssuedAt is the time (UTC) when the invitation was issued.
+	// required: true
+	IssuedAt time.Time `json:"issued_at" faker:"time_type" db:"issued_at"`
+
+	// CreatedAt is a helper struct field for gobuffalo.pop.
+	CreatedAt time.Time `json:"-" faker:"-" db:"created_at"`
+	// UpdatedAt is a helper struct field for gobuffalo.pop.
+	UpdatedAt time.Time `json:"-" faker:"-" db:"updated_at"`
+	// IdentityID is the ID of the invited identity.
+	//
+	// required: true
+	// type: string
+	// format: uuid
+	IdentityID uuid.UUID `json:"identity_id" faker:"-" db:"identity_id"`
+	NID        uuid.UUID `json:"-" faker:"-" db:"nid"`
+}
+
+func (Invitation) TableName(ctx context.Context) string {
+	return "identity_invitations"
+}
+
+func NewInvitation(i *identity.Identity, address string, expiresIn time.Duration) *Invitation {
+	now := time.Now().UTC()
+	return &Invitation{
+		ID:         x.NewUUID(),
+		Token:      randx.MustString(32, randx.AlphaNum),
+		Address:    address,
+		State:      InvitationStatePending,
+		ExpiresAt:  now.Add(expiresIn),
+		IssuedAt:   now,
+		IdentityID: i.ID,
+	}
+}
+
+// Valid returns an error if the invitation can no longer be accepted.
+func (i *Invitation) Valid() error {
+	if i.ExpiresAt.Before(time.Now().UTC()) {
+		return errors.WithStack(flow.NewFlowExpiredError(i.ExpiresAt))
+	}
+	return nil
+}
@@ -26,4 +26,27 @@ type (
 	VerificationTokenPersistenceProvider interface {
 		VerificationTokenPersister() VerificationTokenPersister
 	}
+
+	AddressChangePersister interface {
+		CreateAddressChange(ctx context.Context, change *AddressChange) error
+		GetPendingAddressChange(ctx context.Context, fID uuid.UUID, token string) (*AddressChange, error)
+		GetAddressChangeByRevertToken(ctx context.Context, token string) (*AddressChange, error)
+		UpdateAddressChangeState(ctx context.Context, id uuid.UUID, from, to AddressChangeState) error
+		DeletePendingAddressChanges(ctx context.Context, identityID uuid.UUID) error
+	}
+
+	AddressChangePersistenceProvider interface {
+		AddressChangePersister() AddressChangePersister
+	}
+
+	InvitationPersister interface {
+		CreateInvitation(ctx context.Context, invitation *Invitation) error
+		GetInvitationByToken(ctx context.Context, token string) (*Invitation, error)
+		DeletePendingInvitations(ctx context.Context, identityID uuid.UUID) error
+		UseInvitation(ctx context.Context, token string) (*Invitation, error)
+	}
+
+	InvitationPersistenceProvider interface {
+		InvitationPersister() InvitationPersister
+	}
 )
@@ -9,3 +9,6 @@ var recoveryMethodSchema []byte
 
 //go:embed .schema/verification.schema.json
 var verificationMethodSchema []byte
+
+//go:embed .schema/confirmation.schema.json
+var confirmationSchema []byte
@@ -185,6 +185,64 @@ func (s *Sender) SendVerificationTokenTo(ctx context.Context, f *verification.Fl
 	return nil
 }
 
+// SendAddressChangeTo sends the confirmation link of an address change to the new address and a notification with a
+// link to revert the change to the previous addresses.
+func (s *Sender) SendAddressChangeTo(ctx context.Context, f *verification.Flow, i *identity.Identity, change *AddressChange, previous []string) error {
+	s.r.Audit().
+		WithField("identity_id", change.IdentityID).
+		WithField("address_change_id", change.ID).
+		WithSensitiveField("email_address", change.Address).
+		WithSensitiveField("address_change_token", change.ConfirmToken).
+		Info("Sending out address change confirmation and notification emails.")
+
+	model, err := x.StructToMap(i)
+	if err != nil {
+		return err
+	}
+
+	if err := s.send(ctx, identity.AddressTypeEmail, email.NewAddressChangeConfirm(s.r,
+		&email.AddressChangeConfirmModel{To: change.Address, VerificationURL: urlx.CopyWithQuery(
+			urlx.AppendPaths(s.r.Config().SelfServiceLinkMethodBaseURL(ctx), verification.RouteSubmitFlow),
+			url.Values{
+				"flow":  {f.ID.String()},
+				"token": {change.ConfirmToken},
+			}).String(), Identity: model})); err != nil {
+		return err
+	}
+
+	revertURL := urlx.CopyWithQuery(
+		urlx.AppendPaths(s.r.Config().SelfServiceLinkMethodBaseURL(ctx), RouteRevertAddressChange),
+		url.Values{"token": {change.RevertToken}}).String()
+	for _, to := range previous {
+		if err := s.send(ctx, identity.AddressTypeEmail, email.NewAddressChangeNotice(s.r,
+			&email.AddressChangeNoticeModel{To: to, NewAddress: change.Address, RevertURL: revertURL, Identity: model})); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SendInvitation sends the link which accepts an invitation to the invited address.
+func (s *Sender) SendInvitation(ctx context.Context, i *identity.Identity, invitation *Invitation) error {
+	s.r.Audit().
+		WithField("identity_id", invitation.IdentityID).
+		WithField("invitation_id", invitation.ID).
+		WithSensitiveField("email_address", invitation.Address).
+		WithSensitiveField("invitation_token", invitation.Token).
+		Info("Sending out invitation email.")
+
+	model, err := x.StructToMap(i)
+	if err != nil {
+		return err
+	}
+
+	return s.send(ctx, identity.AddressTypeEmail, email.NewInvitation(s.r,
+		&email.InvitationModel{To: invitation.Address, InvitationURL: urlx.CopyWithQuery(
+			urlx.AppendPaths(s.r.Config().SelfServiceLinkMethodBaseURL(ctx), RouteAcceptInvitation),
+			url.Values{"token": {invitation.Token}}).String(), Identity: model}))
+}
+
 func (s *Sender) send(ctx context.Context, via string, t courier.EmailTemplate) error {
 	switch via {
 	case identity.AddressTypeEmail:
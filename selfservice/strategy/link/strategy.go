@@ -7,6 +7,7 @@ import (
 	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/selfservice/errorx"
 	"github.com/ory/kratos/selfservice/flow/recovery"
+	"github.com/ory/kratos/selfservice/flow/registration"
 	"github.com/ory/kratos/selfservice/flow/settings"
 	"github.com/ory/kratos/selfservice/flow/verification"
 	"github.com/ory/kratos/session"
@@ -35,11 +36,17 @@ type (
 		x.CSRFTokenGeneratorProvider
 		x.WriterProvider
 		x.LoggingProvider
+		x.TransactionalPersisterProvider
 
 		config.Provider
 
 		session.HandlerProvider
 		session.ManagementProvider
+		session.PersistenceProvider
+
+		registration.HooksProvider
+		registration.FlowPersistenceProvider
+
 		settings.HandlerProvider
 		settings.FlowPersistenceProvider
 
@@ -65,6 +72,8 @@ type (
 
 		RecoveryTokenPersistenceProvider
 		VerificationTokenPersistenceProvider
+		AddressChangePersistenceProvider
+		InvitationPersistenceProvider
 		SenderProvider
 
 		schema.IdentityTraitsProvider
@@ -0,0 +1,188 @@
+package link
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/sqlcon"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/verification"
+	"github.com/ory/kratos/text"
+)
+
+const (
+	RouteRevertAddressChange = "/self-service/verification/address-change/revert"
+)
+
+// verificationUseAddressChangeToken applies an address change which was held back by the profile settings
+// method once the new address has been confirmed using the verification link.
+func (s *Strategy) verificationUseAddressChangeToken(w http.ResponseWriter, r *http.Request, body *verificationSubmitPayload, f *verification.Flow) error {
+	ctx := r.Context()
+	change, err := s.d.AddressChangePersister().GetPendingAddressChange(ctx, f.ID, body.Token)
+	if err != nil {
+		if errors.Is(err, sqlcon.ErrNoRows) {
+			return s.retryVerificationFlowWithMessage(w, r, flow.TypeBrowser, text.NewErrorValidationVerificationTokenInvalidOrAlreadyUsed())
+		}
+
+		return s.retryVerificationFlowWithError(w, r, flow.TypeBrowser, err)
+	}
+
+	if err := change.Valid(); err != nil {
+		return s.retryVerificationFlowWithError(w, r, flow.TypeBrowser, err)
+	}
+
+	var i *identity.Identity
+	if err := s.d.TransactionalPersisterProvider().Transaction(ctx, func(ctx context.Context, _ *pop.Connection) error {
+		// The state is only updated if nobody else confirmed or reverted the change in the meantime.
+		if err := s.d.AddressChangePersister().UpdateAddressChangeState(ctx, change.ID, AddressChangeStatePending, AddressChangeStateApplied); err != nil {
+			return err
+		}
+
+		original, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, change.IdentityID)
+		if err != nil {
+			return err
+		}
+
+		traits, err := change.Apply(original.Traits)
+		if err != nil {
+			return err
+		}
+
+		if err := s.d.IdentityManager().UpdateTraits(ctx, original.ID, traits, identity.ManagerAllowWriteProtectedTraits); err != nil {
+			return err
+		}
+
+		// The addresses are extracted from the traits, so the identity has to be loaded again.
+		i, err = s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, change.IdentityID)
+		return err
+	}); errors.Is(err, sqlcon.ErrNoRows) {
+		return s.retryVerificationFlowWithMessage(w, r, flow.TypeBrowser, text.NewErrorValidationVerificationTokenInvalidOrAlreadyUsed())
+	} else if err != nil {
+		return s.retryVerificationFlowWithError(w, r, flow.TypeBrowser, err)
+	}
+
+	s.d.Audit().
+		WithRequest(r).
+		WithField("identity_id", change.IdentityID).
+		WithField("address_change_id", change.ID).
+		WithSensitiveField("email_address", change.Address).
+		Info("An address change was confirmed and applied.")
+
+	for k := range i.VerifiableAddresses {
+		if address := &i.VerifiableAddresses[k]; strings.EqualFold(address.Value, change.Address) {
+			return s.verificationCompleted(w, r, f, i.ID, address)
+		}
+	}
+
+	// The new address is only used for recovery, so there is no verifiable address to mark as verified.
+	return s.verificationCompleted(w, r, f, i.ID, nil)
+}
+
+// Confirm Reverting an Address Change
+//
+// This endpoint is linked in the notification which is sent to the previous addresses of an identity
+// when its identifier or recovery address is changed. Following the link does not change anything.
+// Instead, the browser is sent to a verification flow which asks the user to confirm the revert.
+func (s *Strategy) initRevertAddressChange(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ctx := r.Context()
+	token := r.URL.Query().Get("token")
+	change, err := s.d.AddressChangePersister().GetAddressChangeByRevertToken(ctx, token)
+	if errors.Is(err, sqlcon.ErrNoRows) || (err == nil && !change.Revertible()) {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, errAddressChangeRevertInvalid())
+		return
+	} else if err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	f, err := s.newVerificationConfirmationFlow(r, RouteRevertAddressChange, token,
+		text.NewInfoSelfServiceVerificationRevertAddressChange(change.Address), text.NewInfoNodeLabelRevertAddressChange())
+	if err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, f.AppendTo(s.d.Config().SelfServiceFlowVerificationUI(ctx)).String(), http.StatusSeeOther)
+}
+
+// Revert an Address Change
+//
+// This endpoint is called by the confirmation form of initRevertAddressChange. It restores the previous
+// addresses and revokes all sessions of the identity.
+func (s *Strategy) revertAddressChange(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ctx := r.Context()
+	body, err := s.decodeConfirmation(r)
+	if err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	if err := flow.EnsureCSRF(s.d, r, flow.TypeBrowser, false, s.d.GenerateCSRFToken, body.CSRFToken); err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	change, err := s.d.AddressChangePersister().GetAddressChangeByRevertToken(ctx, body.Token)
+	if errors.Is(err, sqlcon.ErrNoRows) || (err == nil && !change.Revertible()) {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, errAddressChangeRevertInvalid())
+		return
+	} else if err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	if err := s.d.TransactionalPersisterProvider().Transaction(ctx, func(ctx context.Context, _ *pop.Connection) error {
+		// The state is only updated if nobody else confirmed or reverted the change in the meantime.
+		if err := s.d.AddressChangePersister().UpdateAddressChangeState(ctx, change.ID, change.State, AddressChangeStateReverted); err != nil {
+			return err
+		}
+
+		if change.State == AddressChangeStateApplied {
+			i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, change.IdentityID)
+			if err != nil {
+				return err
+			}
+
+			traits, err := change.Revert(i.Traits)
+			if err != nil {
+				return err
+			}
+
+			if err := s.d.IdentityManager().UpdateTraits(ctx, i.ID, traits, identity.ManagerAllowWriteProtectedTraits); err != nil {
+				return err
+			}
+		}
+
+		if err := s.d.SessionPersister().DeleteSessionsByIdentity(ctx, change.IdentityID); err != nil && !errors.Is(err, sqlcon.ErrNoRows) {
+			return err
+		}
+
+		return nil
+	}); errors.Is(err, sqlcon.ErrNoRows) {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, errors.WithStack(herodot.ErrConflict.WithReason("The address change was confirmed or reverted in the meantime. Please try again.")))
+		return
+	} else if err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	s.d.Audit().
+		WithRequest(r).
+		WithField("identity_id", change.IdentityID).
+		WithField("address_change_id", change.ID).
+		Info("An address change was reverted by a previous address and all sessions were revoked.")
+
+	http.Redirect(w, r, s.d.Config().SelfServiceBrowserDefaultReturnTo(ctx).String(), http.StatusSeeOther)
+}
+
+func errAddressChangeRevertInvalid() error {
+	return errors.WithStack(herodot.ErrNotFound.WithReason("The address change revert link is invalid, expired, or has already been used."))
+}
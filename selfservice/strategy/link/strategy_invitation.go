@@ -0,0 +1,468 @@
+package link
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/decoderx"
+	"github.com/ory/x/sqlcon"
+	"github.com/ory/x/sqlxx"
+	"github.com/ory/x/urlx"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/registration"
+	"github.com/ory/kratos/selfservice/strategy"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/x"
+)
+
+const (
+	RouteAdminCreateInvitation = "/invitations"
+	RouteAdminResendInvitation = "/invitations/resend"
+	RouteAcceptInvitation      = "/self-service/invitation/accept"
+)
+
+func (s *Strategy) registerPublicInvitationRoutes(public *x.RouterPublic) {
+	s.d.CSRFHandler().IgnorePath(RouteAdminCreateInvitation)
+	s.d.CSRFHandler().IgnorePath(RouteAdminResendInvitation)
+	public.POST(RouteAdminCreateInvitation, x.RedirectToAdminRoute(s.d))
+	public.POST(RouteAdminResendInvitation, x.RedirectToAdminRoute(s.d))
+	public.GET(RouteAcceptInvitation, strategy.IsDisabled(s.d, s.VerificationStrategyID(), s.initAcceptInvitation))
+	public.POST(RouteAcceptInvitation, strategy.IsDisabled(s.d, s.VerificationStrategyID(), s.acceptInvitation))
+}
+
+func (s *Strategy) registerAdminInvitationRoutes(admin *x.RouterAdmin) {
+	admin.POST(RouteAdminCreateInvitation, strategy.IsDisabled(s.d, s.VerificationStrategyID(), s.createInvitation))
+	admin.POST(RouteAdminResendInvitation, strategy.IsDisabled(s.d, s.VerificationStrategyID(), s.resendInvitation))
+}
+
+// swagger:parameters adminCreateSelfServiceInvitation
+//
+// nolint
+type adminCreateSelfServiceInvitation struct {
+	// in: body
+	Body adminCreateSelfServiceInvitationBody
+}
+
+// swagger:model adminCreateSelfServiceInvitationBody
+type adminCreateSelfServiceInvitationBody struct {
+	// SchemaID is the ID of the JSON Schema to be used for validating the invited identity's traits.
+	//
+	// Defaults to the default identity schema.
+	SchemaID string `json:"schema_id"`
+
+	// Traits represent the invited identity's traits. They must contain an email address which is
+	// used as a verifiable or recovery address, because the invitation is sent to it.
+	//
+	// required: true
+	Traits json.RawMessage `json:"traits"`
+
+	// Invitation Expires In
+	//
+	// The invitation will expire at that point in time. Defaults to the configuration value of
+	// `selfservice.methods.link.config.invitation_lifespan`.
+	//
+	// pattern: ^[0-9]+(ns|us|ms|s|m|h)$
+	// example:
+	//	- 168h
+	//	- 24h
+	ExpiresIn string `json:"expires_in"`
+}
+
+// swagger:route POST /admin/invitations v0alpha2 adminCreateSelfServiceInvitation
+//
+// # Invite a User
+//
+// This endpoint creates an identity without any credentials on behalf of the invited user and sends an
+// invitation link to the identity's email address. Accepting the invitation signs the user in and sends
+// them to the settings flow, where they set up their credentials.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  201: selfServiceInvitation
+//	  400: jsonError
+//	  404: jsonError
+//	  409: jsonError
+//	  500: jsonError
+func (s *Strategy) createInvitation(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ctx := r.Context()
+
+	var p adminCreateSelfServiceInvitationBody
+	if err := s.dx.Decode(r, &p, decoderx.HTTPJSONDecoder()); err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	expiresIn, err := s.invitationLifespan(ctx, p.ExpiresIn)
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	schemaID := p.SchemaID
+	if schemaID == "" {
+		schemaID = s.d.Config().DefaultIdentityTraitsSchemaID(ctx)
+	}
+
+	i := identity.NewIdentity(schemaID)
+	i.Traits = identity.Traits(p.Traits)
+
+	// Validating the identity extracts its addresses, which tells us where to send the invitation to.
+	if err := s.d.IdentityValidator().Validate(ctx, i); err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	address := invitationAddress(i)
+	if address == "" {
+		s.d.Writer().WriteError(w, r, errInvitationAddressMissing())
+		return
+	}
+
+	invitation := NewInvitation(i, address, expiresIn)
+	if err := s.d.TransactionalPersisterProvider().Transaction(ctx, func(ctx context.Context, _ *pop.Connection) error {
+		if err := s.d.IdentityManager().Create(ctx, i); err != nil {
+			return err
+		}
+
+		if err := s.d.InvitationPersister().CreateInvitation(ctx, invitation); err != nil {
+			return err
+		}
+
+		return s.d.LinkSender().SendInvitation(ctx, i, invitation)
+	}); errors.Is(err, sqlcon.ErrUniqueViolation) {
+		s.d.Writer().WriteError(w, r, errors.WithStack(herodot.ErrConflict.
+			WithReasonf("An identity with these traits exists already. If it has not accepted its invitation yet, use %s to send a new one.", urlx.AppendPaths(s.d.Config().SelfAdminURL(ctx), RouteAdminResendInvitation))))
+		return
+	} else if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	s.d.Audit().
+		WithRequest(r).
+		WithField("identity_id", i.ID).
+		WithField("invitation_id", invitation.ID).
+		Info("An identity has been invited.")
+
+	s.d.Writer().WriteCreated(w, r,
+		urlx.AppendPaths(s.d.Config().SelfAdminURL(ctx), "identities", i.ID.String()).String(),
+		invitation,
+	)
+}
+
+// swagger:parameters adminResendSelfServiceInvitation
+//
+// nolint
+type adminResendSelfServiceInvitation struct {
+	// in: body
+	Body adminResendSelfServiceInvitationBody
+}
+
+// swagger:model adminResendSelfServiceInvitationBody
+type adminResendSelfServiceInvitationBody struct {
+	// IdentityID is the ID of the invited identity.
+	//
+	// required: true
+	// type: string
+	// format: uuid
+	IdentityID uuid.UUID `json:"identity_id"`
+
+	// Invitation Expires In
+	//
+	// The invitation will expire at that point in time. Defaults to the configuration value of
+	// `selfservice.methods.link.config.invitation_lifespan`.
+	//
+	// pattern: ^[0-9]+(ns|us|ms|s|m|h)$
+	// example:
+	//	- 168h
+	//	- 24h
+	ExpiresIn string `json:"expires_in"`
+}
+
+// swagger:route POST /admin/invitations/resend v0alpha2 adminResendSelfServiceInvitation
+//
+// # Resend an Invitation
+//
+// This endpoint sends a new invitation link to an identity which has not accepted its invitation yet, for
+// example because the invitation expired or the email got lost. Previously sent invitation links stop working.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  201: selfServiceInvitation
+//	  400: jsonError
+//	  404: jsonError
+//	  500: jsonError
+func (s *Strategy) resendInvitation(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ctx := r.Context()
+
+	var p adminResendSelfServiceInvitationBody
+	if err := s.dx.Decode(r, &p, decoderx.HTTPJSONDecoder()); err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	expiresIn, err := s.invitationLifespan(ctx, p.ExpiresIn)
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, p.IdentityID)
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	address := invitationAddress(i)
+	if address == "" {
+		s.d.Writer().WriteError(w, r, errInvitationAddressMissing())
+		return
+	}
+
+	invitation := NewInvitation(i, address, expiresIn)
+	if err := s.d.TransactionalPersisterProvider().Transaction(ctx, func(ctx context.Context, _ *pop.Connection) error {
+		if err := s.d.InvitationPersister().DeletePendingInvitations(ctx, i.ID); err != nil {
+			return err
+		}
+
+		if err := s.d.InvitationPersister().CreateInvitation(ctx, invitation); err != nil {
+			return err
+		}
+
+		return s.d.LinkSender().SendInvitation(ctx, i, invitation)
+	}); errors.Is(err, sqlcon.ErrNoRows) {
+		s.d.Writer().WriteError(w, r, errors.WithStack(herodot.ErrNotFound.WithReason("The identity has no pending invitation. It was either not invited or accepted its invitation already.")))
+		return
+	} else if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	s.d.Audit().
+		WithRequest(r).
+		WithField("identity_id", i.ID).
+		WithField("invitation_id", invitation.ID).
+		Info("An invitation has been sent again.")
+
+	s.d.Writer().WriteCreated(w, r,
+		urlx.AppendPaths(s.d.Config().SelfAdminURL(ctx), "identities", i.ID.String()).String(),
+		invitation,
+	)
+}
+
+// Initialize Accepting an Invitation
+//
+// This endpoint is linked in the invitation email. Following the link does not accept the invitation, because
+// email scanners follow links too. Instead, the browser is sent to a verification flow whose form confirms it.
+func (s *Strategy) initAcceptInvitation(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ctx := r.Context()
+	token := r.URL.Query().Get("token")
+	invitation, err := s.d.InvitationPersister().GetInvitationByToken(ctx, token)
+	if errors.Is(err, sqlcon.ErrNoRows) || (err == nil && invitation.Valid() != nil) {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, errInvitationInvalid())
+		return
+	} else if err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	f, err := s.newVerificationConfirmationFlow(r, RouteAcceptInvitation, token,
+		text.NewInfoSelfServiceVerificationAcceptInvitation(invitation.Address), text.NewInfoNodeLabelAcceptInvitation())
+	if err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, f.AppendTo(s.d.Config().SelfServiceFlowVerificationUI(ctx)).String(), http.StatusSeeOther)
+}
+
+// Accept an Invitation
+//
+// This endpoint is called by the confirmation form of initAcceptInvitation. It signs the invited identity in,
+// marks the invited address as verified, and sends the browser to the settings flow where the user sets up
+// their credentials.
+//
+// Invited identities are not created by a registration flow, so the registration hooks run here instead, on
+// behalf of a registration flow which is created for this purpose. There are no method specific registration
+// hooks for invitations, which is why the global ones apply. Like in a registration flow, the hooks run once
+// the invitation was accepted and the user was signed in, so a failing hook does not lock the user out.
+func (s *Strategy) acceptInvitation(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ctx := r.Context()
+	body, err := s.decodeConfirmation(r)
+	if err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	if err := flow.EnsureCSRF(s.d, r, flow.TypeBrowser, false, s.d.GenerateCSRFToken, body.CSRFToken); err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	var invitation *Invitation
+	var i *identity.Identity
+	if err := s.d.TransactionalPersisterProvider().Transaction(ctx, func(ctx context.Context, _ *pop.Connection) (err error) {
+		invitation, err = s.d.InvitationPersister().UseInvitation(ctx, body.Token)
+		if err != nil {
+			return err
+		} else if invitation.Valid() != nil {
+			return errInvitationInvalid()
+		}
+
+		i, err = s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, invitation.IdentityID)
+		if err != nil {
+			return err
+		}
+
+		// The invitation was delivered to the address, which proves that the user has access to it.
+		for k := range i.VerifiableAddresses {
+			if address := &i.VerifiableAddresses[k]; strings.EqualFold(address.Value, invitation.Address) && !address.Verified {
+				verifiedAt := sqlxx.NullTime(time.Now().UTC())
+				address.Verified = true
+				address.VerifiedAt = &verifiedAt
+				address.Status = identity.VerifiableAddressStatusCompleted
+				if err := s.d.PrivilegedIdentityPool().UpdateVerifiableAddress(ctx, address); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}); errors.Is(err, sqlcon.ErrNoRows) {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, errInvitationInvalid())
+		return
+	} else if err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	s.d.Audit().
+		WithRequest(r).
+		WithField("identity_id", i.ID).
+		WithField("invitation_id", invitation.ID).
+		Info("An invitation was accepted.")
+
+	// Like a recovery link, the invitation link is the proof of possession of the invited address.
+	sess, err := session.NewActiveSession(ctx, i, s.d.Config(), time.Now().UTC(), identity.CredentialsTypeRecoveryLink, identity.AuthenticatorAssuranceLevel1)
+	if err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	if sess.MFAEnrollmentRequired, err = s.d.SessionManager().RequiresMFAEnrollment(ctx, i); err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	if err := s.d.SessionManager().UpsertAndIssueCookie(ctx, w, r, sess); err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	rf, err := registration.NewFlow(s.d.Config(), s.d.Config().SelfServiceFlowRegistrationRequestLifespan(ctx), s.d.GenerateCSRFToken(r), r, flow.TypeBrowser)
+	if err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	rf.Active = identity.CredentialsTypeRecoveryLink
+	if err := s.d.RegistrationFlowPersister().CreateRegistrationFlow(ctx, rf); err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	for _, executor := range s.d.PostRegistrationPostPersistHooks(ctx, identity.CredentialsTypeRecoveryLink) {
+		if err := executor.ExecutePostRegistrationPostPersistHook(w, r, rf, sess); errors.Is(err, registration.ErrHookAbortFlow) {
+			return
+		} else if err != nil {
+			s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+			return
+		}
+	}
+
+	sf, err := s.d.SettingsHandler().NewFlow(w, r, sess.Identity, flow.TypeBrowser)
+	if err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	sf.UI.Messages.Set(text.NewInfoSelfServiceSettingsInvitationAccepted())
+	if err := s.d.SettingsFlowPersister().UpdateSettingsFlow(ctx, sf); err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, sf.AppendTo(s.d.Config().SelfServiceFlowSettingsUI(ctx)).String(), http.StatusSeeOther)
+}
+
+// invitationLifespan parses the optional "expires_in" value of an admin request.
+func (s *Strategy) invitationLifespan(ctx context.Context, expiresIn string) (time.Duration, error) {
+	if len(expiresIn) == 0 {
+		return s.d.Config().SelfServiceLinkMethodInvitationLifespan(ctx), nil
+	}
+
+	lifespan, err := time.ParseDuration(expiresIn)
+	if err != nil {
+		return 0, errors.WithStack(herodot.ErrBadRequest.WithReasonf(`Unable to parse "expires_in" whose format should match "[0-9]+(ns|us|ms|s|m|h)" but did not: %s`, expiresIn))
+	}
+
+	if time.Now().Add(lifespan).Before(time.Now()) {
+		return 0, errors.WithStack(herodot.ErrBadRequest.WithReasonf(`Value from "expires_in" must be result to a future time: %s`, expiresIn))
+	}
+
+	return lifespan, nil
+}
+
+func errInvitationInvalid() error {
+	return errors.WithStack(herodot.ErrNotFound.WithReason("The invitation link is invalid, expired, or has already been used."))
+}
+
+func errInvitationAddressMissing() error {
+	return errors.WithStack(herodot.ErrBadRequest.WithReason("The invited identity does not have an email address which is used for verification or recovery, so the invitation can not be sent."))
+}
+
+func invitationAddress(i *identity.Identity) string {
+	for _, a := range i.VerifiableAddresses {
+		if a.Via == identity.AddressTypeEmail {
+			return a.Value
+		}
+	}
+	for _, a := range i.RecoveryAddresses {
+		if a.Via == identity.AddressTypeEmail {
+			return a.Value
+		}
+	}
+	return ""
+}
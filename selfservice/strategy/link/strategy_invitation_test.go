@@ -0,0 +1,294 @@
+package link_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/x/ioutilx"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/flow/verification"
+	"github.com/ory/kratos/selfservice/strategy/link"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/kratos/x"
+)
+
+func TestInvitation(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	initViper(t, conf)
+
+	_ = testhelpers.NewSettingsUIFlowEchoServer(t, reg)
+	_ = testhelpers.NewLoginUIFlowEchoServer(t, reg)
+	_ = testhelpers.NewErrorTestServer(t, reg)
+
+	publicTS, adminTS := testhelpers.NewKratosServer(t, reg)
+
+	adminPost := func(t *testing.T, route, body string, expectCode int) gjson.Result {
+		t.Helper()
+		res, err := adminTS.Client().Post(adminTS.URL+x.AdminPrefix+route, "application/json", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		payload := ioutilx.MustReadAll(res.Body)
+		require.Equal(t, expectCode, res.StatusCode, "%s", payload)
+		return gjson.ParseBytes(payload)
+	}
+
+	invite := func(t *testing.T, body string, expectCode int) gjson.Result {
+		t.Helper()
+		return adminPost(t, link.RouteAdminCreateInvitation, body, expectCode)
+	}
+
+	resend := func(t *testing.T, identityID string, expectCode int) gjson.Result {
+		t.Helper()
+		return adminPost(t, link.RouteAdminResendInvitation, `{"identity_id":"`+identityID+`"}`, expectCode)
+	}
+
+	// accept follows the invitation link, which renders a confirmation form, and submits that form.
+	accept := func(t *testing.T, cl *http.Client, invitationLink string) (*http.Response, []byte) {
+		t.Helper()
+		noRedirects := *cl
+		noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+		res, err := noRedirects.Get(invitationLink)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusSeeOther, res.StatusCode)
+
+		location, err := res.Location()
+		require.NoError(t, err)
+		f, err := reg.VerificationFlowPersister().GetVerificationFlow(ctx, uuid.FromStringOrNil(location.Query().Get("flow")))
+		require.NoError(t, err, "the link must render a confirmation form: %s", location)
+		require.Len(t, f.UI.Messages, 1)
+		assert.EqualValues(t, text.InfoSelfServiceVerificationAcceptInvitation, f.UI.Messages[0].ID)
+
+		values := url.Values{}
+		for _, n := range f.UI.Nodes {
+			if a, ok := n.Attributes.(*node.InputAttributes); ok {
+				values.Set(a.Name, fmt.Sprintf("%v", a.FieldValue))
+			}
+		}
+
+		res, err = cl.PostForm(f.UI.Action, values)
+		require.NoError(t, err)
+		body := ioutilx.MustReadAll(res.Body)
+		require.NoError(t, res.Body.Close())
+		return res, body
+	}
+
+	expectInvalid := func(t *testing.T, invitationLink string) {
+		t.Helper()
+		res, err := testhelpers.NewClientWithCookies(t).Get(invitationLink)
+		require.NoError(t, err)
+		body := ioutilx.MustReadAll(res.Body)
+		require.NoError(t, res.Body.Close())
+
+		assert.Contains(t, res.Request.URL.String(), conf.SelfServiceFlowErrorURL(ctx).String())
+		assert.EqualValues(t, http.StatusNotFound, gjson.GetBytes(body, "code").Int(), "%s", body)
+	}
+
+	t.Run("description=should invite a user who then sets up their credentials", func(t *testing.T) {
+		email := testhelpers.RandomEmail()
+		invitation := invite(t, `{"traits":{"email":"`+email+`"}}`, http.StatusCreated)
+		assert.Equal(t, email, invitation.Get("address").String(), "%s", invitation.Raw)
+		assert.EqualValues(t, link.InvitationStatePending, invitation.Get("state").String(), "%s", invitation.Raw)
+		assert.False(t, invitation.Get("token").Exists(), "%s", invitation.Raw)
+
+		i, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, x.ParseUUID(invitation.Get("identity_id").String()))
+		require.NoError(t, err)
+		assert.Equal(t, identity.StateActive, i.State)
+		c, ok := i.GetCredentials(identity.CredentialsTypePassword)
+		require.True(t, ok, "the identifier is extracted from the traits")
+		assert.False(t, gjson.GetBytes(c.Config, "hashed_password").Exists(), "but no password is set")
+
+		message := testhelpers.CourierExpectMessage(t, reg, email, "You have been invited to create an account")
+		invitationLink := testhelpers.CourierExpectLinkInMessage(t, message, 1)
+		assert.Contains(t, invitationLink, publicTS.URL+link.RouteAcceptInvitation)
+
+		t.Run("case=following the link does not accept the invitation", func(t *testing.T) {
+			cl := testhelpers.NewClientWithCookies(t)
+			cl.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+			res, err := cl.Get(invitationLink)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			address, err := reg.IdentityPool().FindVerifiableAddressByValue(ctx, identity.VerifiableAddressTypeEmail, email)
+			require.NoError(t, err)
+			assert.False(t, address.Verified)
+		})
+
+		cl := testhelpers.NewClientWithCookies(t)
+		res, body := accept(t, cl, invitationLink)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Contains(t, res.Request.URL.String(), conf.SelfServiceFlowSettingsUI(ctx).String())
+		assert.EqualValues(t, text.InfoSelfServiceSettingsInvitationAccepted, gjson.GetBytes(body, "ui.messages.0.id").Int(), "%s", body)
+		assert.Equal(t, i.ID.String(), gjson.GetBytes(body, "identity.id").String(), "%s", body)
+
+		address, err := reg.IdentityPool().FindVerifiableAddressByValue(ctx, identity.VerifiableAddressTypeEmail, email)
+		require.NoError(t, err)
+		assert.True(t, address.Verified)
+		assert.Equal(t, identity.VerifiableAddressStatusCompleted, address.Status)
+
+		res, err = cl.Get(publicTS.URL + session.RouteWhoami)
+		require.NoError(t, err)
+		body = ioutilx.MustReadAll(res.Body)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, i.ID.String(), gjson.GetBytes(body, "identity.id").String(), "%s", body)
+
+		t.Run("case=the invitation can only be accepted once", func(t *testing.T) {
+			expectInvalid(t, invitationLink)
+		})
+
+		t.Run("case=an accepted invitation can not be sent again", func(t *testing.T) {
+			res := resend(t, i.ID.String(), http.StatusNotFound)
+			assert.Contains(t, res.Get("error.reason").String(), "no pending invitation", "%s", res.Raw)
+		})
+	})
+
+	t.Run("description=should send a new invitation which replaces the previous one", func(t *testing.T) {
+		email := strings.ToLower(testhelpers.RandomEmail())
+		body := `{"traits":{"email":"` + email + `"}}`
+		invitation := invite(t, body, http.StatusCreated)
+		previousLink := testhelpers.CourierExpectLinkInMessage(t, testhelpers.CourierExpectMessage(t, reg, email, "You have been invited to create an account"), 1)
+
+		res := invite(t, body, http.StatusConflict)
+		assert.Contains(t, res.Get("error.reason").String(), link.RouteAdminResendInvitation, "%s", res.Raw)
+
+		resent := resend(t, invitation.Get("identity_id").String(), http.StatusCreated)
+		assert.Equal(t, invitation.Get("identity_id").String(), resent.Get("identity_id").String(), "%s", resent.Raw)
+		assert.NotEqual(t, invitation.Get("id").String(), resent.Get("id").String(), "%s", resent.Raw)
+
+		invitationLink := testhelpers.CourierExpectLinkInMessage(t, testhelpers.CourierExpectMessage(t, reg, email, "You have been invited to create an account"), 1)
+		assert.NotEqual(t, previousLink, invitationLink)
+		expectInvalid(t, previousLink)
+
+		res2, _ := accept(t, testhelpers.NewClientWithCookies(t), invitationLink)
+		assert.Contains(t, res2.Request.URL.String(), conf.SelfServiceFlowSettingsUI(ctx).String())
+
+		t.Run("case=unknown identity", func(t *testing.T) {
+			resend(t, x.NewUUID().String(), http.StatusNotFound)
+		})
+	})
+
+	// useWebHook configures a registration web hook which responds with the given status code and returns the
+	// identity and registration flow IDs it was called with.
+	useWebHook := func(t *testing.T, status int) *[][2]string {
+		var called [][2]string
+		hookTS := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body := ioutilx.MustReadAll(r.Body)
+			called = append(called, [2]string{gjson.GetBytes(body, "identity_id").String(), gjson.GetBytes(body, "flow_id").String()})
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(hookTS.Close)
+
+		template := "base64://" + base64.StdEncoding.EncodeToString([]byte(`function(ctx) { identity_id: ctx.identity.id, flow_id: ctx.flow.id }`))
+		conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationAfter+".hooks", []config.SelfServiceHook{
+			{Name: "web_hook", Config: json.RawMessage(`{"method":"POST","url":"` + hookTS.URL + `","body":"` + template + `"}`)},
+		})
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationAfter+".hooks", nil)
+		})
+		return &called
+	}
+
+	t.Run("description=should run the registration web hooks when the invitation is accepted", func(t *testing.T) {
+		called := useWebHook(t, http.StatusOK)
+
+		email := strings.ToLower(testhelpers.RandomEmail())
+		invitation := invite(t, `{"traits":{"email":"`+email+`"}}`, http.StatusCreated)
+		assert.Empty(t, *called, "the hooks run once the invited user shows up")
+
+		res, _ := accept(t, testhelpers.NewClientWithCookies(t), testhelpers.CourierExpectLinkInMessage(t, testhelpers.CourierExpectMessage(t, reg, email, "You have been invited to create an account"), 1))
+		assert.Contains(t, res.Request.URL.String(), conf.SelfServiceFlowSettingsUI(ctx).String())
+		require.Len(t, *called, 1)
+		assert.Equal(t, invitation.Get("identity_id").String(), (*called)[0][0])
+
+		rf, err := reg.RegistrationFlowPersister().GetRegistrationFlow(ctx, uuid.FromStringOrNil((*called)[0][1]))
+		require.NoError(t, err, "the hooks must be called with a persisted registration flow")
+		assert.Equal(t, identity.CredentialsTypeRecoveryLink, rf.Active)
+	})
+
+	t.Run("description=should accept the invitation even if a registration web hook fails", func(t *testing.T) {
+		called := useWebHook(t, http.StatusBadRequest)
+
+		email := strings.ToLower(testhelpers.RandomEmail())
+		invitation := invite(t, `{"traits":{"email":"`+email+`"}}`, http.StatusCreated)
+		invitationLink := testhelpers.CourierExpectLinkInMessage(t, testhelpers.CourierExpectMessage(t, reg, email, "You have been invited to create an account"), 1)
+
+		cl := testhelpers.NewClientWithCookies(t)
+		res, body := accept(t, cl, invitationLink)
+		assert.Contains(t, res.Request.URL.String(), conf.SelfServiceFlowErrorURL(ctx).String(), "%s", body)
+		assert.EqualValues(t, http.StatusInternalServerError, gjson.GetBytes(body, "code").Int(), "%s", body)
+		require.Len(t, *called, 1)
+
+		address, err := reg.IdentityPool().FindVerifiableAddressByValue(ctx, identity.VerifiableAddressTypeEmail, email)
+		require.NoError(t, err)
+		assert.True(t, address.Verified, "the identity is updated before the hooks run")
+		expectInvalid(t, invitationLink)
+
+		res, err = cl.Get(publicTS.URL + session.RouteWhoami)
+		require.NoError(t, err)
+		body = ioutilx.MustReadAll(res.Body)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, invitation.Get("identity_id").String(), gjson.GetBytes(body, "identity.id").String(), "the user is signed in and can set up their credentials: %s", body)
+	})
+
+	t.Run("description=should not accept an expired invitation", func(t *testing.T) {
+		email := testhelpers.RandomEmail()
+		invite(t, `{"traits":{"email":"`+email+`"},"expires_in":"100ms"}`, http.StatusCreated)
+		time.Sleep(time.Millisecond * 100)
+
+		expectInvalid(t, testhelpers.CourierExpectLinkInMessage(t, testhelpers.CourierExpectMessage(t, reg, email, "You have been invited to create an account"), 1))
+	})
+
+	t.Run("description=should reject invalid invitations", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			body   string
+			reason string
+		}{
+			"no email":           {body: `{"traits":{}}`, reason: "does not have an email address"},
+			"invalid expires_in": {body: `{"traits":{"email":"` + testhelpers.RandomEmail() + `"},"expires_in":"foo"}`, reason: "Unable to parse"},
+		} {
+			t.Run("case="+name, func(t *testing.T) {
+				res := invite(t, tc.body, http.StatusBadRequest)
+				assert.Contains(t, res.Get("error.reason").String(), tc.reason, "%s", res.Raw)
+			})
+		}
+
+		t.Run("case=duplicate identity", func(t *testing.T) {
+			body, err := json.Marshal(map[string]interface{}{"traits": map[string]string{"email": testhelpers.RandomEmail()}})
+			require.NoError(t, err)
+			invite(t, string(body), http.StatusCreated)
+			invite(t, string(body), http.StatusConflict)
+		})
+	})
+
+	t.Run("description=should not be available when the link method is disabled", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+verification.StrategyVerificationLinkName+".enabled", false)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+verification.StrategyVerificationLinkName+".enabled", true)
+		})
+
+		invite(t, `{"traits":{"email":"`+testhelpers.RandomEmail()+`"}}`, http.StatusNotFound)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/pkg/errors"
 
 	"github.com/ory/kratos/identity"
@@ -12,6 +13,7 @@ import (
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/verification"
 	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/ui/container"
 	"github.com/ory/kratos/ui/node"
 	"github.com/ory/kratos/x"
 	"github.com/ory/x/decoderx"
@@ -25,9 +27,13 @@ func (s *Strategy) VerificationStrategyID() string {
 }
 
 func (s *Strategy) RegisterPublicVerificationRoutes(public *x.RouterPublic) {
+	public.GET(RouteRevertAddressChange, s.initRevertAddressChange)
+	public.POST(RouteRevertAddressChange, s.revertAddressChange)
+	s.registerPublicInvitationRoutes(public)
 }
 
 func (s *Strategy) RegisterAdminVerificationRoutes(admin *x.RouterAdmin) {
+	s.registerAdminInvitationRoutes(admin)
 }
 
 func (s *Strategy) PopulateVerificationMethod(r *http.Request, f *verification.Flow) error {
@@ -69,6 +75,58 @@ func (s *Strategy) decodeVerification(r *http.Request) (*verificationSubmitPaylo
 	return &body, nil
 }
 
+type confirmationPayload struct {
+	Method    string `json:"method" form:"method"`
+	Token     string `json:"token" form:"token"`
+	CSRFToken string `json:"csrf_token" form:"csrf_token"`
+}
+
+func (s *Strategy) decodeConfirmation(r *http.Request) (*confirmationPayload, error) {
+	var body confirmationPayload
+
+	compiler, err := decoderx.HTTPRawJSONSchemaCompiler(confirmationSchema)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := s.dx.Decode(r, &body, compiler,
+		decoderx.HTTPDecoderAllowedMethods("POST"),
+		decoderx.HTTPDecoderSetValidatePayloads(true),
+		decoderx.HTTPDecoderJSONFollowsFormFormat(),
+	); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &body, nil
+}
+
+// newVerificationConfirmationFlow creates a browser verification flow whose only form asks the user to confirm
+// an action of a link they followed. The form submits the link's token to the given route, because following a
+// link must not change any state.
+func (s *Strategy) newVerificationConfirmationFlow(r *http.Request, route, token string, message, label *text.Message) (*verification.Flow, error) {
+	ctx := r.Context()
+	csrfToken := s.d.GenerateCSRFToken(r)
+	f, err := verification.NewFlow(s.d.Config(), s.d.Config().SelfServiceFlowVerificationRequestLifespan(ctx), csrfToken, r, nil, flow.TypeBrowser)
+	if err != nil {
+		return nil, err
+	}
+
+	f.UI = &container.Container{
+		Method: "POST",
+		Action: urlx.AppendPaths(s.d.Config().SelfPublicURL(ctx), route).String(),
+	}
+	f.UI.SetCSRF(csrfToken)
+	f.UI.Nodes.Append(node.NewInputField("token", token, node.LinkGroup, node.InputAttributeTypeHidden))
+	f.UI.Nodes.Append(node.NewInputField("method", s.VerificationStrategyID(), node.LinkGroup, node.InputAttributeTypeSubmit).WithMetaLabel(label))
+	f.UI.Messages.Set(message)
+
+	if err := s.d.VerificationFlowPersister().CreateVerificationFlow(ctx, f); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
 // handleVerificationError is a convenience function for handling all types of errors that may occur (e.g. validation error).
 func (s *Strategy) handleVerificationError(w http.ResponseWriter, r *http.Request, f *verification.Flow, body *verificationSubmitPayload, err error) error {
 	if f != nil {
@@ -191,7 +249,7 @@ func (s *Strategy) verificationUseToken(w http.ResponseWriter, r *http.Request,
 	token, err := s.d.VerificationTokenPersister().UseVerificationToken(r.Context(), f.ID, body.Token)
 	if err != nil {
 		if errors.Is(err, sqlcon.ErrNoRows) {
-			return s.retryVerificationFlowWithMessage(w, r, flow.TypeBrowser, text.NewErrorValidationVerificationTokenInvalidOrAlreadyUsed())
+			return s.verificationUseAddressChangeToken(w, r, body, f)
 		}
 
 		return s.retryVerificationFlowWithError(w, r, flow.TypeBrowser, err)
@@ -201,6 +259,11 @@ func (s *Strategy) verificationUseToken(w http.ResponseWriter, r *http.Request,
 		return s.retryVerificationFlowWithError(w, r, flow.TypeBrowser, err)
 	}
 
+	return s.verificationCompleted(w, r, f, token.VerifiableAddress.IdentityID, token.VerifiableAddress)
+}
+
+// verificationCompleted marks the flow as passed and the address, if any, as verified.
+func (s *Strategy) verificationCompleted(w http.ResponseWriter, r *http.Request, f *verification.Flow, identityID uuid.UUID, address *identity.VerifiableAddress) error {
 	f.UI.Messages.Clear()
 	f.State = verification.StatePassedChallenge
 	// See https://github.com/ory/kratos/issues/1547
@@ -210,7 +273,7 @@ func (s *Strategy) verificationUseToken(w http.ResponseWriter, r *http.Request,
 		return s.retryVerificationFlowWithError(w, r, flow.TypeBrowser, err)
 	}
 
-	i, err := s.d.IdentityPool().GetIdentity(r.Context(), token.VerifiableAddress.IdentityID)
+	i, err := s.d.IdentityPool().GetIdentity(r.Context(), identityID)
 	if err != nil {
 		return s.retryVerificationFlowWithError(w, r, flow.TypeBrowser, err)
 	}
@@ -219,13 +282,14 @@ func (s *Strategy) verificationUseToken(w http.ResponseWriter, r *http.Request,
 		return s.retryVerificationFlowWithError(w, r, flow.TypeBrowser, err)
 	}
 
-	address := token.VerifiableAddress
-	address.Verified = true
-	verifiedAt := sqlxx.NullTime(time.Now().UTC())
-	address.VerifiedAt = &verifiedAt
-	address.Status = identity.VerifiableAddressStatusCompleted
-	if err := s.d.PrivilegedIdentityPool().UpdateVerifiableAddress(r.Context(), address); err != nil {
-		return s.retryVerificationFlowWithError(w, r, flow.TypeBrowser, err)
+	if address != nil {
+		address.Verified = true
+		verifiedAt := sqlxx.NullTime(time.Now().UTC())
+		address.VerifiedAt = &verifiedAt
+		address.Status = identity.VerifiableAddressStatusCompleted
+		if err := s.d.PrivilegedIdentityPool().UpdateVerifiableAddress(r.Context(), address); err != nil {
+			return s.retryVerificationFlowWithError(w, r, flow.TypeBrowser, err)
+		}
 	}
 
 	defaultRedirectURL := s.d.Config().SelfServiceFlowVerificationReturnTo(r.Context(), f.AppendTo(s.d.Config().SelfServiceFlowVerificationUI(r.Context())))
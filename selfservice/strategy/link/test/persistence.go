@@ -183,5 +183,144 @@ func TestPersister(ctx context.Context, conf *config.Config, p interface {
 				require.Error(t, err)
 			})
 		})
+
+		t.Run("token=address_change", func(t *testing.T) {
+			newAddressChange := func(t *testing.T) (*verification.Flow, *link.AddressChange) {
+				var f verification.Flow
+				require.NoError(t, faker.FakeData(&f))
+				require.NoError(t, p.CreateVerificationFlow(ctx, &f))
+
+				var i identity.Identity
+				require.NoError(t, faker.FakeData(&i))
+				require.NoError(t, p.CreateIdentity(ctx, &i))
+
+				i.Traits = identity.Traits(`{"email":"new@ory.sh"}`)
+				return &f, link.NewAddressChange(&i, identity.Traits(`{"email":"old@ory.sh"}`), "new@ory.sh", &f, time.Hour, time.Hour)
+			}
+
+			t.Run("case=should error when the address change does not exist", func(t *testing.T) {
+				_, err := p.GetPendingAddressChange(ctx, x.NewUUID(), "i-do-not-exist")
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+				_, err = p.GetAddressChangeByRevertToken(ctx, "i-do-not-exist")
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+
+			t.Run("case=should create an address change and look it up", func(t *testing.T) {
+				f, expected := newAddressChange(t)
+				confirm, revert := expected.ConfirmToken, expected.RevertToken
+				require.NoError(t, p.CreateAddressChange(ctx, expected))
+				assert.Equal(t, confirm, expected.ConfirmToken)
+
+				t.Run("not work on another network", func(t *testing.T) {
+					_, p := testhelpers.NewNetwork(t, ctx, p)
+					_, err := p.GetPendingAddressChange(ctx, f.ID, confirm)
+					require.ErrorIs(t, err, sqlcon.ErrNoRows)
+					_, err = p.GetAddressChangeByRevertToken(ctx, revert)
+					require.ErrorIs(t, err, sqlcon.ErrNoRows)
+				})
+
+				_, err := p.GetPendingAddressChange(ctx, x.NewUUID(), confirm)
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+
+				actual, err := p.GetPendingAddressChange(ctx, f.ID, confirm)
+				require.NoError(t, err)
+				assert.Equal(t, nid, actual.NID)
+				assert.Equal(t, expected.IdentityID, actual.IdentityID)
+				assert.Equal(t, "new@ory.sh", actual.Address)
+				assert.JSONEq(t, `{"email":"new@ory.sh"}`, string(actual.Traits))
+				assert.JSONEq(t, `{"email":"old@ory.sh"}`, string(actual.PreviousTraits))
+				assert.NotEqual(t, confirm, actual.ConfirmToken)
+
+				require.NoError(t, p.UpdateAddressChangeState(ctx, actual.ID, link.AddressChangeStatePending, link.AddressChangeStateApplied))
+				_, err = p.GetPendingAddressChange(ctx, f.ID, confirm)
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+
+				t.Run("case=should only update the expected state", func(t *testing.T) {
+					err := p.UpdateAddressChangeState(ctx, actual.ID, link.AddressChangeStatePending, link.AddressChangeStateReverted)
+					require.ErrorIs(t, err, sqlcon.ErrNoRows)
+				})
+
+				actual, err = p.GetAddressChangeByRevertToken(ctx, revert)
+				require.NoError(t, err)
+				assert.Equal(t, link.AddressChangeStateApplied, actual.State)
+			})
+
+			t.Run("case=should delete pending address changes", func(t *testing.T) {
+				f, pending := newAddressChange(t)
+				require.NoError(t, p.CreateAddressChange(ctx, pending))
+
+				require.NoError(t, p.DeletePendingAddressChanges(ctx, pending.IdentityID))
+				_, err := p.GetPendingAddressChange(ctx, f.ID, pending.ConfirmToken)
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+		})
+
+		t.Run("token=invitation", func(t *testing.T) {
+			newInvitation := func(t *testing.T) *link.Invitation {
+				var i identity.Identity
+				require.NoError(t, faker.FakeData(&i))
+				require.NoError(t, p.CreateIdentity(ctx, &i))
+				return link.NewInvitation(&i, "invited@ory.sh", time.Hour)
+			}
+
+			t.Run("case=should error when the invitation does not exist", func(t *testing.T) {
+				_, err := p.UseInvitation(ctx, "i-do-not-exist")
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+
+			t.Run("case=should create an invitation and use it", func(t *testing.T) {
+				expected := newInvitation(t)
+				token := expected.Token
+				require.NoError(t, p.CreateInvitation(ctx, expected))
+				assert.Equal(t, token, expected.Token)
+
+				t.Run("not work on another network", func(t *testing.T) {
+					_, p := testhelpers.NewNetwork(t, ctx, p)
+					_, err := p.UseInvitation(ctx, token)
+					require.ErrorIs(t, err, sqlcon.ErrNoRows)
+				})
+
+				t.Run("method=get by token", func(t *testing.T) {
+					actual, err := p.GetInvitationByToken(ctx, token)
+					require.NoError(t, err)
+					assert.Equal(t, expected.ID, actual.ID)
+					assert.Equal(t, link.InvitationStatePending, actual.State)
+
+					_, other := testhelpers.NewNetwork(t, ctx, p)
+					_, err = other.GetInvitationByToken(ctx, token)
+					require.ErrorIs(t, err, sqlcon.ErrNoRows)
+				})
+
+				actual, err := p.UseInvitation(ctx, token)
+				require.NoError(t, err)
+				assert.Equal(t, nid, actual.NID)
+				assert.Equal(t, expected.ID, actual.ID)
+				assert.Equal(t, expected.IdentityID, actual.IdentityID)
+				assert.Equal(t, "invited@ory.sh", actual.Address)
+				assert.Equal(t, link.InvitationStateAccepted, actual.State)
+				assert.NotEqual(t, token, actual.Token)
+
+				_, err = p.UseInvitation(ctx, token)
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+
+				_, err = p.GetInvitationByToken(ctx, token)
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+
+				require.ErrorIs(t, p.DeletePendingInvitations(ctx, expected.IdentityID), sqlcon.ErrNoRows, "accepted invitations are kept")
+			})
+
+			t.Run("case=should delete pending invitations", func(t *testing.T) {
+				expected := newInvitation(t)
+				token := expected.Token
+				require.NoError(t, p.CreateInvitation(ctx, expected))
+
+				_, other := testhelpers.NewNetwork(t, ctx, p)
+				require.ErrorIs(t, other.DeletePendingInvitations(ctx, expected.IdentityID), sqlcon.ErrNoRows)
+
+				require.NoError(t, p.DeletePendingInvitations(ctx, expected.IdentityID))
+				_, err := p.UseInvitation(ctx, token)
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+		})
 	}
 }
@@ -0,0 +1,152 @@
+package lookup
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/randx"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/x"
+)
+
+const RouteAdminIdentityCredentials = identity.RouteItemCredentials + "/lookup_secret"
+
+var _ identity.CredentialsAdminHandler = new(Strategy)
+var _ identity.CredentialsDescriber = new(Strategy)
+
+func (s *Strategy) RegisterAdminCredentialsRoutes(admin *x.RouterAdmin) {
+	admin.POST(RouteAdminIdentityCredentials, s.adminRegenerateIdentityCredentials)
+}
+
+type credentialsDescription struct {
+	Remaining int `json:"remaining"`
+	Used      int `json:"used"`
+}
+
+func (s *Strategy) DescribeCredentials(c identity.Credentials) (json.RawMessage, error) {
+	var cc CredentialsConfig
+	if err := json.Unmarshal(c.Config, &cc); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReason("The lookup secrets could not be decoded properly").WithDebug(err.Error()).WithWrap(err))
+	}
+
+	var d credentialsDescription
+	for _, code := range cc.RecoveryCodes {
+		if time.Time(code.UsedAt).IsZero() {
+			d.Remaining++
+		} else {
+			d.Used++
+		}
+	}
+
+	out, err := json.Marshal(&d)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return out, nil
+}
+
+// Lookup Secrets
+//
+// swagger:model lookupSecrets
+type Secrets struct {
+	// Codes are the newly generated lookup secrets. They replace all previous lookup secrets of the identity.
+	//
+	// required: true
+	Codes []string `json:"codes"`
+}
+
+// swagger:parameters adminRegenerateIdentityLookupSecrets
+// nolint:deadcode,unused
+type adminRegenerateIdentityLookupSecrets struct {
+	// ID is the identity's ID.
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+}
+
+// swagger:route POST /admin/identities/{id}/credentials/lookup_secret v0alpha2 adminRegenerateIdentityLookupSecrets
+//
+// # Regenerate the Lookup Secrets of an Identity
+//
+// Replaces the identity's lookup secrets (backup codes) with new ones and returns them, so that they can be handed
+// to the user. The identity must have set up lookup secrets before. All sessions and trusted devices of the identity
+// are revoked.
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  200: lookupSecrets
+//	  404: jsonError
+//	  500: jsonError
+func (s *Strategy) adminRegenerateIdentityCredentials(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	secrets, err := s.regenerateIdentityCredentials(r.Context(), x.ParseUUID(ps.ByName("id")))
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	s.d.Writer().Write(w, r, secrets)
+}
+
+func (s *Strategy) regenerateIdentityCredentials(ctx context.Context, id uuid.UUID) (*Secrets, error) {
+	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.CountActiveMultiFactorCredentials(i.Credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	if count == 0 {
+		return nil, errors.WithStack(herodot.ErrNotFound.WithReason("The identity has no lookup secrets."))
+	}
+
+	secrets := &Secrets{Codes: make([]string, numCodes)}
+	codes := make([]RecoveryCode, numCodes)
+	for k := range codes {
+		codes[k] = RecoveryCode{Code: randx.MustString(8, randx.AlphaLowerNum)}
+		secrets.Codes[k] = codes[k].Code
+	}
+
+	c, _ := i.GetCredentials(s.ID())
+	if err := i.SetCredentialsWithConfig(s.ID(), *c, &CredentialsConfig{RecoveryCodes: codes}); err != nil {
+		return nil, err
+	}
+
+	if err := s.d.PrivilegedIdentityPool().UpdateIdentity(ctx, i); err != nil {
+		return nil, err
+	}
+
+	// The codes are usually regenerated because the old ones leaked, so sessions and devices which might have been
+	// established with them must end.
+	if _, err := s.d.SessionPersister().RevokeSessionsIdentityExcept(ctx, i.ID, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	if err := s.d.SessionPersister().DeleteTrustedDevicesByIdentity(ctx, i.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.d.SessionManager().RefreshMFAEnrollment(ctx, i); err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
@@ -0,0 +1,80 @@
+package lookup_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/x"
+)
+
+func TestAdminRegenerateIdentityCredentials(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/login.schema.json")
+	_, adminTS := testhelpers.NewKratosServer(t, reg)
+
+	regenerate := func(t *testing.T, id *identity.Identity) (*http.Response, []byte) {
+		res, err := adminTS.Client().Post(adminTS.URL+"/admin/identities/"+id.ID.String()+"/credentials/lookup_secret", "application/json", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		return res, x.MustReadAll(res.Body)
+	}
+
+	t.Run("case=describes the lookup secrets", func(t *testing.T) {
+		id, _ := createIdentity(t, reg)
+
+		res, err := adminTS.Client().Get(adminTS.URL + "/admin/identities/" + id.ID.String() + "/credentials")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body := x.MustReadAll(res.Body)
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+
+		assert.EqualValues(t, 8, gjson.GetBytes(body, "#(type==lookup_secret).metadata.remaining").Int(), "%s", body)
+		assert.EqualValues(t, 4, gjson.GetBytes(body, "#(type==lookup_secret).metadata.used").Int(), "%s", body)
+		assert.NotContains(t, string(body), "key-0")
+	})
+
+	t.Run("case=regenerates the lookup secrets and revokes sessions and trusted devices", func(t *testing.T) {
+		id, codes := createIdentity(t, reg)
+		sess, err := session.NewActiveSession(ctx, id, conf, time.Now(), identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
+		require.NoError(t, err)
+		require.NoError(t, reg.SessionPersister().UpsertSession(ctx, sess))
+		require.NoError(t, reg.SessionPersister().CreateTrustedDevice(ctx, session.NewTrustedDevice(id.ID, "Mozilla/5.0", time.Hour)))
+
+		res, body := regenerate(t, id)
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+		generated := gjson.GetBytes(body, "codes").Array()
+		require.Len(t, generated, 12, "%s", body)
+
+		actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, id.ID)
+		require.NoError(t, err)
+		c, ok := actual.GetCredentials(identity.CredentialsTypeLookup)
+		require.True(t, ok)
+		assert.Equal(t, generated[0].String(), gjson.GetBytes(c.Config, "recovery_codes.0.code").String())
+		assert.Nil(t, gjson.GetBytes(c.Config, "recovery_codes.1.used_at").Value())
+		assert.NotContains(t, string(c.Config), codes[0].Code)
+
+		actualSession, err := reg.SessionPersister().GetSession(ctx, sess.ID)
+		require.NoError(t, err)
+		assert.False(t, actualSession.Active)
+
+		devices, err := reg.SessionPersister().ListTrustedDevicesByIdentity(ctx, id.ID)
+		require.NoError(t, err)
+		assert.Empty(t, devices)
+	})
+
+	t.Run("case=fails if the identity has no lookup secrets", func(t *testing.T) {
+		res, body := regenerate(t, createIdentityWithoutLookup(t, reg))
+		assert.Equal(t, http.StatusNotFound, res.StatusCode, "%s", body)
+	})
+}
@@ -312,6 +312,7 @@ func (s *Strategy) continueSettingsFlowConfirm(w http.ResponseWriter, r *http.Re
 	}
 
 	ctxUpdate.UpdateIdentity(i)
+	ctxUpdate.MarkCredentialsAddedOrRotated()
 	return nil
 }
 
@@ -0,0 +1,22 @@
+package oidc
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+)
+
+type (
+	ProviderPersister interface {
+		CreateOIDCProvider(ctx context.Context, c *StoredConfiguration) error
+		GetOIDCProvider(ctx context.Context, id string) (*StoredConfiguration, error)
+		ListOIDCProviders(ctx context.Context) ([]StoredConfiguration, error)
+		UpdateOIDCProvider(ctx context.Context, c *StoredConfiguration) error
+		DeleteOIDCProvider(ctx context.Context, id string) error
+		NetworkID(ctx context.Context) uuid.UUID
+	}
+
+	ProviderPersistenceProvider interface {
+		OIDCProviderPersister() ProviderPersister
+	}
+)
@@ -0,0 +1,83 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"golang.org/x/oauth2"
+
+	"github.com/ory/x/randx"
+	"github.com/ory/x/stringslice"
+)
+
+const (
+	// PKCEAuto uses PKCE if the provider advertises support for it in its OpenID Connect discovery document.
+	PKCEAuto = "auto"
+	// PKCEAlways always uses PKCE.
+	PKCEAlways = "always"
+	// PKCENever never uses PKCE.
+	PKCENever = "never"
+)
+
+// pkceDiscoverer is implemented by providers which can tell whether they support PKCE.
+type pkceDiscoverer interface {
+	supportsPKCE(ctx context.Context) bool
+}
+
+// newPKCEVerifier returns a new PKCE code verifier or an empty string if PKCE should not be
+// used with the provider.
+func newPKCEVerifier(ctx context.Context, provider Provider) string {
+	switch provider.Config().PKCE {
+	case PKCEAlways:
+	case PKCENever:
+		return ""
+	default:
+		d, ok := provider.(pkceDiscoverer)
+		if !ok || !d.supportsPKCE(ctx) {
+			return ""
+		}
+	}
+
+	return randx.MustString(64, randx.AlphaNum)
+}
+
+// pkceAuthCodeURLOptions returns the S256 code challenge parameters for the given verifier.
+func pkceAuthCodeURLOptions(verifier string) []oauth2.AuthCodeOption {
+	if len(verifier) == 0 {
+		return nil
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	return []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	}
+}
+
+// pkceExchangeOptions returns the code verifier parameter for the token exchange.
+func pkceExchangeOptions(verifier string) []oauth2.AuthCodeOption {
+	if len(verifier) == 0 {
+		return nil
+	}
+
+	return []oauth2.AuthCodeOption{oauth2.SetAuthURLParam("code_verifier", verifier)}
+}
+
+// supportsPKCE uses the cached discovery document of the provider, so checking for PKCE support does
+// not cause additional requests to the provider.
+func (g *ProviderGenericOIDC) supportsPKCE(ctx context.Context) bool {
+	p, err := g.provider(ctx)
+	if err != nil {
+		return false
+	}
+
+	var discovery struct {
+		CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+	}
+	if err := p.Claims(&discovery); err != nil {
+		return false
+	}
+
+	return stringslice.Has(discovery.CodeChallengeMethodsSupported, "S256")
+}
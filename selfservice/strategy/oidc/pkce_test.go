@@ -0,0 +1,158 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/ory/x/httpx"
+
+	"github.com/ory/kratos/x"
+)
+
+type pkceTestProvider struct {
+	c *Configuration
+}
+
+func (p *pkceTestProvider) Config() *Configuration {
+	return p.c
+}
+
+func (p *pkceTestProvider) OAuth2(context.Context) (*oauth2.Config, error) {
+	return &oauth2.Config{ClientID: "client", Endpoint: oauth2.Endpoint{AuthURL: "https://www.ory.sh/oauth2/auth"}}, nil
+}
+
+func (p *pkceTestProvider) Claims(context.Context, *oauth2.Token, url.Values) (*Claims, error) {
+	return nil, nil
+}
+
+func (p *pkceTestProvider) AuthCodeURLOptions(ider) []oauth2.AuthCodeOption {
+	return nil
+}
+
+type pkceTestDiscoveringProvider struct {
+	pkceTestProvider
+	supported bool
+}
+
+func (p *pkceTestDiscoveringProvider) supportsPKCE(context.Context) bool {
+	return p.supported
+}
+
+type pkceTestRegistry struct {
+	dependencies
+	nid   uuid.UUID
+	cache *DiscoveryCache
+}
+
+func (*pkceTestRegistry) HTTPClient(context.Context, ...httpx.ResilientOptions) *retryablehttp.Client {
+	return retryablehttp.NewClient()
+}
+
+func (r *pkceTestRegistry) OIDCDiscoveryCache() *DiscoveryCache {
+	return r.cache
+}
+
+func (r *pkceTestRegistry) OIDCProviderPersister() ProviderPersister {
+	return pkceTestPersister{nid: r.nid}
+}
+
+type pkceTestPersister struct {
+	ProviderPersister
+	nid uuid.UUID
+}
+
+func (p pkceTestPersister) NetworkID(context.Context) uuid.UUID {
+	return p.nid
+}
+
+func TestPKCE(t *testing.T) {
+	ctx := context.Background()
+
+	for k, tc := range []struct {
+		p      Provider
+		expect bool
+	}{
+		{p: &pkceTestProvider{c: &Configuration{}}, expect: false},
+		{p: &pkceTestProvider{c: &Configuration{PKCE: PKCEAuto}}, expect: false},
+		{p: &pkceTestProvider{c: &Configuration{PKCE: PKCEAlways}}, expect: true},
+		{p: &pkceTestProvider{c: &Configuration{PKCE: PKCENever}}, expect: false},
+		{p: &pkceTestDiscoveringProvider{pkceTestProvider: pkceTestProvider{c: &Configuration{}}, supported: true}, expect: true},
+		{p: &pkceTestDiscoveringProvider{pkceTestProvider: pkceTestProvider{c: &Configuration{PKCE: PKCEAuto}}, supported: false}, expect: false},
+		{p: &pkceTestDiscoveringProvider{pkceTestProvider: pkceTestProvider{c: &Configuration{PKCE: PKCENever}}, supported: true}, expect: false},
+		{p: &pkceTestDiscoveringProvider{pkceTestProvider: pkceTestProvider{c: &Configuration{PKCE: PKCEAlways}}, supported: false}, expect: true},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			assert.Equal(t, tc.expect, len(newPKCEVerifier(ctx, tc.p)) > 0)
+		})
+	}
+
+	t.Run("case=sends the S256 challenge and the verifier", func(t *testing.T) {
+		p := &pkceTestProvider{c: &Configuration{PKCE: PKCEAlways}}
+		verifier := newPKCEVerifier(ctx, p)
+		require.GreaterOrEqual(t, len(verifier), 43)
+
+		c, err := p.OAuth2(ctx)
+		require.NoError(t, err)
+
+		u, err := url.Parse(c.AuthCodeURL("state", pkceAuthCodeURLOptions(verifier)...))
+		require.NoError(t, err)
+		expected := sha256.Sum256([]byte(verifier))
+		assert.Equal(t, base64.RawURLEncoding.EncodeToString(expected[:]), u.Query().Get("code_challenge"))
+		assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+		assert.Len(t, pkceExchangeOptions(verifier), 1)
+	})
+
+	t.Run("case=sends nothing without a verifier", func(t *testing.T) {
+		assert.Empty(t, pkceAuthCodeURLOptions(""))
+		assert.Empty(t, pkceExchangeOptions(""))
+	})
+
+	t.Run("case=reuses the discovery document", func(t *testing.T) {
+		var requests int32
+		var issuer string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+				"issuer":                           issuer,
+				"authorization_endpoint":           issuer + "/oauth2/auth",
+				"token_endpoint":                   issuer + "/oauth2/token",
+				"code_challenge_methods_supported": []string{"S256"},
+			}))
+		}))
+		t.Cleanup(ts.Close)
+		issuer = ts.URL
+
+		// Providers are created for every request, so the discovery document must be reused across them.
+		reg := &pkceTestRegistry{nid: x.NewUUID(), cache: NewDiscoveryCache()}
+		for i := 0; i < 3; i++ {
+			reqCtx, cancel := context.WithCancel(ctx)
+			p := NewProviderGenericOIDC(&Configuration{IssuerURL: issuer}, reg)
+			assert.NotEmpty(t, newPKCEVerifier(reqCtx, p))
+			cancel()
+		}
+		assert.EqualValues(t, 1, atomic.LoadInt32(&requests))
+
+		// The discovery document is not shared with other networks or registries.
+		p := NewProviderGenericOIDC(&Configuration{IssuerURL: issuer}, &pkceTestRegistry{nid: x.NewUUID(), cache: reg.cache})
+		assert.NotEmpty(t, newPKCEVerifier(ctx, p))
+		assert.EqualValues(t, 2, atomic.LoadInt32(&requests))
+
+		p = NewProviderGenericOIDC(&Configuration{IssuerURL: issuer}, &pkceTestRegistry{nid: reg.nid, cache: NewDiscoveryCache()})
+		assert.NotEmpty(t, newPKCEVerifier(ctx, p))
+		assert.EqualValues(t, 3, atomic.LoadInt32(&requests))
+	})
+}
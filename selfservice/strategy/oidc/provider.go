@@ -2,10 +2,14 @@ package oidc
 
 import (
 	"context"
+	"encoding/json"
 	"net/url"
 
+	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 
+	"github.com/ory/herodot"
+
 	"github.com/ory/kratos/x"
 )
 
@@ -20,6 +24,12 @@ type TokenExchanger interface {
 	Exchange(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error)
 }
 
+// IDTokenVerifier is implemented by providers which can verify ID tokens obtained by native apps, e.g. using
+// the Apple or Google sign in SDKs.
+type IDTokenVerifier interface {
+	Verify(ctx context.Context, rawIDToken string) (*Claims, error)
+}
+
 // ConvertibleBoolean is used as Apple casually sends the email_verified field as a string.
 type Claims struct {
 	Issuer              string               `json:"iss,omitempty"`
@@ -45,4 +55,24 @@ type Claims struct {
 	UpdatedAt           int64                `json:"updated_at,omitempty"`
 	HD                  string               `json:"hd,omitempty"`
 	Team                string               `json:"team,omitempty"`
+	Nonce               string               `json:"nonce,omitempty"`
+
+	// RawClaims contains all claims returned by the provider, including custom claims which are not
+	// mapped to any of the fields above.
+	RawClaims map[string]interface{} `json:"raw_claims,omitempty"`
+}
+
+// newRawClaims converts the user information returned by a provider's API to raw claims.
+func newRawClaims(v interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+
+	return raw, nil
 }
@@ -12,12 +12,16 @@ import (
 	"github.com/ory/x/urlx"
 )
 
+// OpenID Connect Provider Configuration
+//
+// swagger:model oidcProvider
 type Configuration struct {
 	// ID is the provider's ID
 	ID string `json:"id"`
 
 	// Provider is either "generic" for a generic OAuth 2.0 / OpenID Connect Provider or one of:
 	// - generic
+	// - generic-oauth2
 	// - google
 	// - github
 	// - github-app
@@ -55,6 +59,27 @@ type Configuration struct {
 	// `provider` is set to `generic`.
 	TokenURL string `json:"token_url"`
 
+	// EndSessionEndpoint is the provider's logout URL, typically something like: https://example.org/oauth2/sessions/logout
+	// If set, users who signed in with this provider are redirected through it when they log out, so that their
+	// session at the provider ends as well (OpenID Connect RP-initiated logout).
+	EndSessionEndpoint string `json:"end_session_endpoint"`
+
+	// UserinfoRequests lists the requests which are made with the access token to fetch the user's information
+	// when `provider` is set to `generic-oauth2`. Each response is available under the request's name.
+	UserinfoRequests []UserinfoRequest `json:"userinfo_requests"`
+
+	// ClaimsMapper specifies the JSONNet code snippet which maps the userinfo responses, available as
+	// `std.extVar('userinfo')`, to OpenID Connect claims when `provider` is set to `generic-oauth2`. It takes
+	// precedence over `claims_paths`.
+	//
+	// It can be a URL (file://, http(s)://, base64://).
+	ClaimsMapper string `json:"claims_mapper_url"`
+
+	// ClaimsPaths maps OpenID Connect claims (e.g. `sub` or `email`) to JSON paths in the userinfo responses
+	// when `provider` is set to `generic-oauth2`. Each path starts with the name of a userinfo request,
+	// for example `user.id`.
+	ClaimsPaths map[string]string `json:"claims_paths"`
+
 	// Tenant is the Azure AD Tenant to use for authentication, and must be set when `provider` is set to `microsoft`.
 	// Can be either `common`, `organizations`, `consumers` for a multitenant application or a specific tenant like
 	// `8eaef023-2b34-4da1-9baa-8bc8c9d6a490` or `contoso.onmicrosoft.com`.
@@ -85,7 +110,8 @@ type Configuration struct {
 	Scope []string `json:"scope"`
 
 	// Mapper specifies the JSONNet code snippet which uses the OpenID Connect Provider's data (e.g. GitHub or Google
-	// profile information) to hydrate the identity's data.
+	// profile information) to hydrate the identity's data. All claims returned by the provider, including custom
+	// claims, are available as `claims.raw_claims`.
 	//
 	// It can be either a URL (file://, http(s)://, base64://) or an inline JSONNet code snippet.
 	Mapper string `json:"mapper_url"`
@@ -95,6 +121,47 @@ type Configuration struct {
 	//
 	// More information: https://openid.net/specs/openid-connect-core-1_0.html#ClaimsParameter
 	RequestedClaims json.RawMessage `json:"requested_claims"`
+
+	// AdditionalIDTokenAudiences lists further audiences which are accepted in ID tokens besides the client ID.
+	// This is needed when native apps sign in with ID tokens which were issued to their own client, e.g. an iOS
+	// app's bundle ID when using Sign in with Apple.
+	AdditionalIDTokenAudiences []string `json:"additional_id_token_audiences"`
+
+	// PKCE controls if the OAuth 2.0 Proof Key for Code Exchange (PKCE) is used. Can be one of:
+	// - auto: use PKCE if the provider's OpenID Connect discovery document advertises support for it (default)
+	// - always: always use PKCE
+	// - never: never use PKCE
+	PKCE string `json:"pkce"`
+
+	// SyncOnLogin runs the Jsonnet mapper on every login and updates the identity with its output.
+	SyncOnLogin bool `json:"sync_on_login"`
+
+	// SyncTraits lists the trait paths (e.g. `email` or `name.first`) which are overwritten with the Jsonnet
+	// mapper's output when `sync_on_login` is enabled. All other traits are left untouched.
+	SyncTraits []string `json:"sync_traits"`
+
+	// SyncMetadataAdmin replaces the identity's admin metadata with the Jsonnet mapper's output when
+	// `sync_on_login` is enabled.
+	SyncMetadataAdmin bool `json:"sync_metadata_admin"`
+}
+
+// withoutSecrets returns a copy of the configuration without the client secret and the Apple private key.
+func (p Configuration) withoutSecrets() Configuration {
+	p.ClientSecret = ""
+	p.PrivateKey = ""
+	return p
+}
+
+// UserinfoRequest is a request to a provider's API which returns information about the user.
+type UserinfoRequest struct {
+	// Name is the key under which the response is available when mapping it to claims.
+	Name string `json:"name"`
+
+	// URL is the API endpoint, for example https://api.bitbucket.org/2.0/user
+	URL string `json:"url"`
+
+	// Headers are sent in addition to the access token.
+	Headers map[string]string `json:"headers"`
 }
 
 func (p Configuration) Redir(public *url.URL) string {
@@ -108,6 +175,15 @@ type ConfigurationCollection struct {
 	Providers       []Configuration `json:"providers"`
 }
 
+func (c ConfigurationCollection) find(id string) (*Configuration, bool) {
+	for k := range c.Providers {
+		if c.Providers[k].ID == id {
+			return &c.Providers[k], true
+		}
+	}
+	return nil, false
+}
+
 func (c ConfigurationCollection) Provider(id string, reg dependencies) (Provider, error) {
 	for k := range c.Providers {
 		p := c.Providers[k]
@@ -125,6 +201,8 @@ func (c ConfigurationCollection) Provider(id string, reg dependencies) (Provider
 			switch p.Provider {
 			case addProviderName("generic"):
 				return NewProviderGenericOIDC(&p, reg), nil
+			case addProviderName("generic-oauth2"):
+				return NewProviderGenericOAuth2(&p, reg), nil
 			case addProviderName("google"):
 				return NewProviderGoogle(&p, reg), nil
 			case addProviderName("github"):
@@ -3,6 +3,7 @@ package oidc
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/url"
 	"strings"
 	"time"
@@ -141,7 +142,17 @@ func (g *ProviderDingTalk) Claims(ctx context.Context, exchange *oauth2.Token, _
 		ErrCode   string `json:"code"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+
+	if err := json.Unmarshal(b, &user); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
 		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
 	}
 
@@ -150,11 +161,12 @@ func (g *ProviderDingTalk) Claims(ctx context.Context, exchange *oauth2.Token, _
 	}
 
 	return &Claims{
-		Issuer:   userInfoURL,
-		Subject:  user.OpenId,
-		Nickname: user.Nick,
-		Name:     user.Nick,
-		Picture:  user.AvatarUrl,
-		Email:    user.Email,
+		Issuer:    userInfoURL,
+		Subject:   user.OpenId,
+		Nickname:  user.Nick,
+		Name:      user.Nick,
+		Picture:   user.AvatarUrl,
+		Email:     user.Email,
+		RawClaims: raw,
 	}, nil
 }
@@ -81,6 +81,11 @@ func (d *ProviderDiscord) Claims(ctx context.Context, exchange *oauth2.Token, qu
 		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
 	}
 
+	raw, err := newRawClaims(user)
+	if err != nil {
+		return nil, err
+	}
+
 	claims := &Claims{
 		Issuer:            discordgo.EndpointOauth2,
 		Subject:           user.ID,
@@ -91,6 +96,7 @@ func (d *ProviderDiscord) Claims(ctx context.Context, exchange *oauth2.Token, qu
 		Email:             user.Email,
 		EmailVerified:     x.ConvertibleBoolean(user.Verified),
 		Locale:            user.Locale,
+		RawClaims:         raw,
 	}
 
 	return claims, nil
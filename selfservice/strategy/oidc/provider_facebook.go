@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/url"
 
 	"github.com/hashicorp/go-retryablehttp"
@@ -99,7 +100,17 @@ func (g *ProviderFacebook) Claims(ctx context.Context, exchange *oauth2.Token, q
 		BirthDay string `json:"birthday,omitempty"`
 		Gender   string `json:"gender,omitempty"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+
+	if err := json.Unmarshal(b, &user); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
 		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
 	}
 
@@ -121,5 +132,6 @@ func (g *ProviderFacebook) Claims(ctx context.Context, exchange *oauth2.Token, q
 		EmailVerified:     x.ConvertibleBoolean(user.EmailVerified),
 		Gender:            user.Gender,
 		Birthdate:         user.BirthDay,
+		RawClaims:         raw,
 	}, nil
 }
@@ -86,5 +86,13 @@ func TestProviderFacebook_Claims(t *testing.T) {
 		Email:             "john.doe@example.com",
 		EmailVerified:     true,
 		Birthdate:         "01/01/1990",
+		RawClaims: map[string]interface{}{
+			"id":         "123456789012345",
+			"name":       "John Doe",
+			"first_name": "John",
+			"last_name":  "Doe",
+			"email":      "john.doe@example.com",
+			"birthday":   "01/01/1990",
+		},
 	}, actual)
 }
@@ -0,0 +1,183 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"golang.org/x/oauth2"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/fetcher"
+	"github.com/ory/x/httpx"
+	"github.com/ory/x/jsonnetsecure"
+)
+
+// ProviderGenericOAuth2 is a provider for OAuth 2.0 servers which do not support OpenID Connect. The user's
+// information is fetched from one or more API endpoints and mapped to claims using Jsonnet or JSON paths.
+type ProviderGenericOAuth2 struct {
+	config *Configuration
+	reg    dependencies
+}
+
+func NewProviderGenericOAuth2(
+	config *Configuration,
+	reg dependencies,
+) *ProviderGenericOAuth2 {
+	return &ProviderGenericOAuth2{
+		config: config,
+		reg:    reg,
+	}
+}
+
+func (g *ProviderGenericOAuth2) Config() *Configuration {
+	return g.config
+}
+
+func (g *ProviderGenericOAuth2) oauth2(ctx context.Context) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     g.config.ClientID,
+		ClientSecret: g.config.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  g.config.AuthURL,
+			TokenURL: g.config.TokenURL,
+		},
+		Scopes:      g.config.Scope,
+		RedirectURL: g.config.Redir(g.reg.Config().OIDCRedirectURIBase(ctx)),
+	}
+}
+
+func (g *ProviderGenericOAuth2) OAuth2(ctx context.Context) (*oauth2.Config, error) {
+	return g.oauth2(ctx), nil
+}
+
+func (g *ProviderGenericOAuth2) AuthCodeURLOptions(r ider) []oauth2.AuthCodeOption {
+	return []oauth2.AuthCodeOption{}
+}
+
+func (g *ProviderGenericOAuth2) Claims(ctx context.Context, exchange *oauth2.Token, query url.Values) (*Claims, error) {
+	if len(g.config.UserinfoRequests) == 0 {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("OAuth2 provider %s has no userinfo requests configured.", g.config.ID))
+	}
+
+	client := g.reg.HTTPClient(ctx, httpx.ResilientClientWithClient(g.oauth2(ctx).Client(ctx, exchange)))
+
+	userinfo := []byte("{}")
+	for _, ur := range g.config.UserinfoRequests {
+		body, err := g.userinfo(client, ur)
+		if err != nil {
+			return nil, err
+		}
+
+		if userinfo, err = sjson.SetRawBytes(userinfo, ur.Name, body); err != nil {
+			return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+		}
+	}
+
+	mapped, err := g.mapClaims(ctx, userinfo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Many APIs return numeric user IDs, but the subject must be a string.
+	if sub := gjson.GetBytes(mapped, "sub"); sub.Type == gjson.Number {
+		if mapped, err = sjson.SetBytes(mapped, "sub", sub.Raw); err != nil {
+			return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+		}
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(mapped, &claims); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to decode the claims mapped from the userinfo responses: %s", err))
+	}
+
+	if len(claims.Subject) == 0 {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("The claims mapped from the userinfo responses of OAuth2 provider %s do not contain a subject.", g.config.ID))
+	}
+
+	if len(claims.Issuer) == 0 {
+		claims.Issuer = g.config.TokenURL
+	}
+
+	if err := json.Unmarshal(userinfo, &claims.RawClaims); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+
+	return &claims, nil
+}
+
+func (g *ProviderGenericOAuth2) userinfo(client *retryablehttp.Client, ur UserinfoRequest) ([]byte, error) {
+	req, err := retryablehttp.NewRequest("GET", ur.URL, nil)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+
+	req.Header.Set("Accept", "application/json")
+	for k, v := range ur.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Userinfo request %s of OAuth2 provider %s failed with status code %d.", ur.Name, g.config.ID, resp.StatusCode))
+	}
+
+	if !gjson.ValidBytes(body) {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Userinfo request %s of OAuth2 provider %s did not return JSON.", ur.Name, g.config.ID))
+	}
+
+	return body, nil
+}
+
+// mapClaims maps the userinfo responses to claims using either the claims Jsonnet mapper or the claims JSON paths.
+func (g *ProviderGenericOAuth2) mapClaims(ctx context.Context, userinfo []byte) ([]byte, error) {
+	if len(g.config.ClaimsMapper) > 0 {
+		jn, err := fetcher.NewFetcher(fetcher.WithClient(g.reg.HTTPClient(ctx))).Fetch(g.config.ClaimsMapper)
+		if err != nil {
+			return nil, err
+		}
+
+		vm := jsonnetsecure.MakeSecureVM()
+		vm.ExtCode("userinfo", string(userinfo))
+		evaluated, err := vm.EvaluateAnonymousSnippet(g.config.ClaimsMapper, jn.String())
+		if err != nil {
+			return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("OAuth2 provider %s claims Jsonnet mapper failed: %s", g.config.ID, err))
+		}
+
+		if !gjson.Parse(evaluated).IsObject() {
+			return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("OAuth2 provider %s claims Jsonnet mapper did not return an object. Please check your Jsonnet code!", g.config.ID))
+		}
+
+		return []byte(evaluated), nil
+	}
+
+	mapped := []byte("{}")
+	for claim, path := range g.config.ClaimsPaths {
+		value := gjson.GetBytes(userinfo, path)
+		if !value.Exists() {
+			continue
+		}
+
+		var err error
+		if mapped, err = sjson.SetRawBytes(mapped, claim, []byte(value.Raw)); err != nil {
+			return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+		}
+	}
+
+	return mapped, nil
+}
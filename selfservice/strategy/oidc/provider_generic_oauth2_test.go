@@ -0,0 +1,118 @@
+package oidc_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
+)
+
+func TestProviderGenericOAuth2(t *testing.T) {
+	_, reg := internal.NewFastRegistryWithMocks(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/user":
+			assert.Equal(t, "client", r.Header.Get("Client-Id"))
+			_, _ = w.Write([]byte(`{"id":1234,"login":"foobar","profile":{"name":"Foo Bar"}}`))
+		case "/emails":
+			_, _ = w.Write([]byte(`{"values":[{"email":"foo@bar.com","is_confirmed":true}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	newConfig := func() *oidc.Configuration {
+		return &oidc.Configuration{
+			ID:       "oauth2",
+			Provider: "generic-oauth2",
+			ClientID: "client",
+			AuthURL:  ts.URL + "/oauth2/auth",
+			TokenURL: ts.URL + "/oauth2/token",
+			UserinfoRequests: []oidc.UserinfoRequest{
+				{Name: "user", URL: ts.URL + "/user", Headers: map[string]string{"Client-Id": "client"}},
+				{Name: "emails", URL: ts.URL + "/emails"},
+			},
+		}
+	}
+
+	token := &oauth2.Token{AccessToken: "access-token", Expiry: time.Now().Add(time.Hour)}
+
+	t.Run("case=maps claims using JSON paths", func(t *testing.T) {
+		c := newConfig()
+		c.ClaimsPaths = map[string]string{
+			"sub":            "user.id",
+			"name":           "user.profile.name",
+			"email":          "emails.values.0.email",
+			"email_verified": "emails.values.0.is_confirmed",
+			"website":        "user.does_not_exist",
+		}
+
+		claims, err := oidc.NewProviderGenericOAuth2(c, reg).Claims(context.Background(), token, url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, "1234", claims.Subject)
+		assert.Equal(t, ts.URL+"/oauth2/token", claims.Issuer)
+		assert.Equal(t, "Foo Bar", claims.Name)
+		assert.Equal(t, "foo@bar.com", claims.Email)
+		assert.True(t, bool(claims.EmailVerified))
+		assert.Empty(t, claims.Website)
+		assert.Equal(t, "foobar", claims.RawClaims["user"].(map[string]interface{})["login"])
+	})
+
+	t.Run("case=maps claims using Jsonnet", func(t *testing.T) {
+		c := newConfig()
+		c.ClaimsPaths = map[string]string{"sub": "user.login"}
+		c.ClaimsMapper = "base64://" + base64.StdEncoding.EncodeToString([]byte(`
+local userinfo = std.extVar('userinfo');
+{
+  iss: 'https://oauth2.example.org',
+  sub: std.toString(userinfo.user.id),
+  nickname: userinfo.user.login,
+  email: userinfo.emails.values[0].email,
+}`))
+
+		claims, err := oidc.NewProviderGenericOAuth2(c, reg).Claims(context.Background(), token, url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, "1234", claims.Subject)
+		assert.Equal(t, "https://oauth2.example.org", claims.Issuer)
+		assert.Equal(t, "foobar", claims.Nickname)
+		assert.Equal(t, "foo@bar.com", claims.Email)
+	})
+
+	t.Run("case=fails without a subject", func(t *testing.T) {
+		c := newConfig()
+		c.ClaimsPaths = map[string]string{"email": "emails.values.0.email"}
+
+		_, err := oidc.NewProviderGenericOAuth2(c, reg).Claims(context.Background(), token, url.Values{})
+		require.Error(t, err)
+		assert.Contains(t, fmt.Sprintf("%+v", err), "do not contain a subject")
+	})
+
+	t.Run("case=fails if a userinfo request fails", func(t *testing.T) {
+		c := newConfig()
+		c.ClaimsPaths = map[string]string{"sub": "user.id"}
+		c.UserinfoRequests = append(c.UserinfoRequests, oidc.UserinfoRequest{Name: "missing", URL: ts.URL + "/missing"})
+
+		_, err := oidc.NewProviderGenericOAuth2(c, reg).Claims(context.Background(), token, url.Values{})
+		require.Error(t, err)
+		assert.Contains(t, fmt.Sprintf("%+v", err), "failed with status code 404")
+	})
+}
@@ -2,8 +2,12 @@ package oidc
 
 import (
 	"context"
+	"encoding/json"
 	"net/url"
+	"time"
 
+	"github.com/gofrs/uuid"
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 
@@ -15,6 +19,47 @@ import (
 
 var _ Provider = new(ProviderGenericOIDC)
 
+// discoveryCacheTTL is how long the OpenID Connect discovery document of an issuer is reused.
+const discoveryCacheTTL = time.Hour
+
+type (
+	// DiscoveryCache holds the OpenID Connect providers created from the discovery documents of the
+	// issuers. Providers are created for every request, so this avoids fetching the discovery document
+	// and the signing keys on every login. Entries are kept per network.
+	DiscoveryCache struct {
+		c *lru.Cache
+	}
+	DiscoveryCacheProvider interface {
+		OIDCDiscoveryCache() *DiscoveryCache
+	}
+	cachedDiscovery struct {
+		provider  *gooidc.Provider
+		fetchedAt time.Time
+	}
+)
+
+func NewDiscoveryCache() *DiscoveryCache {
+	c, _ := lru.New(256)
+	return &DiscoveryCache{c: c}
+}
+
+func (d *DiscoveryCache) get(nid uuid.UUID, issuer string) (*gooidc.Provider, bool) {
+	c, ok := d.c.Get(nid.String() + issuer)
+	if !ok {
+		return nil, false
+	}
+
+	cached := c.(cachedDiscovery)
+	if time.Since(cached.fetchedAt) >= discoveryCacheTTL {
+		return nil, false
+	}
+	return cached.provider, true
+}
+
+func (d *DiscoveryCache) add(nid uuid.UUID, issuer string, p *gooidc.Provider) {
+	d.c.Add(nid.String()+issuer, cachedDiscovery{provider: p, fetchedAt: time.Now()})
+}
+
 type ProviderGenericOIDC struct {
 	p      *gooidc.Provider
 	config *Configuration
@@ -36,13 +81,26 @@ func (g *ProviderGenericOIDC) Config() *Configuration {
 }
 
 func (g *ProviderGenericOIDC) provider(ctx context.Context) (*gooidc.Provider, error) {
-	if g.p == nil {
-		p, err := gooidc.NewProvider(context.WithValue(ctx, oauth2.HTTPClient, g.reg.HTTPClient(ctx).HTTPClient), g.config.IssuerURL)
-		if err != nil {
-			return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to initialize OpenID Connect Provider: %s", err))
-		}
+	if g.p != nil {
+		return g.p, nil
+	}
+
+	nid := g.reg.OIDCProviderPersister().NetworkID(ctx)
+	if p, ok := g.reg.OIDCDiscoveryCache().get(nid, g.config.IssuerURL); ok {
 		g.p = p
+		return g.p, nil
+	}
+
+	// The provider keeps the context to fetch the signing keys later on, so it must not be bound to this
+	// request. The HTTP client is taken from this network's configuration as the provider is only reused
+	// within this network.
+	p, err := gooidc.NewProvider(gooidc.ClientContext(context.Background(), g.reg.HTTPClient(ctx).HTTPClient), g.config.IssuerURL)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to initialize OpenID Connect Provider: %s", err))
 	}
+
+	g.reg.OIDCDiscoveryCache().add(nid, g.config.IssuerURL, p)
+	g.p = p
 	return g.p, nil
 }
 
@@ -85,16 +143,26 @@ func (g *ProviderGenericOIDC) AuthCodeURLOptions(r ider) []oauth2.AuthCodeOption
 }
 
 func (g *ProviderGenericOIDC) verifyAndDecodeClaimsWithProvider(ctx context.Context, provider *gooidc.Provider, raw string) (*Claims, error) {
-	token, err := provider.Verifier(&gooidc.Config{ClientID: g.config.ClientID}).Verify(ctx, raw)
+	// The audience is checked below because the token may have been issued to one of the additional audiences.
+	token, err := provider.Verifier(&gooidc.Config{SkipClientIDCheck: true}).Verify(ctx, raw)
 	if err != nil {
 		return nil, errors.WithStack(herodot.ErrBadRequest.WithReasonf("%s", err))
 	}
 
+	audiences := append([]string{g.config.ClientID}, g.config.AdditionalIDTokenAudiences...)
+	if !hasAudience(token.Audience, audiences) {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReasonf("The id_token was issued for an unexpected audience: %v", token.Audience))
+	}
+
 	var claims Claims
 	if err := token.Claims(&claims); err != nil {
 		return nil, errors.WithStack(herodot.ErrBadRequest.WithReasonf("%s", err))
 	}
 
+	if err := token.Claims(&claims.RawClaims); err != nil {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReasonf("%s", err))
+	}
+
 	return &claims, nil
 }
 
@@ -109,5 +177,79 @@ func (g *ProviderGenericOIDC) Claims(ctx context.Context, exchange *oauth2.Token
 		return nil, err
 	}
 
-	return g.verifyAndDecodeClaimsWithProvider(ctx, p, raw)
+	claims, err := g.verifyAndDecodeClaimsWithProvider(ctx, p, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.mergeUserInfo(ctx, p, exchange, claims)
+}
+
+// mergeUserInfo adds the claims from the provider's userinfo endpoint to the claims of the ID token. Claims
+// of the ID token take precedence because they are signed by the provider.
+//
+// The userinfo claims are optional, so the claims of the ID token are used as they are if the userinfo
+// endpoint can not be reached or returns the claims of another subject.
+func (g *ProviderGenericOIDC) mergeUserInfo(ctx context.Context, p *gooidc.Provider, exchange *oauth2.Token, claims *Claims) (*Claims, error) {
+	var discovery struct {
+		UserInfoEndpoint string `json:"userinfo_endpoint"`
+	}
+	if err := p.Claims(&discovery); err != nil || len(discovery.UserInfoEndpoint) == 0 || len(exchange.AccessToken) == 0 {
+		return claims, nil
+	}
+
+	logger := g.reg.Logger().WithField("provider", g.config.ID).WithField("subject", claims.Subject)
+
+	userInfo, err := p.UserInfo(gooidc.ClientContext(ctx, g.reg.HTTPClient(ctx).HTTPClient), oauth2.StaticTokenSource(exchange))
+	if err != nil {
+		logger.WithError(err).Warn("Unable to fetch the userinfo of the OpenID Connect Provider, using the claims of the id_token only.")
+		return claims, nil
+	}
+
+	// The userinfo response must be about the same user as the ID token, see
+	// https://openid.net/specs/openid-connect-core-1_0.html#UserInfoResponse
+	if userInfo.Subject != claims.Subject {
+		logger.Warn("The subject of the userinfo response does not match the subject of the id_token, using the claims of the id_token only.")
+		return claims, nil
+	}
+
+	merged := map[string]interface{}{}
+	if err := userInfo.Claims(&merged); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+	for k, v := range claims.RawClaims {
+		merged[k] = v
+	}
+
+	b, err := json.Marshal(merged)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+
+	var result Claims
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+	result.RawClaims = merged
+
+	return &result, nil
+}
+
+// Verify verifies an ID token which was obtained by a native app and returns its claims.
+func (g *ProviderGenericOIDC) Verify(ctx context.Context, rawIDToken string) (*Claims, error) {
+	p, err := g.provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.verifyAndDecodeClaimsWithProvider(ctx, p, rawIDToken)
+}
+
+func hasAudience(actual []string, expected []string) bool {
+	for _, a := range actual {
+		if len(a) > 0 && stringslice.Has(expected, a) {
+			return true
+		}
+	}
+	return false
 }
@@ -6,6 +6,9 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+
 	"github.com/ory/kratos/driver"
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/internal"
@@ -91,3 +94,145 @@ func TestProviderGenericOIDC_AddAuthCodeURLOptions(t *testing.T) {
 		assert.Contains(t, makeAuthCodeURL(t, r, reg), "claims="+url.QueryEscape(string(makeOIDCClaims())))
 	})
 }
+
+func TestProviderGenericOIDC_Verify(t *testing.T) {
+	issuer, sign := newIDTokenIssuer(t)
+
+	_, reg := internal.NewFastRegistryWithMocks(t)
+	p := oidc.NewProviderGenericOIDC(&oidc.Configuration{
+		Provider:                   "generic",
+		ID:                         "valid",
+		ClientID:                   "client",
+		ClientSecret:               "secret",
+		IssuerURL:                  issuer,
+		AdditionalIDTokenAudiences: []string{"com.example.ios"},
+	}, reg)
+
+	newIDToken := func(audience string) string {
+		return sign(jwt.MapClaims{
+			"sub":   "some-subject",
+			"aud":   audience,
+			"nonce": "some-nonce",
+			"email": "foo@ory.sh",
+		})
+	}
+
+	for _, audience := range []string{"client", "com.example.ios"} {
+		t.Run("case=accepts audience "+audience, func(t *testing.T) {
+			claims, err := p.Verify(context.Background(), newIDToken(audience))
+			require.NoError(t, err)
+			assert.Equal(t, "some-subject", claims.Subject)
+			assert.Equal(t, "some-nonce", claims.Nonce)
+			assert.Equal(t, "foo@ory.sh", claims.RawClaims["email"])
+		})
+	}
+
+	t.Run("case=rejects unknown audience", func(t *testing.T) {
+		_, err := p.Verify(context.Background(), newIDToken("com.example.other"))
+		require.Error(t, err)
+	})
+
+	t.Run("case=rejects tampered token", func(t *testing.T) {
+		_, err := p.Verify(context.Background(), newIDToken("client")+"x")
+		require.Error(t, err)
+	})
+
+	t.Run("case=verifies tokens after the request which discovered the issuer ended", func(t *testing.T) {
+		issuer, sign := newIDTokenIssuer(t)
+		newProvider := func() *oidc.ProviderGenericOIDC {
+			return oidc.NewProviderGenericOIDC(&oidc.Configuration{
+				Provider:  "generic",
+				ID:        "valid",
+				ClientID:  "client",
+				IssuerURL: issuer,
+			}, reg)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		_, err := newProvider().OAuth2(ctx)
+		require.NoError(t, err)
+		cancel()
+
+		claims, err := newProvider().Verify(context.Background(), sign(jwt.MapClaims{"sub": "some-subject", "aud": "client"}))
+		require.NoError(t, err)
+		assert.Equal(t, "some-subject", claims.Subject)
+	})
+}
+
+func TestProviderGenericOIDC_Claims(t *testing.T) {
+	newProvider := func(t *testing.T, userInfo map[string]interface{}) (*oidc.ProviderGenericOIDC, func(claims jwt.MapClaims) string) {
+		issuer, sign := newIDTokenIssuerWithUserInfo(t, userInfo)
+		_, reg := internal.NewFastRegistryWithMocks(t)
+		return oidc.NewProviderGenericOIDC(&oidc.Configuration{
+			Provider:     "generic",
+			ID:           "valid",
+			ClientID:     "client",
+			ClientSecret: "secret",
+			IssuerURL:    issuer,
+		}, reg), sign
+	}
+
+	exchange := func(idToken string) *oauth2.Token {
+		return (&oauth2.Token{AccessToken: "some-access-token"}).WithExtra(map[string]interface{}{"id_token": idToken})
+	}
+
+	t.Run("case=merges the userinfo claims", func(t *testing.T) {
+		p, sign := newProvider(t, map[string]interface{}{
+			"sub":    "some-subject",
+			"email":  "userinfo@ory.sh",
+			"name":   "Some Name",
+			"groups": []string{"admin"},
+		})
+
+		claims, err := p.Claims(context.Background(), exchange(sign(jwt.MapClaims{
+			"sub":   "some-subject",
+			"aud":   "client",
+			"email": "foo@ory.sh",
+		})), url.Values{})
+		require.NoError(t, err)
+
+		assert.Equal(t, "foo@ory.sh", claims.Email, "the id_token takes precedence")
+		assert.Equal(t, "foo@ory.sh", claims.RawClaims["email"], "the id_token takes precedence")
+		assert.Equal(t, "Some Name", claims.Name)
+		assert.Equal(t, []interface{}{"admin"}, claims.RawClaims["groups"])
+	})
+
+	t.Run("case=ignores userinfo of another subject", func(t *testing.T) {
+		p, sign := newProvider(t, map[string]interface{}{"sub": "other-subject", "name": "Other Name"})
+
+		claims, err := p.Claims(context.Background(), exchange(sign(jwt.MapClaims{
+			"sub": "some-subject",
+			"aud": "client",
+		})), url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, "some-subject", claims.Subject)
+		assert.Empty(t, claims.Name)
+		assert.NotContains(t, claims.RawClaims, "name")
+	})
+
+	t.Run("case=uses the id_token if the userinfo request fails", func(t *testing.T) {
+		p, sign := newProvider(t, map[string]interface{}{"sub": "some-subject", "name": "Some Name"})
+
+		token := (&oauth2.Token{AccessToken: "invalid-access-token"}).WithExtra(map[string]interface{}{"id_token": sign(jwt.MapClaims{
+			"sub":   "some-subject",
+			"aud":   "client",
+			"email": "foo@ory.sh",
+		})})
+		claims, err := p.Claims(context.Background(), token, url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, "foo@ory.sh", claims.RawClaims["email"])
+		assert.NotContains(t, claims.RawClaims, "name")
+	})
+
+	t.Run("case=uses the id_token if there is no userinfo endpoint", func(t *testing.T) {
+		p, sign := newProvider(t, nil)
+
+		claims, err := p.Claims(context.Background(), exchange(sign(jwt.MapClaims{
+			"sub":   "some-subject",
+			"aud":   "client",
+			"email": "foo@ory.sh",
+		})), url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, "foo@ory.sh", claims.RawClaims["email"])
+	})
+}
@@ -71,6 +71,11 @@ func (g *ProviderGitHub) Claims(ctx context.Context, exchange *oauth2.Token, que
 		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
 	}
 
+	raw, err := newRawClaims(user)
+	if err != nil {
+		return nil, err
+	}
+
 	claims := &Claims{
 		Subject:   fmt.Sprintf("%d", user.GetID()),
 		Issuer:    github.Endpoint.TokenURL,
@@ -80,6 +85,7 @@ func (g *ProviderGitHub) Claims(ctx context.Context, exchange *oauth2.Token, que
 		Picture:   user.GetAvatarURL(),
 		Profile:   user.GetHTMLURL(),
 		UpdatedAt: user.GetUpdatedAt().Unix(),
+		RawClaims: raw,
 	}
 
 	// GitHub does not provide the user's private emails in the call to `/user`. Therefore, if scope "user:email" is set,
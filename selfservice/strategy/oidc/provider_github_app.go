@@ -61,6 +61,11 @@ func (g *ProviderGitHubApp) Claims(ctx context.Context, exchange *oauth2.Token,
 		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
 	}
 
+	raw, err := newRawClaims(user)
+	if err != nil {
+		return nil, err
+	}
+
 	claims := &Claims{
 		Subject:   fmt.Sprintf("%d", user.GetID()),
 		Issuer:    github.Endpoint.TokenURL,
@@ -70,6 +75,7 @@ func (g *ProviderGitHubApp) Claims(ctx context.Context, exchange *oauth2.Token,
 		Picture:   user.GetAvatarURL(),
 		Profile:   user.GetHTMLURL(),
 		UpdatedAt: user.GetUpdatedAt().Unix(),
+		RawClaims: raw,
 	}
 
 	// GitHub does not provide the user's private emails in the call to `/user`. Therefore, if scope "user:email" is set,
@@ -3,6 +3,7 @@ package oidc
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/url"
 	"path"
 
@@ -88,8 +89,17 @@ func (g *ProviderGitLab) Claims(ctx context.Context, exchange *oauth2.Token, que
 	}
 	defer resp.Body.Close()
 
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+
 	var claims Claims
-	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+	if err := json.Unmarshal(b, &claims); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+
+	if err := json.Unmarshal(b, &claims.RawClaims); err != nil {
 		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
 	}
 
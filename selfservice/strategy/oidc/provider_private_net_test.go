@@ -35,6 +35,9 @@ func TestProviderPrivateIP(t *testing.T) {
 	gitlab := func(c *oidc.Configuration) oidc.Provider {
 		return oidc.NewProviderGitLab(c, reg)
 	}
+	genericOAuth2 := func(c *oidc.Configuration) oidc.Provider {
+		return oidc.NewProviderGenericOAuth2(c, reg)
+	}
 
 	// We do not test the Auth URL as the Auth URL is not vulnerable to SSRF attacks.
 	// The AuthURL is only given to the user's browser, thus it is not possible to cause SSRF.
@@ -58,6 +61,9 @@ func TestProviderPrivateIP(t *testing.T) {
 		// not use the TokenURL at all!
 		// {p: generic, c: &oidc.Configuration{ClientID: "abcd", IssuerURL: wellknownToken, TokenURL: "http://127.0.0.3/"}, e: "ip 127.0.0.1 is in the 127.0.0.0/8", id: fakeJWTToken},
 
+		// If a userinfo URL is local, we fail
+		{p: genericOAuth2, c: &oidc.Configuration{UserinfoRequests: []oidc.UserinfoRequest{{Name: "user", URL: "http://127.0.0.2/"}}}, e: "ip 127.0.0.2 is in the 127.0.0.0/8"},
+
 		// Discord uses a fixed token URL and does not use the issuer.
 		// Facebook uses a fixed token URL and does not use the issuer.
 		// GitHub uses a fixed token URL and does not use the issuer.
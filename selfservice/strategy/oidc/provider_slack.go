@@ -72,6 +72,11 @@ func (d *ProviderSlack) Claims(ctx context.Context, exchange *oauth2.Token, quer
 		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
 	}
 
+	raw, err := newRawClaims(identity)
+	if err != nil {
+		return nil, err
+	}
+
 	claims := &Claims{
 		Issuer:            "https://slack.com/oauth/",
 		Subject:           identity.User.ID,
@@ -82,6 +87,7 @@ func (d *ProviderSlack) Claims(ctx context.Context, exchange *oauth2.Token, quer
 		EmailVerified:     true,
 		Picture:           identity.User.Image512,
 		Team:              identity.Team.ID,
+		RawClaims:         raw,
 	}
 
 	return claims, nil
@@ -80,6 +80,11 @@ func (g *ProviderSpotify) Claims(ctx context.Context, exchange *oauth2.Token, qu
 		userPicture = user.Images[0].URL
 	}
 
+	raw, err := newRawClaims(user)
+	if err != nil {
+		return nil, err
+	}
+
 	claims := &Claims{
 		Subject:   user.ID,
 		Issuer:    spotify.Endpoint.TokenURL,
@@ -89,6 +94,7 @@ func (g *ProviderSpotify) Claims(ctx context.Context, exchange *oauth2.Token, qu
 		Picture:   userPicture,
 		Profile:   user.ExternalURLs["spotify"],
 		Birthdate: user.Birthdate,
+		RawClaims: raw,
 	}
 
 	return claims, nil
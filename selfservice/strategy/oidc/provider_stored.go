@@ -0,0 +1,95 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ory/x/sqlxx"
+
+	"github.com/ory/kratos/cipher"
+)
+
+// StoredConfiguration is an OpenID Connect provider configuration which is managed using the admin API
+// and stored in the database. The client secret and the Apple private key are stored encrypted.
+type StoredConfiguration struct {
+	ID  uuid.UUID `db:"id"`
+	NID uuid.UUID `db:"nid"`
+
+	// ProviderID is the provider's ID as used in the callback URL.
+	ProviderID string `db:"provider_id"`
+
+	// Config is the provider's configuration without the client secret and the Apple private key.
+	Config sqlxx.JSONRawMessage `db:"config"`
+
+	// ClientSecret is the encrypted client secret.
+	ClientSecret string `db:"client_secret"`
+
+	// PrivateKey is the encrypted Apple private key.
+	PrivateKey string `db:"apple_private_key"`
+
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (StoredConfiguration) TableName(ctx context.Context) string {
+	return "selfservice_oidc_providers"
+}
+
+func (c StoredConfiguration) GetID() uuid.UUID {
+	return c.ID
+}
+
+func (c StoredConfiguration) GetNID() uuid.UUID {
+	return c.NID
+}
+
+// setConfiguration stores the provider configuration and encrypts its secrets.
+func (c *StoredConfiguration) setConfiguration(ctx context.Context, ci cipher.Cipher, conf Configuration) error {
+	secret, err := ci.Encrypt(ctx, []byte(conf.ClientSecret))
+	if err != nil {
+		return err
+	}
+
+	privateKey, err := ci.Encrypt(ctx, []byte(conf.PrivateKey))
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(conf.withoutSecrets())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	c.ProviderID = conf.ID
+	c.Config = raw
+	c.ClientSecret = secret
+	c.PrivateKey = privateKey
+	return nil
+}
+
+// configuration returns the provider configuration with its decrypted secrets.
+func (c *StoredConfiguration) configuration(ctx context.Context, ci cipher.Cipher) (*Configuration, error) {
+	var conf Configuration
+	if err := json.Unmarshal(c.Config, &conf); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	secret, err := ci.Decrypt(ctx, c.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := ci.Decrypt(ctx, c.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	conf.ID = c.ProviderID
+	conf.ClientSecret = string(secret)
+	conf.PrivateKey = string(privateKey)
+	return &conf, nil
+}
@@ -3,6 +3,7 @@ package oidc
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/url"
 	"strconv"
 
@@ -90,7 +91,19 @@ func (g *ProviderVK) Claims(ctx context.Context, exchange *oauth2.Token, query u
 		Result []User `json:"response,omitempty"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+
+	if err := json.Unmarshal(b, &response); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+
+	var rawResponse struct {
+		Result []map[string]interface{} `json:"response,omitempty"`
+	}
+	if err := json.Unmarshal(b, &rawResponse); err != nil {
 		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
 	}
 
@@ -118,5 +131,6 @@ func (g *ProviderVK) Claims(ctx context.Context, exchange *oauth2.Token, query u
 		Email:      user.Email,
 		Gender:     gender,
 		Birthdate:  user.BirthDay,
+		RawClaims:  rawResponse.Result[0],
 	}, nil
 }
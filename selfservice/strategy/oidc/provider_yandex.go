@@ -3,6 +3,7 @@ package oidc
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/url"
 
 	"github.com/hashicorp/go-retryablehttp"
@@ -83,7 +84,17 @@ func (g *ProviderYandex) Claims(ctx context.Context, exchange *oauth2.Token, que
 		BirthDay     string `json:"birthday,omitempty"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+
+	if err := json.Unmarshal(b, &user); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
 		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("%s", err))
 	}
 
@@ -102,5 +113,6 @@ func (g *ProviderYandex) Claims(ctx context.Context, exchange *oauth2.Token, que
 		Email:      user.Email,
 		Gender:     user.Gender,
 		Birthdate:  user.BirthDay,
+		RawClaims:  raw,
 	}, nil
 }
@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ory/kratos/cipher"
 
@@ -93,6 +95,9 @@ type dependencies interface {
 	continuity.ManagementProvider
 
 	cipher.Provider
+
+	ProviderPersistenceProvider
+	DiscoveryCacheProvider
 }
 
 func isForced(req interface{}) bool {
@@ -102,18 +107,34 @@ func isForced(req interface{}) bool {
 	return ok && f.IsForced()
 }
 
+// storedProvidersCacheTTL is how long the decrypted providers from the database are reused. Changes made
+// using the admin API of this instance take effect immediately, changes made on other instances after the TTL.
+const storedProvidersCacheTTL = time.Minute
+
 // Strategy implements selfservice.LoginStrategy, selfservice.RegistrationStrategy and selfservice.SettingsStrategy.
 // It supports login, registration and settings via OpenID Providers.
 type Strategy struct {
 	d         dependencies
 	validator *schema.Validator
 	dec       *decoderx.HTTP
+
+	storedProvidersLock sync.RWMutex
+	storedProviders     map[uuid.UUID]storedProviders
+}
+
+// storedProviders are the decrypted providers of a network which were created using the admin API.
+type storedProviders struct {
+	providers []Configuration
+	fetchedAt time.Time
 }
 
 type authCodeContainer struct {
 	FlowID string          `json:"flow_id"`
 	State  string          `json:"state"`
 	Traits json.RawMessage `json:"traits"`
+
+	// PKCEVerifier is the PKCE code verifier sent on the token exchange. It is empty if PKCE is not used.
+	PKCEVerifier string `json:"pkce_verifier,omitempty"`
 }
 
 func generateState(flowID string) string {
@@ -332,7 +353,7 @@ func (s *Strategy) handleCallback(w http.ResponseWriter, r *http.Request, ps htt
 		}
 	}
 
-	token, err := te.Exchange(r.Context(), code)
+	token, err := te.Exchange(r.Context(), code, pkceExchangeOptions(cntnr.PKCEVerifier)...)
 	if err != nil {
 		s.forwardError(w, r, req, s.handleError(w, r, req, pid, nil, err))
 		return
@@ -394,7 +415,8 @@ func (s *Strategy) populateMethod(r *http.Request, c *container.Container, messa
 	return nil
 }
 
-func (s *Strategy) Config(ctx context.Context) (*ConfigurationCollection, error) {
+// staticConfig returns the providers from the configuration file only.
+func (s *Strategy) staticConfig(ctx context.Context) (*ConfigurationCollection, error) {
 	var c ConfigurationCollection
 
 	conf := s.d.Config().SelfServiceStrategy(ctx, string(s.ID())).Config
@@ -408,6 +430,77 @@ func (s *Strategy) Config(ctx context.Context) (*ConfigurationCollection, error)
 	return &c, nil
 }
 
+func (s *Strategy) Config(ctx context.Context) (*ConfigurationCollection, error) {
+	c, err := s.staticConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Providers managed using the admin API are added to the ones from the configuration file. If both
+	// define the same provider ID, the configuration file takes precedence.
+	stored, err := s.storedConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range stored {
+		if _, ok := c.find(p.ID); ok {
+			continue
+		}
+
+		c.Providers = append(c.Providers, p)
+	}
+
+	return c, nil
+}
+
+// storedConfig returns the decrypted providers which were created using the admin API. Providers which can
+// not be decrypted, for example because the secrets were rotated, are skipped so that the other providers
+// keep working.
+func (s *Strategy) storedConfig(ctx context.Context) ([]Configuration, error) {
+	nid := s.d.OIDCProviderPersister().NetworkID(ctx)
+
+	s.storedProvidersLock.RLock()
+	if cached, ok := s.storedProviders[nid]; ok && time.Since(cached.fetchedAt) < storedProvidersCacheTTL {
+		defer s.storedProvidersLock.RUnlock()
+		return cached.providers, nil
+	}
+	s.storedProvidersLock.RUnlock()
+
+	stored, err := s.d.OIDCProviderPersister().ListOIDCProviders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	providers := make([]Configuration, 0, len(stored))
+	for k := range stored {
+		p, err := stored[k].configuration(ctx, s.d.Cipher(ctx))
+		if err != nil {
+			s.d.Logger().WithError(err).WithField("provider", stored[k].ProviderID).
+				Error("Unable to decrypt the OpenID Connect provider configuration, the provider is skipped.")
+			continue
+		}
+		providers = append(providers, *p)
+	}
+
+	s.storedProvidersLock.Lock()
+	defer s.storedProvidersLock.Unlock()
+	if s.storedProviders == nil {
+		s.storedProviders = make(map[uuid.UUID]storedProviders)
+	}
+	s.storedProviders[nid] = storedProviders{providers: providers, fetchedAt: time.Now()}
+	return providers, nil
+}
+
+// invalidateStoredConfig makes the next call to Config read the providers of the network from the database again.
+func (s *Strategy) invalidateStoredConfig(ctx context.Context) {
+	nid := s.d.OIDCProviderPersister().NetworkID(ctx)
+
+	s.storedProvidersLock.Lock()
+	defer s.storedProvidersLock.Unlock()
+	delete(s.storedProviders, nid)
+}
+
 func (s *Strategy) provider(ctx context.Context, r *http.Request, id string) (Provider, error) {
 	if c, err := s.Config(ctx); err != nil {
 		return nil, err
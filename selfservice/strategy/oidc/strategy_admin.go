@@ -0,0 +1,361 @@
+package oidc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/ory/herodot"
+	"github.com/ory/jsonschema/v3"
+	"github.com/ory/x/jsonx"
+
+	"github.com/ory/kratos/embedx"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/x"
+)
+
+const (
+	RouteAdminProviders = "/oidc/providers"
+	RouteAdminProvider  = RouteAdminProviders + "/:id"
+)
+
+var _ login.AdminHandler = new(Strategy)
+
+var (
+	providerSchema     *jsonschema.Schema
+	providerSchemaErr  error
+	providerSchemaOnce sync.Once
+)
+
+func (s *Strategy) RegisterAdminLoginRoutes(admin *x.RouterAdmin) {
+	admin.GET(RouteAdminProviders, s.adminListProviders)
+	admin.GET(RouteAdminProvider, s.adminGetProvider)
+	admin.POST(RouteAdminProviders, s.adminCreateProvider)
+	admin.PUT(RouteAdminProvider, s.adminUpdateProvider)
+	admin.DELETE(RouteAdminProvider, s.adminDeleteProvider)
+
+	admin.POST(RouteAdminIdentityToken, s.adminRefreshIdentityToken)
+}
+
+// A list of OpenID Connect providers.
+// swagger:model oidcProviderList
+// nolint:deadcode,unused
+type oidcProviderList []Configuration
+
+// swagger:parameters adminGetOidcProvider adminDeleteOidcProvider
+// nolint:deadcode,unused
+type adminOidcProviderID struct {
+	// ID is the provider's ID
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+}
+
+// swagger:parameters adminCreateOidcProvider
+// nolint:deadcode,unused
+type adminCreateOidcProvider struct {
+	// in: body
+	Body Configuration
+}
+
+// swagger:parameters adminUpdateOidcProvider
+// nolint:deadcode,unused
+type adminUpdateOidcProvider struct {
+	// ID is the provider's ID
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+
+	// in: body
+	Body Configuration
+}
+
+// swagger:route GET /admin/oidc/providers v0alpha2 adminListOidcProviders
+//
+// # List OpenID Connect Providers
+//
+// Lists all OpenID Connect providers which were created using the admin API. Providers from the
+// configuration file are not included. Client secrets are never returned.
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  200: oidcProviderList
+//	  500: jsonError
+func (s *Strategy) adminListProviders(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	stored, err := s.d.OIDCProviderPersister().ListOIDCProviders(r.Context())
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	providers := make([]Configuration, len(stored))
+	for k := range stored {
+		c, err := stored[k].configuration(r.Context(), s.d.Cipher(r.Context()))
+		if err != nil {
+			s.d.Writer().WriteError(w, r, err)
+			return
+		}
+		providers[k] = c.withoutSecrets()
+	}
+
+	s.d.Writer().Write(w, r, providers)
+}
+
+// swagger:route GET /admin/oidc/providers/{id} v0alpha2 adminGetOidcProvider
+//
+// # Get an OpenID Connect Provider
+//
+// Returns an OpenID Connect provider which was created using the admin API. The client secret is never returned.
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  200: oidcProvider
+//	  404: jsonError
+//	  500: jsonError
+func (s *Strategy) adminGetProvider(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	stored, err := s.d.OIDCProviderPersister().GetOIDCProvider(r.Context(), ps.ByName("id"))
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	c, err := stored.configuration(r.Context(), s.d.Cipher(r.Context()))
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	s.d.Writer().Write(w, r, c.withoutSecrets())
+}
+
+// swagger:route POST /admin/oidc/providers v0alpha2 adminCreateOidcProvider
+//
+// # Create an OpenID Connect Provider
+//
+// Creates an OpenID Connect provider which can be used for sign in right away. The provider's ID must not
+// be used by another provider, including the ones from the configuration file.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  201: oidcProvider
+//	  400: jsonError
+//	  409: jsonError
+//	  500: jsonError
+func (s *Strategy) adminCreateProvider(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	c, err := s.decodeProviderConfiguration(r, nil)
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if err := s.validateProviderConfiguration(r.Context(), c); err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	var stored StoredConfiguration
+	if err := stored.setConfiguration(r.Context(), s.d.Cipher(r.Context()), *c); err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if err := s.d.OIDCProviderPersister().CreateOIDCProvider(r.Context(), &stored); err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+	s.invalidateStoredConfig(r.Context())
+
+	s.d.Writer().WriteCreated(w, r,
+		x.AdminPrefix+RouteAdminProviders+"/"+c.ID,
+		c.withoutSecrets(),
+	)
+}
+
+// swagger:route PUT /admin/oidc/providers/{id} v0alpha2 adminUpdateOidcProvider
+//
+// # Update an OpenID Connect Provider
+//
+// Replaces the configuration of an OpenID Connect provider which was created using the admin API. If the
+// client secret or the Apple private key are empty, the stored values are kept.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  200: oidcProvider
+//	  400: jsonError
+//	  404: jsonError
+//	  500: jsonError
+func (s *Strategy) adminUpdateProvider(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	stored, err := s.d.OIDCProviderPersister().GetOIDCProvider(r.Context(), ps.ByName("id"))
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	original, err := stored.configuration(r.Context(), s.d.Cipher(r.Context()))
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	c, err := s.decodeProviderConfiguration(r, original)
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if c.ID != stored.ProviderID {
+		s.d.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithReasonf("The provider ID can not be changed.")))
+		return
+	}
+
+	if err := s.validateProviderConfiguration(r.Context(), c); err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if err := stored.setConfiguration(r.Context(), s.d.Cipher(r.Context()), *c); err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if err := s.d.OIDCProviderPersister().UpdateOIDCProvider(r.Context(), stored); err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+	s.invalidateStoredConfig(r.Context())
+
+	s.d.Writer().Write(w, r, c.withoutSecrets())
+}
+
+// swagger:route DELETE /admin/oidc/providers/{id} v0alpha2 adminDeleteOidcProvider
+//
+// # Delete an OpenID Connect Provider
+//
+// Deletes an OpenID Connect provider which was created using the admin API. Identities which signed up using
+// the provider keep their credentials but can no longer sign in with it.
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  204: emptyResponse
+//	  404: jsonError
+//	  500: jsonError
+func (s *Strategy) adminDeleteProvider(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if err := s.d.OIDCProviderPersister().DeleteOIDCProvider(r.Context(), ps.ByName("id")); err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+	s.invalidateStoredConfig(r.Context())
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeProviderConfiguration decodes the provider configuration and validates it against the same
+// JSON schema which is used for providers in the configuration file. If original is set, its secrets
+// are used when the request body omits them.
+func (s *Strategy) decodeProviderConfiguration(r *http.Request, original *Configuration) (*Configuration, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to read the request body: %s", err))
+	}
+
+	if original != nil {
+		for key, value := range map[string]string{
+			"client_secret":     original.ClientSecret,
+			"apple_private_key": original.PrivateKey,
+		} {
+			if len(value) == 0 || len(gjson.GetBytes(body, key).String()) > 0 {
+				continue
+			}
+
+			if body, err = sjson.SetBytes(body, key, value); err != nil {
+				return nil, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to decode the OpenID Connect provider configuration: %s", err))
+			}
+		}
+	}
+
+	providerSchemaOnce.Do(func() {
+		c := jsonschema.NewCompiler()
+		if providerSchemaErr = embedx.AddSchemaResources(c, embedx.Config); providerSchemaErr != nil {
+			return
+		}
+		providerSchema, providerSchemaErr = c.Compile(context.Background(), embedx.Config.GetSchemaID()+"#/definitions/selfServiceOIDCProvider")
+	})
+	if providerSchemaErr != nil {
+		return nil, errors.WithStack(providerSchemaErr)
+	}
+
+	if err := providerSchema.Validate(bytes.NewReader(body)); err != nil {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReasonf("The OpenID Connect provider configuration is invalid: %s", err))
+	}
+
+	var c Configuration
+	if err := jsonx.NewStrictDecoder(bytes.NewReader(body)).Decode(&c); err != nil {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to decode the OpenID Connect provider configuration: %s", err))
+	}
+
+	return &c, nil
+}
+
+func (s *Strategy) validateProviderConfiguration(ctx context.Context, c *Configuration) error {
+	static, err := s.staticConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := static.find(c.ID); ok {
+		return errors.WithStack(herodot.ErrConflict.WithReasonf("An OpenID Connect provider with ID %s is defined in the configuration file already.", c.ID))
+	}
+
+	if _, err := (ConfigurationCollection{Providers: []Configuration{*c}}).Provider(c.ID, s.d); err != nil {
+		return errors.WithStack(herodot.ErrBadRequest.WithReasonf("%s", err))
+	}
+
+	return nil
+}
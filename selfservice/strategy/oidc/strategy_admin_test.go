@@ -0,0 +1,228 @@
+package oidc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/x/contextx"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
+	"github.com/ory/kratos/x"
+)
+
+func TestStrategyAdminProviders(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	publicTS, adminTS := testhelpers.NewKratosServer(t, reg)
+	_ = newUI(t, reg)
+
+	viperSetProviderConfig(t, conf, oidc.Configuration{
+		Provider:     "generic",
+		ID:           "from-file",
+		ClientID:     "client",
+		ClientSecret: "secret",
+		IssuerURL:    "https://foobar.ory.sh",
+		Mapper:       "file://./stub/oidc.hydra.jsonnet",
+	})
+
+	do := func(t *testing.T, method, path string, body interface{}) (*http.Response, []byte) {
+		var payload bytes.Buffer
+		if body != nil {
+			require.NoError(t, json.NewEncoder(&payload).Encode(body))
+		}
+
+		req, err := http.NewRequest(method, adminTS.URL+"/admin/oidc/providers"+path, &payload)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := adminTS.Client().Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		raw, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, raw
+	}
+
+	loginProviders := func(t *testing.T) []string {
+		f := testhelpers.InitializeLoginFlowViaBrowser(t, testhelpers.NewClientWithCookies(t), publicTS, false, true)
+		var providers []string
+		for _, n := range f.Ui.Nodes {
+			if n.Group == "oidc" && n.Attributes.UiNodeInputAttributes != nil {
+				providers = append(providers, n.Attributes.UiNodeInputAttributes.Value.(string))
+			}
+		}
+		return providers
+	}
+
+	newProvider := func(id string) map[string]interface{} {
+		return map[string]interface{}{
+			"id":            id,
+			"provider":      "generic",
+			"client_id":     "client-" + id,
+			"client_secret": "secret-" + id,
+			"issuer_url":    "https://foobar.ory.sh",
+			"mapper_url":    "file://./stub/oidc.hydra.jsonnet",
+		}
+	}
+
+	storedSecret := func(t *testing.T, id string) string {
+		stored, err := reg.OIDCProviderPersister().GetOIDCProvider(ctx, id)
+		require.NoError(t, err)
+		secret, err := reg.Cipher(ctx).Decrypt(ctx, stored.ClientSecret)
+		require.NoError(t, err)
+		return string(secret)
+	}
+
+	t.Run("case=should create a provider and use it right away", func(t *testing.T) {
+		res, body := do(t, "POST", "", newProvider("acme"))
+		require.Equal(t, http.StatusCreated, res.StatusCode, "%s", body)
+		assert.Equal(t, "acme", gjson.GetBytes(body, "id").String())
+		assert.Equal(t, "client-acme", gjson.GetBytes(body, "client_id").String())
+		assert.Empty(t, gjson.GetBytes(body, "client_secret").String())
+
+		stored, err := reg.OIDCProviderPersister().GetOIDCProvider(ctx, "acme")
+		require.NoError(t, err)
+		assert.NotContains(t, string(stored.Config), "secret-acme")
+		assert.NotEqual(t, "secret-acme", stored.ClientSecret)
+		assert.Equal(t, "secret-acme", storedSecret(t, "acme"))
+
+		providers := loginProviders(t)
+		assert.Contains(t, providers, "from-file")
+		assert.Contains(t, providers, "acme")
+	})
+
+	t.Run("case=should get and list providers without secrets", func(t *testing.T) {
+		res, body := do(t, "GET", "/acme", nil)
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+		assert.Equal(t, "acme", gjson.GetBytes(body, "id").String())
+		assert.Empty(t, gjson.GetBytes(body, "client_secret").String())
+
+		res, body = do(t, "GET", "", nil)
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+		assert.Equal(t, []interface{}{"acme"}, gjson.GetBytes(body, "#.id").Value())
+		assert.NotContains(t, string(body), "secret-acme")
+	})
+
+	t.Run("case=should reject invalid providers", func(t *testing.T) {
+		for k, tc := range []struct {
+			d    string
+			body map[string]interface{}
+			code int
+		}{
+			{d: "id from the configuration file", body: newProvider("from-file"), code: http.StatusConflict},
+			{d: "id already in use", body: newProvider("acme"), code: http.StatusConflict},
+			{d: "missing mapper", body: func() map[string]interface{} {
+				p := newProvider("no-mapper")
+				delete(p, "mapper_url")
+				return p
+			}(), code: http.StatusBadRequest},
+			{d: "unknown provider", body: func() map[string]interface{} {
+				p := newProvider("unknown")
+				p["provider"] = "i-do-not-exist"
+				return p
+			}(), code: http.StatusBadRequest},
+		} {
+			t.Run("case="+tc.d, func(t *testing.T) {
+				res, body := do(t, "POST", "", tc.body)
+				assert.Equal(t, tc.code, res.StatusCode, "%d: %s", k, body)
+			})
+		}
+	})
+
+	t.Run("case=should update a provider and keep the secret if omitted", func(t *testing.T) {
+		p := newProvider("acme")
+		p["client_id"] = "changed"
+		delete(p, "client_secret")
+
+		res, body := do(t, "PUT", "/acme", p)
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+		assert.Equal(t, "changed", gjson.GetBytes(body, "client_id").String())
+		assert.Equal(t, "secret-acme", storedSecret(t, "acme"))
+
+		p["client_secret"] = "rotated"
+		res, body = do(t, "PUT", "/acme", p)
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+		assert.Equal(t, "rotated", storedSecret(t, "acme"))
+
+		p["id"] = "renamed"
+		res, body = do(t, "PUT", "/acme", p)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+
+		res, body = do(t, "PUT", "/i-do-not-exist", newProvider("i-do-not-exist"))
+		assert.Equal(t, http.StatusNotFound, res.StatusCode, "%s", body)
+	})
+
+	t.Run("case=should delete a provider", func(t *testing.T) {
+		res, body := do(t, "DELETE", "/acme", nil)
+		require.Equal(t, http.StatusNoContent, res.StatusCode, "%s", body)
+
+		res, _ = do(t, "GET", "/acme", nil)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+		res, _ = do(t, "DELETE", "/acme", nil)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+		assert.NotContains(t, loginProviders(t), "acme")
+	})
+
+	t.Run("case=should skip providers which can not be decrypted", func(t *testing.T) {
+		require.NoError(t, reg.OIDCProviderPersister().CreateOIDCProvider(ctx, &oidc.StoredConfiguration{
+			ProviderID:   "broken",
+			Config:       []byte(`{"provider":"generic","client_id":"client","issuer_url":"https://foobar.ory.sh","mapper_url":"file://./stub/oidc.hydra.jsonnet"}`),
+			ClientSecret: "not-encrypted",
+			PrivateKey:   "not-encrypted",
+		}))
+
+		res, body := do(t, "POST", "", newProvider("working"))
+		require.Equal(t, http.StatusCreated, res.StatusCode, "%s", body)
+
+		providers := loginProviders(t)
+		assert.Contains(t, providers, "from-file")
+		assert.Contains(t, providers, "working")
+		assert.NotContains(t, providers, "broken")
+	})
+}
+
+func TestStrategyAdminProvidersNetworks(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	reg.WithContextualizer(&contextx.TestContextualizer{})
+	viperSetProviderConfig(t, conf)
+
+	s, err := reg.AllLoginStrategies().Strategy(identity.CredentialsTypeOIDC)
+	require.NoError(t, err)
+	strategy := s.(*oidc.Strategy)
+
+	ids := func(t *testing.T, ctx context.Context) []string {
+		c, err := strategy.Config(ctx)
+		require.NoError(t, err)
+		var ids []string
+		for _, p := range c.Providers {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	secret, err := reg.Cipher(ctx).Encrypt(ctx, []byte("secret"))
+	require.NoError(t, err)
+	require.NoError(t, reg.OIDCProviderPersister().CreateOIDCProvider(ctx, &oidc.StoredConfiguration{
+		ProviderID:   "acme",
+		Config:       []byte(`{"provider":"generic","client_id":"client","issuer_url":"https://foobar.ory.sh","mapper_url":"file://./stub/oidc.hydra.jsonnet"}`),
+		ClientSecret: secret,
+	}))
+
+	assert.Equal(t, []string{"acme"}, ids(t, ctx))
+	assert.Empty(t, ids(t, contextx.SetNIDContext(ctx, x.NewUUID())), "providers of one network must not be served to another")
+}
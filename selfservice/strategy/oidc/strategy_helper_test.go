@@ -3,9 +3,13 @@ package oidc_test
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/big"
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httptest"
@@ -14,6 +18,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/julienschmidt/httprouter"
 	"github.com/phayes/freeport"
 	"github.com/pkg/errors"
@@ -35,6 +40,8 @@ import (
 )
 
 type idTokenClaims struct {
+	// email is sent as a verified email address if set.
+	email  string
 	traits struct {
 		website string
 	}
@@ -137,7 +144,12 @@ func newHydraIntegration(t *testing.T, remote *string, subject *string, claims *
 		require.NotEmpty(t, challenge)
 
 		var b bytes.Buffer
-		var msg = `{"id_token":{"website":"` + claims.traits.website + `","picture":"` + *&claims.metadataPublic.picture + `","phone_number":"` + *&claims.metadataAdmin.phoneNumber + `"}}`
+		var email string
+		if claims.email != "" {
+			email = `"email":"` + claims.email + `","email_verified":true,`
+		}
+
+		var msg = `{"id_token":{` + email + `"website":"` + claims.traits.website + `","picture":"` + *&claims.metadataPublic.picture + `","phone_number":"` + *&claims.metadataAdmin.phoneNumber + `"}}`
 		require.NoError(t, json.NewEncoder(&b).Encode(&p{GrantScope: *scope, Session: json.RawMessage(msg)}))
 		href := urlx.MustJoin(*remote, "/oauth2/auth/requests/consent/accept") + "?consent_challenge=" + challenge
 		do(w, r, href, &b)
@@ -307,3 +319,62 @@ func AssertSystemError(t *testing.T, errTS *httptest.Server, res *http.Response,
 	assert.Equal(t, int64(code), gjson.GetBytes(body, "code").Int(), "%s", body)
 	assert.Contains(t, gjson.GetBytes(body, "reason").String(), reason, "%s", body)
 }
+
+// newIDTokenIssuer starts an OpenID Connect issuer which signs ID tokens as they are obtained by native apps.
+func newIDTokenIssuer(t *testing.T) (issuer string, sign func(claims jwt.MapClaims) string) {
+	return newIDTokenIssuerWithUserInfo(t, nil)
+}
+
+// newIDTokenIssuerWithUserInfo is like newIDTokenIssuer but also serves the given claims at the userinfo endpoint.
+func newIDTokenIssuerWithUserInfo(t *testing.T, userInfo map[string]interface{}) (issuer string, sign func(claims jwt.MapClaims) string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	router := http.NewServeMux()
+	router.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		discovery := map[string]interface{}{
+			"issuer":                 issuer,
+			"authorization_endpoint": issuer + "/oauth2/auth",
+			"token_endpoint":         issuer + "/oauth2/token",
+			"jwks_uri":               issuer + "/.well-known/jwks.json",
+		}
+		if userInfo != nil {
+			discovery["userinfo_endpoint"] = issuer + "/userinfo"
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(discovery))
+	})
+	router.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer some-access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(userInfo))
+	})
+	router.HandleFunc("/.well-known/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]interface{}{{
+				"kty": "RSA",
+				"kid": "key",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		}))
+	})
+	ts := httptest.NewServer(router)
+	t.Cleanup(ts.Close)
+	issuer = ts.URL
+
+	return issuer, func(claims jwt.MapClaims) string {
+		claims["iss"] = issuer
+		claims["iat"] = time.Now().Unix()
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "key"
+		raw, err := token.SignedString(key)
+		require.NoError(t, err)
+		return raw
+	}
+}
@@ -0,0 +1,100 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+
+	"github.com/ory/herodot"
+
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/selfservice/flow/registration"
+)
+
+// verifyIDToken verifies an ID token which a native app obtained from the provider, e.g. using the Apple or
+// Google sign in SDKs, and returns its claims.
+//
+// ID tokens can only be submitted to API flows. The token must contain the ID of the flow as its nonce so that it
+// can not be replayed in another flow. Because some SDKs require the app to pass the SHA-256 hash of the nonce to
+// the provider, the token's nonce may also be the hex encoded hash of the flow ID.
+func (s *Strategy) verifyIDToken(ctx context.Context, f flow.Flow, provider Provider, idToken string) (*Claims, error) {
+	if f.GetType() != flow.TypeAPI {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReason("Signing in with an id_token is only supported for API flows."))
+	}
+
+	verifier, ok := provider.(IDTokenVerifier)
+	if !ok {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReasonf("The provider %s does not support signing in with an id_token.", provider.Config().ID))
+	}
+
+	claims, err := verifier.Verify(ctx, idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyNonce(claims, f.GetID().String()); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func verifyNonce(claims *Claims, nonce string) error {
+	if len(claims.Nonce) == 0 {
+		return errors.WithStack(herodot.ErrBadRequest.WithReason("The id_token does not contain a nonce."))
+	}
+
+	hashed := fmt.Sprintf("%x", sha256.Sum256([]byte(nonce)))
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 &&
+		subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(hashed)) != 1 {
+		return errors.WithStack(herodot.ErrBadRequest.WithReason("The nonce of the id_token must be the ID of the flow or its SHA-256 hash."))
+	}
+
+	return nil
+}
+
+// idTokenToken wraps an ID token obtained by a native app so that it is stored with the credentials the same
+// way as ID tokens obtained using the redirect flow.
+func idTokenToken(idToken string) *oauth2.Token {
+	return new(oauth2.Token).WithExtra(map[string]interface{}{"id_token": idToken})
+}
+
+func (s *Strategy) loginWithIDToken(w http.ResponseWriter, r *http.Request, f *login.Flow, provider Provider, p *SubmitSelfServiceLoginFlowWithOidcMethodBody) error {
+	claims, err := s.verifyIDToken(r.Context(), f, provider, p.IDToken)
+	if err != nil {
+		return s.handleError(w, r, f, provider.Config().ID, nil, err)
+	}
+
+	f.Active = s.ID()
+	if _, err := s.processLogin(w, r, f, idTokenToken(p.IDToken), claims, provider, &authCodeContainer{
+		FlowID: f.ID.String(),
+		Traits: p.Traits,
+	}); err != nil {
+		return err
+	}
+
+	return errors.WithStack(flow.ErrCompletedByStrategy)
+}
+
+func (s *Strategy) registerWithIDToken(w http.ResponseWriter, r *http.Request, f *registration.Flow, provider Provider, p *SubmitSelfServiceRegistrationFlowWithOidcMethodBody) error {
+	claims, err := s.verifyIDToken(r.Context(), f, provider, p.IDToken)
+	if err != nil {
+		return s.handleError(w, r, f, provider.Config().ID, nil, err)
+	}
+
+	f.Active = s.ID()
+	if _, err := s.processRegistration(w, r, f, idTokenToken(p.IDToken), claims, provider, &authCodeContainer{
+		FlowID: f.ID.String(),
+		Traits: p.Traits,
+	}); err != nil {
+		return err
+	}
+
+	return errors.WithStack(flow.ErrCompletedByStrategy)
+}
@@ -0,0 +1,33 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyNonce(t *testing.T) {
+	hashed := fmt.Sprintf("%x", sha256.Sum256([]byte("nonce")))
+	for k, tc := range []struct {
+		claims *Claims
+		nonce  string
+		pass   bool
+	}{
+		{claims: &Claims{Nonce: "nonce"}, nonce: "nonce", pass: true},
+		{claims: &Claims{Nonce: hashed}, nonce: "nonce", pass: true},
+		{claims: &Claims{Nonce: "nonce"}, nonce: "other", pass: false},
+		{claims: &Claims{Nonce: "nonce"}, nonce: hashed, pass: false},
+		{claims: &Claims{}, nonce: "nonce", pass: false},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			err := verifyNonce(tc.claims, tc.nonce)
+			if tc.pass {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package oidc
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/x/sqlcon"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/selfservice/flow/registration"
+	"github.com/ory/kratos/text"
+)
+
+// linkToExistingIdentity checks if an identity with the email address returned by the provider exists
+// already. If so, the browser is sent to a new login flow in which the user has to sign in to that identity
+// using one of its existing credentials. The OpenID Connect credentials are added to the identity once
+// the login succeeded.
+//
+// Only email addresses verified by the provider are considered. Returns true if the browser was redirected.
+func (s *Strategy) linkToExistingIdentity(w http.ResponseWriter, r *http.Request, a *registration.Flow, claims *Claims, provider Provider, creds *identity.Credentials) (bool, error) {
+	// Linking requires the user to sign in using the browser.
+	if a.Type != flow.TypeBrowser || len(claims.Email) == 0 || !bool(claims.EmailVerified) {
+		return false, nil
+	}
+
+	address, err := s.d.PrivilegedIdentityPool().FindVerifiableAddressByValue(r.Context(), identity.VerifiableAddressTypeEmail, strings.ToLower(claims.Email))
+	if errors.Is(err, sqlcon.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	s.d.Logger().
+		WithRequest(r).
+		WithField("provider", provider.Config().ID).
+		WithField("identity_id", address.IdentityID).
+		Debug("Received successful OpenID Connect callback but an identity with the same email address exists already. Asking the user to sign in to link the accounts.")
+
+	// If return_to was set before, we need to preserve it.
+	var opts []login.FlowOption
+	if len(a.ReturnTo) > 0 {
+		opts = append(opts, login.WithFlowReturnTo(a.ReturnTo))
+	}
+
+	lf, err := s.d.LoginHandler().NewLoginFlow(w, r, flow.TypeBrowser, opts...)
+	if err != nil {
+		return false, err
+	}
+
+	if err := lf.SetLinkCredentials(&login.LinkCredentials{IdentityID: address.IdentityID, Credentials: *creds}); err != nil {
+		return false, err
+	}
+
+	lf.UI.Messages.Add(text.NewInfoLoginLinkCredentials(provider.Config().ID, claims.Email))
+	lf.UI.Nodes.SetValueAttribute("identifier", claims.Email)
+	if err := s.d.LoginFlowPersister().UpdateLoginFlow(r.Context(), lf); err != nil {
+		return false, err
+	}
+
+	http.Redirect(w, r, lf.AppendTo(s.d.Config().SelfServiceFlowLoginUI(r.Context())).String(), http.StatusSeeOther)
+	return true, nil
+}
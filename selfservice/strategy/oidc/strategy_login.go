@@ -70,6 +70,12 @@ type SubmitSelfServiceLoginFlowWithOidcMethodBody struct {
 
 	// The identity traits. This is a placeholder for the registration flow.
 	Traits json.RawMessage `json:"traits"`
+
+	// IDToken is an ID token obtained by a native app from the provider, e.g. using the Apple or Google sign in
+	// SDKs. If set, the ID token is verified and used instead of redirecting to the provider. ID tokens are only
+	// accepted by API flows and must have been requested with the flow's ID, or its hex encoded SHA-256 hash, as
+	// the nonce.
+	IDToken string `json:"id_token"`
 }
 
 func (s *Strategy) processLogin(w http.ResponseWriter, r *http.Request, a *login.Flow, token *oauth2.Token, claims *Claims, provider Provider, container *authCodeContainer) (*registration.Flow, error) {
@@ -94,8 +100,7 @@ func (s *Strategy) processLogin(w http.ResponseWriter, r *http.Request, a *login
 				opts = append(opts, registration.WithFlowReturnTo(a.ReturnTo))
 			}
 
-			// This flow only works for browsers anyways.
-			aa, err := s.d.RegistrationHandler().NewRegistrationFlow(w, r, flow.TypeBrowser, opts...)
+			aa, err := s.d.RegistrationHandler().NewRegistrationFlow(w, r, a.Type, opts...)
 			if err != nil {
 				return nil, s.handleError(w, r, a, provider.Config().ID, nil, err)
 			}
@@ -116,9 +121,15 @@ func (s *Strategy) processLogin(w http.ResponseWriter, r *http.Request, a *login
 	}
 
 	sess := session.NewInactiveSession()
-	sess.CompletedLoginFor(s.ID(), identity.AuthenticatorAssuranceLevel1)
+	sess.CompletedLoginForWithProvider(s.ID(), identity.AuthenticatorAssuranceLevel1, provider.Config().ID)
 	for _, c := range o.Providers {
 		if c.Subject == claims.Subject && c.Provider == provider.Config().ID {
+			if provider.Config().SyncOnLogin {
+				if i, err = s.syncIdentity(r, i, claims, provider); err != nil {
+					return nil, s.handleError(w, r, a, provider.Config().ID, nil, err)
+				}
+			}
+
 			if err = s.d.LoginHookExecutor().PostLoginHook(w, r, node.OpenIDConnectGroup, a, i, sess); err != nil {
 				return nil, s.handleError(w, r, a, provider.Config().ID, nil, err)
 			}
@@ -153,6 +164,10 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 		return nil, s.handleError(w, r, f, pid, nil, err)
 	}
 
+	if len(p.IDToken) > 0 {
+		return nil, s.loginWithIDToken(w, r, f, provider, &p)
+	}
+
 	c, err := provider.OAuth2(r.Context())
 	if err != nil {
 		return nil, s.handleError(w, r, f, pid, nil, err)
@@ -168,11 +183,13 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 	}
 
 	state := generateState(f.ID.String())
+	verifier := newPKCEVerifier(r.Context(), provider)
 	if err := s.d.ContinuityManager().Pause(r.Context(), w, r, sessionName,
 		continuity.WithPayload(&authCodeContainer{
-			State:  state,
-			FlowID: f.ID.String(),
-			Traits: p.Traits,
+			State:        state,
+			FlowID:       f.ID.String(),
+			Traits:       p.Traits,
+			PKCEVerifier: verifier,
 		}),
 		continuity.WithLifespan(time.Minute*30)); err != nil {
 		return nil, s.handleError(w, r, f, pid, nil, err)
@@ -183,7 +200,7 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 		return nil, s.handleError(w, r, f, pid, nil, errors.WithStack(herodot.ErrInternalServerError.WithReason("Could not update flow").WithDebug(err.Error())))
 	}
 
-	codeURL := c.AuthCodeURL(state, provider.AuthCodeURLOptions(req)...)
+	codeURL := c.AuthCodeURL(state, append(provider.AuthCodeURLOptions(req), pkceAuthCodeURLOptions(verifier)...)...)
 	if x.IsJSONRequest(r) {
 		s.d.Writer().WriteError(w, r, flow.NewBrowserLocationChangeRequiredError(codeURL))
 	} else {
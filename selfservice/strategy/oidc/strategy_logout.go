@@ -0,0 +1,104 @@
+package oidc
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow/logout"
+	"github.com/ory/kratos/session"
+)
+
+var _ logout.UpstreamLogoutStrategy = new(Strategy)
+
+// UpstreamLogoutURL returns the end session endpoint of the OpenID Connect provider the session was established
+// with, including the ID token hint and the URL to return to after logging out at the provider.
+func (s *Strategy) UpstreamLogoutURL(ctx context.Context, sess *session.Session, returnTo *url.URL) (*url.URL, error) {
+	pid := lastProvider(sess)
+	if len(pid) == 0 {
+		return nil, nil
+	}
+
+	c, err := s.Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conf, ok := c.find(pid)
+	if !ok || len(conf.EndSessionEndpoint) == 0 {
+		return nil, nil
+	}
+
+	u, err := url.Parse(conf.EndSessionEndpoint)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("The end session endpoint of OpenID Connect provider %s is invalid: %s", pid, err))
+	}
+
+	idToken, err := s.idToken(ctx, sess, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	q := u.Query()
+	if len(idToken) > 0 {
+		q.Set("id_token_hint", idToken)
+	}
+	q.Set("client_id", conf.ClientID)
+	q.Set("post_logout_redirect_uri", returnTo.String())
+	u.RawQuery = q.Encode()
+
+	return u, nil
+}
+
+// lastProvider returns the ID of the OpenID Connect provider the user last signed in with. Second factors are
+// ignored as they are completed after the first factor.
+func lastProvider(sess *session.Session) string {
+	for k := len(sess.AMR) - 1; k >= 0; k-- {
+		m := sess.AMR[k]
+		if m.AAL == identity.AuthenticatorAssuranceLevel2 {
+			continue
+		}
+
+		if m.Method != identity.CredentialsTypeOIDC {
+			return ""
+		}
+
+		return m.Provider
+	}
+
+	return ""
+}
+
+// idToken returns the decrypted ID token which the provider issued to the session's identity.
+func (s *Strategy) idToken(ctx context.Context, sess *session.Session, pid string) (string, error) {
+	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, sess.IdentityID)
+	if err != nil {
+		return "", err
+	}
+
+	var conf identity.CredentialsOIDC
+	if _, err := i.ParseCredentials(s.ID(), &conf); errors.Is(err, herodot.ErrNotFound) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	for _, p := range conf.Providers {
+		if p.Provider != pid {
+			continue
+		}
+
+		idToken, err := s.d.Cipher(ctx).Decrypt(ctx, p.InitialIDToken)
+		if err != nil {
+			return "", err
+		}
+
+		return string(idToken), nil
+	}
+
+	return "", nil
+}
@@ -0,0 +1,147 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/x/urlx"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/flow/logout"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/x"
+)
+
+func TestStrategyUpstreamLogoutURL(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	testhelpers.SetDefaultIdentitySchema(conf, "file://stub/registration.schema.json")
+
+	viperSetProviderConfig(t, conf,
+		oidc.Configuration{
+			ID:                 "enterprise",
+			Provider:           "generic",
+			ClientID:           "client",
+			IssuerURL:          "https://idp.example.org",
+			EndSessionEndpoint: "https://idp.example.org/logout?tenant=acme",
+		},
+		oidc.Configuration{
+			ID:        "social",
+			Provider:  "generic",
+			ClientID:  "client",
+			IssuerURL: "https://social.example.org",
+		},
+	)
+
+	s, err := reg.AllLoginStrategies().Strategy(identity.CredentialsTypeOIDC)
+	require.NoError(t, err)
+	strategy := s.(logout.UpstreamLogoutStrategy)
+
+	createIdentity := func(t *testing.T, provider, idToken string) *identity.Identity {
+		it, err := reg.Cipher(ctx).Encrypt(ctx, []byte(idToken))
+		require.NoError(t, err)
+
+		creds, err := identity.NewCredentialsOIDC(it, "", "", provider, x.NewUUID().String())
+		require.NoError(t, err)
+
+		i := identity.NewIdentity("")
+		i.Traits = identity.Traits(`{"subject":"` + x.NewUUID().String() + `@ory.sh"}`)
+		i.SetCredentials(identity.CredentialsTypeOIDC, *creds)
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+		return i
+	}
+
+	newSession := func(i *identity.Identity, amr ...session.AuthenticationMethod) *session.Session {
+		return &session.Session{IdentityID: i.ID, AMR: amr}
+	}
+
+	returnTo := urlx.ParseOrPanic("https://www.ory.sh/logged-out")
+
+	t.Run("case=redirects through the provider's end session endpoint", func(t *testing.T) {
+		i := createIdentity(t, "enterprise", "id-token")
+
+		for k, amr := range [][]session.AuthenticationMethod{
+			{{Method: identity.CredentialsTypeOIDC, AAL: identity.AuthenticatorAssuranceLevel1, Provider: "enterprise"}},
+			{
+				{Method: identity.CredentialsTypeOIDC, AAL: identity.AuthenticatorAssuranceLevel1, Provider: "enterprise"},
+				{Method: identity.CredentialsTypeTOTP, AAL: identity.AuthenticatorAssuranceLevel2},
+			},
+		} {
+			u, err := strategy.UpstreamLogoutURL(ctx, newSession(i, amr...), returnTo)
+			require.NoError(t, err, "%d", k)
+			require.NotNil(t, u, "%d", k)
+
+			assert.Equal(t, "idp.example.org", u.Host)
+			assert.Equal(t, "/logout", u.Path)
+			assert.Equal(t, url.Values{
+				"tenant":                   {"acme"},
+				"id_token_hint":            {"id-token"},
+				"client_id":                {"client"},
+				"post_logout_redirect_uri": {returnTo.String()},
+			}, u.Query())
+		}
+	})
+
+	t.Run("case=omits the id token hint if none was stored", func(t *testing.T) {
+		i := createIdentity(t, "enterprise", "")
+
+		u, err := strategy.UpstreamLogoutURL(ctx, newSession(i, session.AuthenticationMethod{Method: identity.CredentialsTypeOIDC, Provider: "enterprise"}), returnTo)
+		require.NoError(t, err)
+		require.NotNil(t, u)
+		assert.False(t, u.Query().Has("id_token_hint"))
+	})
+
+	t.Run("case=does not redirect", func(t *testing.T) {
+		i := createIdentity(t, "enterprise", "id-token")
+
+		for k, amr := range [][]session.AuthenticationMethod{
+			nil,
+			{{Method: identity.CredentialsTypePassword, AAL: identity.AuthenticatorAssuranceLevel1}},
+			{
+				{Method: identity.CredentialsTypeOIDC, AAL: identity.AuthenticatorAssuranceLevel1, Provider: "enterprise", CompletedAt: time.Now().Add(-time.Hour)},
+				{Method: identity.CredentialsTypePassword, AAL: identity.AuthenticatorAssuranceLevel1},
+			},
+			{{Method: identity.CredentialsTypeOIDC, AAL: identity.AuthenticatorAssuranceLevel1, Provider: "social"}},
+			{{Method: identity.CredentialsTypeOIDC, AAL: identity.AuthenticatorAssuranceLevel1, Provider: "unknown"}},
+			{{Method: identity.CredentialsTypeOIDC, AAL: identity.AuthenticatorAssuranceLevel1}},
+		} {
+			u, err := strategy.UpstreamLogoutURL(ctx, newSession(i, amr...), returnTo)
+			require.NoError(t, err, "%d", k)
+			assert.Nil(t, u, "%d", k)
+		}
+	})
+
+	t.Run("case=returns the upstream logout url to ajax clients", func(t *testing.T) {
+		publicTS, _ := testhelpers.NewKratosServer(t, reg)
+		conf.MustSet(ctx, config.ViperKeySelfServiceBrowserDefaultReturnTo, returnTo.String())
+
+		sess, err := session.NewActiveSession(ctx, createIdentity(t, "enterprise", "id-token"), conf, time.Now().UTC(), identity.CredentialsTypeOIDC, identity.AuthenticatorAssuranceLevel1)
+		require.NoError(t, err)
+		sess.AMR[0].Provider = "enterprise"
+		hc := testhelpers.NewHTTPClientWithSessionCookie(t, reg, sess)
+
+		body, res := testhelpers.HTTPRequestJSON(t, hc, "GET", publicTS.URL+logout.RouteSubmitFlow+"?token="+sess.LogoutToken, nil)
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+
+		u, err := url.Parse(gjson.GetBytes(body, "upstream_logout_url").String())
+		require.NoError(t, err, "%s", body)
+		assert.Equal(t, "idp.example.org", u.Host)
+		assert.Equal(t, "id-token", u.Query().Get("id_token_hint"))
+		assert.Equal(t, returnTo.String(), u.Query().Get("post_logout_redirect_uri"))
+
+		actual, err := reg.SessionPersister().GetSession(ctx, sess.ID)
+		require.NoError(t, err)
+		assert.False(t, actual.IsActive(), "the session is revoked")
+	})
+}
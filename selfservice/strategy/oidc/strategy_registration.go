@@ -76,6 +76,12 @@ type SubmitSelfServiceRegistrationFlowWithOidcMethodBody struct {
 	//
 	// required: true
 	Method string `json:"method"`
+
+	// IDToken is an ID token obtained by a native app from the provider, e.g. using the Apple or Google sign in
+	// SDKs. If set, the ID token is verified and used instead of redirecting to the provider. ID tokens are only
+	// accepted by API flows and must have been requested with the flow's ID, or its hex encoded SHA-256 hash, as
+	// the nonce.
+	IDToken string `json:"id_token"`
 }
 
 func (s *Strategy) newLinkDecoder(p interface{}, r *http.Request) error {
@@ -127,6 +133,10 @@ func (s *Strategy) Register(w http.ResponseWriter, r *http.Request, f *registrat
 		return s.handleError(w, r, f, pid, nil, err)
 	}
 
+	if len(p.IDToken) > 0 {
+		return s.registerWithIDToken(w, r, f, provider, &p)
+	}
+
 	c, err := provider.OAuth2(r.Context())
 	if err != nil {
 		return s.handleError(w, r, f, pid, nil, err)
@@ -142,17 +152,19 @@ func (s *Strategy) Register(w http.ResponseWriter, r *http.Request, f *registrat
 	}
 
 	state := generateState(f.ID.String())
+	verifier := newPKCEVerifier(r.Context(), provider)
 	if err := s.d.ContinuityManager().Pause(r.Context(), w, r, sessionName,
 		continuity.WithPayload(&authCodeContainer{
-			State:  state,
-			FlowID: f.ID.String(),
-			Traits: p.Traits,
+			State:        state,
+			FlowID:       f.ID.String(),
+			Traits:       p.Traits,
+			PKCEVerifier: verifier,
 		}),
 		continuity.WithLifespan(time.Minute*30)); err != nil {
 		return s.handleError(w, r, f, pid, nil, err)
 	}
 
-	codeURL := c.AuthCodeURL(state, provider.AuthCodeURLOptions(req)...)
+	codeURL := c.AuthCodeURL(state, append(provider.AuthCodeURLOptions(req), pkceAuthCodeURLOptions(verifier)...)...)
 	if x.IsJSONRequest(r) {
 		s.d.Writer().WriteError(w, r, flow.NewBrowserLocationChangeRequiredError(codeURL))
 	} else {
@@ -183,8 +195,7 @@ func (s *Strategy) processRegistration(w http.ResponseWriter, r *http.Request, a
 			opts = append(opts, login.WithFlowReturnTo(a.ReturnTo))
 		}
 
-		// This endpoint only handles browser flow at the moment.
-		ar, err := s.d.LoginHandler().NewLoginFlow(w, r, flow.TypeBrowser, opts...)
+		ar, err := s.d.LoginHandler().NewLoginFlow(w, r, a.Type, opts...)
 		if err != nil {
 			return nil, s.handleError(w, r, a, provider.Config().ID, nil, err)
 		}
@@ -233,23 +244,37 @@ func (s *Strategy) processRegistration(w http.ResponseWriter, r *http.Request, a
 		return nil, s.handleError(w, r, a, provider.Config().ID, i.Traits, err)
 	}
 
+	if linked, err := s.linkToExistingIdentity(w, r, a, claims, provider, creds); err != nil {
+		return nil, s.handleError(w, r, a, provider.Config().ID, i.Traits, err)
+	} else if linked {
+		return nil, nil
+	}
+
 	i.SetCredentials(s.ID(), *creds)
-	if err := s.d.RegistrationExecutor().PostRegistrationHook(w, r, identity.CredentialsTypeOIDC, a, i); err != nil {
+	// Remember the provider the identity signed up with, so that logging out can end the session at the
+	// provider as well.
+	if err := s.d.RegistrationExecutor().PostRegistrationHook(w, r, identity.CredentialsTypeOIDC, a, i,
+		registration.WithAuthenticationMethodProvider(provider.Config().ID)); err != nil {
 		return nil, s.handleError(w, r, a, provider.Config().ID, i.Traits, err)
 	}
 
 	return nil, nil
 }
 
-func (s *Strategy) createIdentity(w http.ResponseWriter, r *http.Request, a *registration.Flow, claims *Claims, provider Provider, container *authCodeContainer, jn *bytes.Buffer) (*identity.Identity, error) {
+// evaluateMapper runs the provider's Jsonnet mapper with the given claims.
+func evaluateMapper(claims *Claims, provider Provider, jn *bytes.Buffer) (string, error) {
 	var jsonClaims bytes.Buffer
 	if err := json.NewEncoder(&jsonClaims).Encode(claims); err != nil {
-		return nil, s.handleError(w, r, a, provider.Config().ID, nil, err)
+		return "", errors.WithStack(err)
 	}
 
 	vm := jsonnetsecure.MakeSecureVM()
 	vm.ExtCode("claims", jsonClaims.String())
-	evaluated, err := vm.EvaluateAnonymousSnippet(provider.Config().Mapper, jn.String())
+	return vm.EvaluateAnonymousSnippet(provider.Config().Mapper, jn.String())
+}
+
+func (s *Strategy) createIdentity(w http.ResponseWriter, r *http.Request, a *registration.Flow, claims *Claims, provider Provider, container *authCodeContainer, jn *bytes.Buffer) (*identity.Identity, error) {
+	evaluated, err := evaluateMapper(claims, provider, jn)
 	if err != nil {
 		return nil, s.handleError(w, r, a, provider.Config().ID, nil, err)
 	}
@@ -0,0 +1,115 @@
+package oidc
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/fetcher"
+
+	"github.com/ory/kratos/identity"
+)
+
+// syncIdentity runs the provider's Jsonnet mapper again and updates the identity's traits and admin metadata
+// with its output. Only the trait paths listed in the provider's `sync_traits` are overwritten.
+//
+// The updated identity is validated against its identity schema before it is stored.
+func (s *Strategy) syncIdentity(r *http.Request, i *identity.Identity, claims *Claims, provider Provider) (*identity.Identity, error) {
+	c := provider.Config()
+
+	fetch := fetcher.NewFetcher(fetcher.WithClient(s.d.HTTPClient(r.Context())))
+	jn, err := fetch.Fetch(c.Mapper)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluated, err := evaluateMapper(claims, provider, jn)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonTraits := gjson.Get(evaluated, "identity.traits")
+	if !jsonTraits.IsObject() {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("OpenID Connect Jsonnet mapper did not return an object for key identity.traits. Please check your Jsonnet code!"))
+	}
+
+	// The identity passed to the login strategy does not contain its credentials.
+	ii, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(r.Context(), i.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	traits, err := syncTraits(ii.Traits, json.RawMessage(jsonTraits.Raw), c.SyncTraits)
+	if err != nil {
+		return nil, err
+	}
+
+	metadataAdmin := ii.MetadataAdmin
+	if c.SyncMetadataAdmin {
+		if err := s.setMetadata(evaluated, ii, AdminMetadata); err != nil {
+			return nil, err
+		}
+	}
+
+	if jsonEqual(traits, ii.Traits) && jsonEqual(ii.MetadataAdmin, metadataAdmin) {
+		return i, nil
+	}
+
+	ii.Traits = traits
+	if err := s.d.IdentityManager().Update(r.Context(), ii, identity.ManagerAllowWriteProtectedTraits); err != nil {
+		return nil, err
+	}
+
+	s.d.Logger().
+		WithRequest(r).
+		WithField("oidc_provider", c.ID).
+		WithField("identity_id", ii.ID).
+		WithSensitiveField("identity_traits", ii.Traits).
+		WithSensitiveField("mapper_jsonnet_output", evaluated).
+		Debug("Updated identity with the OpenID Connect Jsonnet mapper output.")
+	return ii.CopyWithoutCredentials(), nil
+}
+
+// syncTraits overwrites the given paths of the current traits with the values of the mapped traits. Paths which
+// are not present in the mapped traits are left untouched.
+func syncTraits(current identity.Traits, mapped json.RawMessage, paths []string) (identity.Traits, error) {
+	result := []byte(current)
+	if len(result) == 0 {
+		result = []byte("{}")
+	}
+
+	for _, path := range paths {
+		value := gjson.GetBytes(mapped, path)
+		if !value.Exists() {
+			continue
+		}
+
+		var err error
+		result, err = sjson.SetRawBytes(result, path, []byte(value.Raw))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	return result, nil
+}
+
+func jsonEqual(a, b []byte) bool {
+	var av, bv interface{}
+	if len(a) > 0 {
+		if err := json.Unmarshal(a, &av); err != nil {
+			return false
+		}
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &bv); err != nil {
+			return false
+		}
+	}
+	return reflect.DeepEqual(av, bv)
+}
@@ -0,0 +1,63 @@
+package oidc
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/identity"
+)
+
+func TestSyncTraits(t *testing.T) {
+	for k, tc := range []struct {
+		current identity.Traits
+		mapped  json.RawMessage
+		paths   []string
+		expect  string
+	}{
+		{
+			current: identity.Traits(`{"email":"old@ory.sh","website":"https://www.ory.sh"}`),
+			mapped:  json.RawMessage(`{"email":"new@ory.sh","website":"https://example.org"}`),
+			expect:  `{"email":"old@ory.sh","website":"https://www.ory.sh"}`,
+		},
+		{
+			current: identity.Traits(`{"email":"old@ory.sh","website":"https://www.ory.sh"}`),
+			mapped:  json.RawMessage(`{"email":"new@ory.sh","website":"https://example.org"}`),
+			paths:   []string{"email"},
+			expect:  `{"email":"new@ory.sh","website":"https://www.ory.sh"}`,
+		},
+		{
+			current: identity.Traits(`{"email":"old@ory.sh","name":{"first":"Old","last":"Name"}}`),
+			mapped:  json.RawMessage(`{"name":{"first":"New","last":"Other"},"groups":["admin"]}`),
+			paths:   []string{"name.first", "groups"},
+			expect:  `{"email":"old@ory.sh","name":{"first":"New","last":"Name"},"groups":["admin"]}`,
+		},
+		{
+			current: identity.Traits(`{"email":"old@ory.sh"}`),
+			mapped:  json.RawMessage(`{}`),
+			paths:   []string{"email"},
+			expect:  `{"email":"old@ory.sh"}`,
+		},
+		{
+			mapped: json.RawMessage(`{"email":"new@ory.sh"}`),
+			paths:  []string{"email"},
+			expect: `{"email":"new@ory.sh"}`,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			actual, err := syncTraits(tc.current, tc.mapped, tc.paths)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expect, string(actual))
+		})
+	}
+}
+
+func TestJSONEqual(t *testing.T) {
+	assert.True(t, jsonEqual(nil, nil))
+	assert.True(t, jsonEqual([]byte(`{"a":1,"b":2}`), []byte(`{"b":2, "a":1}`)))
+	assert.False(t, jsonEqual([]byte(`{"a":1}`), []byte(`{"a":2}`)))
+	assert.False(t, jsonEqual(nil, []byte(`{"a":1}`)))
+}
@@ -3,6 +3,7 @@ package oidc_test
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -346,6 +347,7 @@ func TestStrategy(t *testing.T) {
 			res, body := makeRequest(t, "valid", action, url.Values{})
 			ai(t, res, body)
 			expectTokens(t, "valid", body)
+			assert.Equal(t, "valid", gjson.GetBytes(body, "authentication_methods.0.provider").String(), "%s", body)
 		})
 
 		t.Run("case=should pass login", func(t *testing.T) {
@@ -470,6 +472,59 @@ func TestStrategy(t *testing.T) {
 		})
 	})
 
+	t.Run("case=should link to the identity with the same verified email after signing in", func(t *testing.T) {
+		subject = "link-with-password-strategy@ory.sh"
+		scope = []string{"openid"}
+		claims = idTokenClaims{email: subject}
+		t.Cleanup(func() {
+			claims = idTokenClaims{}
+		})
+
+		i := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+		i.SetCredentials(identity.CredentialsTypePassword, identity.Credentials{
+			Identifiers: []string{subject},
+			Config:      sqlxx.JSONRawMessage(`{"hashed_password":"$2a$08$.cOYmAd.vCpDOoiVJrO5B.hjTLKQQ6cAK40u8uB.FnZDyPvVvQ9Q."}`), // foobar
+		})
+		i.Traits = identity.Traits(`{"subject":"` + subject + `"}`)
+		i.VerifiableAddresses = []identity.VerifiableAddress{{Value: subject, Via: identity.VerifiableAddressTypeEmail, Status: identity.VerifiableAddressStatusPending}}
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(context.Background(), i))
+
+		jar, err := cookiejar.New(nil)
+		require.NoError(t, err)
+
+		r := newRegistrationFlow(t, returnTS.URL, time.Minute)
+		action := afv(t, r.ID, "valid")
+		res, body := makeRequestWithCookieJar(t, "valid", action, url.Values{}, jar)
+		require.Contains(t, res.Request.URL.String(), uiTS.URL+"/login", "%s", body)
+		assert.EqualValues(t, text.InfoSelfServiceLoginLinkCredentials, gjson.GetBytes(body, "ui.messages.0.id").Int(), "%s", body)
+		assert.Equal(t, subject, gjson.GetBytes(body, "ui.nodes.#(attributes.name==identifier).attributes.value").String(), "%s", body)
+
+		res, err = newClient(t, jar).PostForm(gjson.GetBytes(body, "ui.action").String(), url.Values{
+			"method":     {"password"},
+			"identifier": {subject},
+			"password":   {"foobar"},
+			"csrf_token": {gjson.GetBytes(body, "ui.nodes.#(attributes.name==csrf_token).attributes.value").String()},
+		})
+		require.NoError(t, err)
+		body = ioutilx.MustReadAll(res.Body)
+		require.NoError(t, res.Body.Close())
+		assert.Contains(t, res.Request.URL.String(), returnTS.URL, "%s", body)
+		assert.Equal(t, i.ID.String(), gjson.GetBytes(body, "identity.id").String(), "%s", body)
+
+		actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(context.Background(), i.ID)
+		require.NoError(t, err)
+		require.Contains(t, actual.Credentials, identity.CredentialsTypeOIDC)
+		assert.Equal(t, []string{"valid:" + subject}, actual.Credentials[identity.CredentialsTypeOIDC].Identifiers)
+
+		t.Run("case=should sign in with the linked provider", func(t *testing.T) {
+			r := newLoginFlow(t, returnTS.URL, time.Minute)
+			action := afv(t, r.ID, "valid")
+			res, body := makeRequest(t, "valid", action, url.Values{})
+			assert.Contains(t, res.Request.URL.String(), returnTS.URL, "%s", body)
+			assert.Equal(t, i.ID.String(), gjson.GetBytes(body, "identity.id").String(), "%s", body)
+		})
+	})
+
 	t.Run("case=should redirect to default return ts when sending authenticated login flow without forced flag", func(t *testing.T) {
 		subject = "no-reauth-login@ory.sh"
 		scope = []string{"openid"}
@@ -715,3 +770,106 @@ func TestPostEndpointRedirect(t *testing.T) {
 		assert.Equal(t, publicTS.URL+"/self-service/methods/oidc/callback/apple?state=foo&test=3", location.String())
 	})
 }
+
+func TestStrategyIDToken(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	issuer, sign := newIDTokenIssuer(t)
+
+	routerP := x.NewRouterPublic()
+	routerA := x.NewRouterAdmin()
+	ts, _ := testhelpers.NewKratosServerWithRouters(t, reg, routerP, routerA)
+	_ = newUI(t, reg)
+
+	viperSetProviderConfig(t, conf, oidc.Configuration{
+		Provider:                   "generic",
+		ID:                         "valid",
+		ClientID:                   "client",
+		ClientSecret:               "secret",
+		IssuerURL:                  issuer,
+		Mapper:                     "file://./stub/oidc.hydra.jsonnet",
+		AdditionalIDTokenAudiences: []string{"com.example.ios"},
+	})
+	conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationEnabled, true)
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/registration.schema.json")
+	conf.MustSet(ctx, config.HookStrategyKey(config.ViperKeySelfServiceRegistrationAfter,
+		identity.CredentialsTypeOIDC.String()), []config.SelfServiceHook{{Name: "session"}})
+
+	newIDToken := func(subject, nonce string) string {
+		return sign(map[string]interface{}{"sub": subject, "aud": "com.example.ios", "nonce": nonce})
+	}
+
+	submit := func(t *testing.T, c *http.Client, action string, idToken string) (*http.Response, []byte) {
+		res, err := c.Post(action, "application/json", strings.NewReader(fmt.Sprintf(
+			`{"method":"oidc","provider":"valid","id_token":%q}`, idToken)))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, body
+	}
+
+	register := func(t *testing.T, idToken func(flowID string) string) (*http.Response, []byte) {
+		f := testhelpers.InitializeRegistrationFlowViaAPI(t, http.DefaultClient, ts)
+		return submit(t, http.DefaultClient, f.Ui.Action, idToken(f.Id))
+	}
+
+	login := func(t *testing.T, idToken func(flowID string) string) (*http.Response, []byte) {
+		f := testhelpers.InitializeLoginFlowViaAPI(t, http.DefaultClient, ts, false)
+		return submit(t, http.DefaultClient, f.Ui.Action, idToken(f.Id))
+	}
+
+	t.Run("case=should register and then sign in using an id_token", func(t *testing.T) {
+		subject := x.NewUUID().String() + "@ory.sh"
+
+		res, body := register(t, func(flowID string) string { return newIDToken(subject, flowID) })
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+		assert.NotEmpty(t, gjson.GetBytes(body, "session_token").String(), "%s", body)
+		assert.Equal(t, subject, gjson.GetBytes(body, "identity.traits.subject").String(), "%s", body)
+
+		res, body = login(t, func(flowID string) string { return newIDToken(subject, flowID) })
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+		assert.NotEmpty(t, gjson.GetBytes(body, "session_token").String(), "%s", body)
+		assert.Equal(t, subject, gjson.GetBytes(body, "session.identity.traits.subject").String(), "%s", body)
+	})
+
+	t.Run("case=should register when signing in with an id_token of an unknown user", func(t *testing.T) {
+		subject := x.NewUUID().String() + "@ory.sh"
+
+		res, body := login(t, func(flowID string) string { return newIDToken(subject, flowID) })
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+		assert.Equal(t, subject, gjson.GetBytes(body, "identity.traits.subject").String(), "%s", body)
+	})
+
+	t.Run("case=should accept the hashed nonce", func(t *testing.T) {
+		res, body := register(t, func(flowID string) string {
+			return newIDToken(x.NewUUID().String()+"@ory.sh", fmt.Sprintf("%x", sha256.Sum256([]byte(flowID))))
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+	})
+
+	t.Run("case=should fail if the nonce is not the flow ID", func(t *testing.T) {
+		res, body := login(t, func(string) string { return newIDToken(x.NewUUID().String()+"@ory.sh", "nonce") })
+		require.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+		assert.Contains(t, gjson.GetBytes(body, "ui.messages.0.text").String(), "ID of the flow", "%s", body)
+	})
+
+	t.Run("case=should fail if the nonce belongs to another flow", func(t *testing.T) {
+		other := testhelpers.InitializeLoginFlowViaAPI(t, http.DefaultClient, ts, false)
+		res, body := login(t, func(string) string { return newIDToken(x.NewUUID().String()+"@ory.sh", other.Id) })
+		require.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+	})
+
+	t.Run("case=should fail for browser flows", func(t *testing.T) {
+		c := testhelpers.NewClientWithCookies(t)
+		f := testhelpers.InitializeLoginFlowViaBrowser(t, c, ts, false, true)
+		res, body := submit(t, c, f.Ui.Action, newIDToken(x.NewUUID().String()+"@ory.sh", f.Id))
+		require.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+		assert.Contains(t, string(body), "only supported for API flows", "%s", body)
+	})
+
+	t.Run("case=should fail if the id_token is invalid", func(t *testing.T) {
+		res, body := register(t, func(flowID string) string { return newIDToken(x.NewUUID().String()+"@ory.sh", flowID) + "x" })
+		require.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+	})
+}
@@ -0,0 +1,210 @@
+package oidc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/sqlcon"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/x"
+)
+
+const RouteAdminIdentityToken = "/identities/:id/oidc/:provider/token"
+
+var errRefreshConflict = herodot.ErrConflict.WithReason("The tokens of this identity were refreshed concurrently. Please try again.")
+
+// OpenID Connect Provider Token
+//
+// swagger:model oidcProviderToken
+type ProviderToken struct {
+	// Provider is the ID of the OpenID Connect provider which issued the token.
+	//
+	// required: true
+	Provider string `json:"provider"`
+
+	// AccessToken is the fresh access token issued by the provider.
+	//
+	// required: true
+	AccessToken string `json:"access_token"`
+
+	// TokenType is the type of the access token, usually `Bearer`.
+	TokenType string `json:"token_type,omitempty"`
+
+	// Expiry is the time at which the access token expires. It is empty if the provider did not say.
+	Expiry *time.Time `json:"expiry,omitempty"`
+}
+
+// swagger:parameters adminRefreshIdentityOidcToken
+// nolint:deadcode,unused
+type adminRefreshIdentityOidcToken struct {
+	// ID is the identity's ID.
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+
+	// Provider is the ID of the OpenID Connect provider.
+	//
+	// required: true
+	// in: path
+	Provider string `json:"provider"`
+}
+
+// swagger:route POST /admin/identities/{id}/oidc/{provider}/token v0alpha2 adminRefreshIdentityOidcToken
+//
+// # Get a Fresh Upstream Access Token
+//
+// Uses the refresh token which was stored when the identity signed in with the OpenID Connect provider to obtain
+// a fresh access token from the provider. The tokens returned by the provider are encrypted and stored with the
+// identity's OpenID Connect credentials, replacing the previous ones.
+//
+// The provider must have issued a refresh token, which usually requires the `offline_access` scope.
+//
+// If the tokens of the identity are refreshed concurrently, only the first refresh is stored and the others
+// fail with a 409 Conflict error. They can be retried to obtain a fresh access token.
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  200: oidcProviderToken
+//	  400: jsonError
+//	  404: jsonError
+//	  409: jsonError
+//	  500: jsonError
+func (s *Strategy) adminRefreshIdentityToken(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	token, err := s.refreshIdentityToken(r.Context(), x.ParseUUID(ps.ByName("id")), ps.ByName("provider"))
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	s.d.Writer().Write(w, r, token)
+}
+
+func (s *Strategy) refreshIdentityToken(ctx context.Context, id uuid.UUID, pid string) (*ProviderToken, error) {
+	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var conf identity.CredentialsOIDC
+	creds, err := i.ParseCredentials(s.ID(), &conf)
+	if err != nil {
+		return nil, err
+	}
+
+	k := -1
+	for j := range conf.Providers {
+		if conf.Providers[j].Provider == pid {
+			k = j
+			break
+		}
+	}
+	if k < 0 {
+		return nil, errors.WithStack(herodot.ErrNotFound.WithReasonf("The identity is not linked to OpenID Connect provider %s.", pid))
+	}
+
+	refreshToken, err := s.d.Cipher(ctx).Decrypt(ctx, conf.Providers[k].InitialRefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(refreshToken) == 0 {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReasonf("OpenID Connect provider %s did not issue a refresh token for this identity. Request the offline_access scope or the provider's equivalent to receive one.", pid))
+	}
+
+	provider, err := s.provider(ctx, nil, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := provider.OAuth2(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := c.TokenSource(
+		context.WithValue(ctx, oauth2.HTTPClient, s.d.HTTPClient(ctx).HTTPClient),
+		&oauth2.Token{RefreshToken: string(refreshToken)},
+	).Token()
+	if err != nil {
+		// Providers which rotate refresh tokens reject the previous refresh token once it was used. If the tokens
+		// were refreshed concurrently, the refresh token we used might just have been replaced.
+		if changed, cerr := s.credentialsChanged(ctx, id, creds); cerr == nil && changed {
+			return nil, errors.WithStack(errRefreshConflict)
+		}
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to refresh the access token at OpenID Connect provider %s: %s", pid, err))
+	}
+
+	if conf.Providers[k].InitialAccessToken, err = s.d.Cipher(ctx).Encrypt(ctx, []byte(token.AccessToken)); err != nil {
+		return nil, err
+	}
+
+	// Providers may or may not rotate the refresh token. If they do not, the previous one stays valid.
+	if len(token.RefreshToken) > 0 {
+		if conf.Providers[k].InitialRefreshToken, err = s.d.Cipher(ctx).Encrypt(ctx, []byte(token.RefreshToken)); err != nil {
+			return nil, err
+		}
+	}
+
+	if idToken, ok := token.Extra("id_token").(string); ok && len(idToken) > 0 {
+		if conf.Providers[k].InitialIDToken, err = s.d.Cipher(ctx).Encrypt(ctx, []byte(idToken)); err != nil {
+			return nil, err
+		}
+	}
+
+	config, err := json.Marshal(conf)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// The provider is not called while holding a lock. Instead, the tokens are only stored if no other refresh
+	// stored its tokens in the meantime, because those might have replaced the refresh token we used.
+	if err := s.d.PrivilegedIdentityPool().UpdateCredentialsConfigIfUnchanged(ctx, creds.ID, creds.Config, config); errors.Is(err, sqlcon.ErrNoRows) {
+		return nil, errors.WithStack(errRefreshConflict)
+	} else if err != nil {
+		return nil, err
+	}
+
+	result := &ProviderToken{
+		Provider:    pid,
+		AccessToken: token.AccessToken,
+		TokenType:   token.Type(),
+	}
+	if !token.Expiry.IsZero() {
+		result.Expiry = &token.Expiry
+	}
+
+	return result, nil
+}
+
+// credentialsChanged reports whether the OpenID Connect credentials of the identity differ from the given ones.
+func (s *Strategy) credentialsChanged(ctx context.Context, id uuid.UUID, previous *identity.Credentials) (bool, error) {
+	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	current, ok := i.GetCredentials(s.ID())
+	if !ok {
+		return true, nil
+	}
+
+	return current.ID != previous.ID || !bytes.Equal(current.Config, previous.Config), nil
+}
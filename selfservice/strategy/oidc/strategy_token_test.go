@@ -0,0 +1,237 @@
+package oidc_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
+	"github.com/ory/kratos/x"
+)
+
+func TestStrategyRefreshIdentityToken(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	testhelpers.SetDefaultIdentitySchema(conf, "file://stub/registration.schema.json")
+	_, adminTS := testhelpers.NewKratosServer(t, reg)
+
+	var refreshed int
+	// The upstream rotates refresh tokens starting with "rotating-" and only accepts the latest one.
+	var rotatingLock sync.Mutex
+	var rotating = "rotating-0"
+	var concurrent func()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		if rt := r.PostForm.Get("refresh_token"); strings.HasPrefix(rt, "rotating-") {
+			rotatingLock.Lock()
+			defer rotatingLock.Unlock()
+
+			w.Header().Set("Content-Type", "application/json")
+			if rt != rotating {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+
+			rotating = "rotating-" + x.NewUUID().String()
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  "fresh-access-token",
+				"refresh_token": rotating,
+				"token_type":    "Bearer",
+			})
+			return
+		}
+
+		if r.PostForm.Get("refresh_token") == "concurrent-0" && concurrent != nil {
+			concurrent()
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  "fresh-access-token",
+				"refresh_token": "concurrent-2",
+				"token_type":    "Bearer",
+			})
+			return
+		}
+
+		if r.PostForm.Get("grant_type") != "refresh_token" || r.PostForm.Get("refresh_token") != "refresh-token" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+
+		refreshed++
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "fresh-access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	t.Cleanup(upstream.Close)
+
+	viperSetProviderConfig(t, conf, oidc.Configuration{
+		ID:               "oauth2",
+		Provider:         "generic-oauth2",
+		ClientID:         "client",
+		ClientSecret:     "secret",
+		AuthURL:          upstream.URL + "/oauth2/auth",
+		TokenURL:         upstream.URL + "/oauth2/token",
+		UserinfoRequests: []oidc.UserinfoRequest{{Name: "user", URL: upstream.URL + "/user"}},
+		ClaimsPaths:      map[string]string{"sub": "user.id"},
+	})
+
+	encrypt := func(t *testing.T, value string) string {
+		ciphertext, err := reg.Cipher(ctx).Encrypt(ctx, []byte(value))
+		require.NoError(t, err)
+		return ciphertext
+	}
+
+	decrypt := func(t *testing.T, ciphertext string) string {
+		plaintext, err := reg.Cipher(ctx).Decrypt(ctx, ciphertext)
+		require.NoError(t, err)
+		return string(plaintext)
+	}
+
+	createIdentity := func(t *testing.T, provider, refreshToken string) *identity.Identity {
+		creds, err := identity.NewCredentialsOIDC("", encrypt(t, "initial-access-token"), encrypt(t, refreshToken), provider, x.NewUUID().String())
+		require.NoError(t, err)
+
+		i := identity.NewIdentity("")
+		i.Traits = identity.Traits(`{"subject":"` + x.NewUUID().String() + `@ory.sh"}`)
+		i.SetCredentials(identity.CredentialsTypeOIDC, *creds)
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+		return i
+	}
+
+	refresh := func(t *testing.T, i *identity.Identity, provider string) (*http.Response, []byte) {
+		res, err := adminTS.Client().Post(adminTS.URL+"/admin/identities/"+i.ID.String()+"/oidc/"+provider+"/token", "application/json", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, body
+	}
+
+	t.Run("case=should return and store a fresh access token", func(t *testing.T) {
+		i := createIdentity(t, "oauth2", "refresh-token")
+
+		res, body := refresh(t, i, "oauth2")
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+		assert.Equal(t, "oauth2", gjson.GetBytes(body, "provider").String())
+		assert.Equal(t, "fresh-access-token", gjson.GetBytes(body, "access_token").String())
+		assert.Equal(t, "Bearer", gjson.GetBytes(body, "token_type").String())
+		assert.True(t, gjson.GetBytes(body, "expiry").Exists())
+		assert.Equal(t, 1, refreshed)
+
+		actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+		require.NoError(t, err)
+
+		var creds identity.CredentialsOIDC
+		_, err = actual.ParseCredentials(identity.CredentialsTypeOIDC, &creds)
+		require.NoError(t, err)
+		require.Len(t, creds.Providers, 1)
+		assert.Equal(t, "fresh-access-token", decrypt(t, creds.Providers[0].InitialAccessToken))
+		assert.Equal(t, "refresh-token", decrypt(t, creds.Providers[0].InitialRefreshToken))
+	})
+
+	t.Run("case=should not lose rotated refresh tokens when refreshing concurrently", func(t *testing.T) {
+		i := createIdentity(t, "oauth2", "rotating-0")
+
+		var wg sync.WaitGroup
+		var succeeded int32
+		for k := 0; k < 5; k++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res, body := refresh(t, i, "oauth2")
+				if res.StatusCode == http.StatusOK {
+					atomic.AddInt32(&succeeded, 1)
+					return
+				}
+				// Depending on whether the winner stored its tokens already, the provider's rejection is
+				// reported as a conflict or as a provider error.
+				assert.Contains(t, []int{http.StatusConflict, http.StatusInternalServerError}, res.StatusCode, "%s", body)
+			}()
+		}
+		wg.Wait()
+		assert.GreaterOrEqual(t, succeeded, int32(1))
+
+		actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+		require.NoError(t, err)
+
+		var creds identity.CredentialsOIDC
+		_, err = actual.ParseCredentials(identity.CredentialsTypeOIDC, &creds)
+		require.NoError(t, err)
+		rotatingLock.Lock()
+		assert.Equal(t, rotating, decrypt(t, creds.Providers[0].InitialRefreshToken))
+		rotatingLock.Unlock()
+
+		res, body := refresh(t, i, "oauth2")
+		assert.Equal(t, http.StatusOK, res.StatusCode, "retrying succeeds: %s", body)
+	})
+
+	t.Run("case=should not overwrite tokens which were refreshed while calling the provider", func(t *testing.T) {
+		i := createIdentity(t, "oauth2", "concurrent-0")
+		concurrent = func() {
+			// Another refresh stores its tokens while this one waits for the provider.
+			actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+			require.NoError(t, err)
+
+			var creds identity.CredentialsOIDC
+			c, err := actual.ParseCredentials(identity.CredentialsTypeOIDC, &creds)
+			require.NoError(t, err)
+			creds.Providers[0].InitialRefreshToken = encrypt(t, "concurrent-1")
+			require.NoError(t, actual.SetCredentialsWithConfig(identity.CredentialsTypeOIDC, *c, creds))
+			require.NoError(t, reg.PrivilegedIdentityPool().UpdateIdentity(ctx, actual))
+		}
+		t.Cleanup(func() { concurrent = nil })
+
+		res, body := refresh(t, i, "oauth2")
+		assert.Equal(t, http.StatusConflict, res.StatusCode, "%s", body)
+
+		actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+		require.NoError(t, err)
+
+		var creds identity.CredentialsOIDC
+		_, err = actual.ParseCredentials(identity.CredentialsTypeOIDC, &creds)
+		require.NoError(t, err)
+		assert.Equal(t, "concurrent-1", decrypt(t, creds.Providers[0].InitialRefreshToken))
+	})
+
+	t.Run("case=should fail if the identity is not linked to the provider", func(t *testing.T) {
+		i := createIdentity(t, "other", "refresh-token")
+
+		res, body := refresh(t, i, "oauth2")
+		assert.Equal(t, http.StatusNotFound, res.StatusCode, "%s", body)
+	})
+
+	t.Run("case=should fail without a refresh token", func(t *testing.T) {
+		i := createIdentity(t, "oauth2", "")
+
+		res, body := refresh(t, i, "oauth2")
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+		assert.Contains(t, gjson.GetBytes(body, "error.reason").String(), "did not issue a refresh token")
+	})
+
+	t.Run("case=should fail if the provider rejects the refresh token", func(t *testing.T) {
+		i := createIdentity(t, "oauth2", "revoked-refresh-token")
+
+		res, body := refresh(t, i, "oauth2")
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode, "%s", body)
+	})
+}
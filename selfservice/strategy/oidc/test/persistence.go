@@ -0,0 +1,133 @@
+package oidc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/x/sqlcon"
+
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/persistence"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
+	"github.com/ory/kratos/x"
+)
+
+func TestPersister(ctx context.Context, p persistence.Persister) func(t *testing.T) {
+	return func(t *testing.T) {
+		nid, p := testhelpers.NewNetworkUnlessExisting(t, ctx, p)
+
+		newProvider := func(t *testing.T) *oidc.StoredConfiguration {
+			return &oidc.StoredConfiguration{
+				ProviderID:   x.NewUUID().String(),
+				Config:       []byte(`{"provider":"generic"}`),
+				ClientSecret: "encrypted-secret",
+			}
+		}
+
+		t.Run("case=not found", func(t *testing.T) {
+			_, err := p.GetOIDCProvider(ctx, "i-do-not-exist")
+			require.ErrorIs(t, err, sqlcon.ErrNoRows)
+
+			require.ErrorIs(t, p.DeleteOIDCProvider(ctx, "i-do-not-exist"), sqlcon.ErrNoRows)
+		})
+
+		t.Run("case=create and get", func(t *testing.T) {
+			expected := newProvider(t)
+			require.NoError(t, p.CreateOIDCProvider(ctx, expected))
+			assert.Equal(t, nid, expected.NID)
+			assert.NotEqual(t, uuid.Nil, expected.ID)
+
+			actual, err := p.GetOIDCProvider(ctx, expected.ProviderID)
+			require.NoError(t, err)
+			assert.Equal(t, expected.ID, actual.ID)
+			assert.Equal(t, expected.ProviderID, actual.ProviderID)
+			assert.JSONEq(t, string(expected.Config), string(actual.Config))
+			assert.Equal(t, expected.ClientSecret, actual.ClientSecret)
+		})
+
+		t.Run("case=provider id must be unique", func(t *testing.T) {
+			expected := newProvider(t)
+			require.NoError(t, p.CreateOIDCProvider(ctx, expected))
+
+			duplicate := newProvider(t)
+			duplicate.ProviderID = expected.ProviderID
+			require.ErrorIs(t, p.CreateOIDCProvider(ctx, duplicate), sqlcon.ErrUniqueViolation)
+		})
+
+		t.Run("case=update", func(t *testing.T) {
+			expected := newProvider(t)
+			require.NoError(t, p.CreateOIDCProvider(ctx, expected))
+
+			expected.Config = []byte(`{"provider":"google"}`)
+			expected.ClientSecret = "other-secret"
+			require.NoError(t, p.UpdateOIDCProvider(ctx, expected))
+
+			actual, err := p.GetOIDCProvider(ctx, expected.ProviderID)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"provider":"google"}`, string(actual.Config))
+			assert.Equal(t, "other-secret", actual.ClientSecret)
+		})
+
+		t.Run("case=list", func(t *testing.T) {
+			_, p := testhelpers.NewNetwork(t, ctx, p)
+
+			first, second := newProvider(t), newProvider(t)
+			require.NoError(t, p.CreateOIDCProvider(ctx, first))
+			require.NoError(t, p.CreateOIDCProvider(ctx, second))
+
+			actual, err := p.ListOIDCProviders(ctx)
+			require.NoError(t, err)
+			require.Len(t, actual, 2)
+
+			ids := []string{actual[0].ProviderID, actual[1].ProviderID}
+			assert.ElementsMatch(t, []string{first.ProviderID, second.ProviderID}, ids)
+		})
+
+		t.Run("case=delete", func(t *testing.T) {
+			expected := newProvider(t)
+			require.NoError(t, p.CreateOIDCProvider(ctx, expected))
+			require.NoError(t, p.DeleteOIDCProvider(ctx, expected.ProviderID))
+
+			_, err := p.GetOIDCProvider(ctx, expected.ProviderID)
+			require.ErrorIs(t, err, sqlcon.ErrNoRows)
+		})
+
+		t.Run("case=network", func(t *testing.T) {
+			expected := newProvider(t)
+			require.NoError(t, p.CreateOIDCProvider(ctx, expected))
+
+			t.Run("can not get on another network", func(t *testing.T) {
+				_, p := testhelpers.NewNetwork(t, ctx, p)
+				_, err := p.GetOIDCProvider(ctx, expected.ProviderID)
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+
+			t.Run("can not list on another network", func(t *testing.T) {
+				_, p := testhelpers.NewNetwork(t, ctx, p)
+				actual, err := p.ListOIDCProviders(ctx)
+				require.NoError(t, err)
+				assert.Len(t, actual, 0)
+			})
+
+			t.Run("can not update on another network", func(t *testing.T) {
+				_, p := testhelpers.NewNetwork(t, ctx, p)
+				updated := *expected
+				updated.ClientSecret = "changed"
+				require.ErrorIs(t, p.UpdateOIDCProvider(ctx, &updated), sqlcon.ErrNoRows)
+			})
+
+			t.Run("can not delete on another network", func(t *testing.T) {
+				_, p := testhelpers.NewNetwork(t, ctx, p)
+				require.ErrorIs(t, p.DeleteOIDCProvider(ctx, expected.ProviderID), sqlcon.ErrNoRows)
+			})
+
+			actual, err := p.GetOIDCProvider(ctx, expected.ProviderID)
+			require.NoError(t, err)
+			assert.Equal(t, expected.ClientSecret, actual.ClientSecret)
+		})
+	}
+}
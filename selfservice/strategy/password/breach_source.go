@@ -0,0 +1,339 @@
+package password
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	/* #nosec G505 sha1 is used for k-anonymity */
+	"crypto/sha1"
+
+	"github.com/dgraph-io/ristretto"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+)
+
+// BreachSource looks up how often a password has been found in data breaches.
+//
+// Passwords are identified by their SHA-1 hash so that implementations never
+// see the plaintext password, mirroring the k-anonymity model of the
+// HaveIBeenPwned range API.
+type BreachSource interface {
+	// BreachCount returns how often the password with the given SHA-1 hash has
+	// been found in a breach. A count of zero means the password is unknown
+	// to this source.
+	//
+	// Errors wrapping ErrNetworkFailure or ErrUnexpectedStatusCode are treated
+	// as the source being temporarily unreachable and are ignored if
+	// `ignore_network_errors` is enabled. All other errors fail the password
+	// validation.
+	BreachCount(ctx context.Context, hash []byte) (int64, error)
+}
+
+var (
+	_ BreachSource = new(HaveIBeenPwnedAPISource)
+	_ BreachSource = new(HaveIBeenPwnedFileSource)
+	_ BreachSource = new(DenyListSource)
+)
+
+// HaveIBeenPwnedAPISource looks up passwords using the k-anonymity range API
+// of HaveIBeenPwned or a self-hosted copy of it. All hashes of a returned
+// range are cached so that subsequent lookups with the same prefix do not
+// require another request.
+type HaveIBeenPwnedAPISource struct {
+	host   string
+	client *retryablehttp.Client
+	hashes *ristretto.Cache
+}
+
+// NewHaveIBeenPwnedAPISource returns a BreachSource which queries the range API
+// at the given host and caches the results in the given cache.
+func NewHaveIBeenPwnedAPISource(host string, client *retryablehttp.Client, hashes *ristretto.Cache) *HaveIBeenPwnedAPISource {
+	return &HaveIBeenPwnedAPISource{host: host, client: client, hashes: hashes}
+}
+
+func (s *HaveIBeenPwnedAPISource) BreachCount(_ context.Context, hash []byte) (int64, error) {
+	if c, ok := s.hashes.Get(b20(hash)); ok {
+		if count, ok := c.(int64); ok {
+			return count, nil
+		}
+	}
+
+	prefix := b20(hash)[0:5]
+	res, err := s.client.Get(fmt.Sprintf("https://%s/range/%s", s.host, prefix))
+	if err != nil {
+		return 0, errors.Wrapf(ErrNetworkFailure, "%s", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return 0, errors.Wrapf(ErrUnexpectedStatusCode, "%d", res.StatusCode)
+	}
+
+	var thisCount int64
+
+	sc := bufio.NewScanner(res.Body)
+	for sc.Scan() {
+		// HIBP API sometimes responds without the colon, in which case
+		// parseBreachLine assumes that the leak count is one.
+		//
+		// See https://github.com/ory/kratos/issues/2145
+		suffix, count, err := parseBreachLine(sc.Text())
+		if err != nil {
+			return 0, err
+		}
+
+		s.hashes.SetWithTTL(prefix+suffix, count, 1, hashCacheItemTTL)
+		if prefix+suffix == b20(hash) {
+			thisCount = count
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return 0, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to initialize string scanner: %s", err))
+	}
+
+	s.hashes.SetWithTTL(b20(hash), thisCount, 1, hashCacheItemTTL)
+	return thisCount, nil
+}
+
+// HaveIBeenPwnedFileSource reads a locally mirrored copy of the HaveIBeenPwned
+// k-anonymity dataset. This allows deployments without internet access to
+// check passwords against known breaches.
+//
+// A missing or unreadable dataset is a configuration error and not a network
+// failure, so its errors are never ignored by `ignore_network_errors`.
+//
+// Two layouts are supported:
+//
+//   - A directory containing one file per hash prefix, named after the five
+//     character prefix (e.g. `21BD1.txt`). Each file contains lines in the form
+//     `SUFFIX:COUNT`, exactly like the responses of the range API. This is the
+//     layout produced by the official PwnedPasswordsDownloader.
+//   - A single file containing lines in the form `HASH:COUNT`, sorted by hash.
+//     The file is searched using binary search, so only a few kilobytes are read
+//     per lookup regardless of the file size.
+type HaveIBeenPwnedFileSource struct {
+	path string
+}
+
+// NewHaveIBeenPwnedFileSource returns a BreachSource for the given directory or
+// sorted file.
+func NewHaveIBeenPwnedFileSource(path string) *HaveIBeenPwnedFileSource {
+	return &HaveIBeenPwnedFileSource{path: path}
+}
+
+func (s *HaveIBeenPwnedFileSource) BreachCount(_ context.Context, hash []byte) (int64, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return 0, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to open local HaveIBeenPwned dataset: %s", err))
+	}
+
+	if info.IsDir() {
+		return s.countInRangeFile(hash)
+	}
+	return s.countInSortedFile(hash, info.Size())
+}
+
+func (s *HaveIBeenPwnedFileSource) countInRangeFile(hash []byte) (int64, error) {
+	hpw := b20(hash)
+	prefix, suffix := hpw[0:5], hpw[5:]
+
+	f, err := os.Open(filepath.Join(s.path, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to open local HaveIBeenPwned range file: %s", err))
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		candidate, count, err := parseBreachLine(sc.Text())
+		if err != nil {
+			return 0, err
+		}
+
+		if strings.EqualFold(candidate, suffix) {
+			return count, nil
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return 0, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to read local HaveIBeenPwned range file: %s", err))
+	}
+
+	return 0, nil
+}
+
+func (s *HaveIBeenPwnedFileSource) countInSortedFile(hash []byte, size int64) (int64, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return 0, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to open local HaveIBeenPwned dataset: %s", err))
+	}
+	defer f.Close()
+
+	target := b20(hash)
+
+	// Invariant: if the hash is contained in the file, its line starts within [lo, hi).
+	lo, hi := int64(0), size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		start, line, err := readLineAtOrAfter(f, mid, size)
+		if err != nil {
+			return 0, err
+		}
+
+		if start >= hi {
+			hi = mid
+			continue
+		}
+
+		candidate, count, err := parseBreachLine(line)
+		if err != nil {
+			return 0, err
+		}
+
+		switch c := strings.Compare(strings.ToUpper(candidate), target); {
+		case c == 0:
+			return count, nil
+		case c < 0:
+			lo = start + int64(len(line)) + 1
+		default:
+			hi = mid
+		}
+	}
+
+	return 0, nil
+}
+
+// readLineAtOrAfter returns the first line starting at or after offset as well
+// as its start position. The returned line does not contain the line break.
+func readLineAtOrAfter(f *os.File, offset, size int64) (int64, string, error) {
+	start := offset
+	if offset > 0 {
+		// Start reading one byte early so that a line starting exactly at offset is not skipped.
+		start = offset - 1
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(f, start, size-start))
+	if offset > 0 {
+		skipped, err := r.ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			return size, "", nil
+		} else if err != nil {
+			return 0, "", errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to read local HaveIBeenPwned dataset: %s", err))
+		}
+		start += int64(len(skipped))
+	}
+
+	line, err := r.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, "", errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to read local HaveIBeenPwned dataset: %s", err))
+	} else if len(line) == 0 {
+		return size, "", nil
+	}
+
+	return start, strings.TrimSuffix(line, "\n"), nil
+}
+
+// parseBreachLine parses a line in the `HASH:COUNT` format used by HaveIBeenPwned.
+func parseBreachLine(row string) (string, int64, error) {
+	result := strings.Split(strings.TrimSpace(row), ":")
+
+	// We assume a count of 1 if the count is missing, just like we do for the API.
+	count := int64(1)
+	if len(result) == 2 {
+		var err error
+		count, err = strconv.ParseInt(strings.ReplaceAll(result[1], ",", ""), 10, 64)
+		if err != nil {
+			return "", 0, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Expected password hash to contain a count formatted as int but got: %s", result[1]))
+		}
+	}
+
+	return result[0], count, nil
+}
+
+// DenyListSource checks passwords against a custom file of forbidden passwords,
+// for example company or product names. The file contains one plaintext
+// password per line. Only the SHA-1 hashes of the entries are kept in memory.
+//
+// The file is reloaded whenever its modification time changes.
+type DenyListSource struct {
+	path string
+
+	mu      sync.RWMutex
+	modTime time.Time
+	hashes  map[string]struct{}
+}
+
+// NewDenyListSource returns a BreachSource for the given deny-list file.
+func NewDenyListSource(path string) *DenyListSource {
+	return &DenyListSource{path: path}
+}
+
+func (s *DenyListSource) BreachCount(_ context.Context, hash []byte) (int64, error) {
+	hashes, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, ok := hashes[b20(hash)]; ok {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (s *DenyListSource) load() (map[string]struct{}, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to open password deny list: %s", err))
+	}
+
+	s.mu.RLock()
+	if s.hashes != nil && s.modTime.Equal(info.ModTime()) {
+		defer s.mu.RUnlock()
+		return s.hashes, nil
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to open password deny list: %s", err))
+	}
+	defer f.Close()
+
+	hashes := make(map[string]struct{})
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		entry := strings.TrimSuffix(sc.Text(), "\r")
+		if len(entry) == 0 {
+			continue
+		}
+
+		/* #nosec G401 sha1 is used for k-anonymity */
+		h := sha1.Sum([]byte(entry))
+		hashes[fmt.Sprintf("%X", h[:])] = struct{}{}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to read password deny list: %s", err))
+	}
+
+	s.hashes, s.modTime = hashes, info.ModTime()
+	return s.hashes, nil
+}
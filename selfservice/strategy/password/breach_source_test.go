@@ -0,0 +1,166 @@
+package password_test
+
+import (
+	"context"
+	/* #nosec G505 sha1 is used for k-anonymity */
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/selfservice/strategy/password"
+)
+
+func sha1Sum(pw string) []byte {
+	/* #nosec G401 sha1 is used for k-anonymity */
+	h := sha1.Sum([]byte(pw))
+	return h[:]
+}
+
+func TestHaveIBeenPwnedFileSource(t *testing.T) {
+	ctx := context.Background()
+	breached := map[string]int64{}
+	for i := 0; i < 500; i++ {
+		breached[fmt.Sprintf("password-%d", i)] = int64(i + 1)
+	}
+
+	t.Run("layout=sorted file", func(t *testing.T) {
+		lines := make([]string, 0, len(breached))
+		for pw, count := range breached {
+			lines = append(lines, fmt.Sprintf("%X:%d", sha1Sum(pw), count))
+		}
+		sort.Strings(lines)
+
+		for _, lineBreak := range []string{"\n", "\r\n"} {
+			path := filepath.Join(t.TempDir(), "pwned-passwords.txt")
+			require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, lineBreak)), 0600))
+			s := password.NewHaveIBeenPwnedFileSource(path)
+
+			for pw, expected := range breached {
+				actual, err := s.BreachCount(ctx, sha1Sum(pw))
+				require.NoError(t, err)
+				assert.Equal(t, expected, actual, pw)
+			}
+
+			for _, pw := range []string{"not-breached", "", "zzzzzzzzzz"} {
+				actual, err := s.BreachCount(ctx, sha1Sum(pw))
+				require.NoError(t, err)
+				assert.EqualValues(t, 0, actual, pw)
+			}
+		}
+	})
+
+	t.Run("layout=range directory", func(t *testing.T) {
+		dir := t.TempDir()
+		files := map[string][]string{}
+		for pw, count := range breached {
+			h := fmt.Sprintf("%X", sha1Sum(pw))
+			files[h[:5]] = append(files[h[:5]], fmt.Sprintf("%s:%d", h[5:], count))
+		}
+		for prefix, lines := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(strings.Join(lines, "\r\n")), 0600))
+		}
+		s := password.NewHaveIBeenPwnedFileSource(dir)
+
+		for pw, expected := range breached {
+			actual, err := s.BreachCount(ctx, sha1Sum(pw))
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual, pw)
+		}
+
+		actual, err := s.BreachCount(ctx, sha1Sum("not-breached"))
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, actual)
+	})
+
+	t.Run("case=fails if the dataset does not exist", func(t *testing.T) {
+		_, err := password.NewHaveIBeenPwnedFileSource(filepath.Join(t.TempDir(), "does-not-exist")).BreachCount(ctx, sha1Sum("foo"))
+		require.Error(t, err)
+	})
+}
+
+func TestDenyListSource(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "deny-list.txt")
+	require.NoError(t, os.WriteFile(path, []byte("acme-corp-2022\r\n\nSuperSecretProduct\n"), 0600))
+
+	s := password.NewDenyListSource(path)
+	for pw, expected := range map[string]int64{
+		"acme-corp-2022":     1,
+		"SuperSecretProduct": 1,
+		"supersecretproduct": 0,
+		"":                   0,
+	} {
+		actual, err := s.BreachCount(ctx, sha1Sum(pw))
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual, pw)
+	}
+}
+
+func TestValidatorWithLocalBreachSources(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	s, err := password.NewDefaultPasswordValidatorStrategy(reg)
+	require.NoError(t, err)
+
+	fakeClient := NewFakeHTTPClient()
+	s.Client.HTTPClient = &fakeClient.Client
+
+	path := filepath.Join(t.TempDir(), "pwned-passwords.txt")
+	require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf("%X:10", sha1Sum("ohlemifuka"))), 0600))
+	conf.MustSet(ctx, config.ViperKeyPasswordHaveIBeenPwnedSource, config.PasswordBreachSourceFile)
+	conf.MustSet(ctx, config.ViperKeyPasswordHaveIBeenPwnedPath, path)
+	conf.MustSet(ctx, config.ViperKeyPasswordMaxBreaches, 5)
+
+	t.Run("case=rejects passwords found in the local dataset", func(t *testing.T) {
+		assert.ErrorIs(t, s.Validate(ctx, "", "ohlemifuka"), password.ErrTooManyBreaches)
+		assert.NoError(t, s.Validate(ctx, "", "giwunadoha"))
+		assert.Empty(t, fakeClient.RequestedURLs())
+	})
+
+	t.Run("case=rejects passwords on the deny list", func(t *testing.T) {
+		denyList := filepath.Join(t.TempDir(), "deny-list.txt")
+		require.NoError(t, os.WriteFile(denyList, []byte("giwunadoha\n"), 0600))
+		conf.MustSet(ctx, config.ViperKeyPasswordDenyListPath, denyList)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyPasswordDenyListPath, "")
+		})
+
+		assert.ErrorIs(t, s.Validate(ctx, "", "giwunadoha"), password.ErrDenyListed)
+		assert.NoError(t, s.Validate(ctx, "", "zotaluhebi"))
+	})
+	t.Run("case=does not ignore errors of the local dataset", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyPasswordHaveIBeenPwnedPath, filepath.Join(t.TempDir(), "does-not-exist"))
+		conf.MustSet(ctx, config.ViperKeyIgnoreNetworkErrors, true)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyPasswordHaveIBeenPwnedPath, path)
+		})
+
+		assert.Error(t, s.Validate(ctx, "", "ohlemifuka"))
+	})
+
+	t.Run("case=uses a custom breach source", func(t *testing.T) {
+		s.Source = staticBreachSource{hex.EncodeToString(sha1Sum("wapomurefi")): 10}
+		t.Cleanup(func() {
+			s.Source = nil
+		})
+
+		assert.ErrorIs(t, s.Validate(ctx, "", "wapomurefi"), password.ErrTooManyBreaches)
+		assert.NoError(t, s.Validate(ctx, "", "ohlemifuka"))
+	})
+}
+
+type staticBreachSource map[string]int64
+
+func (s staticBreachSource) BreachCount(_ context.Context, hash []byte) (int64, error) {
+	return s[hex.EncodeToString(hash)], nil
+}
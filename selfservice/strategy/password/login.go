@@ -5,11 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/ory/kratos/selfservice/flowhelpers"
 
 	"github.com/ory/x/stringsx"
+	"github.com/ory/x/urlx"
 
 	"github.com/gofrs/uuid"
 
@@ -25,6 +27,7 @@ import (
 	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/selfservice/flow/settings"
 	"github.com/ory/kratos/text"
 	"github.com/ory/kratos/ui/node"
 	"github.com/ory/kratos/x"
@@ -82,12 +85,25 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 		return nil, s.handleLoginError(w, r, f, &p, errors.WithStack(schema.NewInvalidCredentialsError()))
 	}
 
-	if !s.d.Hasher(r.Context()).Understands([]byte(o.HashedPassword)) {
-		if err := s.migratePasswordHash(r.Context(), i.ID, []byte(p.Password)); err != nil {
+	if !s.d.Hasher(r.Context()).Understands([]byte(o.HashedPassword)) || o.ChangedAt == nil {
+		if err := s.migratePasswordHash(r.Context(), i.ID, o, []byte(p.Password)); err != nil {
 			return nil, s.handleLoginError(w, r, f, &p, err)
 		}
 	}
 
+	if o.IsExpired(s.d.Config().PasswordPolicyConfig(r.Context()).MaxPasswordAge) {
+		// The password has expired. Like a reset requested by an administrator, this restricts the session to the
+		// settings flow until a new password was chosen, regardless of the flow type.
+		if err := s.requirePasswordReset(r.Context(), i); err != nil {
+			return nil, s.handleLoginError(w, r, f, &p, err)
+		}
+
+		// Browsers are sent to the settings flow straight away once they are signed in.
+		if f.Type == flow.TypeBrowser {
+			f.ReturnTo = s.passwordExpiredReturnTo(r.Context(), f.ReturnTo).String()
+		}
+	}
+
 	f.Active = identity.CredentialsTypePassword
 	f.Active = s.ID()
 	if err = s.d.LoginFlowPersister().UpdateLoginFlow(r.Context(), f); err != nil {
@@ -97,12 +113,82 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 	return i, nil
 }
 
-func (s *Strategy) migratePasswordHash(ctx context.Context, identifier uuid.UUID, password []byte) error {
-	hpw, err := s.d.Hasher(ctx).Generate(ctx, password)
+// requirePasswordReset marks the identity as having to replace its password. A reset of the second factor which
+// was already requested by an administrator is kept, as the session is restricted until it is resolved anyway.
+func (s *Strategy) requirePasswordReset(ctx context.Context, i *identity.Identity) error {
+	if i.CredentialsResetRequired != identity.CredentialsResetNone {
+		return nil
+	}
+
+	stored, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
 	if err != nil {
 		return err
 	}
-	co, err := json.Marshal(&identity.CredentialsPassword{HashedPassword: string(hpw)})
+
+	stored.CredentialsResetRequired = identity.CredentialsResetPassword
+	if err := s.d.PrivilegedIdentityPool().UpdateIdentity(ctx, stored); err != nil {
+		return err
+	}
+
+	i.CredentialsResetRequired = identity.CredentialsResetPassword
+	return nil
+}
+
+// passwordExpiredReturnTo returns the URL of the settings flow which asks the user to change their
+// expired password. Once the password was changed, the user is sent to returnTo.
+func (s *Strategy) passwordExpiredReturnTo(ctx context.Context, returnTo string) *url.URL {
+	query := url.Values{}
+	if len(returnTo) > 0 {
+		query.Set("return_to", returnTo)
+	}
+	return urlx.CopyWithQuery(urlx.AppendPaths(s.d.Config().SelfPublicURL(ctx), settings.RouteInitBrowserFlow), query)
+}
+
+// passwordExpired returns true if the identity's password is older than the configured maximum password age.
+func (s *Strategy) passwordExpired(ctx context.Context, id *identity.Identity) (bool, error) {
+	maxAge := s.d.Config().PasswordPolicyConfig(ctx).MaxPasswordAge
+	if maxAge == 0 || id == nil {
+		return false, nil
+	}
+
+	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, id.ID)
+	if err != nil {
+		return false, err
+	}
+
+	c, ok := i.GetCredentials(s.ID())
+	if !ok {
+		return false, nil
+	}
+
+	var o identity.CredentialsPassword
+	if err := json.Unmarshal(c.Config, &o); err != nil {
+		return false, errors.WithStack(herodot.ErrInternalServerError.WithReason("The password credentials could not be decoded properly").WithDebug(err.Error()))
+	}
+
+	return o.IsExpired(maxAge), nil
+}
+
+// migratePasswordHash re-hashes the password using the configured hasher. Passwords which were set before the time
+// of their last change was recorded start to age now, so that they can expire without relying on the credentials'
+// update time, which changes whenever the identity is saved.
+func (s *Strategy) migratePasswordHash(ctx context.Context, identifier uuid.UUID, o identity.CredentialsPassword, password []byte) error {
+	if !s.d.Hasher(ctx).Understands([]byte(o.HashedPassword)) {
+		hpw, err := s.d.Hasher(ctx).Generate(ctx, password)
+		if err != nil {
+			return err
+		}
+
+		// Only the hash algorithm changes, so we keep the password history and the time the password was changed at.
+		o.HashedPassword = string(hpw)
+	}
+
+	if o.ChangedAt == nil {
+		changedAt := time.Now().UTC()
+		o.ChangedAt = &changedAt
+	}
+
+	co, err := json.Marshal(&o)
 	if err != nil {
 		return errors.Wrap(err, "unable to encode password configuration to JSON")
 	}
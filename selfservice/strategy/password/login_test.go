@@ -38,6 +38,7 @@ import (
 	"github.com/ory/kratos/internal/testhelpers"
 	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/text"
 	"github.com/ory/kratos/x"
 )
@@ -858,4 +859,115 @@ func TestCompleteLogin(t *testing.T) {
 			false, true, http.StatusOK, redirTS.URL)
 		assert.Equal(t, identifier, gjson.Get(body, "identity.traits.subject").String(), "%s", body)
 	})
+
+	t.Run("should send the user to the settings flow if the password expired", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyPasswordMaxAge, "1h")
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyPasswordMaxAge, "0s")
+		})
+		settingsTS := testhelpers.NewSettingsUIFlowEchoServer(t, reg)
+
+		identifier, pwd := x.NewUUID().String(), "password"
+		p, _ := reg.Hasher(ctx).Generate(context.Background(), []byte(pwd))
+		changedAt := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+		iId := x.NewUUID()
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(context.Background(), &identity.Identity{
+			ID:     iId,
+			Traits: identity.Traits(fmt.Sprintf(`{"subject":"%s"}`, identifier)),
+			Credentials: map[identity.CredentialsType]identity.Credentials{
+				identity.CredentialsTypePassword: {
+					Type:        identity.CredentialsTypePassword,
+					Identifiers: []string{identifier},
+					Config:      sqlxx.JSONRawMessage(`{"hashed_password":"` + string(p) + `","changed_at":"` + changedAt + `"}`),
+				},
+			},
+			VerifiableAddresses: []identity.VerifiableAddress{
+				{
+					ID:         x.NewUUID(),
+					Value:      identifier,
+					Verified:   true,
+					CreatedAt:  time.Now(),
+					IdentityID: iId,
+				},
+			},
+		}))
+
+		var values = func(v url.Values) {
+			v.Set("identifier", identifier)
+			v.Set("password", pwd)
+		}
+
+		body := testhelpers.SubmitLoginForm(t, false, nil, publicTS, values,
+			false, false, http.StatusOK, settingsTS.URL)
+		assert.EqualValues(t, text.InfoSelfServiceSettingsPasswordExpired, gjson.Get(body, "ui.messages.0.id").Int(), "%s", body)
+
+		stored, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, iId)
+		require.NoError(t, err)
+		assert.Equal(t, identity.CredentialsResetPassword, stored.CredentialsResetRequired)
+
+		t.Run("case=api flows receive a restricted session", func(t *testing.T) {
+			body := testhelpers.SubmitLoginForm(t, true, nil, publicTS, values,
+				false, false, http.StatusOK, publicTS.URL+login.RouteSubmitFlow)
+			st := gjson.Get(body, "session_token").String()
+			require.NotEmpty(t, st, "%s", body)
+
+			req := testhelpers.NewHTTPGetJSONRequest(t, publicTS.URL+session.RouteWhoami)
+			req.Header.Set("Authorization", "Bearer "+st)
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			whoami := ioutilx.MustReadAll(res.Body)
+
+			assert.EqualValues(t, http.StatusForbidden, res.StatusCode, "%s", whoami)
+			assert.EqualValues(t, text.ErrIDCredentialsResetRequired, gjson.GetBytes(whoami, "error.id").String(), "%s", whoami)
+		})
+
+		t.Run("case=not expired", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeyPasswordMaxAge, "24h")
+			testhelpers.SubmitLoginForm(t, false, nil, publicTS, values,
+				false, false, http.StatusOK, redirTS.URL)
+		})
+
+		t.Run("case=passwords without a recorded change time start to age with the next login", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeyPasswordMaxAge, "1h")
+
+			identifier := x.NewUUID().String()
+			id := &identity.Identity{
+				ID:     x.NewUUID(),
+				Traits: identity.Traits(fmt.Sprintf(`{"subject":"%s"}`, identifier)),
+				Credentials: map[identity.CredentialsType]identity.Credentials{
+					identity.CredentialsTypePassword: {
+						Type:        identity.CredentialsTypePassword,
+						Identifiers: []string{identifier},
+						Config:      sqlxx.JSONRawMessage(`{"hashed_password":"` + string(p) + `"}`),
+					},
+				},
+			}
+			id.VerifiableAddresses = []identity.VerifiableAddress{{ID: x.NewUUID(), Value: identifier, Verified: true, CreatedAt: time.Now(), IdentityID: id.ID}}
+			require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, id))
+
+			// Saving the identity must not make the password look newer or older than it is.
+			id.Credentials[identity.CredentialsTypePassword] = identity.Credentials{
+				Type:        identity.CredentialsTypePassword,
+				Identifiers: []string{identifier},
+				Config:      sqlxx.JSONRawMessage(`{"hashed_password":"` + string(p) + `"}`),
+				UpdatedAt:   time.Now().Add(-2 * time.Hour),
+			}
+			require.NoError(t, reg.PrivilegedIdentityPool().UpdateIdentity(ctx, id))
+
+			testhelpers.SubmitLoginForm(t, false, nil, publicTS, func(v url.Values) {
+				v.Set("identifier", identifier)
+				v.Set("password", pwd)
+			}, false, false, http.StatusOK, redirTS.URL)
+
+			stored, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, id.ID)
+			require.NoError(t, err)
+			assert.Equal(t, identity.CredentialsResetNone, stored.CredentialsResetRequired)
+
+			var o identity.CredentialsPassword
+			require.NoError(t, json.Unmarshal(stored.Credentials[identity.CredentialsTypePassword].Config, &o))
+			require.NotNil(t, o.ChangedAt)
+			assert.WithinDuration(t, time.Now(), *o.ChangedAt, time.Minute)
+		})
+	})
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/ory/kratos/text"
 
@@ -98,7 +99,8 @@ func (s *Strategy) Register(w http.ResponseWriter, r *http.Request, f *registrat
 	}
 
 	i.Traits = identity.Traits(p.Traits)
-	if err := i.SetCredentialsWithConfig(s.ID(), identity.Credentials{Type: s.ID(), Identifiers: []string{}}, &identity.CredentialsPassword{HashedPassword: string(hpw)}); err != nil {
+	changedAt := time.Now().UTC()
+	if err := i.SetCredentialsWithConfig(s.ID(), identity.Credentials{Type: s.ID(), Identifiers: []string{}}, &identity.CredentialsPassword{HashedPassword: string(hpw), ChangedAt: &changedAt}); err != nil {
 		return s.handleRegistrationError(w, r, f, &p, err)
 	}
 
@@ -126,6 +128,8 @@ func (s *Strategy) validateCredentials(ctx context.Context, i *identity.Identity
 		if err := s.d.PasswordValidator().Validate(ctx, id, pw); err != nil {
 			if _, ok := errorsx.Cause(err).(*herodot.DefaultError); ok {
 				return err
+			} else if _, ok := errorsx.Cause(err).(*schema.ValidationError); ok {
+				return err
 			}
 			return schema.NewPasswordPolicyViolationError("#/password", err.Error())
 		}
@@ -1,6 +1,7 @@
 package password
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/ory/herodot"
+	"github.com/ory/kratos/hash"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/selfservice/flow"
@@ -120,31 +122,92 @@ func (s *Strategy) continueSettingsFlow(
 		return schema.NewRequiredError("#/password", "password")
 	}
 
-	hpw, err := s.d.Hasher(r.Context()).Generate(r.Context(), []byte(p.Password))
+	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(r.Context(), ctxUpdate.Session.Identity.ID)
 	if err != nil {
 		return err
 	}
 
-	co, err := json.Marshal(&identity.CredentialsPassword{HashedPassword: string(hpw)})
+	history, err := s.checkPasswordHistory(r.Context(), i, p.Password)
 	if err != nil {
-		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to encode password options to JSON: %s", err))
+		return err
 	}
 
-	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(r.Context(), ctxUpdate.Session.Identity.ID)
+	hpw, err := s.d.Hasher(r.Context()).Generate(r.Context(), []byte(p.Password))
 	if err != nil {
 		return err
 	}
 
+	changedAt := time.Now().UTC()
+	co, err := json.Marshal(&identity.CredentialsPassword{HashedPassword: string(hpw), PreviousHashedPasswords: history, ChangedAt: &changedAt})
+	if err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to encode password options to JSON: %s", err))
+	}
+
 	i.UpsertCredentialsConfig(s.ID(), co, 0)
 	if err := s.validateCredentials(r.Context(), i, p.Password); err != nil {
 		return err
 	}
 	ctxUpdate.UpdateIdentity(i)
+	ctxUpdate.MarkCredentialsAddedOrRotated()
 
 	return nil
 }
 
-func (s *Strategy) PopulateSettingsMethod(r *http.Request, _ *identity.Identity, f *settings.Flow) error {
+// checkPasswordHistory returns an error if the password matches one of the most recently used passwords
+// of the identity. Otherwise, it returns the password history to be stored alongside the new password.
+//
+// The current password is never accepted as the new password, even if no history is kept.
+func (s *Strategy) checkPasswordHistory(ctx context.Context, i *identity.Identity, password string) ([]string, error) {
+	size := int(s.d.Config().PasswordPolicyConfig(ctx).PasswordHistorySize)
+	keep := size
+	if size == 0 {
+		size = 1
+	}
+
+	c, ok := i.GetCredentials(s.ID())
+	if !ok {
+		return nil, nil
+	}
+
+	var o identity.CredentialsPassword
+	if err := json.Unmarshal(c.Config, &o); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReason("The password credentials could not be decoded properly").WithDebug(err.Error()))
+	}
+
+	history := o.PreviousHashedPasswords
+	if len(o.HashedPassword) > 0 {
+		history = append([]string{o.HashedPassword}, history...)
+	}
+	if len(history) > size {
+		history = history[:size]
+	}
+
+	for _, hpw := range history {
+		if err := hash.Compare(ctx, []byte(password), []byte(hpw)); err == nil {
+			return nil, schema.NewPasswordReusedError("#/password", size)
+		}
+	}
+
+	if keep == 0 {
+		return nil, nil
+	}
+
+	// The new password counts towards the history as well, so we only need to keep size-1 previous passwords.
+	if len(history) == size {
+		history = history[:size-1]
+	}
+
+	return history, nil
+}
+
+func (s *Strategy) PopulateSettingsMethod(r *http.Request, id *identity.Identity, f *settings.Flow) error {
+	expired, err := s.passwordExpired(r.Context(), id)
+	if err != nil {
+		return err
+	} else if expired {
+		f.UI.Messages.Add(text.NewInfoSelfServiceSettingsPasswordExpired())
+	}
+
 	f.UI.SetCSRF(s.d.GenerateCSRFToken(r))
 	f.UI.Nodes.Upsert(NewPasswordNode("password", node.InputAttributeAutocompleteNewPassword).WithMetaLabel(text.NewInfoNodeInputPassword()))
 	f.UI.Nodes.Append(node.NewInputField("method", "password", node.PasswordGroup, node.InputAttributeTypeSubmit).WithMetaLabel(text.NewInfoNodeLabelSave()))
@@ -374,6 +374,80 @@ func TestSettings(t *testing.T) {
 		})
 	})
 
+	t.Run("description=should not allow reusing one of the most recent passwords", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyPasswordHistorySize, 2)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyPasswordHistorySize, 0)
+		})
+
+		id := newIdentityWithoutCredentials(x.NewUUID().String() + "@ory.sh")
+		apiUser := testhelpers.NewHTTPClientWithIdentitySessionToken(t, reg, id)
+
+		var payload = func(password string) func(v url.Values) {
+			return func(v url.Values) {
+				v.Set("method", "password")
+				v.Set("password", password)
+			}
+		}
+
+		first, second, third := randx.MustString(16, randx.AlphaNum), randx.MustString(16, randx.AlphaNum), randx.MustString(16, randx.AlphaNum)
+		assert.Equal(t, "success", gjson.Get(expectSuccess(t, true, false, apiUser, payload(first)), "state").String())
+		assert.Equal(t, "success", gjson.Get(expectSuccess(t, true, false, apiUser, payload(second)), "state").String())
+
+		for _, reused := range []string{first, second} {
+			actual := expectValidationError(t, true, false, apiUser, payload(reused))
+			assert.EqualValues(t, text.ErrorValidationPasswordReused, gjson.Get(actual, "ui.nodes.#(attributes.name==password).messages.0.id").Int(), "%s", actual)
+		}
+
+		assert.Equal(t, "success", gjson.Get(expectSuccess(t, true, false, apiUser, payload(third)), "state").String())
+		// The first password dropped out of the history and may be used again.
+		assert.Equal(t, "success", gjson.Get(expectSuccess(t, true, false, apiUser, payload(first)), "state").String())
+
+		actualIdentity, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(context.Background(), id.ID)
+		require.NoError(t, err)
+		assert.Len(t, gjson.GetBytes(actualIdentity.Credentials[identity.CredentialsTypePassword].Config, "previous_hashed_passwords").Array(), 1)
+		assert.NotEmpty(t, gjson.GetBytes(actualIdentity.Credentials[identity.CredentialsTypePassword].Config, "changed_at").String())
+	})
+
+	t.Run("description=should not allow setting the current password again", func(t *testing.T) {
+		id := newIdentityWithoutCredentials(x.NewUUID().String() + "@ory.sh")
+		apiUser := testhelpers.NewHTTPClientWithIdentitySessionToken(t, reg, id)
+
+		password := randx.MustString(16, randx.AlphaNum)
+		var payload = func(v url.Values) {
+			v.Set("method", "password")
+			v.Set("password", password)
+		}
+
+		assert.Equal(t, "success", gjson.Get(expectSuccess(t, true, false, apiUser, payload), "state").String())
+		actual := expectValidationError(t, true, false, apiUser, payload)
+		assert.EqualValues(t, text.ErrorValidationPasswordReused, gjson.Get(actual, "ui.nodes.#(attributes.name==password).messages.0.id").Int(), "%s", actual)
+
+		actualIdentity, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(context.Background(), id.ID)
+		require.NoError(t, err)
+		assert.False(t, gjson.GetBytes(actualIdentity.Credentials[identity.CredentialsTypePassword].Config, "previous_hashed_passwords").Exists())
+	})
+
+	t.Run("description=should clear a required credentials reset", func(t *testing.T) {
+		id := newIdentityWithoutCredentials(x.NewUUID().String() + "@ory.sh")
+		id.CredentialsResetRequired = identity.CredentialsResetPassword
+		apiUser := testhelpers.NewHTTPClientWithIdentitySessionToken(t, reg, id)
+
+		f := testhelpers.InitializeSettingsFlowViaAPI(t, apiUser, publicTS)
+		require.Len(t, f.Ui.Messages, 1)
+		assert.EqualValues(t, text.InfoSelfServiceSettingsCredentialsResetRequired, f.Ui.Messages[0].Id)
+
+		actual := expectSuccess(t, true, false, apiUser, func(v url.Values) {
+			v.Set("method", "password")
+			v.Set("password", randx.MustString(16, randx.AlphaNum))
+		})
+		assert.Equal(t, "success", gjson.Get(actual, "state").String(), "%s", actual)
+
+		actualIdentity, err := reg.PrivilegedIdentityPool().GetIdentity(context.Background(), id.ID)
+		require.NoError(t, err)
+		assert.Equal(t, identity.CredentialsResetNone, actualIdentity.CredentialsResetRequired)
+	})
+
 	t.Run("description=should update the password and perform the correct redirection", func(t *testing.T) {
 		rts := testhelpers.NewRedirTS(t, "", conf)
 		conf.MustSet(ctx, config.ViperKeySelfServiceSettingsAfter+"."+config.DefaultBrowserReturnURL, rts.URL+"/return-ts")
@@ -1,7 +1,6 @@
 package password
 
 import (
-	"bufio"
 	"context"
 	stderrs "errors"
 
@@ -10,17 +9,17 @@ import (
 	/* #nosec G505 sha1 is used for k-anonymity */
 	"crypto/sha1"
 	"fmt"
-	"net/http"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"github.com/arbovm/levenshtein"
 	"github.com/dgraph-io/ristretto"
 	"github.com/pkg/errors"
 
-	"github.com/ory/herodot"
 	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/schema"
 	"github.com/ory/x/httpx"
 )
 
@@ -30,8 +29,8 @@ const hashCacheItemTTL = time.Hour
 // has to have at least 6 characters and at least one lower and one uppercase password.
 type Validator interface {
 	// Validate returns nil if the password is passing the validation strategy and an error otherwise. If a validation error
-	// occurs, a regular error will be returned. If some other type of error occurs (e.g. HTTP request failed), an error
-	// of type *herodot.DefaultError will be returned.
+	// occurs, a regular error or an error of type *schema.ValidationError will be returned. If some other type of error
+	// occurs (e.g. HTTP request failed), an error of type *herodot.DefaultError will be returned.
 	Validate(ctx context.Context, identifier, password string) error
 }
 
@@ -44,6 +43,7 @@ var (
 	ErrNetworkFailure                 = stderrs.New("unable to check if password has been leaked because an unexpected network error occurred")
 	ErrUnexpectedStatusCode           = stderrs.New("unexpected status code")
 	ErrTooManyBreaches                = stderrs.New("the password has been found in data breaches and must no longer be used")
+	ErrDenyListed                     = stderrs.New("the password is not allowed because it is on the list of forbidden passwords")
 )
 
 // DefaultPasswordValidator implements Validator. It is based on best
@@ -54,12 +54,20 @@ var (
 //
 // Additionally passwords are being checked against Troy Hunt's
 // [haveibeenpwnd](https://haveibeenpwned.com/API/v2#SearchingPwnedPasswordsByRange) service to check if the
-// password has been breached in a previous data leak using k-anonymity.
+// password has been breached in a previous data leak using k-anonymity. Instead of the API, a locally
+// mirrored copy of the dataset (see HaveIBeenPwnedFileSource) or any other BreachSource can be used.
+// Passwords can also be checked against a custom deny list (see DenyListSource).
 type DefaultPasswordValidator struct {
 	reg    validatorDependencies
 	Client *retryablehttp.Client
 	hashes *ristretto.Cache
 
+	// Source replaces the breach source selected by the `haveibeenpwned_source` configuration if set.
+	Source BreachSource
+
+	denyListLock sync.Mutex
+	denyList     *DenyListSource
+
 	minIdentifierPasswordDist            int
 	maxIdentifierPasswordSubstrThreshold float32
 }
@@ -112,50 +120,31 @@ func lcsLength(a, b string) int {
 	return greatestLength
 }
 
-func (s *DefaultPasswordValidator) fetch(hpw []byte, apiDNSName string) (int64, error) {
-	prefix := fmt.Sprintf("%X", hpw)[0:5]
-	loc := fmt.Sprintf("https://%s/range/%s", apiDNSName, prefix)
-	res, err := s.Client.Get(loc)
-	if err != nil {
-		return 0, errors.Wrapf(ErrNetworkFailure, "%s", err)
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode != http.StatusOK {
-		return 0, errors.Wrapf(ErrUnexpectedStatusCode, "%d", res.StatusCode)
-	}
-
-	var thisCount int64
-
-	sc := bufio.NewScanner(res.Body)
-	for sc.Scan() {
-		row := sc.Text()
-		result := strings.Split(strings.TrimSpace(row), ":")
-
-		// We assume a count of 1. HIBP API sometimes responds without the
-		// colon, so we just assume that the leak count is one.
-		//
-		// See https://github.com/ory/kratos/issues/2145
-		count := int64(1)
-		if len(result) == 2 {
-			count, err = strconv.ParseInt(strings.ReplaceAll(result[1], ",", ""), 10, 64)
-			if err != nil {
-				return 0, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Expected password hash to contain a count formatted as int but got: %s", result[1]))
+// missingCharacterClasses returns those of the required character classes of which the password
+// does not contain a single character.
+func missingCharacterClasses(password string, required []string) (missing []string) {
+	for _, class := range required {
+		var matches func(rune) bool
+		switch class {
+		case config.PasswordCharacterClassLowercase:
+			matches = unicode.IsLower
+		case config.PasswordCharacterClassUppercase:
+			matches = unicode.IsUpper
+		case config.PasswordCharacterClassDigits:
+			matches = unicode.IsDigit
+		case config.PasswordCharacterClassSymbols:
+			matches = func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r)
 			}
+		default:
+			continue
 		}
 
-		s.hashes.SetWithTTL(prefix+result[0], count, 1, hashCacheItemTTL)
-		if prefix+result[0] == b20(hpw) {
-			thisCount = count
+		if strings.IndexFunc(password, matches) == -1 {
+			missing = append(missing, class)
 		}
 	}
-
-	if err := sc.Err(); err != nil {
-		return 0, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to initialize string scanner: %s", err))
-	}
-
-	s.hashes.SetWithTTL(b20(hpw), thisCount, 1, hashCacheItemTTL)
-	return thisCount, nil
+	return missing
 }
 
 func (s *DefaultPasswordValidator) Validate(ctx context.Context, identifier, password string) error {
@@ -165,6 +154,14 @@ func (s *DefaultPasswordValidator) Validate(ctx context.Context, identifier, pas
 		return errors.Errorf("password length must be at least %d characters but only got %d", passwordPolicyConfig.MinPasswordLength, len(password))
 	}
 
+	if passwordPolicyConfig.MaxPasswordLength > 0 && len(password) > int(passwordPolicyConfig.MaxPasswordLength) {
+		return schema.NewPasswordMaxLengthError("#/password", int(passwordPolicyConfig.MaxPasswordLength), len(password))
+	}
+
+	if missing := missingCharacterClasses(password, passwordPolicyConfig.RequiredCharacterClasses); len(missing) > 0 {
+		return schema.NewPasswordCharacterClassesError("#/password", missing)
+	}
+
 	if passwordPolicyConfig.IdentifierSimilarityCheckEnabled && len(identifier) > 0 {
 		compIdentifier, compPassword := strings.ToLower(identifier), strings.ToLower(password)
 		dist := levenshtein.Distance(compIdentifier, compPassword)
@@ -174,10 +171,6 @@ func (s *DefaultPasswordValidator) Validate(ctx context.Context, identifier, pas
 		}
 	}
 
-	if !passwordPolicyConfig.HaveIBeenPwnedEnabled {
-		return nil
-	}
-
 	/* #nosec G401 sha1 is used for k-anonymity */
 	h := sha1.New()
 	if _, err := h.Write([]byte(password)); err != nil {
@@ -185,21 +178,55 @@ func (s *DefaultPasswordValidator) Validate(ctx context.Context, identifier, pas
 	}
 	hpw := h.Sum(nil)
 
-	c, ok := s.hashes.Get(b20(hpw))
-	if !ok {
-		var err error
-		c, err = s.fetch(hpw, passwordPolicyConfig.HaveIBeenPwnedHost)
-		if (errors.Is(err, ErrNetworkFailure) || errors.Is(err, ErrUnexpectedStatusCode)) && passwordPolicyConfig.IgnoreNetworkErrors {
-			return nil
-		} else if err != nil {
+	if len(passwordPolicyConfig.DenyListPath) > 0 {
+		c, err := s.denyListSource(passwordPolicyConfig.DenyListPath).BreachCount(ctx, hpw)
+		if err != nil {
 			return err
+		} else if c > 0 {
+			return errors.WithStack(ErrDenyListed)
 		}
 	}
 
-	v, ok := c.(int64)
-	if ok && v > int64(s.reg.Config().PasswordPolicyConfig(ctx).MaxBreaches) {
+	if !passwordPolicyConfig.HaveIBeenPwnedEnabled {
+		return nil
+	}
+
+	c, err := s.breachSource(ctx).BreachCount(ctx, hpw)
+	if (errors.Is(err, ErrNetworkFailure) || errors.Is(err, ErrUnexpectedStatusCode)) && passwordPolicyConfig.IgnoreNetworkErrors {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if c > int64(passwordPolicyConfig.MaxBreaches) {
 		return errors.WithStack(ErrTooManyBreaches)
 	}
 
 	return nil
 }
+
+// breachSource returns the BreachSource passwords are looked up in. Unless a Source is set
+// explicitly, it is selected by the `haveibeenpwned_source` configuration.
+func (s *DefaultPasswordValidator) breachSource(ctx context.Context) BreachSource {
+	if s.Source != nil {
+		return s.Source
+	}
+
+	passwordPolicyConfig := s.reg.Config().PasswordPolicyConfig(ctx)
+	switch passwordPolicyConfig.HaveIBeenPwnedSource {
+	case config.PasswordBreachSourceFile:
+		return NewHaveIBeenPwnedFileSource(passwordPolicyConfig.HaveIBeenPwnedPath)
+	default:
+		return NewHaveIBeenPwnedAPISource(passwordPolicyConfig.HaveIBeenPwnedHost, s.Client, s.hashes)
+	}
+}
+
+func (s *DefaultPasswordValidator) denyListSource(path string) *DenyListSource {
+	s.denyListLock.Lock()
+	defer s.denyListLock.Unlock()
+
+	if s.denyList == nil || s.denyList.path != path {
+		s.denyList = NewDenyListSource(path)
+	}
+	return s.denyList
+}
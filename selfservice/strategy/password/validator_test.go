@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,7 +25,9 @@ import (
 
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/selfservice/strategy/password"
+	"github.com/ory/kratos/text"
 )
 
 func TestDefaultPasswordValidationStrategy(t *testing.T) {
@@ -380,3 +383,59 @@ type fakeRoundTripper struct {
 func (rt *fakeRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
 	return rt.client.handle(request)
 }
+
+func TestChangeMaxPasswordLength(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	s, _ := password.NewDefaultPasswordValidatorStrategy(reg)
+	conf.MustSet(ctx, config.ViperKeyPasswordHaveIBeenPwnedEnabled, false)
+	conf.MustSet(ctx, config.ViperKeyPasswordMaxLength, 12)
+
+	t.Run("case=should not fail if password is shorter than max length", func(t *testing.T) {
+		require.NoError(t, s.Validate(context.Background(), "", "kuobahcaasxy"))
+	})
+
+	t.Run("case=should fail if password is longer than max length", func(t *testing.T) {
+		err := s.Validate(context.Background(), "", "kuobahcaasxyz")
+		var ve *schema.ValidationError
+		require.ErrorAs(t, err, &ve)
+		assert.EqualValues(t, text.ErrorValidationPasswordMaxLength, ve.Messages[0].ID)
+	})
+}
+
+func TestChangeRequiredCharacterClasses(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	s, _ := password.NewDefaultPasswordValidatorStrategy(reg)
+	conf.MustSet(ctx, config.ViperKeyPasswordHaveIBeenPwnedEnabled, false)
+	conf.MustSet(ctx, config.ViperKeyPasswordRequiredCharacterClasses, []string{
+		config.PasswordCharacterClassLowercase,
+		config.PasswordCharacterClassUppercase,
+		config.PasswordCharacterClassDigits,
+		config.PasswordCharacterClassSymbols,
+	})
+
+	for _, tc := range []struct {
+		pw      string
+		missing []string
+	}{
+		{pw: "kuobahcaas", missing: []string{"uppercase", "digits", "symbols"}},
+		{pw: "KUOBAHCAAS1", missing: []string{"lowercase", "symbols"}},
+		{pw: "Kuobahcaas!", missing: []string{"digits"}},
+		{pw: "Kuöbahcääs 1"},
+		{pw: "Kuobahcaas1!"},
+	} {
+		t.Run("case="+tc.pw, func(t *testing.T) {
+			err := s.Validate(context.Background(), "", tc.pw)
+			if len(tc.missing) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var ve *schema.ValidationError
+			require.ErrorAs(t, err, &ve)
+			assert.EqualValues(t, text.ErrorValidationPasswordCharacterClasses, ve.Messages[0].ID)
+			assert.Contains(t, ve.Messages[0].Text, strings.Join(tc.missing, ", "))
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package profile
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ory/x/stringslice"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/schema"
+	"github.com/ory/kratos/selfservice/flow/settings"
+	"github.com/ory/kratos/selfservice/flow/verification"
+	"github.com/ory/kratos/selfservice/strategy/link"
+	"github.com/ory/kratos/text"
+)
+
+// holdAddressChange holds back the new identifier or recovery address of an update until the address has been
+// confirmed. All other changes are applied right away. It returns false if the update does not need to be held back.
+func (s *Strategy) holdAddressChange(r *http.Request, ctxUpdate *settings.UpdateContext, update *identity.Identity, options ...identity.ManagerOption) (bool, error) {
+	ctx := r.Context()
+	if !s.d.Config().ProfileVerifyAddressChanges(ctx) {
+		return false, nil
+	}
+
+	original, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, update.ID)
+	if err != nil {
+		return false, err
+	}
+
+	added := addedAddresses(original, update)
+	if len(added) == 0 {
+		return false, nil
+	} else if len(added) > 1 {
+		return false, schema.NewTooManyAddressChangesError()
+	}
+
+	// Only the most recent address change can be confirmed.
+	if err := s.d.AddressChangePersister().DeletePendingAddressChanges(ctx, original.ID); err != nil {
+		return false, err
+	}
+
+	f, err := verification.NewPostHookFlow(s.d.Config(),
+		s.d.Config().SelfServiceFlowVerificationRequestLifespan(ctx),
+		s.d.GenerateCSRFToken(r), r, s.d.VerificationStrategies(ctx), ctxUpdate.Flow)
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.d.VerificationFlowPersister().CreateVerificationFlow(ctx, f); err != nil {
+		return false, err
+	}
+
+	change := link.NewAddressChange(update, original.Traits, added[0], f,
+		s.d.Config().SelfServiceLinkMethodLifespan(ctx),
+		s.d.Config().ProfileAddressChangeRevertLifespan(ctx))
+
+	// Everything but the new address is applied right away.
+	traits, err := change.Revert(update.Traits)
+	if err != nil {
+		return false, err
+	}
+
+	withoutAddress, err := s.d.IdentityManager().SetTraits(ctx, original.ID, traits, options...)
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.d.AddressChangePersister().CreateAddressChange(ctx, change); err != nil {
+		return false, err
+	}
+
+	if err := s.d.LinkSender().SendAddressChangeTo(ctx, f, original, change, original.EmailAddresses()); err != nil {
+		return false, err
+	}
+
+	ctxUpdate.UpdateIdentity(withoutAddress)
+	ctxUpdate.SetSuccessMessage(text.NewInfoSelfServiceSettingsAddressChangePending(change.Address))
+	return true, nil
+}
+
+// addedAddresses returns the email addresses which are used for recovery or as an identifier
+// by the updated identity but were unknown to the original identity.
+func addedAddresses(original, updated *identity.Identity) []string {
+	known := make(map[string]struct{})
+	for _, a := range original.EmailAddresses() {
+		known[strings.ToLower(a)] = struct{}{}
+	}
+	for _, c := range original.Credentials {
+		for _, id := range c.Identifiers {
+			known[strings.ToLower(id)] = struct{}{}
+		}
+	}
+
+	identifiers := make(map[string]struct{})
+	for _, c := range updated.Credentials {
+		for _, id := range c.Identifiers {
+			identifiers[strings.ToLower(id)] = struct{}{}
+		}
+	}
+
+	var added []string
+	for _, a := range updated.RecoveryAddresses {
+		if _, ok := known[strings.ToLower(a.Value)]; !ok && a.Via == identity.AddressTypeEmail {
+			added = append(added, strings.ToLower(a.Value))
+		}
+	}
+	for _, a := range updated.VerifiableAddresses {
+		if _, ok := identifiers[strings.ToLower(a.Value)]; !ok || a.Via != identity.AddressTypeEmail {
+			continue
+		}
+		if _, ok := known[strings.ToLower(a.Value)]; !ok {
+			added = append(added, strings.ToLower(a.Value))
+		}
+	}
+
+	return stringslice.Unique(added)
+}
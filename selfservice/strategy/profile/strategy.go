@@ -22,6 +22,8 @@ import (
 	"github.com/ory/kratos/selfservice/errorx"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/settings"
+	"github.com/ory/kratos/selfservice/flow/verification"
+	"github.com/ory/kratos/selfservice/strategy/link"
 	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/ui/container"
 	"github.com/ory/kratos/ui/node"
@@ -57,6 +59,12 @@ type (
 		settings.StrategyProvider
 		settings.HooksProvider
 
+		verification.StrategyProvider
+		verification.FlowPersistenceProvider
+
+		link.SenderProvider
+		link.AddressChangePersistenceProvider
+
 		schema.IdentityTraitsProvider
 	}
 	Strategy struct {
@@ -176,6 +184,12 @@ func (s *Strategy) continueFlow(w http.ResponseWriter, r *http.Request, ctxUpdat
 		return err
 	}
 
+	if held, err := s.holdAddressChange(r, ctxUpdate, update, options...); err != nil {
+		return err
+	} else if held {
+		return nil
+	}
+
 	ctxUpdate.UpdateIdentity(update)
 	return nil
 }
@@ -0,0 +1,118 @@
+package totp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/x"
+)
+
+const RouteAdminIdentityCredentials = identity.RouteItemCredentials + "/totp"
+
+var _ identity.CredentialsAdminHandler = new(Strategy)
+var _ identity.CredentialsDescriber = new(Strategy)
+
+func (s *Strategy) RegisterAdminCredentialsRoutes(admin *x.RouterAdmin) {
+	admin.DELETE(RouteAdminIdentityCredentials, s.adminDeleteIdentityCredentials)
+}
+
+type credentialsDescription struct {
+	Digits    int    `json:"digits"`
+	Period    uint   `json:"period"`
+	Algorithm string `json:"algorithm"`
+}
+
+func (s *Strategy) DescribeCredentials(c identity.Credentials) (json.RawMessage, error) {
+	var cc CredentialsConfig
+	if err := json.Unmarshal(c.Config, &cc); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReason("The TOTP credentials could not be decoded properly").WithDebug(err.Error()).WithWrap(err))
+	}
+
+	opts := cc.validateOpts()
+	out, err := json.Marshal(&credentialsDescription{
+		Digits:    opts.Digits.Length(),
+		Period:    opts.Period,
+		Algorithm: strings.ToLower(opts.Algorithm.String()),
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return out, nil
+}
+
+// swagger:parameters adminDeleteIdentityTotpCredentials
+// nolint:deadcode,unused
+type adminDeleteIdentityTotpCredentials struct {
+	// ID is the identity's ID.
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+}
+
+// swagger:route DELETE /admin/identities/{id}/credentials/totp v0alpha2 adminDeleteIdentityTotpCredentials
+//
+// # Remove the TOTP Device of an Identity
+//
+// Removes the identity's TOTP device, for example because the user lost their phone. All sessions of the identity
+// which were authenticated with a second factor, as well as all trusted devices, are revoked.
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  204: emptyResponse
+//	  404: jsonError
+//	  500: jsonError
+func (s *Strategy) adminDeleteIdentityCredentials(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if err := s.deleteIdentityCredentials(r.Context(), x.ParseUUID(ps.ByName("id"))); err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Strategy) deleteIdentityCredentials(ctx context.Context, id uuid.UUID) error {
+	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.CountActiveMultiFactorCredentials(i.Credentials)
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return errors.WithStack(herodot.ErrNotFound.WithReason("The identity has no TOTP device."))
+	}
+
+	i.DeleteCredentialsType(s.ID())
+	if err := s.d.PrivilegedIdentityPool().UpdateIdentity(ctx, i); err != nil {
+		return err
+	}
+
+	// The device might be in the wrong hands, so sessions and devices which it vouched for as a second factor
+	// must no longer be trusted.
+	if _, err := s.d.SessionPersister().RevokeSessionsIdentityAboveAAL(ctx, i.ID, identity.AuthenticatorAssuranceLevel1); err != nil {
+		return err
+	}
+
+	if err := s.d.SessionPersister().DeleteTrustedDevicesByIdentity(ctx, i.ID); err != nil {
+		return err
+	}
+
+	return s.d.SessionManager().RefreshMFAEnrollment(ctx, i)
+}
@@ -0,0 +1,90 @@
+package totp_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/x"
+)
+
+func TestAdminDeleteIdentityCredentials(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/login.schema.json")
+	_, adminTS := testhelpers.NewKratosServer(t, reg)
+
+	remove := func(t *testing.T, id *identity.Identity) *http.Response {
+		req, err := http.NewRequest("DELETE", adminTS.URL+"/admin/identities/"+id.ID.String()+"/credentials/totp", nil)
+		require.NoError(t, err)
+		res, err := adminTS.Client().Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		return res
+	}
+
+	t.Run("case=describes the device", func(t *testing.T) {
+		id, _, _ := createIdentity(t, reg)
+
+		res, err := adminTS.Client().Get(adminTS.URL + "/admin/identities/" + id.ID.String() + "/credentials")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body := x.MustReadAll(res.Body)
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+
+		assert.JSONEq(t, `{"digits":6,"period":30,"algorithm":"sha1"}`, gjson.GetBytes(body, "#(type==totp).metadata").Raw, "%s", body)
+		assert.NotContains(t, string(body), "totp_url")
+	})
+
+	t.Run("case=removes the device and revokes second factor sessions and trusted devices", func(t *testing.T) {
+		id, _, _ := createIdentity(t, reg)
+		newSession := func(aal identity.AuthenticatorAssuranceLevel) *session.Session {
+			sess, err := session.NewActiveSession(ctx, id, conf, time.Now(), identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
+			require.NoError(t, err)
+			if aal == identity.AuthenticatorAssuranceLevel2 {
+				sess.CompletedLoginFor(identity.CredentialsTypeTOTP, aal)
+				sess.SetAuthenticatorAssuranceLevel()
+			}
+			require.NoError(t, reg.SessionPersister().UpsertSession(ctx, sess))
+			return sess
+		}
+		aal1, aal2 := newSession(identity.AuthenticatorAssuranceLevel1), newSession(identity.AuthenticatorAssuranceLevel2)
+		require.NoError(t, reg.SessionPersister().CreateTrustedDevice(ctx, session.NewTrustedDevice(id.ID, "Mozilla/5.0", time.Hour)))
+
+		res := remove(t, id)
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+
+		actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, id.ID)
+		require.NoError(t, err)
+		_, ok := actual.GetCredentials(identity.CredentialsTypeTOTP)
+		assert.False(t, ok)
+		_, ok = actual.GetCredentials(identity.CredentialsTypePassword)
+		assert.True(t, ok)
+
+		actualSession, err := reg.SessionPersister().GetSession(ctx, aal1.ID)
+		require.NoError(t, err)
+		assert.True(t, actualSession.Active)
+
+		actualSession, err = reg.SessionPersister().GetSession(ctx, aal2.ID)
+		require.NoError(t, err)
+		assert.False(t, actualSession.Active)
+
+		devices, err := reg.SessionPersister().ListTrustedDevicesByIdentity(ctx, id.ID)
+		require.NoError(t, err)
+		assert.Empty(t, devices)
+	})
+
+	t.Run("case=fails if the identity has no device", func(t *testing.T) {
+		res := remove(t, createIdentityWithoutTOTP(t, reg))
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
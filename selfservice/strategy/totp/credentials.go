@@ -1,9 +1,101 @@
 package totp
 
+import (
+	"crypto/subtle"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
 // CredentialsConfig is the struct that is being used as part of the identity credentials.
 type CredentialsConfig struct {
 	// TOTPURL is the TOTP URL
 	//
 	// For more details see: https://github.com/google/google-authenticator/wiki/Key-Uri-Format
 	TOTPURL string `json:"totp_url"`
+
+	// Digits is the number of digits of a code. Defaults to six for devices enrolled before this was stored.
+	Digits int `json:"digits,omitempty"`
+
+	// Period is the number of seconds a code is valid for. Defaults to 30.
+	Period uint `json:"period,omitempty"`
+
+	// Algorithm is the hash algorithm (sha1, sha256, or sha512). Defaults to sha1.
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// LastUsedTimeStep is the time step of the last accepted code. Codes of this and earlier time steps are
+	// rejected so that a code can not be replayed.
+	LastUsedTimeStep uint64 `json:"last_used_time_step,omitempty"`
+}
+
+// NewCredentialsConfig returns the credentials for the key, using the parameters encoded in the key URL.
+func NewCredentialsConfig(key *otp.Key) (*CredentialsConfig, error) {
+	u, err := url.Parse(key.URL())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	c := &CredentialsConfig{TOTPURL: key.URL(), Period: uint(key.Period()), Algorithm: "sha1", Digits: int(otp.DigitsSix)}
+	q := u.Query()
+	if digits := q.Get("digits"); digits != "" {
+		if c.Digits, err = strconv.Atoi(digits); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if algorithm := q.Get("algorithm"); algorithm != "" {
+		c.Algorithm = strings.ToLower(algorithm)
+	}
+
+	return c, nil
+}
+
+func (c *CredentialsConfig) validateOpts() totp.ValidateOpts {
+	opts := totp.ValidateOpts{Period: c.Period, Digits: otp.Digits(c.Digits), Algorithm: otp.AlgorithmSHA1}
+	if opts.Period == 0 {
+		opts.Period = 30
+	}
+	if opts.Digits == 0 {
+		opts.Digits = otp.DigitsSix
+	}
+	switch c.Algorithm {
+	case "sha256":
+		opts.Algorithm = otp.AlgorithmSHA256
+	case "sha512":
+		opts.Algorithm = otp.AlgorithmSHA512
+	}
+	return opts
+}
+
+// Validate checks the code against the time steps within skew periods around now and returns the time step the
+// code belongs to. Codes of time steps up to and including LastUsedTimeStep are never accepted.
+func (c *CredentialsConfig) Validate(code string, now time.Time, skew uint) (uint64, bool, error) {
+	key, err := otp.NewKeyFromURL(c.TOTPURL)
+	if err != nil {
+		return 0, false, errors.WithStack(err)
+	}
+
+	opts := c.validateOpts()
+	current := uint64(now.Unix()) / uint64(opts.Period)
+	for i := -int64(skew); i <= int64(skew); i++ {
+		step := int64(current) + i
+		if step < 0 || uint64(step) <= c.LastUsedTimeStep {
+			continue
+		}
+
+		expected, err := totp.GenerateCodeCustom(key.Secret(), time.Unix(step*int64(opts.Period), 0).UTC(), opts)
+		if err != nil {
+			return 0, false, errors.WithStack(err)
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return uint64(step), true, nil
+		}
+	}
+
+	return 0, false, nil
 }
@@ -0,0 +1,92 @@
+package totp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp"
+	stdtotp "github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/selfservice/strategy/totp"
+)
+
+func TestCredentialsConfig(t *testing.T) {
+	now := time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("case=stores the key parameters", func(t *testing.T) {
+		key, err := stdtotp.Generate(stdtotp.GenerateOpts{Issuer: "ory.sh", AccountName: "foo", Digits: otp.DigitsEight, Period: 60, Algorithm: otp.AlgorithmSHA512})
+		require.NoError(t, err)
+
+		c, err := totp.NewCredentialsConfig(key)
+		require.NoError(t, err)
+		assert.Equal(t, 8, c.Digits)
+		assert.EqualValues(t, 60, c.Period)
+		assert.Equal(t, "sha512", c.Algorithm)
+
+		code, err := stdtotp.GenerateCodeCustom(key.Secret(), now, stdtotp.ValidateOpts{Digits: otp.DigitsEight, Period: 60, Algorithm: otp.AlgorithmSHA512})
+		require.NoError(t, err)
+
+		step, ok, err := c.Validate(code, now, 0)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.EqualValues(t, now.Unix()/60, step)
+	})
+
+	t.Run("case=validates credentials without stored parameters", func(t *testing.T) {
+		key, err := stdtotp.Generate(stdtotp.GenerateOpts{Issuer: "ory.sh", AccountName: "foo"})
+		require.NoError(t, err)
+
+		c := &totp.CredentialsConfig{TOTPURL: key.URL()}
+		code, err := stdtotp.GenerateCode(key.Secret(), now)
+		require.NoError(t, err)
+
+		_, ok, err := c.Validate(code, now, 0)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("case=respects the skew", func(t *testing.T) {
+		key, err := stdtotp.Generate(stdtotp.GenerateOpts{Issuer: "ory.sh", AccountName: "foo"})
+		require.NoError(t, err)
+
+		c, err := totp.NewCredentialsConfig(key)
+		require.NoError(t, err)
+		code, err := stdtotp.GenerateCode(key.Secret(), now.Add(-30*time.Second))
+		require.NoError(t, err)
+
+		_, ok, err := c.Validate(code, now, 0)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		_, ok, err = c.Validate(code, now, 1)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("case=rejects codes of used time steps", func(t *testing.T) {
+		key, err := stdtotp.Generate(stdtotp.GenerateOpts{Issuer: "ory.sh", AccountName: "foo"})
+		require.NoError(t, err)
+
+		c, err := totp.NewCredentialsConfig(key)
+		require.NoError(t, err)
+		code, err := stdtotp.GenerateCode(key.Secret(), now)
+		require.NoError(t, err)
+
+		step, ok, err := c.Validate(code, now, 1)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		c.LastUsedTimeStep = step
+		_, ok, err = c.Validate(code, now, 1)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		earlier, err := stdtotp.GenerateCode(key.Secret(), now.Add(-30*time.Second))
+		require.NoError(t, err)
+		_, ok, err = c.Validate(earlier, now, 1)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
@@ -30,8 +30,9 @@ func NewKey(ctx context.Context, accountName string, d interface {
 		Issuer:      d.Config().TOTPIssuer(ctx),
 		AccountName: accountName,
 		SecretSize:  secretSize,
-		Digits:      otp.DigitsSix,
-		Period:      30,
+		Digits:      d.Config().TOTPDigits(ctx),
+		Period:      d.Config().TOTPPeriod(ctx),
+		Algorithm:   d.Config().TOTPAlgorithm(ctx),
 	})
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -28,6 +28,18 @@ func TestGenerator(t *testing.T) {
 	assert.Equal(t, "foobar.com", key.Issuer(), "if issuer is set explicitly it should be the correct value")
 	assert.Equal(t, "foo", key.AccountName())
 
+	assert.EqualValues(t, 30, key.Period())
+
+	require.NoError(t, conf.Set(ctx, config.ViperKeyTOTPDigits, 8))
+	require.NoError(t, conf.Set(ctx, config.ViperKeyTOTPPeriod, 60))
+	require.NoError(t, conf.Set(ctx, config.ViperKeyTOTPAlgorithm, "sha256"))
+
+	custom, err := totp.NewKey(context.Background(), "foo", reg)
+	require.NoError(t, err)
+	assert.EqualValues(t, 60, custom.Period())
+	assert.Contains(t, custom.URL(), "digits=8")
+	assert.Contains(t, custom.URL(), "algorithm=SHA256")
+
 	img, err := totp.KeyToHTMLImage(key)
 	require.NoError(t, err)
 	assert.True(t, strings.HasPrefix(img, "data:image/png;base64,"), "image is a base64 encoded png")
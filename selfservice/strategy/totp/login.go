@@ -3,10 +3,9 @@ package totp
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/pkg/errors"
-	"github.com/pquerna/otp"
-	"github.com/pquerna/otp/totp"
 
 	"github.com/ory/herodot"
 	"github.com/ory/kratos/identity"
@@ -18,6 +17,7 @@ import (
 	"github.com/ory/kratos/ui/node"
 	"github.com/ory/kratos/x"
 	"github.com/ory/x/decoderx"
+	"github.com/ory/x/sqlcon"
 )
 
 func (s *Strategy) RegisterLoginRoutes(r *x.RouterPublic) {
@@ -113,13 +113,24 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReason("The TOTP credentials could not be decoded properly").WithDebug(err.Error()).WithWrap(err))
 	}
 
-	key, err := otp.NewKeyFromURL(o.TOTPURL)
+	step, ok, err := o.Validate(p.TOTPCode, time.Now(), s.d.Config().TOTPSkew(r.Context()))
 	if err != nil {
-		return nil, s.handleLoginError(r, f, errors.WithStack(err))
+		return nil, s.handleLoginError(r, f, err)
+	} else if !ok {
+		return nil, s.handleLoginError(r, f, errors.WithStack(schema.NewTOTPVerifierWrongError("#/")))
+	}
+
+	o.LastUsedTimeStep = step
+	encoded, err := json.Marshal(&o)
+	if err != nil {
+		return nil, s.handleLoginError(r, f, errors.WithStack(herodot.ErrInternalServerError.WithReason("Unable to encode updated TOTP credentials.").WithDebug(err.Error())))
 	}
 
-	if !totp.Validate(p.TOTPCode, key.Secret()) {
+	// The time step is advanced by a conditional update so that concurrent requests can not both accept the code.
+	if err := s.d.PrivilegedIdentityPool().UseTOTPTimeStep(r.Context(), c.ID, step, encoded); errors.Is(err, sqlcon.ErrNoRows) {
 		return nil, s.handleLoginError(r, f, errors.WithStack(schema.NewTOTPVerifierWrongError("#/")))
+	} else if err != nil {
+		return nil, s.handleLoginError(r, f, err)
 	}
 
 	f.Active = s.ID()
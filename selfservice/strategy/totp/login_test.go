@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	_ "embed"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -24,6 +25,7 @@ import (
 	stdtotp "github.com/pquerna/otp/totp"
 
 	"github.com/ory/x/sqlxx"
+	"github.com/ory/x/urlx"
 
 	"github.com/ory/kratos/driver"
 	"github.com/ory/kratos/driver/config"
@@ -32,6 +34,8 @@ import (
 	"github.com/ory/kratos/internal/testhelpers"
 	"github.com/ory/kratos/selfservice/flow/login"
 	"github.com/ory/kratos/selfservice/strategy/totp"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/ui/node"
 	"github.com/ory/kratos/x"
 )
 
@@ -299,11 +303,14 @@ func TestCompleteLogin(t *testing.T) {
 	})
 
 	t.Run("case=should pass when TOTP is supplied correctly", func(t *testing.T) {
-		id, _, key := createIdentity(t, reg)
-		code, err := stdtotp.GenerateCode(key.Secret(), time.Now())
-		require.NoError(t, err)
-		payload := func(v url.Values) {
-			v.Set("totp_code", code)
+		// Each code can only be used once, so every sub test uses its own identity.
+		setup := func(t *testing.T) (*identity.Identity, func(v url.Values)) {
+			id, _, key := createIdentity(t, reg)
+			code, err := stdtotp.GenerateCode(key.Secret(), time.Now())
+			require.NoError(t, err)
+			return id, func(v url.Values) {
+				v.Set("totp_code", code)
+			}
 		}
 
 		startAt := time.Now()
@@ -327,16 +334,19 @@ func TestCompleteLogin(t *testing.T) {
 		}
 
 		t.Run("type=api", func(t *testing.T) {
+			id, payload := setup(t)
 			body, res := doAPIFlow(t, payload, id)
 			check(t, false, body, res)
 		})
 
 		t.Run("type=browser", func(t *testing.T) {
+			id, payload := setup(t)
 			body, res := doBrowserFlow(t, false, payload, id, "")
 			check(t, true, body, res)
 		})
 
 		t.Run("type=browser set return_to", func(t *testing.T) {
+			id, payload := setup(t)
 			returnTo := "https://www.ory.sh"
 			_, res := doBrowserFlow(t, false, payload, id, returnTo)
 			t.Log(res.Request.URL.String())
@@ -344,11 +354,95 @@ func TestCompleteLogin(t *testing.T) {
 		})
 
 		t.Run("type=spa", func(t *testing.T) {
+			id, payload := setup(t)
 			body, res := doBrowserFlow(t, true, payload, id, "")
 			check(t, false, body, res)
 		})
 	})
 
+	t.Run("case=should fail when a TOTP code is replayed", func(t *testing.T) {
+		id, _, key := createIdentity(t, reg)
+		code, err := stdtotp.GenerateCode(key.Secret(), time.Now())
+		require.NoError(t, err)
+		payload := func(v url.Values) {
+			v.Set("totp_code", code)
+		}
+
+		body, _ := doAPIFlow(t, payload, id)
+		assert.True(t, gjson.Get(body, "session.active").Bool(), "%s", body)
+
+		body, res := doAPIFlow(t, payload, id)
+		assert.Contains(t, res.Request.URL.String(), publicTS.URL+login.RouteSubmitFlow)
+		assert.Equal(t, text.NewErrorValidationTOTPVerifierWrong().Text, gjson.Get(body, "ui.messages.0.text").String(), "%s", body)
+	})
+
+	t.Run("case=should skip the second factor on a trusted device", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+session.TrustedDeviceMethod+".enabled", true)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+session.TrustedDeviceMethod+".enabled", false)
+		})
+
+		id, _, key := createIdentity(t, reg)
+		code, err := stdtotp.GenerateCode(key.Secret(), time.Now())
+		require.NoError(t, err)
+
+		browserClient := testhelpers.NewHTTPClientWithIdentitySessionCookie(t, reg, id)
+		f := testhelpers.InitializeLoginFlowViaBrowser(t, browserClient, publicTS, false, false, testhelpers.InitFlowWithAAL(identity.AuthenticatorAssuranceLevel2))
+		nodes, err := json.Marshal(f.Ui.Nodes)
+		require.NoError(t, err)
+		assert.Equal(t, "checkbox", gjson.GetBytes(nodes, "#(attributes.name==trust_device).attributes.type").String(), "%s", nodes)
+
+		values := testhelpers.SDKFormFieldsToURLValues(f.Ui.Nodes)
+		values.Set("method", "totp")
+		values.Set("totp_code", code)
+		values.Set(node.TrustDevice, "true")
+		body, res := testhelpers.LoginMakeRequest(t, false, false, f, browserClient, values.Encode())
+		assert.Contains(t, res.Request.URL.String(), redirTS.URL+"/return-ts")
+		assert.EqualValues(t, identity.AuthenticatorAssuranceLevel2, gjson.Get(body, "authenticator_assurance_level").String(), "%s", body)
+
+		devices, err := reg.SessionPersister().ListTrustedDevicesByIdentity(ctx, id.ID)
+		require.NoError(t, err)
+		require.Len(t, devices, 1)
+
+		// A new session on the same device only completed the first factor.
+		trustedClient := testhelpers.NewHTTPClientWithIdentitySessionCookie(t, reg, id)
+		u := urlx.ParseOrPanic(publicTS.URL)
+		for _, c := range browserClient.Jar.Cookies(u) {
+			if c.Name == session.TrustedDeviceCookieName {
+				trustedClient.Jar.SetCookies(u, []*http.Cookie{c})
+			}
+		}
+
+		res, err = trustedClient.Get(publicTS.URL + login.RouteInitBrowserFlow + "?aal=aal2")
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Contains(t, res.Request.URL.String(), redirTS.URL+"/return-ts")
+
+		t.Run("case=requires the second factor after the device was revoked", func(t *testing.T) {
+			require.NoError(t, reg.SessionPersister().DeleteTrustedDevice(ctx, id.ID, devices[0].ID))
+
+			res, err := trustedClient.Get(publicTS.URL + login.RouteInitBrowserFlow + "?aal=aal2")
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			assert.Contains(t, res.Request.URL.String(), uiTS.URL+"/login-ts")
+		})
+
+		t.Run("case=does not trust the device unless asked to", func(t *testing.T) {
+			id, _, key := createIdentity(t, reg)
+			code, err := stdtotp.GenerateCode(key.Secret(), time.Now())
+			require.NoError(t, err)
+
+			_, res := doBrowserFlow(t, false, func(v url.Values) {
+				v.Set("totp_code", code)
+			}, id, "")
+			assert.Contains(t, res.Request.URL.String(), redirTS.URL+"/return-ts")
+
+			devices, err := reg.SessionPersister().ListTrustedDevicesByIdentity(ctx, id.ID)
+			require.NoError(t, err)
+			assert.Len(t, devices, 0)
+		})
+	})
+
 	t.Run("case=should fail because totp can not handle AAL1", func(t *testing.T) {
 		apiClient := testhelpers.NewDebugClient(t)
 		f := testhelpers.InitializeLoginFlowViaAPI(t, apiClient, publicTS, false)
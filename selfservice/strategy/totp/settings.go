@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/pquerna/otp"
-	"github.com/pquerna/otp/totp"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 
@@ -148,6 +147,7 @@ func (s *Strategy) continueSettingsFlow(
 		i, err = s.continueSettingsFlowRemoveTOTP(w, r, ctxUpdate, p)
 	} else {
 		i, err = s.continueSettingsFlowAddTOTP(w, r, ctxUpdate, p)
+		ctxUpdate.MarkCredentialsAddedOrRotated()
 	}
 
 	if err != nil {
@@ -177,11 +177,21 @@ func (s *Strategy) continueSettingsFlowAddTOTP(w http.ResponseWriter, r *http.Re
 		return nil, schema.NewRequiredError("#/totp_code", "totp_code")
 	}
 
-	if !totp.Validate(p.ValidationTOTP, key.Secret()) {
+	cc, err := NewCredentialsConfig(key)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithTrace(err).WithReasonf("Could not decode TOTP key from the internal context. This is a code bug and should be reported to https://github.com/ory/kratos/."))
+	}
+
+	step, ok, err := cc.Validate(p.ValidationTOTP, time.Now(), s.d.Config().TOTPSkew(r.Context()))
+	if err != nil {
+		return nil, err
+	} else if !ok {
 		return nil, schema.NewTOTPVerifierWrongError("#/totp_code")
 	}
 
-	co, err := json.Marshal(&CredentialsConfig{TOTPURL: key.URL()})
+	// The code used to enroll the device must not be usable for signing in.
+	cc.LastUsedTimeStep = step
+	co, err := json.Marshal(cc)
 	if err != nil {
 		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to encode totp options to JSON: %s", err))
 	}
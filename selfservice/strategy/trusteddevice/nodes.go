@@ -0,0 +1,15 @@
+package trusteddevice
+
+import (
+	"github.com/ory/x/stringsx"
+
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/ui/node"
+)
+
+func NewRevokeTrustedDeviceNode(d *session.TrustedDevice) *node.Node {
+	return node.NewInputField(node.TrustedDeviceRevoke, d.ID.String(), node.TrustedDeviceGroup,
+		node.InputAttributeTypeSubmit).
+		WithMetaLabel(text.NewInfoSelfServiceRevokeTrustedDevice(stringsx.Coalesce(d.UserAgent, "unknown"), d.CreatedAt, d.ExpiresAt))
+}
@@ -0,0 +1,8 @@
+package trusteddevice
+
+import (
+	_ "embed"
+)
+
+//go:embed .schema/settings.schema.json
+var settingsSchema []byte
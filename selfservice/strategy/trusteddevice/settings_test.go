@@ -0,0 +1,168 @@
+package trusteddevice_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/driver"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/kratos/x"
+)
+
+func createIdentity(t *testing.T, reg driver.Registry) *identity.Identity {
+	i := &identity.Identity{Traits: identity.Traits(fmt.Sprintf(`{"subject":"%s@ory.sh"}`, x.NewUUID()))}
+	require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(context.Background(), i))
+	return i
+}
+
+func createTrustedDevice(t *testing.T, reg driver.Registry, id *identity.Identity) *session.TrustedDevice {
+	d := session.NewTrustedDevice(id.ID, "Mozilla/5.0", time.Hour)
+	require.NoError(t, reg.SessionPersister().CreateTrustedDevice(context.Background(), d))
+	return d
+}
+
+func TestCompleteSettings(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+string(identity.CredentialsTypePassword)+".enabled", false)
+	conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+".profile.enabled", false)
+	conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+session.TrustedDeviceMethod+".enabled", true)
+	conf.MustSet(ctx, config.ViperKeySelfServiceSettingsRequiredAAL, "aal1")
+
+	router := x.NewRouterPublic()
+	publicTS, _ := testhelpers.NewKratosServerWithRouters(t, reg, router, x.NewRouterAdmin())
+
+	_ = testhelpers.NewErrorTestServer(t, reg)
+	uiTS := testhelpers.NewSettingsUIFlowEchoServer(t, reg)
+	_ = testhelpers.NewRedirSessionEchoTS(t, reg)
+	loginTS := testhelpers.NewLoginUIFlowEchoServer(t, reg)
+
+	conf.MustSet(ctx, config.ViperKeySelfServiceSettingsPrivilegedAuthenticationAfter, "1m")
+
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/login.schema.json")
+	conf.MustSet(ctx, config.ViperKeySecretsDefault, []string{"not-a-secure-session-key"})
+
+	doAPIFlow := func(t *testing.T, v func(url.Values), id *identity.Identity) (string, *http.Response) {
+		apiClient := testhelpers.NewHTTPClientWithIdentitySessionToken(t, reg, id)
+		f := testhelpers.InitializeSettingsFlowViaAPI(t, apiClient, publicTS)
+		values := testhelpers.SDKFormFieldsToURLValues(f.Ui.Nodes)
+		v(values)
+		payload := testhelpers.EncodeFormAsJSON(t, true, values)
+		return testhelpers.SettingsMakeRequest(t, true, false, f, apiClient, payload)
+	}
+
+	doBrowserFlow := func(t *testing.T, spa bool, v func(url.Values), id *identity.Identity) (string, *http.Response) {
+		browserClient := testhelpers.NewHTTPClientWithIdentitySessionCookie(t, reg, id)
+		f := testhelpers.InitializeSettingsFlowViaBrowser(t, browserClient, spa, publicTS)
+		values := testhelpers.SDKFormFieldsToURLValues(f.Ui.Nodes)
+		v(values)
+		return testhelpers.SettingsMakeRequest(t, false, spa, f, browserClient, testhelpers.EncodeFormAsJSON(t, spa, values))
+	}
+
+	listDevices := func(t *testing.T, id *identity.Identity) []session.TrustedDevice {
+		devices, err := reg.SessionPersister().ListTrustedDevicesByIdentity(ctx, id.ID)
+		require.NoError(t, err)
+		return devices
+	}
+
+	t.Run("case=shows a revoke button for every trusted device", func(t *testing.T) {
+		id := createIdentity(t, reg)
+		first, second := createTrustedDevice(t, reg, id), createTrustedDevice(t, reg, id)
+
+		browserClient := testhelpers.NewHTTPClientWithIdentitySessionCookie(t, reg, id)
+		f := testhelpers.InitializeSettingsFlowViaBrowser(t, browserClient, true, publicTS)
+		raw, err := json.Marshal(f.Ui.Nodes)
+		require.NoError(t, err)
+
+		values := gjson.GetBytes(raw, `#(group=="trusted_device")#.attributes.value`).Array()
+		require.Len(t, values, 2, "%s", raw)
+		assert.ElementsMatch(t, []string{first.ID.String(), second.ID.String()}, []string{values[0].String(), values[1].String()})
+		assert.Equal(t, node.TrustedDeviceRevoke, gjson.GetBytes(raw, `#(group=="trusted_device").attributes.name`).String())
+	})
+
+	t.Run("case=shows no buttons without trusted devices", func(t *testing.T) {
+		id := createIdentity(t, reg)
+
+		browserClient := testhelpers.NewHTTPClientWithIdentitySessionCookie(t, reg, id)
+		f := testhelpers.InitializeSettingsFlowViaBrowser(t, browserClient, true, publicTS)
+		raw, err := json.Marshal(f.Ui.Nodes)
+		require.NoError(t, err)
+		assert.Empty(t, gjson.GetBytes(raw, `#(group=="trusted_device")#`).Array(), "%s", raw)
+	})
+
+	t.Run("case=should revoke a trusted device", func(t *testing.T) {
+		for _, tc := range []struct {
+			d string
+			f func(t *testing.T, v func(url.Values), id *identity.Identity) (string, *http.Response)
+		}{
+			{d: "api", f: doAPIFlow},
+			{d: "spa", f: func(t *testing.T, v func(url.Values), id *identity.Identity) (string, *http.Response) {
+				return doBrowserFlow(t, true, v, id)
+			}},
+			{d: "browser", f: func(t *testing.T, v func(url.Values), id *identity.Identity) (string, *http.Response) {
+				return doBrowserFlow(t, false, v, id)
+			}},
+		} {
+			t.Run("type="+tc.d, func(t *testing.T) {
+				id := createIdentity(t, reg)
+				revoked, kept := createTrustedDevice(t, reg, id), createTrustedDevice(t, reg, id)
+
+				actual, res := tc.f(t, func(v url.Values) {
+					v.Set(node.TrustedDeviceRevoke, revoked.ID.String())
+				}, id)
+				assert.Equal(t, http.StatusOK, res.StatusCode, "%s", actual)
+				if tc.d == "browser" {
+					assert.Contains(t, res.Request.URL.String(), uiTS.URL)
+				}
+				assert.EqualValues(t, "success", gjson.Get(actual, "state").String(), "%s", actual)
+
+				devices := listDevices(t, id)
+				require.Len(t, devices, 1)
+				assert.Equal(t, kept.ID, devices[0].ID)
+			})
+		}
+	})
+
+	t.Run("case=can not revoke the device of another identity", func(t *testing.T) {
+		id, other := createIdentity(t, reg), createIdentity(t, reg)
+		d := createTrustedDevice(t, reg, other)
+
+		actual, res := doAPIFlow(t, func(v url.Values) {
+			v.Set(node.TrustedDeviceRevoke, d.ID.String())
+		}, id)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", actual)
+		assert.Contains(t, gjson.Get(actual, "ui.messages.0.text").String(), "You tried to revoke a trusted device which does not exist.", "%s", actual)
+		assert.Len(t, listDevices(t, other), 1)
+	})
+
+	t.Run("case=can not revoke without privileged session", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceSettingsPrivilegedAuthenticationAfter, "1ns")
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceSettingsPrivilegedAuthenticationAfter, "1m")
+		})
+
+		id := createIdentity(t, reg)
+		d := createTrustedDevice(t, reg, id)
+
+		actual, res := doBrowserFlow(t, false, func(v url.Values) {
+			v.Set(node.TrustedDeviceRevoke, d.ID.String())
+		}, id)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Contains(t, res.Request.URL.String(), loginTS.URL+"/login-ts", "%s", actual)
+		assert.Len(t, listDevices(t, id), 1)
+	})
+}
@@ -0,0 +1,176 @@
+package webauthn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/x"
+)
+
+const RouteAdminIdentityCredential = identity.RouteItemCredentials + "/webauthn/:credential"
+
+var _ identity.CredentialsAdminHandler = new(Strategy)
+var _ identity.CredentialsDescriber = new(Strategy)
+
+func (s *Strategy) RegisterAdminCredentialsRoutes(admin *x.RouterAdmin) {
+	admin.DELETE(RouteAdminIdentityCredential, s.adminDeleteIdentityCredential)
+}
+
+type credentialsDescription struct {
+	Credentials []credentialDescription `json:"credentials"`
+}
+
+type credentialDescription struct {
+	ID             string    `json:"id"`
+	DisplayName    string    `json:"display_name"`
+	AddedAt        time.Time `json:"added_at"`
+	IsPasswordless bool      `json:"is_passwordless"`
+	AAGUID         string    `json:"aaguid,omitempty"`
+}
+
+func (s *Strategy) DescribeCredentials(c identity.Credentials) (json.RawMessage, error) {
+	var cc CredentialsConfig
+	if err := json.Unmarshal(c.Config, &cc); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReason("The WebAuthn credentials could not be decoded properly").WithDebug(err.Error()).WithWrap(err))
+	}
+
+	d := credentialsDescription{Credentials: make([]credentialDescription, len(cc.Credentials))}
+	for k, cred := range cc.Credentials {
+		d.Credentials[k] = credentialDescription{
+			ID:             fmt.Sprintf("%x", cred.ID),
+			DisplayName:    cred.DisplayName,
+			AddedAt:        cred.AddedAt,
+			IsPasswordless: cred.IsPasswordless,
+		}
+		if aaguid, err := uuid.FromBytes(cred.Authenticator.AAGUID); err == nil {
+			d.Credentials[k].AAGUID = aaguid.String()
+		}
+	}
+
+	out, err := json.Marshal(d)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return out, nil
+}
+
+// swagger:parameters adminDeleteIdentityWebAuthnCredential
+// nolint:deadcode,unused
+type adminDeleteIdentityWebAuthnCredential struct {
+	// ID is the identity's ID.
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+
+	// Credential is the ID of the security key as listed by `adminListIdentityCredentials`.
+	//
+	// required: true
+	// in: path
+	Credential string `json:"credential"`
+}
+
+// swagger:route DELETE /admin/identities/{id}/credentials/webauthn/{credential} v0alpha2 adminDeleteIdentityWebAuthnCredential
+//
+// # Remove a Security Key of an Identity
+//
+// Removes a single WebAuthn security key, for example because the user lost it. A security key which is used for
+// passwordless sign in can only be removed if the identity has another way of signing in. All trusted devices and all
+// sessions which were authenticated with a second factor are revoked. If a passwordless key is removed, all sessions
+// are revoked.
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  204: emptyResponse
+//	  400: jsonError
+//	  404: jsonError
+//	  500: jsonError
+func (s *Strategy) adminDeleteIdentityCredential(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if err := s.deleteIdentityCredential(r.Context(), x.ParseUUID(ps.ByName("id")), ps.ByName("credential")); err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Strategy) deleteIdentityCredential(ctx context.Context, id uuid.UUID, credentialID string) error {
+	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var cc CredentialsConfig
+	creds, err := i.ParseCredentials(s.ID(), &cc)
+	if err != nil {
+		return err
+	}
+
+	var found, wasPasswordless bool
+	updated := make(Credentials, 0, len(cc.Credentials))
+	for k, cred := range cc.Credentials {
+		if fmt.Sprintf("%x", cred.ID) == credentialID {
+			found, wasPasswordless = true, cred.IsPasswordless
+			continue
+		}
+		updated = append(updated, cc.Credentials[k])
+	}
+
+	if !found {
+		return errors.WithStack(herodot.ErrNotFound.WithReasonf("The identity has no WebAuthn credential with ID %s.", credentialID))
+	}
+
+	if wasPasswordless {
+		count, err := s.d.IdentityManager().CountActiveFirstFactorCredentials(ctx, i)
+		if err != nil {
+			return err
+		}
+
+		if count < 2 {
+			return errors.WithStack(herodot.ErrBadRequest.WithReason("Unable to remove this security key because the identity would not be able to sign in anymore."))
+		}
+	}
+
+	if len(updated) == 0 {
+		i.DeleteCredentialsType(s.ID())
+	} else {
+		cc.Credentials = updated
+		if err := i.SetCredentialsWithConfig(s.ID(), *creds, cc); err != nil {
+			return err
+		}
+	}
+
+	if err := s.d.PrivilegedIdentityPool().UpdateIdentity(ctx, i); err != nil {
+		return err
+	}
+
+	// The security key might be in the wrong hands, so sessions and devices which it vouched for must no longer be
+	// trusted. Passwordless keys are a first factor, so all sessions end in that case.
+	if wasPasswordless {
+		if _, err := s.d.SessionPersister().RevokeSessionsIdentityExcept(ctx, i.ID, uuid.Nil); err != nil {
+			return err
+		}
+	} else if _, err := s.d.SessionPersister().RevokeSessionsIdentityAboveAAL(ctx, i.ID, identity.AuthenticatorAssuranceLevel1); err != nil {
+		return err
+	}
+
+	if err := s.d.SessionPersister().DeleteTrustedDevicesByIdentity(ctx, i.ID); err != nil {
+		return err
+	}
+
+	return s.d.SessionManager().RefreshMFAEnrollment(ctx, i)
+}
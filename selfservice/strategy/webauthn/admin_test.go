@@ -0,0 +1,137 @@
+package webauthn_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/x"
+)
+
+func TestAdminDeleteIdentityCredential(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	enableWebAuthn(conf)
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/login.schema.json")
+	_, adminTS := testhelpers.NewKratosServer(t, reg)
+
+	createIdentity := func(t *testing.T, withPassword bool) (*identity.Identity, *session.Session) {
+		i := identity.NewIdentity("")
+		i.Traits = identity.Traits(`{"subject":"` + x.NewUUID().String() + `@ory.sh"}`)
+		i.SetCredentials(identity.CredentialsTypeWebAuthn, identity.Credentials{
+			Type:        identity.CredentialsTypeWebAuthn,
+			Identifiers: []string{x.NewUUID().String()},
+			Config:      []byte(`{"credentials":[{"id":"Zm9vZm9v","display_name":"foo","is_passwordless":true},{"id":"YmFyYmFy","display_name":"bar","is_passwordless":false}]}`),
+		})
+		if withPassword {
+			i.SetCredentials(identity.CredentialsTypePassword, identity.Credentials{
+				Type:        identity.CredentialsTypePassword,
+				Identifiers: []string{x.NewUUID().String()},
+				Config:      []byte(`{"hashed_password":"$2a$08$.cOYmAd.vCpDOoiVJrO5B.hjTLKQQ6cAK40u8uB.FnZDyPvVvQ9Q."}`),
+			})
+		}
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+
+		sess, err := session.NewActiveSession(ctx, i, conf, time.Now(), identity.CredentialsTypeWebAuthn, identity.AuthenticatorAssuranceLevel1)
+		require.NoError(t, err)
+		require.NoError(t, reg.SessionPersister().UpsertSession(ctx, sess))
+		return i, sess
+	}
+
+	remove := func(t *testing.T, id fmt.Stringer, credential []byte) *http.Response {
+		req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/admin/identities/%s/credentials/webauthn/%x", adminTS.URL, id, credential), nil)
+		require.NoError(t, err)
+		res, err := adminTS.Client().Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		return res
+	}
+
+	t.Run("case=lists the security keys", func(t *testing.T) {
+		i, _ := createIdentity(t, true)
+
+		res, err := adminTS.Client().Get(adminTS.URL + "/admin/identities/" + i.ID.String() + "/credentials")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body := x.MustReadAll(res.Body)
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+
+		assert.Equal(t, fmt.Sprintf("%x", "foofoo"), gjson.GetBytes(body, "#(type==webauthn).metadata.credentials.0.id").String(), "%s", body)
+		assert.Equal(t, "bar", gjson.GetBytes(body, "#(type==webauthn).metadata.credentials.1.display_name").String(), "%s", body)
+		assert.False(t, gjson.GetBytes(body, "#(type==webauthn).metadata.credentials.1.is_passwordless").Bool(), "%s", body)
+		assert.False(t, gjson.GetBytes(body, "#(type==webauthn).metadata.credentials.0.public_key").Exists(), "%s", body)
+		assert.False(t, gjson.GetBytes(body, "#(type==password).metadata").Exists(), "%s", body)
+	})
+
+	t.Run("case=removes a security key and revokes second factor sessions and trusted devices", func(t *testing.T) {
+		i, sess := createIdentity(t, false)
+		aal2, err := session.NewActiveSession(ctx, i, conf, time.Now(), identity.CredentialsTypeWebAuthn, identity.AuthenticatorAssuranceLevel1)
+		require.NoError(t, err)
+		aal2.CompletedLoginFor(identity.CredentialsTypeWebAuthn, identity.AuthenticatorAssuranceLevel2)
+		aal2.SetAuthenticatorAssuranceLevel()
+		require.Equal(t, identity.AuthenticatorAssuranceLevel2, aal2.AuthenticatorAssuranceLevel)
+		require.NoError(t, reg.SessionPersister().UpsertSession(ctx, aal2))
+		require.NoError(t, reg.SessionPersister().CreateTrustedDevice(ctx, session.NewTrustedDevice(i.ID, "Mozilla/5.0", time.Hour)))
+
+		res := remove(t, i.ID, []byte("barbar"))
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+
+		actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+		require.NoError(t, err)
+		c, ok := actual.GetCredentials(identity.CredentialsTypeWebAuthn)
+		require.True(t, ok)
+		assert.Len(t, gjson.GetBytes(c.Config, "credentials").Array(), 1)
+		assert.Equal(t, "foo", gjson.GetBytes(c.Config, "credentials.0.display_name").String())
+
+		actualSession, err := reg.SessionPersister().GetSession(ctx, sess.ID)
+		require.NoError(t, err)
+		assert.True(t, actualSession.Active)
+
+		actualSession, err = reg.SessionPersister().GetSession(ctx, aal2.ID)
+		require.NoError(t, err)
+		assert.False(t, actualSession.Active)
+
+		devices, err := reg.SessionPersister().ListTrustedDevicesByIdentity(ctx, i.ID)
+		require.NoError(t, err)
+		assert.Empty(t, devices)
+	})
+
+	t.Run("case=refuses to remove the last passwordless credential", func(t *testing.T) {
+		i, sess := createIdentity(t, false)
+
+		res := remove(t, i.ID, []byte("foofoo"))
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		actualSession, err := reg.SessionPersister().GetSession(ctx, sess.ID)
+		require.NoError(t, err)
+		assert.True(t, actualSession.Active)
+	})
+
+	t.Run("case=removes a passwordless credential if another first factor exists", func(t *testing.T) {
+		i, sess := createIdentity(t, true)
+
+		res := remove(t, i.ID, []byte("foofoo"))
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+
+		actualSession, err := reg.SessionPersister().GetSession(ctx, sess.ID)
+		require.NoError(t, err)
+		assert.False(t, actualSession.Active)
+	})
+
+	t.Run("case=fails for an unknown security key", func(t *testing.T) {
+		i, _ := createIdentity(t, true)
+
+		res := remove(t, i.ID, []byte("unknown"))
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
@@ -9,3 +9,4 @@ import (
 var ErrNotEnoughCredentials = &jsonschema.ValidationError{
 	Message: "unable to remove this security key because it would lock you out of your account", InstancePtr: "#/webauthn_remove"}
 var ErrNoCredentials = errors.New("required credentials not found")
+var ErrOriginNotAllowed = errors.New("the origin of the WebAuthn response is not allowed")
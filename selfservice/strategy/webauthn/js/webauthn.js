@@ -24,18 +24,35 @@
       .replace(/=/g, '');
   }
 
-  function __oryWebAuthnLogin(opt, resultQuerySelector = '*[name="webauthn_login"]', triggerQuerySelector = '*[name="webauthn_login_trigger"]') {
+  // Holds the abort controller of a pending conditional (autofill) login which must be cancelled
+  // before another login can be started.
+  var __oryWebAuthnConditionalLogin = null
+
+  function __oryWebAuthnLogin(opt, resultQuerySelector = '*[name="webauthn_login"]', triggerQuerySelector = '*[name="webauthn_login_trigger"]', mediation = undefined) {
     if (!window.PublicKeyCredential) {
       alert('This browser does not support WebAuthn!');
     }
 
     opt.publicKey.challenge = __oryWebAuthnBufferDecode(opt.publicKey.challenge);
-    opt.publicKey.allowCredentials = opt.publicKey.allowCredentials.map(function (value) {
-      return {
-        ...value,
-        id: __oryWebAuthnBufferDecode(value.id)
-      }
-    });
+    if (opt.publicKey.allowCredentials) {
+      opt.publicKey.allowCredentials = opt.publicKey.allowCredentials.map(function (value) {
+        return {
+          ...value,
+          id: __oryWebAuthnBufferDecode(value.id)
+        }
+      });
+    }
+
+    if (__oryWebAuthnConditionalLogin) {
+      __oryWebAuthnConditionalLogin.abort()
+      __oryWebAuthnConditionalLogin = null
+    }
+
+    if (mediation) {
+      __oryWebAuthnConditionalLogin = new AbortController()
+      opt.mediation = mediation
+      opt.signal = __oryWebAuthnConditionalLogin.signal
+    }
 
     navigator.credentials.get(opt).then(function (credential) {
       document.querySelector(resultQuerySelector).value = JSON.stringify({
@@ -52,10 +69,32 @@
 
       document.querySelector(triggerQuerySelector).closest('form').submit()
     }).catch((err) => {
+      if (mediation && err.name === 'AbortError') {
+        return
+      }
       alert(err)
     })
   }
 
+  // Offers discoverable credentials (passkeys) in the browser's autofill UI of inputs with
+  // autocomplete="username webauthn" if the browser supports conditional mediation.
+  function __oryWebAuthnLoginAutocomplete(optionsQuerySelector = '*[name="webauthn_login_options"]') {
+    if (!window.PublicKeyCredential || !PublicKeyCredential.isConditionalMediationAvailable) {
+      return
+    }
+
+    const options = document.querySelector(optionsQuerySelector)
+    if (!options || !options.value) {
+      return
+    }
+
+    PublicKeyCredential.isConditionalMediationAvailable().then(function (available) {
+      if (available) {
+        __oryWebAuthnLogin(JSON.parse(options.value), undefined, undefined, 'conditional')
+      }
+    })
+  }
+
   function __oryWebAuthnRegistration(opt, resultQuerySelector = '*[name="webauthn_register"]', triggerQuerySelector = '*[name="webauthn_register_trigger"]') {
     if (!window.PublicKeyCredential) {
       alert('This browser does not support WebAuthn!');
@@ -92,5 +131,14 @@
 
   window['__oryWebAuthnLogin'] = __oryWebAuthnLogin
   window['__oryWebAuthnRegistration'] = __oryWebAuthnRegistration
+  window['__oryWebAuthnLoginAutocomplete'] = __oryWebAuthnLoginAutocomplete
   window['__oryWebAuthnInitialized'] = true
+
+  if (document.readyState === 'loading') {
+    document.addEventListener('DOMContentLoaded', function () {
+      __oryWebAuthnLoginAutocomplete()
+    })
+  } else {
+    __oryWebAuthnLoginAutocomplete()
+  }
 })()
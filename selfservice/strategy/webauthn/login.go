@@ -1,6 +1,7 @@
 package webauthn
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"strings"
@@ -89,8 +90,53 @@ func (s *Strategy) populateLoginMethodForPasswordless(r *http.Request, sr *login
 	}
 
 	sr.UI.SetCSRF(s.d.GenerateCSRFToken(r))
-	sr.UI.SetNode(node.NewInputField("identifier", "", node.DefaultGroup, node.InputAttributeTypeText, node.WithRequiredInputAttribute).WithMetaLabel(text.NewInfoNodeLabelID()))
+	sr.UI.SetNode(node.NewInputField("identifier", "", node.DefaultGroup, node.InputAttributeTypeText, node.WithRequiredInputAttribute, node.WithInputAttributes(func(a *node.InputAttributes) {
+		a.Autocomplete = node.InputAttributeAutocompleteUsernameWebAuthn
+	})).WithMetaLabel(text.NewInfoNodeLabelID()))
 	sr.UI.GetNodes().Append(node.NewInputField("method", "webauthn", node.WebAuthnGroup, node.InputAttributeTypeSubmit).WithMetaLabel(text.NewInfoLoginPasswordlessWebAuthn()))
+	return s.populateDiscoverableLoginMethod(r, sr)
+}
+
+// populateDiscoverableLoginMethod starts an assertion without an allow-list. This lets the browser offer every
+// discoverable credential (passkey) it holds for the relying party, either when the trigger is clicked or in the
+// autofill UI of the identifier field. The identity is resolved from the user handle once the assertion is submitted.
+func (s *Strategy) populateDiscoverableLoginMethod(r *http.Request, sr *login.Flow) error {
+	web, err := s.newWebAuthn(r)
+	if err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to initiate WebAuth.").WithDebug(err.Error()))
+	}
+
+	challenge, err := protocol.CreateChallenge()
+	if err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to initiate WebAuth login.").WithDebug(err.Error()))
+	}
+
+	options := protocol.CredentialAssertion{Response: protocol.PublicKeyCredentialRequestOptions{
+		Challenge:        challenge,
+		Timeout:          web.Config.Timeout,
+		RelyingPartyID:   web.Config.RPID,
+		UserVerification: web.Config.AuthenticatorSelection.UserVerification,
+	}}
+
+	sr.InternalContext, err = sjson.SetBytes(sr.InternalContext, flow.PrefixInternalContextKey(s.ID(), InternalContextKeySessionData), &webauthn.SessionData{
+		Challenge:        base64.RawURLEncoding.EncodeToString(challenge),
+		UserVerification: options.Response.UserVerification,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	injectWebAuthnOptions, err := json.Marshal(options)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	sr.UI.Nodes.Upsert(NewWebAuthnScript(urlx.AppendPaths(s.d.Config().SelfPublicURL(r.Context()), webAuthnRoute).String(), jsOnLoad))
+	sr.UI.SetNode(NewWebAuthnLoginTrigger(string(injectWebAuthnOptions)).
+		WithMetaLabel(text.NewInfoSelfServiceLoginWebAuthn()))
+	sr.UI.Nodes.Upsert(NewWebAuthnLoginInput())
+	sr.UI.Nodes.Upsert(NewWebAuthnLoginOptions(string(injectWebAuthnOptions)))
+
 	return nil
 }
 
@@ -121,7 +167,7 @@ func (s *Strategy) populateLoginMethod(r *http.Request, sr *login.Flow, i *ident
 		return ErrNoCredentials
 	}
 
-	web, err := s.newWebAuthn(r.Context())
+	web, err := s.newWebAuthn(r)
 	if err != nil {
 		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to initiate WebAuth.").WithDebug(err.Error()))
 	}
@@ -233,6 +279,10 @@ func (s *Strategy) loginPasswordless(w http.ResponseWriter, r *http.Request, f *
 		return nil, s.handleLoginError(r, f, err)
 	}
 
+	if len(p.Login) > 0 && s.isDiscoverableLogin(f) {
+		return s.loginDiscoverable(w, r, f, p)
+	}
+
 	if p.Identifier == "" {
 		return nil, s.handleLoginError(r, f, errors.WithStack(herodot.ErrBadRequest.WithReason("identifier is required")))
 	}
@@ -277,6 +327,27 @@ func (s *Strategy) loginPasswordless(w http.ResponseWriter, r *http.Request, f *
 	return s.loginAuthenticate(w, r, f, i.ID, p, identity.AuthenticatorAssuranceLevel1)
 }
 
+// isDiscoverableLogin returns true if the flow's assertion was started without knowing the user.
+func (s *Strategy) isDiscoverableLogin(f *login.Flow) bool {
+	sess := gjson.GetBytes(f.InternalContext, flow.PrefixInternalContextKey(s.ID(), InternalContextKeySessionData))
+	return sess.IsObject() && len(sess.Get("user_id").String()) == 0
+}
+
+func (s *Strategy) loginDiscoverable(w http.ResponseWriter, r *http.Request, f *login.Flow, p *submitSelfServiceLoginFlowWithWebAuthnMethodBody) (*identity.Identity, error) {
+	webAuthnResponse, err := protocol.ParseCredentialRequestResponseBody(strings.NewReader(p.Login))
+	if err != nil {
+		return nil, s.handleLoginError(r, f, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to parse WebAuthn response.").WithDebug(err.Error())))
+	}
+
+	i, err := s.d.PrivilegedIdentityPool().FindByWebAuthnUserHandle(r.Context(), webAuthnResponse.Response.UserHandle)
+	if err != nil {
+		time.Sleep(x.RandomDelay(s.d.Config().HasherArgon2(r.Context()).ExpectedDuration, s.d.Config().HasherArgon2(r.Context()).ExpectedDeviation))
+		return nil, s.handleLoginError(r, f, errors.WithStack(schema.NewNoWebAuthnCredentials()))
+	}
+
+	return s.loginAuthenticate(w, r, f, i.ID, p, identity.AuthenticatorAssuranceLevel1)
+}
+
 func (s *Strategy) loginAuthenticate(_ http.ResponseWriter, r *http.Request, f *login.Flow, identityID uuid.UUID, p *submitSelfServiceLoginFlowWithWebAuthnMethodBody, aal identity.AuthenticatorAssuranceLevel) (*identity.Identity, error) {
 	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(r.Context(), identityID)
 	if err != nil {
@@ -293,16 +364,18 @@ func (s *Strategy) loginAuthenticate(_ http.ResponseWriter, r *http.Request, f *
 		return nil, s.handleLoginError(r, f, errors.WithStack(herodot.ErrInternalServerError.WithReason("The WebAuthn credentials could not be decoded properly").WithDebug(err.Error()).WithWrap(err)))
 	}
 
-	web, err := s.newWebAuthn(r.Context())
-	if err != nil {
-		return nil, s.handleLoginError(r, f, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to get webAuthn config.").WithDebug(err.Error())))
-	}
-
 	webAuthnResponse, err := protocol.ParseCredentialRequestResponseBody(strings.NewReader(p.Login))
 	if err != nil {
 		return nil, s.handleLoginError(r, f, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to parse WebAuthn response.").WithDebug(err.Error())))
 	}
 
+	web, err := s.newWebAuthnForOrigin(r, webAuthnResponse.Response.CollectedClientData.Origin)
+	if errors.Is(err, ErrOriginNotAllowed) {
+		return nil, s.handleLoginError(r, f, errors.WithStack(schema.NewWebAuthnVerifierWrongError("#/")))
+	} else if err != nil {
+		return nil, s.handleLoginError(r, f, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to get webAuthn config.").WithDebug(err.Error())))
+	}
+
 	var webAuthnSess webauthn.SessionData
 	if err := json.Unmarshal([]byte(gjson.GetBytes(f.InternalContext, flow.PrefixInternalContextKey(s.ID(), InternalContextKeySessionData)).Raw), &webAuthnSess); err != nil {
 		return nil, s.handleLoginError(r, f, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Expected WebAuthN in internal context to be an object but got: %s", err)))
@@ -313,6 +386,12 @@ func (s *Strategy) loginAuthenticate(_ http.ResponseWriter, r *http.Request, f *
 		webAuthCreds = o.Credentials.ToWebAuthn()
 	}
 
+	if len(webAuthnSess.UserID) == 0 {
+		// Discoverable logins do not know the user up front. The identity was resolved from the user handle,
+		// which ValidateLogin checks against the identity's credentials.
+		webAuthnSess.UserID = o.UserHandle
+	}
+
 	if _, err := web.ValidateLogin(&wrappedUser{id: o.UserHandle, c: webAuthCreds}, webAuthnSess, webAuthnResponse); err != nil {
 		return nil, s.handleLoginError(r, f, errors.WithStack(schema.NewWebAuthnVerifierWrongError("#/")))
 	}
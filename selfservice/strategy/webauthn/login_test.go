@@ -25,6 +25,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
@@ -287,7 +288,7 @@ func TestCompleteLogin(t *testing.T) {
 		t.Run("case=webauthn button exists", func(t *testing.T) {
 			client := testhelpers.NewClientWithCookies(t)
 			f := testhelpers.InitializeLoginFlowViaBrowser(t, client, publicTS, false, true)
-			testhelpers.SnapshotTExcept(t, f.Ui.Nodes, []string{"0.attributes.value"})
+			testhelpers.SnapshotTExcept(t, f.Ui.Nodes, []string{"0.attributes.value", "2.attributes.onclick", "4.attributes.nonce", "4.attributes.src", "5.attributes.value"})
 		})
 
 		t.Run("case=webauthn shows error if user tries to sign in but no such user exists", func(t *testing.T) {
@@ -452,6 +453,124 @@ func TestCompleteLogin(t *testing.T) {
 				run(t, true)
 			})
 		})
+
+		t.Run("case=respects the allowed origins", func(t *testing.T) {
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeyWebAuthnRPOrigins, nil)
+			})
+
+			for _, tc := range []struct {
+				d       string
+				origins []string
+				success bool
+			}{
+				{d: "origin is one of several", origins: []string{"https://app.example.com", "http://localhost:4455"}, success: true},
+				{d: "origin is not allowed", origins: []string{"https://app.example.com", "android:apk-key-hash:ABCDEF"}},
+			} {
+				t.Run("case="+tc.d, func(t *testing.T) {
+					conf.MustSet(ctx, config.ViperKeyWebAuthnRPOrigins, tc.origins)
+
+					id := createIdentityWithWebAuthn(t, identity.Credentials{
+						Config:  loginFixtureSuccessV1PasswordlessCredentials,
+						Version: 1,
+					})
+
+					body, _, _ := submitWebAuthnLoginWithClient(t, false, id, loginFixtureSuccessV1PasswordlessContext, testhelpers.NewClientWithCookies(t), func(values url.Values) {
+						values.Set("identifier", loginFixtureSuccessEmail)
+						values.Set(node.WebAuthnLogin, string(loginFixtureSuccessV1PasswordlessResponse))
+					}, testhelpers.InitFlowWithAAL(identity.AuthenticatorAssuranceLevel1))
+
+					if tc.success {
+						assert.True(t, gjson.Get(body, "active").Bool(), "%s", body)
+						assert.EqualValues(t, id.ID.String(), gjson.Get(body, "identity.id").String(), "%s", body)
+					} else {
+						assert.False(t, gjson.Get(body, "active").Bool(), "%s", body)
+						assert.Equal(t, text.NewErrorValidationTOTPVerifierWrong().Text, gjson.Get(body, "ui.messages.0.text").String(), "%s", body)
+					}
+				})
+			}
+		})
+
+		t.Run("case=starts a discoverable login", func(t *testing.T) {
+			client := testhelpers.NewClientWithCookies(t)
+			f := testhelpers.InitializeLoginFlowViaBrowser(t, client, publicTS, false, true)
+			nodes, err := json.Marshal(f.Ui.Nodes)
+			require.NoError(t, err)
+
+			options := gjson.GetBytes(nodes, "#(attributes.name==webauthn_login_options).attributes.value").String()
+			assert.NotEmpty(t, gjson.Get(options, "publicKey.challenge").String(), "%s", nodes)
+			assert.False(t, gjson.Get(options, "publicKey.allowCredentials").Exists(), "%s", nodes)
+			assert.Contains(t, gjson.GetBytes(nodes, "#(attributes.name==webauthn_login_trigger).attributes.onclick").String(), options, "%s", nodes)
+
+			actualFlow, err := reg.LoginFlowPersister().GetLoginFlow(context.Background(), uuid.FromStringOrNil(f.Id))
+			require.NoError(t, err)
+			sess := gjson.GetBytes(actualFlow.InternalContext, flow.PrefixInternalContextKey(identity.CredentialsTypeWebAuthn, webauthn.InternalContextKeySessionData))
+			assert.NotEmpty(t, sess.Get("challenge").String(), "%s", actualFlow.InternalContext)
+			assert.Empty(t, sess.Get("user_id").String(), "%s", actualFlow.InternalContext)
+		})
+
+		// The discoverable session does not know the user and the response carries the user handle instead.
+		discoverableContext, err := sjson.DeleteBytes(loginFixtureSuccessV1PasswordlessContext, "webauthn_session_data.user_id")
+		require.NoError(t, err)
+		discoverableContext, err = sjson.DeleteBytes(discoverableContext, "webauthn_session_data.allowed_credentials")
+		require.NoError(t, err)
+		discoverableResponse, err := sjson.SetBytes(loginFixtureSuccessV1PasswordlessResponse, "response.userHandle", "9dG2o6S7RPeRYfT4d-_prQ")
+		require.NoError(t, err)
+
+		t.Run("case=succeeds with discoverable passwordless login", func(t *testing.T) {
+			run := func(t *testing.T, spa bool) {
+				id := createIdentityWithWebAuthn(t, identity.Credentials{
+					Config:  loginFixtureSuccessV1PasswordlessCredentials,
+					Version: 1,
+				})
+
+				browserClient := testhelpers.NewClientWithCookies(t)
+				body, res, _ := submitWebAuthnLoginWithClient(t, spa, id, discoverableContext, browserClient, func(values url.Values) {
+					values.Del("identifier")
+					values.Set(node.WebAuthnLogin, string(discoverableResponse))
+				}, testhelpers.InitFlowWithAAL(identity.AuthenticatorAssuranceLevel1))
+
+				prefix := ""
+				if spa {
+					assert.Contains(t, res.Request.URL.String(), publicTS.URL+login.RouteSubmitFlow)
+					prefix = "session."
+				} else {
+					assert.Contains(t, res.Request.URL.String(), redirTS.URL)
+				}
+
+				assert.True(t, gjson.Get(body, prefix+"active").Bool(), "%s", body)
+				assert.EqualValues(t, identity.AuthenticatorAssuranceLevel1, gjson.Get(body, prefix+"authenticator_assurance_level").String(), "%s", body)
+				assert.EqualValues(t, id.ID.String(), gjson.Get(body, prefix+"identity.id").String(), "%s", body)
+			}
+
+			t.Run("type=browser", func(t *testing.T) {
+				run(t, false)
+			})
+
+			t.Run("type=spa", func(t *testing.T) {
+				run(t, true)
+			})
+		})
+
+		t.Run("case=fails discoverable passwordless login with an unknown user handle", func(t *testing.T) {
+			id := createIdentityWithWebAuthn(t, identity.Credentials{
+				Config:  loginFixtureSuccessV1PasswordlessCredentials,
+				Version: 1,
+			})
+
+			for _, handle := range []string{"", "dW5rbm93bg"} {
+				response, err := sjson.SetBytes(loginFixtureSuccessV1PasswordlessResponse, "response.userHandle", handle)
+				require.NoError(t, err)
+
+				body, res, _ := submitWebAuthnLoginWithClient(t, true, id, discoverableContext, testhelpers.NewClientWithCookies(t), func(values url.Values) {
+					values.Del("identifier")
+					values.Set(node.WebAuthnLogin, string(response))
+				}, testhelpers.InitFlowWithAAL(identity.AuthenticatorAssuranceLevel1))
+
+				checkURL(t, false, res)
+				assert.Equal(t, text.NewErrorValidationSuchNoWebAuthnUser().Text, gjson.Get(body, "ui.messages.0.text").String(), "%s", body)
+			}
+		})
 	})
 
 	t.Run("flow=mfa", func(t *testing.T) {
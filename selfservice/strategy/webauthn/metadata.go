@@ -0,0 +1,227 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/duo-labs/webauthn/metadata"
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+)
+
+// metadataSource reads a locally stored FIDO Metadata Service (MDS3) blob. The blob is a JSON Web Token whose
+// payload lists the known authenticator models and their certification status. The blob is only accepted if it
+// is signed by a certificate which chains to the configured root certificate.
+//
+// The blob is reloaded whenever its modification time changes.
+type metadataSource struct {
+	path     string
+	rootPath string
+
+	mu      sync.RWMutex
+	modTime time.Time
+	entries map[uuid.UUID]metadataEntry
+}
+
+type metadataBlob struct {
+	Entries []metadataEntry `json:"entries"`
+}
+
+// Valid implements jwt.Claims. The blob does not use the registered claims and is refreshed out of band.
+func (*metadataBlob) Valid() error {
+	return nil
+}
+
+type metadataEntry struct {
+	AAGUID            string                  `json:"aaguid"`
+	StatusReports     []metadataStatusReport  `json:"statusReports"`
+	MetadataStatement metadataStatementSubset `json:"metadataStatement"`
+}
+
+type metadataStatementSubset struct {
+	AttestationRootCertificates []string `json:"attestationRootCertificates"`
+}
+
+type metadataStatusReport struct {
+	Status        metadata.AuthenticatorStatus `json:"status"`
+	EffectiveDate string                       `json:"effectiveDate"`
+}
+
+func newMetadataSource(path, rootPath string) *metadataSource {
+	return &metadataSource{path: path, rootPath: rootPath}
+}
+
+// Find returns the metadata entry of the authenticator model with the given AAGUID.
+func (s *metadataSource) Find(aaguid uuid.UUID) (*metadataEntry, bool, error) {
+	entries, err := s.load()
+	if err != nil {
+		return nil, false, err
+	}
+
+	e, ok := entries[aaguid]
+	return &e, ok, nil
+}
+
+func (s *metadataSource) load() (map[uuid.UUID]metadataEntry, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to open FIDO metadata blob: %s", err))
+	}
+
+	s.mu.RLock()
+	if s.entries != nil && s.modTime.Equal(info.ModTime()) {
+		defer s.mu.RUnlock()
+		return s.entries, nil
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	roots, err := loadMetadataRoots(s.rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to read FIDO metadata blob: %s", err))
+	}
+
+	blob, err := decodeMetadataBlob(raw, roots)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[uuid.UUID]metadataEntry, len(blob.Entries))
+	for _, e := range blob.Entries {
+		// Entries of U2F authenticators are identified by their attestation certificate key instead.
+		aaguid, err := uuid.FromString(e.AAGUID)
+		if err != nil {
+			continue
+		}
+		entries[aaguid] = e
+	}
+
+	s.entries, s.modTime = entries, info.ModTime()
+	return s.entries, nil
+}
+
+func loadMetadataRoots(path string) (*x509.CertPool, error) {
+	if len(path) == 0 {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReason("Unable to verify FIDO metadata blob: no root certificate is configured."))
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to read FIDO metadata root certificate: %s", err))
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(raw) {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReason("Unable to read FIDO metadata root certificate: expected a PEM encoded certificate."))
+	}
+
+	return roots, nil
+}
+
+// decodeMetadataBlob verifies the signature of the blob and returns its payload. The blob must carry its signing
+// certificate chain in the x5c header, and the chain must verify against the given roots.
+func decodeMetadataBlob(raw []byte, roots *x509.CertPool) (*metadataBlob, error) {
+	var blob metadataBlob
+	if _, err := jwt.ParseWithClaims(string(bytes.TrimSpace(raw)), &blob, func(token *jwt.Token) (interface{}, error) {
+		x5c, ok := token.Header["x5c"].([]interface{})
+		if !ok || len(x5c) == 0 {
+			return nil, errors.New("the x5c header is missing")
+		}
+
+		chain := make([]*x509.Certificate, len(x5c))
+		for k, encoded := range x5c {
+			encoded, ok := encoded.(string)
+			if !ok {
+				return nil, errors.New("the x5c header is malformed")
+			}
+
+			der, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			if chain[k], err = x509.ParseCertificate(der); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+
+		if err := verifyCertificateChain(chain, roots); err != nil {
+			return nil, err
+		}
+
+		return chain[0].PublicKey, nil
+	}); err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to verify FIDO metadata blob: %s", err))
+	}
+
+	return &blob, nil
+}
+
+// VerifyAttestation checks that the attestation certificate chain presented by an authenticator chains to one of
+// the attestation root certificates of its model.
+func (e *metadataEntry) VerifyAttestation(chain []*x509.Certificate) error {
+	if len(chain) == 0 {
+		return errors.New("the attestation statement does not contain a certificate chain")
+	}
+
+	roots := x509.NewCertPool()
+	for _, encoded := range e.MetadataStatement.AttestationRootCertificates {
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			continue
+		}
+		roots.AddCert(cert)
+	}
+
+	return verifyCertificateChain(chain, roots)
+}
+
+// verifyCertificateChain verifies the leaf, which comes first, against the roots using the remaining certificates
+// as intermediates.
+func verifyCertificateChain(chain []*x509.Certificate, roots *x509.CertPool) error {
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return errors.WithStack(err)
+}
+
+// Status returns the most recent status reported for the authenticator model.
+func (e *metadataEntry) Status() metadata.AuthenticatorStatus {
+	var latest *metadataStatusReport
+	for k := range e.StatusReports {
+		if r := &e.StatusReports[k]; latest == nil || r.EffectiveDate >= latest.EffectiveDate {
+			latest = r
+		}
+	}
+
+	if latest == nil {
+		return ""
+	}
+	return latest.Status
+}
@@ -0,0 +1,99 @@
+package webauthn
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/duo-labs/webauthn/metadata"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/x"
+)
+
+func TestMetadataSource(t *testing.T) {
+	aaguid := uuid.FromStringOrNil("cb69481e-8ff7-4039-93ec-0a2729a154a8")
+	payload := `{"no":1,"entries":[
+		{"aaguid":"cb69481e-8ff7-4039-93ec-0a2729a154a8","statusReports":[{"status":"REVOKED","effectiveDate":"2021-01-01"},{"status":"FIDO_CERTIFIED","effectiveDate":"2020-01-01"}]},
+		{"attestationCertificateKeyIdentifiers":["bf7bcaa0d0c6187a8c6abbdd16a15640e7c7bde2"],"statusReports":[{"status":"FIDO_CERTIFIED"}]}
+	]}`
+
+	ca := x.NewTestCertificateAuthority(t)
+	rootPath := ca.WriteCertificate(t)
+	writeBlob := func(t *testing.T, path, payload string) {
+		require.NoError(t, os.WriteFile(path, []byte(ca.SignFIDOMetadataBlob(t, payload)+"\n"), 0600))
+	}
+
+	t.Run("case=finds entries of signed blobs", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "mds")
+		writeBlob(t, path, payload)
+		s := newMetadataSource(path, rootPath)
+
+		e, ok, err := s.Find(aaguid)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.EqualValues(t, metadata.Revoked, e.Status())
+
+		_, ok, err = s.Find(uuid.Nil)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("case=reloads the blob when it changes", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "mds")
+		writeBlob(t, path, `{"entries":[]}`)
+		s := newMetadataSource(path, rootPath)
+
+		_, ok, err := s.Find(aaguid)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		writeBlob(t, path, payload)
+		require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+		_, ok, err = s.Find(aaguid)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("case=fails on invalid blobs", func(t *testing.T) {
+		unsigned := "e30." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".c2lnbmF0dXJl"
+		for _, blob := range []string{"not-a-jwt", "a.!!!.c", "e30.e30", payload, unsigned, x.NewTestCertificateAuthority(t).SignFIDOMetadataBlob(t, payload)} {
+			path := filepath.Join(t.TempDir(), "mds")
+			require.NoError(t, os.WriteFile(path, []byte(blob), 0600))
+
+			_, _, err := newMetadataSource(path, rootPath).Find(aaguid)
+			assert.Error(t, err, "%s", blob)
+		}
+
+		path := filepath.Join(t.TempDir(), "mds")
+		writeBlob(t, path, payload)
+		for _, root := range []string{"", path, filepath.Join(t.TempDir(), "does-not-exist")} {
+			_, _, err := newMetadataSource(path, root).Find(aaguid)
+			assert.Error(t, err, "%s", root)
+		}
+
+		_, _, err := newMetadataSource(filepath.Join(t.TempDir(), "does-not-exist"), rootPath).Find(aaguid)
+		assert.Error(t, err)
+	})
+}
+
+func TestMetadataEntryVerifyAttestation(t *testing.T) {
+	ca := x.NewTestCertificateAuthority(t)
+	leaf, _ := ca.Issue(t)
+	other, _ := x.NewTestCertificateAuthority(t).Issue(t)
+
+	e := metadataEntry{MetadataStatement: metadataStatementSubset{
+		AttestationRootCertificates: []string{base64.StdEncoding.EncodeToString(ca.Certificate.Raw)},
+	}}
+
+	assert.NoError(t, e.VerifyAttestation([]*x509.Certificate{leaf}))
+	assert.Error(t, e.VerifyAttestation([]*x509.Certificate{other}))
+	assert.Error(t, e.VerifyAttestation(nil))
+	assert.Error(t, (&metadataEntry{}).VerifyAttestation([]*x509.Certificate{leaf}))
+}
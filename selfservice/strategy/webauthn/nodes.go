@@ -36,6 +36,13 @@ func NewWebAuthnLoginTrigger(options string) *node.Node {
 		}))
 }
 
+// NewWebAuthnLoginOptions exposes the options of a discoverable login to the WebAuthn JavaScript which uses them to
+// offer passkeys in the browser's autofill UI.
+func NewWebAuthnLoginOptions(options string) *node.Node {
+	return node.NewInputField(node.WebAuthnLoginOptions, options, node.WebAuthnGroup,
+		node.InputAttributeTypeHidden)
+}
+
 func NewWebAuthnLoginInput() *node.Node {
 	return node.NewInputField(node.WebAuthnLogin, "", node.WebAuthnGroup,
 		node.InputAttributeTypeHidden)
@@ -0,0 +1,107 @@
+package webauthn
+
+import (
+	"context"
+	"crypto/x509"
+
+	"github.com/duo-labs/webauthn/metadata"
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/schema"
+)
+
+// validateAttestationPolicy checks a newly registered credential against the configured attestation policy.
+func (s *Strategy) validateAttestationPolicy(ctx context.Context, response *protocol.ParsedCredentialCreationData, c *webauthn.Credential) error {
+	policy := s.d.Config().WebAuthnAttestationPolicy(ctx)
+	if policy.Required && len(policy.MetadataPath) == 0 {
+		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("WebAuthn attestation can only be required if FIDO metadata is configured in %s.", config.ViperKeyWebAuthnAttestationMetadataPath))
+	}
+
+	// Self and none attestation do not carry a certificate chain and prove nothing about the authenticator model.
+	chain, err := attestationCertificates(response)
+	if err != nil || (policy.Required && len(chain) == 0) {
+		return schema.NewWebAuthnAuthenticatorNotAllowedError()
+	}
+
+	// Authenticators which do not disclose their model use the nil AAGUID.
+	aaguid, err := uuid.FromBytes(c.Authenticator.AAGUID)
+	if err != nil {
+		aaguid = uuid.Nil
+	}
+
+	if len(policy.AllowedAAGUIDs) > 0 && !containsAAGUID(policy.AllowedAAGUIDs, aaguid) {
+		return schema.NewWebAuthnAuthenticatorNotAllowedError()
+	}
+
+	if containsAAGUID(policy.DeniedAAGUIDs, aaguid) {
+		return schema.NewWebAuthnAuthenticatorNotAllowedError()
+	}
+
+	if len(policy.MetadataPath) > 0 {
+		entry, ok, err := s.metadataSource(policy.MetadataPath, policy.MetadataRootCertificatePath).Find(aaguid)
+		if err != nil {
+			return err
+		}
+
+		if !ok || metadata.IsUndesiredAuthenticatorStatus(entry.Status()) {
+			return schema.NewWebAuthnAuthenticatorNotAllowedError()
+		}
+
+		if len(chain) > 0 {
+			if err := entry.VerifyAttestation(chain); err != nil {
+				s.d.Logger().WithError(err).WithField("aaguid", aaguid).Info("Rejected WebAuthn credential because its attestation certificate chain could not be verified.")
+				return schema.NewWebAuthnAuthenticatorNotAllowedError()
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Strategy) metadataSource(path, rootPath string) *metadataSource {
+	s.metadataLock.Lock()
+	defer s.metadataLock.Unlock()
+
+	if s.metadata == nil || s.metadata.path != path || s.metadata.rootPath != rootPath {
+		s.metadata = newMetadataSource(path, rootPath)
+	}
+	return s.metadata
+}
+
+// attestationCertificates returns the attestation certificate chain of the response, leaf first.
+func attestationCertificates(response *protocol.ParsedCredentialCreationData) ([]*x509.Certificate, error) {
+	x5c, ok := response.Response.AttestationObject.AttStatement["x5c"].([]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	chain := make([]*x509.Certificate, 0, len(x5c))
+	for _, raw := range x5c {
+		der, ok := raw.([]byte)
+		if !ok {
+			return nil, errors.New("the x5c attestation statement is malformed")
+		}
+
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		chain = append(chain, cert)
+	}
+
+	return chain, nil
+}
+
+func containsAAGUID(list []string, aaguid uuid.UUID) bool {
+	for _, candidate := range list {
+		if id, err := uuid.FromString(candidate); err == nil && id == aaguid {
+			return true
+		}
+	}
+	return false
+}
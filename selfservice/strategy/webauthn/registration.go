@@ -125,8 +125,10 @@ func (s *Strategy) Register(w http.ResponseWriter, r *http.Request, f *registrat
 		return s.handleRegistrationError(w, r, f, &p, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to parse WebAuthn response: %s", err)))
 	}
 
-	web, err := s.newWebAuthn(r.Context())
-	if err != nil {
+	web, err := s.newWebAuthnForOrigin(r, webAuthnResponse.Response.CollectedClientData.Origin)
+	if errors.Is(err, ErrOriginNotAllowed) {
+		return s.handleRegistrationError(w, r, f, &p, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to create WebAuthn credential: %s", err)))
+	} else if err != nil {
 		return s.handleRegistrationError(w, r, f, &p, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to get webAuthn config.").WithDebug(err.Error())))
 	}
 
@@ -135,6 +137,10 @@ func (s *Strategy) Register(w http.ResponseWriter, r *http.Request, f *registrat
 		return s.handleRegistrationError(w, r, f, &p, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to create WebAuthn credential: %s", err)))
 	}
 
+	if err := s.validateAttestationPolicy(r.Context(), webAuthnResponse, credential); err != nil {
+		return s.handleRegistrationError(w, r, f, &p, err)
+	}
+
 	var cc CredentialsConfig
 	wc := CredentialFromWebAuthn(credential, true)
 	wc.AddedAt = time.Now().UTC().Round(time.Second)
@@ -185,13 +191,13 @@ func (s *Strategy) PopulateRegistrationMethod(r *http.Request, f *registration.F
 		f.UI.SetNode(n)
 	}
 
-	web, err := s.newWebAuthn(r.Context())
+	web, err := s.newWebAuthn(r)
 	if err != nil {
 		return err
 	}
 
 	webauthID := x.NewUUID()
-	option, sessionData, err := web.BeginRegistration(&wrappedUser{id: webauthID[:]})
+	option, sessionData, err := web.BeginRegistration(&wrappedUser{id: webauthID[:]}, s.registrationOptions(r.Context())...)
 	if err != nil {
 		return errors.WithStack(err)
 	}
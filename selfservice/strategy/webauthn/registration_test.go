@@ -3,6 +3,7 @@ package webauthn_test
 import (
 	"context"
 	_ "embed"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -141,6 +142,10 @@ func TestRegistration(t *testing.T) {
 					"6.attributes.nonce",
 					"6.attributes.src",
 				})
+
+				onclick := f.Ui.Nodes[5].Attributes.UiNodeInputAttributes.Onclick
+				require.NotNil(t, onclick)
+				assert.Contains(t, *onclick, `"residentKey":"required"`, "passkeys must be discoverable")
 			})
 		}
 	})
@@ -372,6 +377,71 @@ func TestRegistration(t *testing.T) {
 		})
 	})
 
+	t.Run("case=enforces the attestation policy", func(t *testing.T) {
+		useReturnToFromTS(redirNoSessionTS)
+		t.Cleanup(func() {
+			useReturnToFromTS(redirTS)
+		})
+
+		// The fixture was registered without attestation, so the authenticator's AAGUID is the nil UUID.
+		withMetadata := func(t *testing.T, entries string, policy map[string]interface{}) map[string]interface{} {
+			if policy == nil {
+				policy = map[string]interface{}{}
+			}
+			policy[config.ViperKeyWebAuthnAttestationMetadataPath], policy[config.ViperKeyWebAuthnAttestationMetadataRootCertificatePath] = x.NewFIDOMetadataBlob(t, `{"no":1,"entries":`+entries+`}`)
+			return policy
+		}
+
+		for k, tc := range []struct {
+			d       string
+			policy  map[string]interface{}
+			allowed bool
+		}{
+			{d: "attestation is required", policy: withMetadata(t, `[{"aaguid":"00000000-0000-0000-0000-000000000000","statusReports":[{"status":"FIDO_CERTIFIED_L1"}]}]`, map[string]interface{}{config.ViperKeyWebAuthnAttestationRequired: true})},
+			{d: "authenticator is not allowed", policy: map[string]interface{}{config.ViperKeyWebAuthnAuthenticatorsAllow: []string{"cb69481e-8ff7-4039-93ec-0a2729a154a8"}}},
+			{d: "authenticator is denied", policy: map[string]interface{}{config.ViperKeyWebAuthnAuthenticatorsDeny: []string{uuid.Nil.String()}}},
+			{d: "authenticator is not in the metadata", policy: withMetadata(t, `[{"aaguid":"cb69481e-8ff7-4039-93ec-0a2729a154a8","statusReports":[{"status":"FIDO_CERTIFIED"}]}]`, nil)},
+			{d: "authenticator is revoked", policy: withMetadata(t, `[{"aaguid":"00000000-0000-0000-0000-000000000000","statusReports":[{"status":"FIDO_CERTIFIED","effectiveDate":"2020-01-01"},{"status":"REVOKED","effectiveDate":"2021-01-01"}]}]`, nil)},
+			{d: "authenticator is allowed", allowed: true, policy: withMetadata(t, `[{"aaguid":"00000000-0000-0000-0000-000000000000","statusReports":[{"status":"FIDO_CERTIFIED_L1"}]}]`, map[string]interface{}{
+				config.ViperKeyWebAuthnAuthenticatorsAllow: []string{uuid.Nil.String()},
+				config.ViperKeyWebAuthnAuthenticatorsDeny:  []string{"cb69481e-8ff7-4039-93ec-0a2729a154a8"},
+			})},
+		} {
+			t.Run(fmt.Sprintf("case=%d/description=%s", k, tc.d), func(t *testing.T) {
+				for key, value := range tc.policy {
+					conf.MustSet(ctx, key, value)
+				}
+				t.Cleanup(func() {
+					for key := range tc.policy {
+						conf.MustSet(ctx, key, nil)
+					}
+				})
+
+				for _, f := range flows {
+					t.Run("type="+f, func(t *testing.T) {
+						email := testhelpers.RandomEmail()
+						actual, _, _ := makeRegistration(t, f, func(v url.Values) {
+							v.Set("traits.username", email)
+							v.Set("traits.foobar", "bazbar")
+							v.Set(node.WebAuthnRegister, string(registrationFixtureSuccessResponse))
+							v.Del("method")
+						})
+
+						_, _, err := reg.PrivilegedIdentityPool().FindByCredentialsIdentifier(context.Background(), identity.CredentialsTypeWebAuthn, email)
+						if tc.allowed {
+							require.NoError(t, err, "%s", actual)
+							return
+						}
+
+						require.Error(t, err)
+						assert.Contains(t, gjson.Get(actual, "ui.action").String(), publicTS.URL+registration.RouteSubmitFlow, "%s", actual)
+						assert.EqualValues(t, text.ErrorValidationWebAuthnAuthenticatorNotAllowed, gjson.Get(actual, "ui.messages.0.id").Int(), "%s", actual)
+					})
+				}
+			})
+		}
+	})
+
 	t.Run("case=should fail if no identifier was set in the schema", func(t *testing.T) {
 		testhelpers.SetDefaultIdentitySchema(conf, "file://stub/missing-identifier.schema.json")
 
@@ -237,8 +237,10 @@ func (s *Strategy) continueSettingsFlowAdd(w http.ResponseWriter, r *http.Reques
 		return errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to parse WebAuthn response: %s", err))
 	}
 
-	web, err := s.newWebAuthn(r.Context())
-	if err != nil {
+	web, err := s.newWebAuthnForOrigin(r, webAuthnResponse.Response.CollectedClientData.Origin)
+	if errors.Is(err, ErrOriginNotAllowed) {
+		return errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to create WebAuthn credential: %s", err))
+	} else if err != nil {
 		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to get webAuthn config.").WithDebug(err.Error()))
 	}
 
@@ -247,6 +249,10 @@ func (s *Strategy) continueSettingsFlowAdd(w http.ResponseWriter, r *http.Reques
 		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to create WebAuthn credential: %s", err))
 	}
 
+	if err := s.validateAttestationPolicy(r.Context(), webAuthnResponse, credential); err != nil {
+		return err
+	}
+
 	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(r.Context(), ctxUpdate.Session.IdentityID)
 	if err != nil {
 		return err
@@ -300,6 +306,7 @@ func (s *Strategy) continueSettingsFlowAdd(w http.ResponseWriter, r *http.Reques
 	}
 
 	ctxUpdate.UpdateIdentity(i)
+	ctxUpdate.MarkCredentialsAddedOrRotated()
 	return nil
 }
 
@@ -351,12 +358,12 @@ func (s *Strategy) PopulateSettingsMethod(r *http.Request, id *identity.Identity
 		}
 	}
 
-	web, err := s.newWebAuthn(r.Context())
+	web, err := s.newWebAuthn(r)
 	if err != nil {
 		return err
 	}
 
-	option, sessionData, err := web.BeginRegistration(&wrappedUser{id: id.ID[:]})
+	option, sessionData, err := web.BeginRegistration(&wrappedUser{id: id.ID[:]}, s.registrationOptions(r.Context())...)
 	if err != nil {
 		return errors.WithStack(err)
 	}
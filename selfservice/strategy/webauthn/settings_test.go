@@ -439,6 +439,23 @@ func TestCompleteSettings(t *testing.T) {
 		})
 	})
 
+	t.Run("case=removing a security key does not resolve a credentials reset", func(t *testing.T) {
+		id := createIdentity(t, reg)
+		id.CredentialsResetRequired = identity.CredentialsResetSecondFactor
+		require.NoError(t, reg.IdentityManager().Update(ctx, id, identity.ManagerAllowWriteProtectedTraits))
+
+		body, _ := doBrowserFlow(t, true, func(v url.Values) {
+			v.Set(node.WebAuthnRemove, fmt.Sprintf("%x", []byte("foofoo")))
+		}, id)
+		assert.EqualValues(t, settings.StateSuccess, gjson.Get(body, "state").String(), body)
+
+		actual, err := reg.Persister().GetIdentityConfidential(context.Background(), id.ID)
+		require.NoError(t, err)
+		assert.Equal(t, identity.CredentialsResetSecondFactor, actual.CredentialsResetRequired)
+		_, ok := actual.GetCredentials(identity.CredentialsTypeWebAuthn)
+		assert.True(t, ok, "the other security key is left")
+	})
+
 	t.Run("case=remove all security keys", func(t *testing.T) {
 		run := func(t *testing.T, spa bool) {
 			id := createIdentity(t, reg)
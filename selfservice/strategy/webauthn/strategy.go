@@ -3,7 +3,12 @@ package webauthn
 import (
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 
+	"github.com/duo-labs/webauthn/protocol"
 	"github.com/duo-labs/webauthn/webauthn"
 
 	"github.com/pkg/errors"
@@ -63,11 +68,15 @@ type registrationStrategyDependencies interface {
 
 	session.HandlerProvider
 	session.ManagementProvider
+	session.PersistenceProvider
 }
 
 type Strategy struct {
 	d  registrationStrategyDependencies
 	hd *decoderx.HTTP
+
+	metadata     *metadataSource
+	metadataLock sync.Mutex
 }
 
 func NewStrategy(d registrationStrategyDependencies) *Strategy {
@@ -111,9 +120,13 @@ func (s *Strategy) NodeGroup() node.UiNodeGroup {
 	return node.WebAuthnGroup
 }
 
-func (s *Strategy) newWebAuthn(ctx context.Context) (*webauthn.WebAuthn, error) {
-	c := s.d.Config()
-	web, err := webauthn.New(c.WebAuthnConfig(ctx))
+// newWebAuthn returns the relying party for the request. The relying party identifier depends on the host of the
+// request if one is configured for it.
+func (s *Strategy) newWebAuthn(r *http.Request) (*webauthn.WebAuthn, error) {
+	c := s.d.Config().WebAuthnConfig(r.Context())
+	c.RPID = s.d.Config().WebAuthnRPID(r.Context(), x.RequestURL(r).Hostname())
+
+	web, err := webauthn.New(c)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -121,6 +134,59 @@ func (s *Strategy) newWebAuthn(ctx context.Context) (*webauthn.WebAuthn, error)
 	return web, nil
 }
 
+// newWebAuthnForOrigin returns the relying party which verifies a response created at the given origin. Returns
+// ErrOriginNotAllowed if the origin is not one of the configured origins.
+func (s *Strategy) newWebAuthnForOrigin(r *http.Request, origin string) (*webauthn.WebAuthn, error) {
+	c := s.d.Config().WebAuthnConfig(r.Context())
+	c.RPID = s.d.Config().WebAuthnRPID(r.Context(), x.RequestURL(r).Hostname())
+
+	if allowed := s.d.Config().WebAuthnRPOrigins(r.Context()); len(allowed) > 0 {
+		var found bool
+		for _, o := range allowed {
+			if originMatches(o, origin) {
+				c.RPOrigin, found = o, true
+				break
+			}
+		}
+
+		if !found {
+			return nil, errors.WithStack(ErrOriginNotAllowed)
+		}
+	}
+
+	web, err := webauthn.New(c)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return web, nil
+}
+
+// originMatches compares the scheme and host of web origins. Other origins, such as the `android:apk-key-hash:`
+// origins of Android apps, must match exactly because the WebAuthn library only compares their scheme.
+func originMatches(allowed, origin string) bool {
+	a, err := url.Parse(allowed)
+	if err != nil || a.Host == "" {
+		return allowed == origin
+	}
+
+	o, err := url.Parse(origin)
+	if err != nil || o.Host == "" {
+		return false
+	}
+
+	return strings.EqualFold(protocol.FullyQualifiedOrigin(a), protocol.FullyQualifiedOrigin(o))
+}
+
+// registrationOptions returns the options used when registering a new credential. Passkeys must be discoverable so
+// that users can sign in without entering an identifier first.
+func (s *Strategy) registrationOptions(ctx context.Context) []webauthn.RegistrationOption {
+	if !s.d.Config().WebAuthnForPasswordless(ctx) {
+		return nil
+	}
+	return []webauthn.RegistrationOption{webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired)}
+}
+
 func (s *Strategy) CompletedAuthenticationMethod(ctx context.Context) session.AuthenticationMethod {
 	aal := identity.AuthenticatorAssuranceLevel1
 	if !s.d.Config().WebAuthnForPasswordless(ctx) {
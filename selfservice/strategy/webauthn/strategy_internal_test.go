@@ -0,0 +1,26 @@
+package webauthn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOriginMatches(t *testing.T) {
+	for k, tc := range []struct {
+		allowed, origin string
+		expected        bool
+	}{
+		{allowed: "https://app.example.com", origin: "https://app.example.com", expected: true},
+		{allowed: "https://app.example.com/login", origin: "https://APP.example.com", expected: true},
+		{allowed: "https://app.example.com", origin: "http://app.example.com"},
+		{allowed: "https://app.example.com", origin: "https://admin.example.com"},
+		{allowed: "https://app.example.com:8443", origin: "https://app.example.com"},
+		{allowed: "android:apk-key-hash:ABCDEF", origin: "android:apk-key-hash:ABCDEF", expected: true},
+		{allowed: "android:apk-key-hash:ABCDEF", origin: "android:apk-key-hash:abcdef"},
+		{allowed: "android:apk-key-hash:ABCDEF", origin: "android:apk-key-hash:XYZ"},
+		{allowed: "https://app.example.com", origin: ""},
+	} {
+		assert.Equal(t, tc.expected, originMatches(tc.allowed, tc.origin), "%d: %s / %s", k, tc.allowed, tc.origin)
+	}
+}
@@ -11,10 +11,12 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/ory/x/decoderx"
+	"github.com/ory/x/urlx"
 
 	"github.com/ory/herodot"
 
 	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/x"
 )
 
@@ -163,6 +165,8 @@ type toSession struct {
 //
 // - `session_inactive`: No active session was found in the request (e.g. no Ory Session Cookie / Ory Session Token).
 // - `session_aal2_required`: An active session was found but it does not fulfil the Authenticator Assurance Level, implying that the session must (e.g.) authenticate the second factor.
+// - `session_credentials_reset_required`: An active session was found but an administrator requires the identity to replace its password or second factor using the settings flow.
+// - `session_mfa_enrollment_required`: An active session was found but the identity must set up a second factor using the settings flow.
 //
 //	Produces:
 //	- application/json
@@ -194,6 +198,24 @@ func (h *Handler) whoami(w http.ResponseWriter, r *http.Request, ps httprouter.P
 		return
 	}
 
+	if s.Identity.CredentialsResetRequired != identity.CredentialsResetNone {
+		h.r.Audit().WithRequest(r).WithField("identity_id", s.Identity.ID).Info("Session was found but the identity must reset its credentials.")
+		h.r.Writer().WriteError(w, r, NewErrCredentialsResetRequired(c.SelfServiceFlowSettingsUI(r.Context()).String()))
+		return
+	}
+
+	// The requirement is evaluated again instead of relying on the session, because the policy or the identity
+	// might have changed since the session was issued.
+	if mfaEnrollmentRequired, err := h.r.SessionManager().RequiresMFAEnrollment(r.Context(), s.Identity); err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	} else if mfaEnrollmentRequired {
+		h.r.Audit().WithRequest(r).WithField("identity_id", s.Identity.ID).Info("Session was found but the identity must set up a second factor.")
+		h.r.Writer().WriteError(w, r, NewErrMFAEnrollmentRequired(
+			urlx.AppendPaths(c.SelfPublicURL(r.Context()), "/self-service/settings/browser").String()))
+		return
+	}
+
 	// s.Devices = nil
 	s.Identity = s.Identity.CopyWithoutCredentials()
 
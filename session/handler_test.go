@@ -31,6 +31,7 @@ import (
 	"github.com/ory/kratos/internal"
 	"github.com/ory/kratos/internal/testhelpers"
 	. "github.com/ory/kratos/session"
+	"github.com/ory/kratos/text"
 	"github.com/ory/kratos/x"
 	"github.com/ory/x/ioutilx"
 	"github.com/ory/x/urlx"
@@ -670,6 +671,51 @@ func TestHandlerSelfServiceSessionManagement(t *testing.T) {
 		}
 	})
 
+	t.Run("case=whoami should fail if the identity must reset its credentials", func(t *testing.T) {
+		client, i, _ := setup(t)
+
+		i.CredentialsResetRequired = identity.CredentialsResetPassword
+		require.NoError(t, reg.PrivilegedIdentityPool().UpdateIdentity(context.Background(), i))
+
+		resp, err := client.Get(ts.URL + "/sessions/whoami")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		body := ioutilx.MustReadAll(resp.Body)
+		assert.EqualValues(t, text.ErrIDCredentialsResetRequired, gjson.GetBytes(body, "error.id").String(), "%s", body)
+		assert.EqualValues(t, conf.SelfServiceFlowSettingsUI(ctx).String(), gjson.GetBytes(body, "redirect_browser_to").String(), "%s", body)
+	})
+
+	t.Run("case=whoami should fail if the identity must set up a second factor", func(t *testing.T) {
+		client, _, s := setup(t)
+
+		// The session was issued before the policy was enabled.
+		require.False(t, s.MFAEnrollmentRequired)
+		conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentEnabled, true)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentEnabled, false)
+		})
+
+		resp, err := client.Get(ts.URL + "/sessions/whoami")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		body := ioutilx.MustReadAll(resp.Body)
+		assert.EqualValues(t, text.ErrIDMFAEnrollmentRequired, gjson.GetBytes(body, "error.id").String(), "%s", body)
+		assert.True(t, strings.HasSuffix(gjson.GetBytes(body, "redirect_browser_to").String(), "/self-service/settings/browser"), "%s", body)
+	})
+
+	t.Run("case=whoami should succeed if the second factor enrollment is no longer required", func(t *testing.T) {
+		client, _, s := setup(t)
+
+		s.MFAEnrollmentRequired = true
+		require.NoError(t, reg.SessionPersister().UpsertSession(ctx, s))
+
+		resp, err := client.Get(ts.URL + "/sessions/whoami")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
 	t.Run("case=whoami should not issue cookie if request is token based", func(t *testing.T) {
 		_, _, session := setup(t)
 
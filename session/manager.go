@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/url"
 
+	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/text"
 
 	"github.com/gofrs/uuid"
@@ -79,6 +80,68 @@ func NewErrAALNotSatisfied(redirectTo string) *ErrAALNotSatisfied {
 	}
 }
 
+// ErrCredentialsResetRequired is returned when an active session was found but the identity must replace its credentials first.
+//
+// swagger:model errorCredentialsResetRequired
+type ErrCredentialsResetRequired struct {
+	*herodot.DefaultError `json:"error"`
+	RedirectTo            string `json:"redirect_browser_to"`
+
+	// SessionToken is only set when the error is returned by an API login flow. The session
+	// token can only be used to reset the credentials using the settings flow.
+	SessionToken string `json:"session_token,omitempty"`
+}
+
+func (e *ErrCredentialsResetRequired) EnhanceJSONError() interface{} {
+	return e
+}
+
+// NewErrCredentialsResetRequired creates a new ErrCredentialsResetRequired.
+func NewErrCredentialsResetRequired(redirectTo string) *ErrCredentialsResetRequired {
+	return &ErrCredentialsResetRequired{
+		RedirectTo: redirectTo,
+		DefaultError: &herodot.DefaultError{
+			IDField:     text.ErrIDCredentialsResetRequired,
+			StatusField: http.StatusText(http.StatusForbidden),
+			ErrorField:  "Identity must reset its credentials",
+			ReasonField: "An active session was found but the identity was asked to replace its credentials. Please complete the settings flow to resolve this issue.",
+			CodeField:   http.StatusForbidden,
+			DetailsField: map[string]interface{}{
+				"redirect_browser_to": redirectTo,
+			},
+		},
+	}
+}
+
+// ErrMFAEnrollmentRequired is returned when an active session was found but the identity must set up a second factor first.
+//
+// swagger:model errorMFAEnrollmentRequired
+type ErrMFAEnrollmentRequired struct {
+	*herodot.DefaultError `json:"error"`
+	RedirectTo            string `json:"redirect_browser_to"`
+}
+
+func (e *ErrMFAEnrollmentRequired) EnhanceJSONError() interface{} {
+	return e
+}
+
+// NewErrMFAEnrollmentRequired creates a new ErrMFAEnrollmentRequired.
+func NewErrMFAEnrollmentRequired(redirectTo string) *ErrMFAEnrollmentRequired {
+	return &ErrMFAEnrollmentRequired{
+		RedirectTo: redirectTo,
+		DefaultError: &herodot.DefaultError{
+			IDField:     text.ErrIDMFAEnrollmentRequired,
+			StatusField: http.StatusText(http.StatusForbidden),
+			ErrorField:  "Identity must set up a second factor",
+			ReasonField: "An active session was found but the identity has not set up a second factor. Please complete the settings flow to resolve this issue.",
+			CodeField:   http.StatusForbidden,
+			DetailsField: map[string]interface{}{
+				"redirect_browser_to": redirectTo,
+			},
+		},
+	}
+}
+
 // Manager handles identity sessions.
 type Manager interface {
 	// UpsertAndIssueCookie stores a session in the database and issues a cookie by calling IssueCookie.
@@ -101,12 +164,46 @@ type Manager interface {
 	PurgeFromRequest(context.Context, http.ResponseWriter, *http.Request) error
 
 	// DoesSessionSatisfy answers if a session is satisfying the AAL.
-	DoesSessionSatisfy(r *http.Request, sess *Session, requestedAAL string) error
+	DoesSessionSatisfy(r *http.Request, sess *Session, requestedAAL string, opts ...DoesSessionSatisfyOption) error
 
 	// SessionAddAuthenticationMethods adds one or more authentication method to the session.
 	SessionAddAuthenticationMethods(ctx context.Context, sid uuid.UUID, methods ...AuthenticationMethod) error
+
+	// IssueTrustedDeviceCookie stores a new trusted device for the identity and issues a cookie
+	// which identifies the browser as that device.
+	IssueTrustedDeviceCookie(ctx context.Context, w http.ResponseWriter, r *http.Request, identityID uuid.UUID) error
+
+	// IsTrustedDevice answers if the request was made from a device the identity trusts.
+	IsTrustedDevice(ctx context.Context, r *http.Request, identityID uuid.UUID) (bool, error)
+
+	// SeenDeviceID returns the ID of the browser which made the request, as stored in its signed seen device cookie.
+	// Browsers without a valid cookie are given a new ID. The cookie is (re-)issued either way.
+	SeenDeviceID(ctx context.Context, w http.ResponseWriter, r *http.Request) (string, error)
+
+	// RequiresMFAEnrollment answers if the identity must set up a second factor before its sessions can be used.
+	RequiresMFAEnrollment(ctx context.Context, i *identity.Identity) (bool, error)
+
+	// RefreshMFAEnrollment evaluates RequiresMFAEnrollment again and updates all sessions of the identity. It must
+	// be called whenever the second factors of the identity change.
+	RefreshMFAEnrollment(ctx context.Context, i *identity.Identity) error
 }
 
 type ManagementProvider interface {
 	SessionManager() Manager
 }
+
+type (
+	// DoesSessionSatisfyOption configures DoesSessionSatisfy.
+	DoesSessionSatisfyOption func(*doesSessionSatisfyOptions)
+
+	doesSessionSatisfyOptions struct {
+		ignoreTrustedDevices bool
+	}
+)
+
+// WithoutTrustedDevices requires the second factor even if the request was made from a device the identity trusts.
+func WithoutTrustedDevices() DoesSessionSatisfyOption {
+	return func(o *doesSessionSatisfyOptions) {
+		o.ignoreTrustedDevices = true
+	}
+}
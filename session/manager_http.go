@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/gorilla/sessions"
+	"github.com/tidwall/gjson"
 
+	"github.com/ory/x/stringslice"
 	"github.com/ory/x/urlx"
 
 	"github.com/gofrs/uuid"
@@ -91,19 +93,7 @@ func (s *ManagerHTTP) IssueCookie(ctx context.Context, w http.ResponseWriter, r
 		return errors.WithStack(err)
 	}
 
-	if s.r.Config().SessionPath(ctx) != "" {
-		cookie.Options.Path = s.r.Config().SessionPath(ctx)
-	}
-
-	if domain := s.r.Config().SessionDomain(ctx); domain != "" {
-		cookie.Options.Domain = domain
-	}
-
-	if alias := s.r.Config().SelfPublicURL(ctx); s.r.Config().SelfPublicURL(ctx).String() != alias.String() {
-		// If a domain alias is detected use that instead.
-		cookie.Options.Domain = alias.Hostname()
-		cookie.Options.Path = alias.Path
-	}
+	s.setCookieOptions(ctx, cookie)
 
 	old, err := s.FetchFromRequest(ctx, r)
 	if err != nil {
@@ -114,10 +104,6 @@ func (s *ManagerHTTP) IssueCookie(ctx context.Context, w http.ResponseWriter, r
 		_ = s.r.CSRFHandler().RegenerateToken(w, r)
 	}
 
-	if s.r.Config().SessionSameSiteMode(ctx) != 0 {
-		cookie.Options.SameSite = s.r.Config().SessionSameSiteMode(ctx)
-	}
-
 	cookie.Options.MaxAge = 0
 	if s.r.Config().SessionPersistentCookie(ctx) {
 		if session.ExpiresAt.IsZero() {
@@ -136,6 +122,27 @@ func (s *ManagerHTTP) IssueCookie(ctx context.Context, w http.ResponseWriter, r
 	return nil
 }
 
+// setCookieOptions applies the session cookie path, domain, and same site mode to the cookie.
+func (s *ManagerHTTP) setCookieOptions(ctx context.Context, cookie *sessions.Session) {
+	if s.r.Config().SessionPath(ctx) != "" {
+		cookie.Options.Path = s.r.Config().SessionPath(ctx)
+	}
+
+	if domain := s.r.Config().SessionDomain(ctx); domain != "" {
+		cookie.Options.Domain = domain
+	}
+
+	if alias := s.r.Config().SelfPublicURL(ctx); s.r.Config().SelfPublicURL(ctx).String() != alias.String() {
+		// If a domain alias is detected use that instead.
+		cookie.Options.Domain = alias.Hostname()
+		cookie.Options.Path = alias.Path
+	}
+
+	if s.r.Config().SessionSameSiteMode(ctx) != 0 {
+		cookie.Options.SameSite = s.r.Config().SessionSameSiteMode(ctx)
+	}
+}
+
 func getCookieExpiry(s *sessions.Session) *time.Time {
 	expiresAt, ok := s.Values["expires_at"].(string)
 	if !ok {
@@ -223,7 +230,12 @@ func (s *ManagerHTTP) PurgeFromRequest(ctx context.Context, w http.ResponseWrite
 	return nil
 }
 
-func (s *ManagerHTTP) DoesSessionSatisfy(r *http.Request, sess *Session, requestedAAL string) error {
+func (s *ManagerHTTP) DoesSessionSatisfy(r *http.Request, sess *Session, requestedAAL string, opts ...DoesSessionSatisfyOption) error {
+	var o doesSessionSatisfyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	sess.SetAuthenticatorAssuranceLevel()
 	switch requestedAAL {
 	case string(identity.AuthenticatorAssuranceLevel1):
@@ -253,6 +265,15 @@ func (s *ManagerHTTP) DoesSessionSatisfy(r *http.Request, sess *Session, request
 			return nil
 		}
 
+		// The second factor is not required again on devices the identity trusts.
+		if available == identity.AuthenticatorAssuranceLevel2 && !o.ignoreTrustedDevices {
+			if trusted, err := s.IsTrustedDevice(r.Context(), r, sess.IdentityID); err != nil {
+				return err
+			} else if trusted {
+				return nil
+			}
+		}
+
 		return NewErrAALNotSatisfied(
 			urlx.CopyWithQuery(urlx.AppendPaths(s.r.Config().SelfPublicURL(r.Context()), "/self-service/login/browser"), url.Values{"aal": {"aal2"}}).String())
 	}
@@ -271,3 +292,126 @@ func (s *ManagerHTTP) SessionAddAuthenticationMethods(ctx context.Context, sid u
 	sess.SetAuthenticatorAssuranceLevel()
 	return s.r.SessionPersister().UpsertSession(ctx, sess)
 }
+
+func (s *ManagerHTTP) IssueTrustedDeviceCookie(ctx context.Context, w http.ResponseWriter, r *http.Request, identityID uuid.UUID) error {
+	device := NewTrustedDevice(identityID, r.UserAgent(), s.r.Config().TrustedDeviceLifespan(ctx))
+	if err := s.r.SessionPersister().CreateTrustedDevice(ctx, device); err != nil {
+		return err
+	}
+
+	cookie, err := s.r.CookieManager(ctx).Get(r, TrustedDeviceCookieName)
+	if err != nil && cookie == nil {
+		return errors.WithStack(err)
+	}
+
+	s.setCookieOptions(ctx, cookie)
+	cookie.Options.MaxAge = int(time.Until(device.ExpiresAt).Seconds())
+	cookie.Values["device_id"] = device.ID.String()
+	cookie.Values["identity_id"] = device.IdentityID.String()
+
+	if err := cookie.Save(r, w); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func (s *ManagerHTTP) IsTrustedDevice(ctx context.Context, r *http.Request, identityID uuid.UUID) (bool, error) {
+	if !s.r.Config().SelfServiceStrategy(ctx, TrustedDeviceMethod).Enabled {
+		return false, nil
+	}
+
+	// The cookie is signed, which is why a cookie which can not be decoded was tampered with or uses rotated secrets.
+	cookie, err := s.r.CookieManager(ctx).Get(r, TrustedDeviceCookieName)
+	if err != nil {
+		return false, nil
+	}
+
+	if iID, ok := cookie.Values["identity_id"].(string); !ok || iID != identityID.String() {
+		return false, nil
+	}
+
+	deviceID, ok := cookie.Values["device_id"].(string)
+	if !ok {
+		return false, nil
+	}
+
+	id, err := uuid.FromString(deviceID)
+	if err != nil {
+		return false, nil
+	}
+
+	device, err := s.r.SessionPersister().GetTrustedDevice(ctx, identityID, id)
+	if errors.Is(err, sqlcon.ErrNoRows) {
+		// The device was revoked.
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return device.IsActive(), nil
+}
+
+func (s *ManagerHTTP) SeenDeviceID(ctx context.Context, w http.ResponseWriter, r *http.Request) (string, error) {
+	// The cookie is signed, which is why a cookie which can not be decoded is replaced like a missing one.
+	cookie, err := s.r.CookieManager(ctx).Get(r, SeenDeviceCookieName)
+	if err != nil && cookie == nil {
+		return "", errors.WithStack(err)
+	}
+
+	deviceID, ok := cookie.Values["device_id"].(string)
+	if err != nil || !ok || len(deviceID) == 0 {
+		deviceID = x.NewUUID().String()
+	}
+
+	s.setCookieOptions(ctx, cookie)
+	cookie.Options.MaxAge = int(SeenDeviceLifespan.Seconds())
+	cookie.Values["device_id"] = deviceID
+
+	if err := cookie.Save(r, w); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return deviceID, nil
+}
+
+func (s *ManagerHTTP) RequiresMFAEnrollment(ctx context.Context, i *identity.Identity) (bool, error) {
+	policy := s.r.Config().SelfServiceSettingsMFAEnrollment(ctx)
+	if !policy.Enabled {
+		return false, nil
+	}
+
+	if len(policy.Schemas) > 0 && !stringslice.Has(policy.Schemas, i.SchemaID) {
+		return false, nil
+	}
+
+	if len(policy.Trait) > 0 && !gjson.GetBytes(i.Traits, policy.Trait).Bool() {
+		return false, nil
+	}
+
+	// The identity passed in might not contain its credentials.
+	confidential, err := s.r.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := s.r.IdentityManager().CountActiveMultiFactorCredentials(ctx, confidential)
+	if err != nil {
+		return false, err
+	}
+
+	// Passwordless security keys do not count towards the second factor when computing the assurance level, but
+	// an identity which has one does not need to set up another factor.
+	if c, ok := confidential.GetCredentials(identity.CredentialsTypeWebAuthn); ok {
+		count += int(gjson.GetBytes(c.Config, "credentials.#(is_passwordless==true)#|#").Int())
+	}
+
+	return count == 0, nil
+}
+
+func (s *ManagerHTTP) RefreshMFAEnrollment(ctx context.Context, i *identity.Identity) error {
+	required, err := s.RequiresMFAEnrollment(ctx, i)
+	if err != nil {
+		return err
+	}
+
+	return s.r.SessionPersister().SetMFAEnrollmentRequiredByIdentity(ctx, i.ID, required)
+}
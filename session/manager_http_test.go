@@ -567,3 +567,178 @@ func TestDoesSessionSatisfy(t *testing.T) {
 		})
 	}
 }
+
+func TestTrustedDevice(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/fake-session.schema.json")
+	conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+session.TrustedDeviceMethod+".enabled", true)
+
+	id := createAAL2Identity(t, reg)
+	require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, id))
+	other := createAAL2Identity(t, reg)
+	require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, other))
+
+	sess, err := session.NewActiveSession(ctx, id, conf, time.Now(), identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
+	require.NoError(t, err)
+
+	rp := x.NewRouterPublic()
+	rp.GET("/device/trust/:id", func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		require.NoError(t, reg.SessionManager().IssueTrustedDeviceCookie(r.Context(), w, r, x.ParseUUID(ps.ByName("id"))))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	rp.GET("/device/check", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		if err := reg.SessionManager().DoesSessionSatisfy(r, sess, config.HighestAvailableAAL); err != nil {
+			reg.Writer().WriteError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ts := httptest.NewServer(rp)
+	t.Cleanup(ts.Close)
+	conf.MustSet(ctx, config.ViperKeyPublicBaseURL, ts.URL)
+
+	trust := func(t *testing.T, c *http.Client, i *identity.Identity) {
+		res, err := c.Get(ts.URL + "/device/trust/" + i.ID.String())
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.EqualValues(t, http.StatusNoContent, res.StatusCode)
+	}
+
+	check := func(t *testing.T, c *http.Client, expected int) {
+		res, err := c.Get(ts.URL + "/device/check")
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.EqualValues(t, expected, res.StatusCode)
+	}
+
+	t.Run("case=second factor is required without a trusted device", func(t *testing.T) {
+		check(t, testhelpers.NewClientWithCookies(t), http.StatusForbidden)
+	})
+
+	t.Run("case=second factor is not required on a trusted device", func(t *testing.T) {
+		c := testhelpers.NewClientWithCookies(t)
+		trust(t, c, id)
+		check(t, c, http.StatusNoContent)
+
+		devices, err := reg.SessionPersister().ListTrustedDevicesByIdentity(ctx, id.ID)
+		require.NoError(t, err)
+		require.Len(t, devices, 1)
+		assert.Equal(t, "Go-http-client/1.1", devices[0].UserAgent)
+		assert.WithinDuration(t, time.Now().Add(conf.TrustedDeviceLifespan(ctx)), devices[0].ExpiresAt, time.Minute)
+
+		t.Run("case=second factor is required when disabled", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+session.TrustedDeviceMethod+".enabled", false)
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+session.TrustedDeviceMethod+".enabled", true)
+			})
+			check(t, c, http.StatusForbidden)
+		})
+
+		t.Run("case=second factor is required after the device was revoked", func(t *testing.T) {
+			require.NoError(t, reg.SessionPersister().DeleteTrustedDevice(ctx, id.ID, devices[0].ID))
+			check(t, c, http.StatusForbidden)
+		})
+	})
+
+	t.Run("case=second factor is required if another identity trusts the device", func(t *testing.T) {
+		c := testhelpers.NewClientWithCookies(t)
+		trust(t, c, other)
+		check(t, c, http.StatusForbidden)
+	})
+
+	t.Run("case=second factor is required if the device expired", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyTrustedDeviceLifespan, "1ms")
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeyTrustedDeviceLifespan, "720h")
+		})
+
+		c := testhelpers.NewClientWithCookies(t)
+		trust(t, c, id)
+		time.Sleep(time.Millisecond * 10)
+		check(t, c, http.StatusForbidden)
+	})
+}
+
+func TestRequiresMFAEnrollment(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/identity.schema.json")
+
+	withoutMFA := createAAL1Identity(t, reg)
+	withoutMFA.Traits = identity.Traits(`{"mfa_required":true}`)
+	require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, withoutMFA))
+
+	withMFA := createAAL2Identity(t, reg)
+	withMFA.Traits = identity.Traits(`{"mfa_required":true}`)
+	require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, withMFA))
+
+	withPasswordless := createAAL1Identity(t, reg)
+	withPasswordless.Traits = identity.Traits(`{"mfa_required":true}`)
+	withPasswordless.SetCredentials(identity.CredentialsTypeWebAuthn, identity.Credentials{Type: identity.CredentialsTypeWebAuthn, Config: []byte(`{"credentials":[{"is_passwordless":true}]}`), Identifiers: []string{testhelpers.RandomEmail()}})
+	require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, withPasswordless))
+
+	for k, tc := range []struct {
+		d        string
+		enabled  bool
+		schemas  []string
+		trait    string
+		i        *identity.Identity
+		expected bool
+	}{
+		{d: "disabled", i: withoutMFA, expected: false},
+		{d: "without second factor", enabled: true, i: withoutMFA, expected: true},
+		{d: "with second factor", enabled: true, i: withMFA, expected: false},
+		{d: "with passwordless security key", enabled: true, i: withPasswordless, expected: false},
+		{d: "matching schema", enabled: true, schemas: []string{"default"}, i: withoutMFA, expected: true},
+		{d: "other schema", enabled: true, schemas: []string{"employee"}, i: withoutMFA, expected: false},
+		{d: "matching trait", enabled: true, trait: "mfa_required", i: withoutMFA, expected: true},
+		{d: "missing trait", enabled: true, trait: "is_admin", i: withoutMFA, expected: false},
+	} {
+		t.Run(fmt.Sprintf("case=%d/%s", k, tc.d), func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentEnabled, tc.enabled)
+			conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentSchemas, tc.schemas)
+			conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentTrait, tc.trait)
+
+			// Credentials are loaded from the store if the identity does not contain them.
+			actual, err := reg.SessionManager().RequiresMFAEnrollment(ctx, tc.i.CopyWithoutCredentials())
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestRefreshMFAEnrollment(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/identity.schema.json")
+
+	i := createAAL1Identity(t, reg)
+	require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+
+	sessions := make([]*session.Session, 2)
+	for k := range sessions {
+		s, err := session.NewActiveSession(ctx, i, conf, time.Now().UTC(), identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
+		require.NoError(t, err)
+		require.NoError(t, reg.SessionPersister().UpsertSession(ctx, s))
+		sessions[k] = s
+	}
+
+	check := func(t *testing.T, expected bool) {
+		for _, s := range sessions {
+			actual, err := reg.SessionPersister().GetSession(ctx, s.ID)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual.MFAEnrollmentRequired)
+		}
+	}
+
+	conf.MustSet(ctx, config.ViperKeySelfServiceSettingsMFAEnrollmentEnabled, true)
+	require.NoError(t, reg.SessionManager().RefreshMFAEnrollment(ctx, i))
+	check(t, true)
+
+	i.SetCredentials(identity.CredentialsTypeWebAuthn, identity.Credentials{Type: identity.CredentialsTypeWebAuthn, Config: []byte(`{"credentials":[{"is_passwordless":false}]}`), Identifiers: []string{testhelpers.RandomEmail()}})
+	require.NoError(t, reg.PrivilegedIdentityPool().UpdateIdentity(ctx, i))
+	require.NoError(t, reg.SessionManager().RefreshMFAEnrollment(ctx, i))
+	check(t, false)
+}
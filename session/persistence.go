@@ -58,6 +58,46 @@ type Persister interface {
 
 	// RevokeSessionsIdentityExcept marks all except the given session of an identity inactive. It returns the number of sessions that were revoked.
 	RevokeSessionsIdentityExcept(ctx context.Context, iID, sID uuid.UUID) (int, error)
+
+	// RevokeSessionsIdentityAboveAAL marks all sessions of an identity inactive which were authenticated with a higher
+	// assurance level than the given one. It returns the number of sessions that were revoked.
+	RevokeSessionsIdentityAboveAAL(ctx context.Context, iID uuid.UUID, aal identity.AuthenticatorAssuranceLevel) (int, error)
+
+	// SetMFAEnrollmentRequiredByIdentity updates whether the sessions of an identity require it to set up a second factor.
+	SetMFAEnrollmentRequiredByIdentity(ctx context.Context, iID uuid.UUID, required bool) error
+
+	// CreateTrustedDevice stores a trusted device.
+	CreateTrustedDevice(ctx context.Context, d *TrustedDevice) error
+
+	// GetTrustedDevice retrieves a trusted device of an identity from the store.
+	GetTrustedDevice(ctx context.Context, iID, id uuid.UUID) (*TrustedDevice, error)
+
+	// ListTrustedDevicesByIdentity retrieves the trusted devices of an identity which have not yet expired.
+	ListTrustedDevicesByIdentity(ctx context.Context, iID uuid.UUID) ([]TrustedDevice, error)
+
+	// DeleteTrustedDevice revokes a trusted device of an identity.
+	DeleteTrustedDevice(ctx context.Context, iID, id uuid.UUID) error
+
+	// DeleteTrustedDevicesByIdentity revokes all trusted devices of an identity.
+	DeleteTrustedDevicesByIdentity(ctx context.Context, iID uuid.UUID) error
+
+	// DeleteExpiredTrustedDevices deletes trusted devices which expired before the given time.
+	DeleteExpiredTrustedDevices(ctx context.Context, expiresAt time.Time, limit int) error
+
+	// CreateSeenDevice stores a device which an identity signed in with.
+	CreateSeenDevice(ctx context.Context, d *SeenDevice) error
+
+	// GetSeenDevice retrieves the unexpired seen device of an identity which matches the ID of the seen device cookie.
+	GetSeenDevice(ctx context.Context, iID uuid.UUID, device string) (*SeenDevice, error)
+
+	// HasSeenDevices returns true if the identity signed in with at least one unexpired device.
+	HasSeenDevices(ctx context.Context, iID uuid.UUID) (bool, error)
+
+	// ExtendSeenDevice updates the time at which the seen device expires.
+	ExtendSeenDevice(ctx context.Context, id uuid.UUID, expiresAt time.Time) error
+
+	// DeleteExpiredSeenDevices deletes seen devices which expired before the given time.
+	DeleteExpiredSeenDevices(ctx context.Context, expiresAt time.Time, limit int) error
 }
 
 func TestPersister(ctx context.Context, conf *config.Config, p interface {
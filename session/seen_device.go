@@ -0,0 +1,58 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// SeenDeviceLifespan defines for how long a device is remembered after it was last used to sign in.
+	SeenDeviceLifespan = time.Hour * 24 * 180
+
+	// SeenDeviceCookieName is the name of the cookie which identifies a browser as a seen device.
+	SeenDeviceCookieName = "ory_kratos_seen_device"
+)
+
+// SeenDevice is a browser which an identity signed in with before. It is used to tell
+// sign-ins from known devices apart from sign-ins from devices which were not seen before.
+type SeenDevice struct {
+	// ID is the seen device's unique ID.
+	ID uuid.UUID `json:"id" faker:"-" db:"id"`
+
+	// IdentityID is the ID of the identity which signed in with this device.
+	IdentityID uuid.UUID `json:"identity_id" faker:"-" db:"identity_id"`
+
+	// UserAgent is the user agent of the device when it was first seen. It is only used to describe
+	// the device to the identity and never to identify it.
+	UserAgent string `json:"user_agent" db:"user_agent"`
+
+	// Device is the ID of the browser, as stored in its signed seen device cookie.
+	Device string `json:"device" db:"device"`
+
+	// ExpiresAt is the time at which the device is forgotten unless it is used to sign in again.
+	ExpiresAt time.Time `json:"expires_at" faker:"time_type" db:"expires_at"`
+
+	// CreatedAt is the time at which the device was first seen.
+	CreatedAt time.Time `json:"created_at" faker:"-" db:"created_at"`
+
+	// UpdatedAt is a helper struct field for gobuffalo.pop.
+	UpdatedAt time.Time `json:"-" faker:"-" db:"updated_at"`
+
+	NID uuid.UUID `json:"-"  faker:"-" db:"nid"`
+}
+
+func (d SeenDevice) TableName(ctx context.Context) string {
+	return "session_seen_devices"
+}
+
+// NewSeenDevice creates a new seen device for the given identity which is remembered for the SeenDeviceLifespan.
+func NewSeenDevice(identityID uuid.UUID, device, userAgent string) *SeenDevice {
+	return &SeenDevice{
+		IdentityID: identityID,
+		UserAgent:  userAgent,
+		Device:     device,
+		ExpiresAt:  time.Now().UTC().Add(SeenDeviceLifespan),
+	}
+}
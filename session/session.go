@@ -76,6 +76,10 @@ type Session struct {
 	// Use this token to log out a user.
 	LogoutToken string `json:"-" db:"logout_token"`
 
+	// MFAEnrollmentRequired is true if the identity must set up a second factor before this session can be
+	// used anywhere but in the settings flow.
+	MFAEnrollmentRequired bool `json:"-" faker:"-" db:"mfa_enrollment_required"`
+
 	// required: true
 	Identity *identity.Identity `json:"identity" faker:"identity" db:"-" belongs_to:"identities" fk_id:"IdentityID"`
 
@@ -100,7 +104,13 @@ func (s Session) TableName(ctx context.Context) string {
 }
 
 func (s *Session) CompletedLoginFor(method identity.CredentialsType, aal identity.AuthenticatorAssuranceLevel) {
-	s.AMR = append(s.AMR, AuthenticationMethod{Method: method, AAL: aal, CompletedAt: time.Now().UTC()})
+	s.CompletedLoginForWithProvider(method, aal, "")
+}
+
+// CompletedLoginForWithProvider records a completed authentication method which was performed at the given
+// provider, for example an OpenID Connect provider.
+func (s *Session) CompletedLoginForWithProvider(method identity.CredentialsType, aal identity.AuthenticatorAssuranceLevel, provider string) {
+	s.AMR = append(s.AMR, AuthenticationMethod{Method: method, AAL: aal, Provider: provider, CompletedAt: time.Now().UTC()})
 }
 
 func (s *Session) SetAuthenticatorAssuranceLevel() {
@@ -226,6 +236,9 @@ type AuthenticationMethod struct {
 	// The AAL this method introduced.
 	AAL identity.AuthenticatorAssuranceLevel `json:"aal"`
 
+	// The provider the method was completed at, e.g. the ID of the OpenID Connect provider.
+	Provider string `json:"provider,omitempty"`
+
 	// When the authentication challenge was completed.
 	CompletedAt time.Time `json:"completed_at"`
 }
@@ -260,6 +260,41 @@ func TestPersister(ctx context.Context, conf *config.Config, p interface {
 			assert.False(t, actual.Active)
 		})
 
+		t.Run("method=revoke sessions above assurance level", func(t *testing.T) {
+			var aal1, aal2 session.Session
+			require.NoError(t, faker.FakeData(&aal1))
+			aal1.Active, aal1.AuthenticatorAssuranceLevel = true, identity.AuthenticatorAssuranceLevel1
+			require.NoError(t, p.CreateIdentity(ctx, aal1.Identity))
+			require.NoError(t, p.UpsertSession(ctx, &aal1))
+
+			require.NoError(t, faker.FakeData(&aal2))
+			aal2.Identity, aal2.IdentityID = aal1.Identity, aal1.IdentityID
+			aal2.Active, aal2.AuthenticatorAssuranceLevel = true, identity.AuthenticatorAssuranceLevel2
+			require.NoError(t, p.UpsertSession(ctx, &aal2))
+
+			count, err := p.RevokeSessionsIdentityAboveAAL(ctx, aal1.IdentityID, identity.AuthenticatorAssuranceLevel1)
+			require.NoError(t, err)
+			assert.Equal(t, 1, count)
+
+			actual, err := p.GetSession(ctx, aal1.ID)
+			require.NoError(t, err)
+			assert.True(t, actual.Active)
+
+			actual, err = p.GetSession(ctx, aal2.ID)
+			require.NoError(t, err)
+			assert.False(t, actual.Active)
+
+			t.Run("on another network", func(t *testing.T) {
+				_, other := testhelpers.NewNetwork(t, ctx, p)
+				aal2.Active = true
+				require.NoError(t, p.UpsertSession(ctx, &aal2))
+
+				count, err := other.RevokeSessionsIdentityAboveAAL(ctx, aal1.IdentityID, identity.AuthenticatorAssuranceLevel1)
+				require.NoError(t, err)
+				assert.Equal(t, 0, count)
+			})
+		})
+
 		t.Run("method=revoke other sessions for identity", func(t *testing.T) {
 			// here we set up 2 identities with each having 2 sessions
 			sessions := make([]session.Session, 4)
@@ -382,6 +417,168 @@ func TestPersister(ctx context.Context, conf *config.Config, p interface {
 			require.Error(t, err)
 		})
 
+		t.Run("case=trusted devices", func(t *testing.T) {
+			var s session.Session
+			require.NoError(t, faker.FakeData(&s))
+			require.NoError(t, p.CreateIdentity(ctx, s.Identity))
+			iID := s.Identity.ID
+
+			expected := session.NewTrustedDevice(iID, "Mozilla/5.0", time.Hour)
+			require.NoError(t, p.CreateTrustedDevice(ctx, expected))
+			assert.NotEqual(t, uuid.Nil, expected.ID)
+
+			expired := session.NewTrustedDevice(iID, "curl/7.79.1", -time.Hour)
+			require.NoError(t, p.CreateTrustedDevice(ctx, expired))
+
+			t.Run("method=get", func(t *testing.T) {
+				actual, err := p.GetTrustedDevice(ctx, iID, expected.ID)
+				require.NoError(t, err)
+				assert.Equal(t, expected.ID, actual.ID)
+				assert.Equal(t, iID, actual.IdentityID)
+				assert.Equal(t, "Mozilla/5.0", actual.UserAgent)
+				assert.True(t, actual.IsActive())
+
+				_, err = p.GetTrustedDevice(ctx, x.NewUUID(), expected.ID)
+				assert.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+
+			t.Run("method=list skips expired devices", func(t *testing.T) {
+				actual, err := p.ListTrustedDevicesByIdentity(ctx, iID)
+				require.NoError(t, err)
+				require.Len(t, actual, 1)
+				assert.Equal(t, expected.ID, actual[0].ID)
+			})
+
+			t.Run("on another network", func(t *testing.T) {
+				_, other := testhelpers.NewNetwork(t, ctx, p)
+				_, err := other.GetTrustedDevice(ctx, iID, expected.ID)
+				assert.ErrorIs(t, err, sqlcon.ErrNoRows)
+
+				actual, err := other.ListTrustedDevicesByIdentity(ctx, iID)
+				require.NoError(t, err)
+				assert.Len(t, actual, 0)
+
+				assert.ErrorIs(t, other.DeleteTrustedDevice(ctx, iID, expected.ID), sqlcon.ErrNoRows)
+				require.NoError(t, other.DeleteTrustedDevicesByIdentity(ctx, iID))
+
+				_, err = p.GetTrustedDevice(ctx, iID, expected.ID)
+				require.NoError(t, err)
+			})
+
+			t.Run("method=delete", func(t *testing.T) {
+				require.NoError(t, p.DeleteTrustedDevice(ctx, iID, expected.ID))
+				assert.ErrorIs(t, p.DeleteTrustedDevice(ctx, iID, expected.ID), sqlcon.ErrNoRows)
+
+				_, err := p.GetTrustedDevice(ctx, iID, expected.ID)
+				assert.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+
+			t.Run("method=delete expired", func(t *testing.T) {
+				active := session.NewTrustedDevice(iID, "Mozilla/5.0", time.Hour)
+				require.NoError(t, p.CreateTrustedDevice(ctx, active))
+
+				_, other := testhelpers.NewNetwork(t, ctx, p)
+				require.NoError(t, other.DeleteExpiredTrustedDevices(ctx, time.Now(), 100))
+				_, err := p.GetTrustedDevice(ctx, iID, expired.ID)
+				require.NoError(t, err)
+
+				require.NoError(t, p.DeleteExpiredTrustedDevices(ctx, time.Now(), 100))
+				_, err = p.GetTrustedDevice(ctx, iID, expired.ID)
+				assert.ErrorIs(t, err, sqlcon.ErrNoRows)
+				_, err = p.GetTrustedDevice(ctx, iID, active.ID)
+				require.NoError(t, err)
+			})
+
+			t.Run("method=delete by identity", func(t *testing.T) {
+				require.NoError(t, p.DeleteTrustedDevicesByIdentity(ctx, iID))
+
+				actual, err := p.ListTrustedDevicesByIdentity(ctx, iID)
+				require.NoError(t, err)
+				assert.Len(t, actual, 0)
+			})
+
+			t.Run("method=delete sessions by identity", func(t *testing.T) {
+				d := session.NewTrustedDevice(iID, "Mozilla/5.0", time.Hour)
+				require.NoError(t, p.CreateTrustedDevice(ctx, d))
+
+				assert.ErrorIs(t, p.DeleteSessionsByIdentity(ctx, iID), sqlcon.ErrNoRows)
+				_, err := p.GetTrustedDevice(ctx, iID, d.ID)
+				assert.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+		})
+
+		t.Run("case=seen devices", func(t *testing.T) {
+			var s session.Session
+			require.NoError(t, faker.FakeData(&s))
+			require.NoError(t, p.CreateIdentity(ctx, s.Identity))
+			iID := s.Identity.ID
+
+			known, err := p.HasSeenDevices(ctx, iID)
+			require.NoError(t, err)
+			assert.False(t, known)
+
+			expected := session.NewSeenDevice(iID, x.NewUUID().String(), "Mozilla/5.0 (X11; Linux x86_64; rv:105.0) Gecko/20100101 Firefox/105.0")
+			require.NoError(t, p.CreateSeenDevice(ctx, expected))
+			assert.NotEqual(t, uuid.Nil, expected.ID)
+
+			expired := session.NewSeenDevice(iID, x.NewUUID().String(), "Mozilla/5.0 (X11; Linux x86_64; rv:105.0) Gecko/20100101 Firefox/105.0")
+			expired.ExpiresAt = time.Now().UTC().Add(-time.Minute)
+			require.NoError(t, p.CreateSeenDevice(ctx, expired))
+
+			t.Run("method=get", func(t *testing.T) {
+				actual, err := p.GetSeenDevice(ctx, iID, expected.Device)
+				require.NoError(t, err)
+				assert.Equal(t, expected.ID, actual.ID)
+				assert.Equal(t, expected.UserAgent, actual.UserAgent)
+
+				_, err = p.GetSeenDevice(ctx, iID, x.NewUUID().String())
+				assert.ErrorIs(t, err, sqlcon.ErrNoRows)
+
+				_, err = p.GetSeenDevice(ctx, iID, expired.Device)
+				assert.ErrorIs(t, err, sqlcon.ErrNoRows)
+
+				known, err := p.HasSeenDevices(ctx, iID)
+				require.NoError(t, err)
+				assert.True(t, known)
+			})
+
+			t.Run("method=extend", func(t *testing.T) {
+				require.NoError(t, p.ExtendSeenDevice(ctx, expired.ID, time.Now().UTC().Add(time.Hour)))
+				_, err := p.GetSeenDevice(ctx, iID, expired.Device)
+				require.NoError(t, err)
+
+				require.NoError(t, p.ExtendSeenDevice(ctx, expired.ID, time.Now().UTC().Add(-time.Minute)))
+				assert.ErrorIs(t, p.ExtendSeenDevice(ctx, x.NewUUID(), time.Now()), sqlcon.ErrNoRows)
+			})
+
+			t.Run("on another network", func(t *testing.T) {
+				_, other := testhelpers.NewNetwork(t, ctx, p)
+				_, err := other.GetSeenDevice(ctx, iID, expected.Device)
+				assert.ErrorIs(t, err, sqlcon.ErrNoRows)
+
+				known, err := other.HasSeenDevices(ctx, iID)
+				require.NoError(t, err)
+				assert.False(t, known)
+
+				assert.ErrorIs(t, other.ExtendSeenDevice(ctx, expected.ID, time.Now()), sqlcon.ErrNoRows)
+
+				require.NoError(t, other.DeleteExpiredSeenDevices(ctx, time.Now(), 100))
+				count, err := p.GetConnection(ctx).Where("id = ?", expired.ID).Count(new(session.SeenDevice))
+				require.NoError(t, err)
+				assert.Equal(t, 1, count)
+			})
+
+			t.Run("method=delete expired", func(t *testing.T) {
+				require.NoError(t, p.DeleteExpiredSeenDevices(ctx, time.Now(), 100))
+				count, err := p.GetConnection(ctx).Where("id = ?", expired.ID).Count(new(session.SeenDevice))
+				require.NoError(t, err)
+				assert.Equal(t, 0, count)
+
+				_, err = p.GetSeenDevice(ctx, iID, expected.Device)
+				require.NoError(t, err)
+			})
+		})
+
 		t.Run("network isolation", func(t *testing.T) {
 			nid1, p := testhelpers.NewNetwork(t, ctx, p)
 			nid2, _ := testhelpers.NewNetwork(t, ctx, p)
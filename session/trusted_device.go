@@ -0,0 +1,71 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// TrustedDeviceMethod is the ID of the self-service method which manages trusted devices.
+	TrustedDeviceMethod = "trusted_device"
+
+	// TrustedDeviceCookieName is the name of the cookie which identifies a trusted device.
+	TrustedDeviceCookieName = "ory_kratos_trusted_device"
+)
+
+// A Trusted Device
+//
+// A trusted device is a browser on which the identity completed the second factor and
+// chose to remember the device. Until the trusted device expires or is revoked, the
+// second factor is not required again on that browser.
+//
+// swagger:model trustedDevice
+type TrustedDevice struct {
+	// Trusted Device ID
+	//
+	// required: true
+	ID uuid.UUID `json:"id" faker:"-" db:"id"`
+
+	// IdentityID is the ID of the identity which trusts this device.
+	//
+	// required: true
+	IdentityID uuid.UUID `json:"identity_id" faker:"-" db:"identity_id"`
+
+	// UserAgent is the user agent of the browser at the time it was trusted.
+	UserAgent string `json:"user_agent" db:"user_agent"`
+
+	// ExpiresAt is the time at which the device is no longer trusted.
+	//
+	// required: true
+	ExpiresAt time.Time `json:"expires_at" faker:"time_type" db:"expires_at"`
+
+	// CreatedAt is the time at which the device was trusted.
+	//
+	// required: true
+	CreatedAt time.Time `json:"created_at" faker:"-" db:"created_at"`
+
+	// UpdatedAt is a helper struct field for gobuffalo.pop.
+	UpdatedAt time.Time `json:"-" faker:"-" db:"updated_at"`
+
+	NID uuid.UUID `json:"-"  faker:"-" db:"nid"`
+}
+
+func (d TrustedDevice) TableName(ctx context.Context) string {
+	return "session_trusted_devices"
+}
+
+// NewTrustedDevice creates a new trusted device for the given identity which expires after the lifespan.
+func NewTrustedDevice(identityID uuid.UUID, userAgent string, lifespan time.Duration) *TrustedDevice {
+	return &TrustedDevice{
+		IdentityID: identityID,
+		UserAgent:  userAgent,
+		ExpiresAt:  time.Now().UTC().Add(lifespan),
+	}
+}
+
+// IsActive returns true if the device is still trusted.
+func (d *TrustedDevice) IsActive() bool {
+	return d.ExpiresAt.After(time.Now())
+}
@@ -20,6 +20,8 @@ const (
 	InfoSelfServiceLoginContinueWebAuthn                         // 1010011
 	InfoSelfServiceLoginWebAuthnPasswordless                     // 1010012
 	InfoSelfServiceLoginContinue                                 // 1010013
+	InfoSelfServiceLoginLinkCredentials                          // 1010014
+	InfoSelfServiceLoginTrustDevice                              // 1010015
 )
 
 const (
@@ -36,6 +38,7 @@ const (
 	InfoSelfServiceRegistrationWith                                 // 1040002
 	InfoSelfServiceRegistrationContinue                             // 1040003
 	InfoSelfServiceRegistrationRegisterWebAuthn                     // 1040004
+	InfoSelfServiceRegistrationPendingApproval                      // 1040005
 )
 
 const (
@@ -58,6 +61,14 @@ const (
 	InfoSelfServiceSettingsDisableLookup
 	InfoSelfServiceSettingsTOTPSecretLabel
 	InfoSelfServiceSettingsRemoveWebAuthn
+	InfoSelfServiceSettingsPasswordExpired
+	InfoSelfServiceSettingsCredentialsResetRequired
+	InfoSelfServiceSettingsRevokeTrustedDevice
+	InfoSelfServiceSettingsMFAEnrollmentRequired
+	InfoSelfServiceSettingsAccountDeletionConfirm
+	InfoSelfServiceSettingsAccountDeletion
+	InfoSelfServiceSettingsAddressChangePending
+	InfoSelfServiceSettingsInvitationAccepted
 )
 
 const (
@@ -67,20 +78,24 @@ const (
 )
 
 const (
-	InfoNodeLabel              ID = 1070000 + iota // 1070000
-	InfoNodeLabelInputPassword                     // 1070001
-	InfoNodeLabelGenerated                         // 1070002
-	InfoNodeLabelSave                              // 1070003
-	InfoNodeLabelID                                // 1070004
-	InfoNodeLabelSubmit                            // 1070005
-	InfoNodeLabelVerifyOTP                         // 1070006
-	InfoNodeLabelEmail                             // 1070007
+	InfoNodeLabel                    ID = 1070000 + iota // 1070000
+	InfoNodeLabelInputPassword                           // 1070001
+	InfoNodeLabelGenerated                               // 1070002
+	InfoNodeLabelSave                                    // 1070003
+	InfoNodeLabelID                                      // 1070004
+	InfoNodeLabelSubmit                                  // 1070005
+	InfoNodeLabelVerifyOTP                               // 1070006
+	InfoNodeLabelEmail                                   // 1070007
+	InfoNodeLabelRevertAddressChange                     // 1070008
+	InfoNodeLabelAcceptInvitation                        // 1070009
 )
 
 const (
-	InfoSelfServiceVerification           ID = 1080000 + iota // 1080000
-	InfoSelfServiceVerificationEmailSent                      // 1080001
-	InfoSelfServiceVerificationSuccessful                     // 1080002
+	InfoSelfServiceVerification                    ID = 1080000 + iota // 1080000
+	InfoSelfServiceVerificationEmailSent                               // 1080001
+	InfoSelfServiceVerificationSuccessful                              // 1080002
+	InfoSelfServiceVerificationRevertAddressChange                     // 1080003
+	InfoSelfServiceVerificationAcceptInvitation                        // 1080004
 )
 
 const (
@@ -101,6 +116,13 @@ const (
 	ErrorValidationNoLookup
 	ErrorValidationSuchNoWebAuthnUser
 	ErrorValidationLookupInvalid
+	ErrorValidationPasswordMaxLength
+	ErrorValidationPasswordCharacterClasses
+	ErrorValidationPasswordReused
+	ErrorValidationNoLoginMethod
+	ErrorValidationWebAuthnAuthenticatorNotAllowed
+	ErrorValidationAccountDeletionNotConfirmed
+	ErrorValidationTooManyAddressChanges
 )
 
 const (
@@ -13,6 +13,8 @@ const (
 	ErrIDSessionHasAALAlready        = "session_aal_already_fulfilled"
 	ErrIDSessionRequiredForHigherAAL = "session_aal1_required"
 	ErrIDHigherAALRequired           = "session_aal2_required"
+	ErrIDCredentialsResetRequired    = "session_credentials_reset_required"
+	ErrIDMFAEnrollmentRequired       = "session_mfa_enrollment_required"
 	ErrNoActiveSession               = "session_inactive"
 	ErrIDRedirectURLNotAllowed       = "self_service_flow_return_to_forbidden"
 	ErrIDInitiatedBySomeoneElse      = "security_identity_mismatch"
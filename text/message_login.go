@@ -106,6 +106,18 @@ func NewInfoLoginWith(provider string) *Message {
 	}
 }
 
+func NewInfoLoginLinkCredentials(provider, identifier string) *Message {
+	return &Message{
+		ID:   InfoSelfServiceLoginLinkCredentials,
+		Text: fmt.Sprintf("An account with the email address %s exists already. Sign in to this account to link it with %s.", identifier, provider),
+		Type: Info,
+		Context: context(map[string]interface{}{
+			"provider":   provider,
+			"identifier": identifier,
+		}),
+	}
+}
+
 func NewErrorValidationLoginFlowExpired(ago time.Duration) *Message {
 	return &Message{
 		ID:   ErrorValidationLoginFlowExpired,
@@ -180,3 +192,19 @@ func NewInfoSelfServiceLoginContinue() *Message {
 		Type: Info,
 	}
 }
+
+func NewInfoLoginTrustDevice(lifespan time.Duration) *Message {
+	text := fmt.Sprintf("Remember this device for %d days", int(lifespan.Hours()/24))
+	if lifespan < 24*time.Hour {
+		text = fmt.Sprintf("Remember this device for %d hours", int(lifespan.Hours()))
+	}
+
+	return &Message{
+		ID:   InfoSelfServiceLoginTrustDevice,
+		Text: text,
+		Type: Info,
+		Context: context(map[string]interface{}{
+			"lifespan": lifespan.String(),
+		}),
+	}
+}
@@ -48,6 +48,22 @@ func NewInfoNodeLabelID() *Message {
 	}
 }
 
+func NewInfoNodeLabelRevertAddressChange() *Message {
+	return &Message{
+		ID:   InfoNodeLabelRevertAddressChange,
+		Text: "Revert address change",
+		Type: Info,
+	}
+}
+
+func NewInfoNodeLabelAcceptInvitation() *Message {
+	return &Message{
+		ID:   InfoNodeLabelAcceptInvitation,
+		Text: "Accept invitation",
+		Type: Info,
+	}
+}
+
 func NewInfoNodeInputEmail() *Message {
 	return &Message{
 		ID:   InfoNodeLabelEmail,
@@ -51,3 +51,11 @@ func NewInfoSelfServiceRegistrationRegisterWebAuthn() *Message {
 		Type: Info,
 	}
 }
+
+func NewInfoSelfServiceRegistrationPendingApproval() *Message {
+	return &Message{
+		ID:   InfoSelfServiceRegistrationPendingApproval,
+		Text: "Your account has been created and is awaiting approval by an administrator.",
+		Type: Info,
+	}
+}
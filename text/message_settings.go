@@ -180,3 +180,83 @@ func NewInfoSelfServiceRemoveWebAuthn(name string, createdAt time.Time) *Message
 		}),
 	}
 }
+
+func NewInfoSelfServiceRevokeTrustedDevice(userAgent string, createdAt, expiresAt time.Time) *Message {
+	return &Message{
+		ID:   InfoSelfServiceSettingsRevokeTrustedDevice,
+		Text: fmt.Sprintf("Revoke trusted device \"%s\"", userAgent),
+		Type: Info,
+		Context: context(map[string]interface{}{
+			"user_agent": userAgent,
+			"added_at":   createdAt,
+			"expires_at": expiresAt,
+		}),
+	}
+}
+
+func NewInfoSelfServiceSettingsPasswordExpired() *Message {
+	return &Message{
+		ID:   InfoSelfServiceSettingsPasswordExpired,
+		Text: "Your password has expired. Please choose a new password.",
+		Type: Info,
+	}
+}
+
+func NewInfoSelfServiceSettingsCredentialsResetRequired(reset string) *Message {
+	what := "password"
+	if reset == "second_factor" {
+		what = "second factor"
+	}
+
+	return &Message{
+		ID:   InfoSelfServiceSettingsCredentialsResetRequired,
+		Text: fmt.Sprintf("You are required to set up a new %s before you can continue.", what),
+		Type: Info,
+		Context: context(map[string]interface{}{
+			"reset": reset,
+		}),
+	}
+}
+
+func NewInfoSelfServiceSettingsMFAEnrollmentRequired() *Message {
+	return &Message{
+		ID:   InfoSelfServiceSettingsMFAEnrollmentRequired,
+		Text: "You are required to set up a second factor before you can continue.",
+		Type: Info,
+	}
+}
+
+func NewInfoSelfServiceSettingsAccountDeletionConfirm() *Message {
+	return &Message{
+		ID:   InfoSelfServiceSettingsAccountDeletionConfirm,
+		Text: "I understand that my account and all data associated with it will be deleted permanently.",
+		Type: Info,
+	}
+}
+
+func NewInfoSelfServiceSettingsAccountDeletion() *Message {
+	return &Message{
+		ID:   InfoSelfServiceSettingsAccountDeletion,
+		Text: "Delete account",
+		Type: Info,
+	}
+}
+
+func NewInfoSelfServiceSettingsAddressChangePending(address string) *Message {
+	return &Message{
+		ID:   InfoSelfServiceSettingsAddressChangePending,
+		Text: fmt.Sprintf("A confirmation link has been sent to %s. Your changes are applied once the address has been confirmed.", address),
+		Type: Info,
+		Context: context(map[string]interface{}{
+			"address": address,
+		}),
+	}
+}
+
+func NewInfoSelfServiceSettingsInvitationAccepted() *Message {
+	return &Message{
+		ID:   InfoSelfServiceSettingsInvitationAccepted,
+		Text: "Welcome! Please set up your credentials to finish accepting the invitation.",
+		Type: Info,
+	}
+}
@@ -2,6 +2,7 @@ package text
 
 import (
 	"fmt"
+	"strings"
 )
 
 func NewValidationErrorGeneric(reason string) *Message {
@@ -59,6 +60,40 @@ func NewErrorValidationPasswordPolicyViolation(reason string) *Message {
 	}
 }
 
+func NewErrorValidationPasswordMaxLength(expected, actual int) *Message {
+	return &Message{
+		ID:   ErrorValidationPasswordMaxLength,
+		Text: fmt.Sprintf("The password must be at most %d characters long, but got %d.", expected, actual),
+		Type: Error,
+		Context: context(map[string]interface{}{
+			"max_length":    expected,
+			"actual_length": actual,
+		}),
+	}
+}
+
+func NewErrorValidationPasswordCharacterClasses(missing []string) *Message {
+	return &Message{
+		ID:   ErrorValidationPasswordCharacterClasses,
+		Text: fmt.Sprintf("The password must contain at least one character of each of the following types: %s.", strings.Join(missing, ", ")),
+		Type: Error,
+		Context: context(map[string]interface{}{
+			"missing_character_classes": missing,
+		}),
+	}
+}
+
+func NewErrorValidationPasswordReused(historySize int) *Message {
+	return &Message{
+		ID:   ErrorValidationPasswordReused,
+		Text: fmt.Sprintf("The password has been used before. Please choose a password which is different from your last %d passwords.", historySize),
+		Type: Error,
+		Context: context(map[string]interface{}{
+			"history_size": historySize,
+		}),
+	}
+}
+
 func NewErrorValidationInvalidCredentials() *Message {
 	return &Message{
 		ID:      ErrorValidationInvalidCredentials,
@@ -155,3 +190,39 @@ func NewErrorValidationSuchNoWebAuthnUser() *Message {
 		Context: context(nil),
 	}
 }
+
+func NewErrorValidationNoLoginMethod() *Message {
+	return &Message{
+		ID:      ErrorValidationNoLoginMethod,
+		Text:    "This account does not exist or has no login method set up.",
+		Type:    Error,
+		Context: context(nil),
+	}
+}
+
+func NewErrorValidationWebAuthnAuthenticatorNotAllowed() *Message {
+	return &Message{
+		ID:      ErrorValidationWebAuthnAuthenticatorNotAllowed,
+		Text:    "This security key can not be used. Please use a different security key.",
+		Type:    Error,
+		Context: context(nil),
+	}
+}
+
+func NewErrorValidationAccountDeletionNotConfirmed() *Message {
+	return &Message{
+		ID:      ErrorValidationAccountDeletionNotConfirmed,
+		Text:    "Please confirm that you want to delete your account.",
+		Type:    Error,
+		Context: context(nil),
+	}
+}
+
+func NewErrorValidationTooManyAddressChanges() *Message {
+	return &Message{
+		ID:      ErrorValidationTooManyAddressChanges,
+		Text:    "Please change only one email address at a time.",
+		Type:    Error,
+		Context: context(nil),
+	}
+}
@@ -24,6 +24,28 @@ func NewInfoSelfServiceVerificationSuccessful() *Message {
 	}
 }
 
+func NewInfoSelfServiceVerificationRevertAddressChange(address string) *Message {
+	return &Message{
+		ID:   InfoSelfServiceVerificationRevertAddressChange,
+		Type: Info,
+		Text: fmt.Sprintf("Please confirm that the change of your email address to %s should be reverted. You will be signed out on all devices.", address),
+		Context: context(map[string]interface{}{
+			"address": address,
+		}),
+	}
+}
+
+func NewInfoSelfServiceVerificationAcceptInvitation(address string) *Message {
+	return &Message{
+		ID:   InfoSelfServiceVerificationAcceptInvitation,
+		Type: Info,
+		Text: fmt.Sprintf("You were invited to create an account for %s. Please accept the invitation to sign in and set up your credentials.", address),
+		Context: context(map[string]interface{}{
+			"address": address,
+		}),
+	}
+}
+
 func NewVerificationEmailSent() *Message {
 	return &Message{
 		ID:      InfoSelfServiceVerificationEmailSent,
@@ -21,7 +21,17 @@ const (
 	WebAuthnRegister            = "webauthn_register"
 	WebAuthnLogin               = "webauthn_login"
 	WebAuthnLoginTrigger        = "webauthn_login_trigger"
+	WebAuthnLoginOptions        = "webauthn_login_options"
 	WebAuthnRegisterDisplayName = "webauthn_register_displayname"
 	WebAuthnRemove              = "webauthn_remove"
 	WebAuthnScript              = "webauthn_script"
 )
+
+const (
+	TrustDevice         = "trust_device"
+	TrustedDeviceRevoke = "trusted_device_revoke"
+)
+
+const (
+	AccountDeletionConfirm = "account_deletion_confirm"
+)
@@ -0,0 +1,101 @@
+package x
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCertificateAuthority issues certificates for tests.
+type TestCertificateAuthority struct {
+	Certificate *x509.Certificate
+	Key         *ecdsa.PrivateKey
+}
+
+// NewTestCertificateAuthority creates a self-signed certificate authority.
+func NewTestCertificateAuthority(t *testing.T) *TestCertificateAuthority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &TestCertificateAuthority{Certificate: cert, Key: key}
+}
+
+// Issue returns a leaf certificate signed by the certificate authority and its key.
+func (ca *TestCertificateAuthority) Issue(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "Test Leaf"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Certificate, &key.PublicKey, ca.Key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key
+}
+
+// WriteCertificate stores the PEM encoded certificate of the authority in a temporary file and returns its path.
+func (ca *TestCertificateAuthority) WriteCertificate(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "root.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Certificate.Raw}), 0600))
+	return path
+}
+
+// SignFIDOMetadataBlob signs the FIDO Metadata Service payload the same way the FIDO Alliance does and returns the
+// resulting JSON Web Token.
+func (ca *TestCertificateAuthority) SignFIDOMetadataBlob(t *testing.T, payload string) string {
+	var claims jwt.MapClaims
+	require.NoError(t, json.Unmarshal([]byte(payload), &claims))
+
+	cert, key := ca.Issue(t)
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["x5c"] = []string{base64.StdEncoding.EncodeToString(cert.Raw)}
+
+	blob, err := token.SignedString(key)
+	require.NoError(t, err)
+	return blob
+}
+
+// NewFIDOMetadataBlob signs the FIDO Metadata Service payload with a new certificate authority and returns the
+// paths of the stored blob and of the root certificate.
+func NewFIDOMetadataBlob(t *testing.T, payload string) (blobPath, rootPath string) {
+	ca := NewTestCertificateAuthority(t)
+	blobPath = filepath.Join(t.TempDir(), "mds.jwt")
+	require.NoError(t, os.WriteFile(blobPath, []byte(ca.SignFIDOMetadataBlob(t, payload)), 0600))
+	return blobPath, ca.WriteCertificate(t)
+}
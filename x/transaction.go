@@ -0,0 +1,15 @@
+package x
+
+import (
+	"context"
+
+	"github.com/gobuffalo/pop/v6"
+)
+
+type TransactionalPersister interface {
+	Transaction(ctx context.Context, callback func(ctx context.Context, connection *pop.Connection) error) error
+}
+
+type TransactionalPersisterProvider interface {
+	TransactionalPersisterProvider() TransactionalPersister
+}
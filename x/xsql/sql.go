@@ -17,6 +17,7 @@ import (
 	"github.com/ory/kratos/selfservice/flow/settings"
 	"github.com/ory/kratos/selfservice/flow/verification"
 	"github.com/ory/kratos/selfservice/strategy/link"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
 	"github.com/ory/kratos/session"
 )
 
@@ -26,6 +27,8 @@ func CleanSQL(t *testing.T, c *pop.Connection) {
 		new(continuity.Container).TableName(ctx),
 		new(courier.Message).TableName(ctx),
 
+		new(session.TrustedDevice).TableName(ctx),
+		new(session.SeenDevice).TableName(ctx),
 		new(session.Session).TableName(ctx),
 		new(login.Flow).TableName(ctx),
 		new(registration.Flow).TableName(ctx),
@@ -33,6 +36,10 @@ func CleanSQL(t *testing.T, c *pop.Connection) {
 
 		new(link.RecoveryToken).TableName(ctx),
 		new(link.VerificationToken).TableName(ctx),
+		new(link.AddressChange).TableName(ctx),
+		new(link.Invitation).TableName(ctx),
+
+		new(oidc.StoredConfiguration).TableName(ctx),
 
 		new(recovery.Flow).TableName(ctx),
 